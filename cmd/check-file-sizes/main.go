@@ -0,0 +1,69 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/das7pad/overleaf-go/cmd/check-file-sizes/pkg/checkFileSizes"
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/filestore/pkg/managers/filestore"
+	webTypes "github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func main() {
+	ctx, triggerExit := signal.NotifyContext(
+		context.Background(), syscall.SIGINT, syscall.SIGTERM,
+	)
+	defer triggerExit()
+
+	var concurrency int
+	flag.IntVar(&concurrency, "concurrency", 10, "number of parallel HEAD requests")
+	flag.Parse()
+	if concurrency < 1 {
+		_, _ = fmt.Fprintln(os.Stderr, "ERR: concurrency must be at least 1")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	o := webTypes.Options{}
+	o.FillFromEnv()
+	fm, err := filestore.New(o.APIs.Filestore)
+	if err != nil {
+		panic(errors.Tag(err, "init filestore"))
+	}
+	db := utils.MustConnectPostgres(ctx)
+
+	r, err := checkFileSizes.Run(ctx, db, fm, concurrency)
+	if err != nil {
+		panic(errors.Tag(err, "check file sizes"))
+	}
+	log.Printf(
+		"checked %d files: %d missing, %d size mismatch, %d stuck in pending",
+		r.Checked, r.Missing, r.SizeMismatch, r.StuckInPending,
+	)
+	if !r.OK() {
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,149 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkFileSizes
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/filestore/pkg/managers/filestore"
+)
+
+type Report struct {
+	Checked        int64
+	Missing        int64
+	SizeMismatch   int64
+	StuckInPending int64
+}
+
+func (r Report) OK() bool {
+	return r.Missing == 0 && r.SizeMismatch == 0 && r.StuckInPending == 0
+}
+
+type file struct {
+	projectId sharedTypes.UUID
+	fileId    sharedTypes.UUID
+	size      int64
+	pending   bool
+}
+
+// Run compares the size of all files in the db against the object storage.
+// Pending files may be missing, but a pending file with a complete blob
+// points to a failed finalization of the upload.
+func Run(ctx context.Context, db *pgxpool.Pool, fm filestore.Manager, concurrency int) (Report, error) {
+	r := Report{}
+	queue := make(chan file, concurrency)
+	results := make(chan func(*Report), concurrency)
+
+	eg, pCtx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		defer close(queue)
+		rows, err := db.Query(pCtx, `
+SELECT t.project_id, f.id, f.size, f.pending
+FROM files f
+         INNER JOIN tree_nodes t ON f.id = t.id
+`)
+		if err != nil {
+			return errors.Tag(err, "query files")
+		}
+		defer rows.Close()
+		for rows.Next() {
+			f := file{}
+			err = rows.Scan(&f.projectId, &f.fileId, &f.size, &f.pending)
+			if err != nil {
+				return errors.Tag(err, "scan file")
+			}
+			select {
+			case queue <- f:
+			case <-pCtx.Done():
+				return pCtx.Err()
+			}
+		}
+		if err = rows.Err(); err != nil {
+			return errors.Tag(err, "iter files")
+		}
+		return nil
+	})
+	workers, wCtx := errgroup.WithContext(pCtx)
+	for i := 0; i < concurrency; i++ {
+		workers.Go(func() error {
+			for f := range queue {
+				fn, err := check(wCtx, fm, f)
+				if err != nil {
+					return err
+				}
+				select {
+				case results <- fn:
+				case <-wCtx.Done():
+					return wCtx.Err()
+				}
+			}
+			return nil
+		})
+	}
+	eg.Go(func() error {
+		defer close(results)
+		return workers.Wait()
+	})
+	for fn := range results {
+		fn(&r)
+	}
+	if err := eg.Wait(); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+func check(ctx context.Context, fm filestore.Manager, f file) (func(*Report), error) {
+	size, exists, err := fm.StatProjectFile(ctx, f.projectId, f.fileId)
+	if err != nil {
+		return nil, errors.Tag(
+			err, fmt.Sprintf("stat %s/%s", f.projectId, f.fileId),
+		)
+	}
+	ids := f.projectId.Concat('/', f.fileId)
+	switch {
+	case f.pending && exists && size == f.size:
+		log.Printf("%s: pending, but blob is complete", ids)
+		return func(r *Report) {
+			r.Checked++
+			r.StuckInPending++
+		}, nil
+	case f.pending:
+		return func(r *Report) { r.Checked++ }, nil
+	case !exists:
+		log.Printf("%s: missing blob", ids)
+		return func(r *Report) {
+			r.Checked++
+			r.Missing++
+		}, nil
+	case size != f.size:
+		log.Printf("%s: size mismatch: db=%d storage=%d", ids, f.size, size)
+		return func(r *Report) {
+			r.Checked++
+			r.SizeMismatch++
+		}, nil
+	default:
+		return func(r *Report) { r.Checked++ }, nil
+	}
+}
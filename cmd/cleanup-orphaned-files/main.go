@@ -0,0 +1,70 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/das7pad/overleaf-go/cmd/cleanup-orphaned-files/pkg/cleanupOrphanedFiles"
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/filestore/pkg/managers/filestore"
+	webTypes "github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func main() {
+	ctx, triggerExit := signal.NotifyContext(
+		context.Background(), syscall.SIGINT, syscall.SIGTERM,
+	)
+	defer triggerExit()
+
+	o := cleanupOrphanedFiles.Options{}
+	var confirm bool
+	flag.BoolVar(&o.DryRun, "dry-run", true, "only report orphaned objects")
+	flag.BoolVar(&confirm, "confirm", false, "confirm deletion when running with -dry-run=false")
+	flag.DurationVar(&o.MinAge, "min-age", 24*time.Hour, "skip objects that were modified recently")
+	flag.Parse()
+	if !o.DryRun && !confirm {
+		_, _ = fmt.Fprintln(os.Stderr, "ERR: deletion requires -confirm")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	wo := webTypes.Options{}
+	wo.FillFromEnv()
+	fm, err := filestore.New(wo.APIs.Filestore)
+	if err != nil {
+		panic(errors.Tag(err, "init filestore"))
+	}
+	db := utils.MustConnectPostgres(ctx)
+
+	r, err := cleanupOrphanedFiles.Run(ctx, db, fm, o)
+	log.Printf(
+		"scanned %d objects: %d orphaned with %d bytes, %d deleted",
+		r.Scanned, r.Orphaned, r.Size, r.Deleted,
+	)
+	if err != nil {
+		panic(errors.Tag(err, "cleanup orphaned files"))
+	}
+}
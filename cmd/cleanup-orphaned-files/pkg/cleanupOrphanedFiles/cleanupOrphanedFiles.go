@@ -0,0 +1,132 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cleanupOrphanedFiles
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/filestore/pkg/managers/filestore"
+)
+
+type Options struct {
+	DryRun bool
+	// MinAge protects objects of in-flight uploads and copies, which get
+	// stored ahead of their files row.
+	MinAge time.Duration
+}
+
+type Report struct {
+	Scanned  int64
+	Orphaned int64
+	Deleted  int64
+	Size     int64
+}
+
+const batchSize = 1000
+
+// Run deletes objects in the filestore that have no matching files row.
+// Files of soft-deleted projects and soft-deleted files keep their row and
+// are not touched.
+func Run(ctx context.Context, db *pgxpool.Pool, fm filestore.Manager, o Options) (Report, error) {
+	r := Report{}
+	cutOff := time.Now().Add(-o.MinAge)
+	batch := make([]filestore.ProjectFileObject, 0, batchSize)
+	flush := func() error {
+		orphans, err := findOrphans(ctx, db, batch)
+		batch = batch[:0]
+		if err != nil {
+			return err
+		}
+		for _, f := range orphans {
+			ids := f.ProjectId.Concat('/', f.FileId)
+			r.Orphaned++
+			r.Size += f.Size
+			if o.DryRun {
+				log.Printf("%s: orphaned (dry-run)", ids)
+				continue
+			}
+			err = fm.DeleteProjectFile(ctx, f.ProjectId, f.FileId)
+			if err != nil {
+				return errors.Tag(err, "delete "+ids)
+			}
+			log.Printf("%s: deleted", ids)
+			r.Deleted++
+		}
+		return nil
+	}
+	err := fm.IterateProjectFiles(ctx, func(f filestore.ProjectFileObject) error {
+		r.Scanned++
+		if f.LastModified.After(cutOff) {
+			return nil
+		}
+		batch = append(batch, f)
+		if len(batch) < batchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return r, errors.Tag(err, "iterate files")
+	}
+	if len(batch) > 0 {
+		if err = flush(); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+func findOrphans(ctx context.Context, db *pgxpool.Pool, objects []filestore.ProjectFileObject) ([]filestore.ProjectFileObject, error) {
+	fileIds := make(sharedTypes.UUIDs, len(objects))
+	for i, f := range objects {
+		fileIds[i] = f.FileId
+	}
+	rows, err := db.Query(ctx, `
+SELECT t.project_id, f.id
+FROM files f
+         INNER JOIN tree_nodes t ON f.id = t.id
+WHERE f.id = ANY ($1)
+`, fileIds)
+	if err != nil {
+		return nil, errors.Tag(err, "query files")
+	}
+	defer rows.Close()
+	known := make(map[[2]sharedTypes.UUID]bool, len(objects))
+	for rows.Next() {
+		var projectId, fileId sharedTypes.UUID
+		if err = rows.Scan(&projectId, &fileId); err != nil {
+			return nil, errors.Tag(err, "scan file")
+		}
+		known[[2]sharedTypes.UUID{projectId, fileId}] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Tag(err, "iter files")
+	}
+	orphans := make([]filestore.ProjectFileObject, 0)
+	for _, f := range objects {
+		if !known[[2]sharedTypes.UUID{f.ProjectId, f.FileId}] {
+			orphans = append(orphans, f)
+		}
+	}
+	return orphans, nil
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2023-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -69,6 +69,8 @@ func main() {
 	flag.StringVar(&f.SessionCookieName, "session-cookie-name", f.SessionCookieName, "session cookie name")
 	flag.StringVar(&f.SessionCookieSecretsRaw, "session-cookie-secrets", f.SessionCookieSecretsRaw, "session cookie secrets (comma separated list, first item is used for new cookies)")
 
+	flag.StringVar(&f.SnapshotEncryptionKey, "snapshot-encryption-key", f.SnapshotEncryptionKey, "hex encoded 32 bytes key for encrypting doc snapshots at rest, use '-' for prompt (default: disabled)")
+
 	flag.StringVar(&f.SmokeTestUserEmail, "smoke-test-user-email", f.SmokeTestUserEmail, "(default: smoke-test@<site-url-domain>)")
 	flag.StringVar(&f.SmokeTestUserPassword, "smoke-test-user-password", f.SmokeTestUserPassword, "(default: generated")
 
@@ -95,6 +97,7 @@ func main() {
 	handlePromptInput(&f.JWTOptionsLoggedInUser.Key, "JWT logged in user key")
 	handlePromptInput(&f.JWTOptionsProject.Key, "JWT project key")
 	handlePromptInput(&f.SmokeTestUserPassword, "Smoke test user password")
+	handlePromptInput(&f.SnapshotEncryptionKey, "Snapshot encryption key")
 
 	fmt.Println("# Generated using")
 	fmt.Printf("# $ %s", os.Args[0])
@@ -30,6 +30,7 @@ import (
 
 	"github.com/das7pad/overleaf-go/pkg/email"
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/doc"
 	"github.com/das7pad/overleaf-go/pkg/objectStorage"
 	"github.com/das7pad/overleaf-go/pkg/options/jwtOptions"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
@@ -144,6 +145,7 @@ type Flags struct {
 	SiteURLRaw               string
 	SmokeTestUserEmail       string
 	SmokeTestUserPassword    string
+	SnapshotEncryptionKey    string
 	TexLiveImagesRaw         string
 	TmpDir                   string
 }
@@ -282,6 +284,9 @@ func Generate(f Flags) Config {
 			Count:    10,
 			Interval: 12 * time.Hour,
 		},
+		SnapshotEncryption: doc.EncryptionOptions{
+			Key: f.SnapshotEncryptionKey,
+		},
 	}
 
 	realTimeOptions := realTimeTypes.Options{
@@ -318,8 +323,10 @@ func Generate(f Flags) Config {
 		Email: struct {
 			CustomFooter     string            `json:"custom_footer"`
 			CustomFooterHTML template.HTML     `json:"custom_footer_html"`
+			DKIM             email.DKIMOptions `json:"dkim"`
 			From             email.Identity    `json:"from"`
 			FallbackReplyTo  email.Identity    `json:"fallback_reply_to"`
+			Retry            email.RetryPolicy `json:"retry"`
 			SMTPAddress      email.SMTPAddress `json:"smtp_address"`
 			SMTPHello        string            `json:"smtp_hello"`
 			SMTPIdentity     string            `json:"smtp_identity"`
@@ -332,6 +339,11 @@ func Generate(f Flags) Config {
 			FallbackReplyTo: email.Identity{
 				Address: sharedTypes.Email("support@" + emailHost),
 			},
+			Retry: email.RetryPolicy{
+				MaxAttempts: 3,
+				BaseDelay:   time.Second,
+				Jitter:      500 * time.Millisecond,
+			},
 			SMTPAddress:  email.SMTPAddress(f.SMTPAddress),
 			SMTPHello:    "localhost",
 			SMTPUser:     f.SMTPUser,
@@ -364,11 +376,13 @@ func Generate(f Flags) Config {
 			ProjectId: sharedTypes.UUID{42},
 			UserId:    sharedTypes.UUID{13, 37},
 		},
-		TeXLiveImageNameOverride:  "",
-		EmailConfirmationDisabled: false,
-		RegistrationDisabled:      false,
-		RobotsNoindex:             false,
-		WatchManifest:             false,
+		TeXLiveImageNameOverride:     "",
+		AnonymousTokenAccessDisabled: false,
+		EmailConfirmationDisabled:    false,
+		GitImportEnabled:             false,
+		RegistrationDisabled:         false,
+		RobotsNoindex:                false,
+		WatchManifest:                false,
 		APIs: struct {
 			Clsi struct {
 				URL         sharedTypes.URL `json:"url"`
@@ -420,9 +434,14 @@ func Generate(f Flags) Config {
 			Secrets: strings.Split(f.SessionCookieSecretsRaw, ","),
 		},
 		RateLimits: struct {
-			LinkSharingTokenLookupConcurrency int64 `json:"link_sharing_token_lookup_concurrency"`
+			LinkSharingTokenLookupConcurrency int64         `json:"link_sharing_token_lookup_concurrency"`
+			ProjectInviteResendInterval       time.Duration `json:"project_invite_resend_interval"`
 		}{
 			LinkSharingTokenLookupConcurrency: 1,
+			ProjectInviteResendInterval:       time.Minute,
+		},
+		SnapshotEncryption: doc.EncryptionOptions{
+			Key: f.SnapshotEncryptionKey,
 		},
 	}
 
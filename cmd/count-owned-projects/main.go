@@ -0,0 +1,57 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func main() {
+	ctx, done := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer done()
+
+	var userIdRaw string
+	flag.StringVar(&userIdRaw, "user-id", sharedTypes.AllZeroUUID, "user id")
+
+	flag.Parse()
+	userId, err := sharedTypes.ParseUUID(userIdRaw)
+	if err != nil {
+		err = errors.Tag(err, "invalid user-id")
+		_, _ = fmt.Fprintf(os.Stderr, "ERR: %s\n", err.Error())
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	db := utils.MustConnectPostgres(ctx)
+	pm := project.New(db, nil)
+
+	n, err := pm.CountOwnedProjects(ctx, userId)
+	if err != nil {
+		panic(errors.Tag(err, "count owned projects"))
+	}
+	fmt.Println(n)
+}
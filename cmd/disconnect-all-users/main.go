@@ -18,37 +18,54 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"log"
 	"time"
 
 	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
 	"github.com/das7pad/overleaf-go/pkg/errors"
-	"github.com/das7pad/overleaf-go/pkg/pubSub/channel"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
 func main() {
 	delay := flag.Duration("delay", 10*time.Second, "delay for forced page reload")
+	jitter := flag.Duration("jitter", 0, "spread reconnects across [delay, delay+jitter]")
+	rawProjectId := flag.String("project-id", "", "limit disconnect to given project")
+	rawUserId := flag.String("user-id", "", "limit disconnect to given user")
 	timeout := flag.Duration("timout", 10*time.Second, "timeout for operation")
 	flag.Parse()
 
+	var projectId, userId sharedTypes.UUID
+	if *rawProjectId != "" {
+		var err error
+		if projectId, err = sharedTypes.ParseUUID(*rawProjectId); err != nil {
+			panic(errors.Tag(err, "parse project-id"))
+		}
+	}
+	if *rawUserId != "" {
+		var err error
+		if userId, err = sharedTypes.ParseUUID(*rawUserId); err != nil {
+			panic(errors.Tag(err, "parse user-id"))
+		}
+	}
+
 	ctx, done := context.WithTimeout(context.Background(), *timeout)
 	defer done()
 
 	client := utils.MustConnectRedis(ctx)
-	editorEvents := channel.NewWriter(client, "editor-events")
 
-	payload, err := json.Marshal(delay.Seconds())
-	if err != nil {
-		panic(errors.Tag(err, "serialize payload"))
+	if projectId.IsZero() {
+		log.Println("Broadcasting message.")
+	} else {
+		log.Println("Sending message to project " + projectId.String() + ".")
 	}
-	log.Println("Broadcasting message.")
-	err = editorEvents.Publish(ctx, &sharedTypes.EditorEvent{
-		Message: sharedTypes.ForceDisconnect,
-		Payload: payload,
-	})
+	err := utils.PublishForceDisconnect(
+		ctx, client, projectId, sharedTypes.ForceDisconnectPayload{
+			Delay:  delay.Seconds(),
+			Jitter: jitter.Seconds(),
+			UserId: userId,
+		},
+	)
 	if err != nil {
 		panic(errors.Tag(err, "broadcast message"))
 	}
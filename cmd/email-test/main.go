@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/das7pad/overleaf-go/pkg/email"
@@ -30,18 +31,43 @@ import (
 	webTypes "github.com/das7pad/overleaf-go/services/web/pkg/types"
 )
 
+func parseIdentities(raw string) ([]email.Identity, error) {
+	identities := make([]email.Identity, 0)
+	for _, s := range strings.Split(raw, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		address := sharedTypes.Email(s).Normalize()
+		if err := address.Validate(); err != nil {
+			return nil, errors.Tag(err, s)
+		}
+		identities = append(identities, email.Identity{Address: address})
+	}
+	return identities, nil
+}
+
 func main() {
-	toRaw := flag.String("to", "", "recipient of the email")
+	toRaw := flag.String("to", "", "comma separated recipients of the email")
+	ccRaw := flag.String("cc", "", "comma separated cc recipients of the email")
 	timeout := flag.Duration("timout", 10*time.Second, "timeout for operation")
+	renderOnly := flag.Bool("render-only", false, "render the email without sending it")
+	out := flag.String("out", "-", "destination for -render-only, '-' for stdout")
 	flag.Parse()
-	if *toRaw == "" {
+	to, err := parseIdentities(*toRaw)
+	if err != nil {
+		fmt.Println(errors.Tag(err, "ERR: invalid email address").Error())
+		flag.Usage()
+		os.Exit(101)
+	}
+	if len(to) == 0 {
 		fmt.Println("ERR: must set -to")
 		flag.Usage()
 		os.Exit(101)
 	}
-	to := sharedTypes.Email(*toRaw).Normalize()
-	if err := to.Validate(); err != nil {
-		fmt.Println(errors.Tag(err, "ERR: invalid email address").Error())
+	cc, err := parseIdentities(*ccRaw)
+	if err != nil {
+		fmt.Println(errors.Tag(err, "ERR: invalid cc email address").Error())
 		flag.Usage()
 		os.Exit(101)
 	}
@@ -57,6 +83,8 @@ func main() {
 	defer done()
 
 	e := email.Email{
+		AdditionalTo: to[1:],
+		CC:           cc,
 		Content: &email.CTAContent{
 			PublicOptions: emailOptions.Public,
 			Message: email.Message{
@@ -67,12 +95,26 @@ func main() {
 			CTAURL:  &o.SiteURL,
 		},
 		Subject: "A Test Email from " + o.AppName,
-		To: email.Identity{
-			Address: to,
-		},
+		To:      to[0],
 	}
-	log.Printf("sending to %q", to)
-	if err := e.Send(ctx, emailOptions.Send); err != nil {
+	if *renderOnly {
+		blob, err := e.Render(emailOptions.Send)
+		if err != nil {
+			panic(errors.Tag(err, "render email"))
+		}
+		if *out == "-" {
+			_, err = os.Stdout.Write(blob)
+		} else {
+			err = os.WriteFile(*out, blob, 0o644)
+		}
+		if err != nil {
+			panic(errors.Tag(err, "write email"))
+		}
+		return
+	}
+
+	log.Printf("sending to %q, cc %q", *toRaw, *ccRaw)
+	if err = e.Send(ctx, emailOptions.Send); err != nil {
 		panic(errors.Tag(err, "send email"))
 	}
 	log.Println("sent.")
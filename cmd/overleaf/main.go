@@ -18,7 +18,6 @@ package main
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os/signal"
 	"syscall"
@@ -29,6 +28,7 @@ import (
 	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/httpUtils"
+	"github.com/das7pad/overleaf-go/pkg/logger"
 	"github.com/das7pad/overleaf-go/pkg/options/corsOptions"
 	"github.com/das7pad/overleaf-go/pkg/options/env"
 	"github.com/das7pad/overleaf-go/pkg/options/listenAddress"
@@ -144,6 +144,7 @@ func main() {
 		Handler: r,
 	}
 	httpUtils.ListenAndServeEach(eg.Go, &server, listenAddress.Parse(3000))
+	httpUtils.ServeInternal(pCtx, eg.Go, 13000, realTimeRouter.Internal(rtm))
 	eg.Go(func() error {
 		<-pCtx.Done()
 		// Shutdown sequence:
@@ -159,6 +160,10 @@ func main() {
 		rtm.TriggerGracefulReconnect()
 		// - Wait for existing HTTP requests to finish processing
 		err2 := pendingShutdown.Wait(ctx)
+		// - Wait for clients to reconnect elsewhere
+		if n := rtm.WaitForClientsToDrain(ctx); n > 0 {
+			logger.Warn("clients did not reconnect in time", "clients", n)
+		}
 		// - Close remaining websockets
 		rtm.DisconnectAll()
 		// - Avoid starting new background flush jobs and wait for pending
@@ -171,12 +176,12 @@ func main() {
 		ctx2, done2 := context.WithTimeout(context.Background(), time.Minute)
 		defer done2()
 		if _, flushErr := dum.FlushAll(ctx2); flushErr != nil {
-			log.Printf("final flush: %s", flushErr)
+			logger.Error("final flush", flushErr)
 		}
 		// - Hard cut for processing in document-updater. Go-Redis does not
 		//    respect context cancellation for the blocking reads anymore :/
 		if closeErr := rClient.Close(); closeErr != nil {
-			log.Printf("close redis: %s", closeErr)
+			logger.Error("close redis", closeErr)
 		}
 		return err2
 	})
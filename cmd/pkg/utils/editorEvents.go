@@ -0,0 +1,54 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/pubSub/channel"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+// PublishForceDisconnect publishes a forceDisconnect event to the editor.
+// A zero projectId results in a broadcast to all projects.
+func PublishForceDisconnect(ctx context.Context, client redis.UniversalClient, projectId sharedTypes.UUID, p sharedTypes.ForceDisconnectPayload) error {
+	var payload []byte
+	var err error
+	if p.UserId.IsZero() && p.Jitter <= 0 {
+		// Retain compatibility with the plain delay payload.
+		payload, err = json.Marshal(p.Delay)
+	} else {
+		payload, err = json.Marshal(p)
+	}
+	if err != nil {
+		return errors.Tag(err, "serialize payload")
+	}
+	editorEvents := channel.NewWriter(client, "editor-events")
+	err = editorEvents.Publish(ctx, &sharedTypes.EditorEvent{
+		RoomId:  projectId,
+		Message: sharedTypes.ForceDisconnect,
+		Payload: payload,
+	})
+	if err != nil {
+		return errors.Tag(err, "publish message")
+	}
+	return nil
+}
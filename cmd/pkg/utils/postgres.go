@@ -26,6 +26,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/metrics"
 	"github.com/das7pad/overleaf-go/pkg/options/postgresOptions"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
@@ -42,6 +43,9 @@ func MustConnectPostgres(ctx context.Context) *pgxpool.Pool {
 			panic(errors.Tag(err, "parse postgres DSN"))
 		}
 	}
+	if metrics.Enabled() {
+		cfg.ConnConfig.Tracer = metrics.PostgresTracer{}
+	}
 	var extraTypes []*pgtype.Type
 	var extraTypesMu sync.Mutex
 	cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
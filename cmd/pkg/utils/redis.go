@@ -23,6 +23,7 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/metrics"
 	"github.com/das7pad/overleaf-go/pkg/options/redisOptions"
 )
 
@@ -43,6 +44,9 @@ func MustConnectRedis(ctx context.Context) redis.UniversalClient {
 	rOptions := redisOptions.Parse()
 
 	rClient := redis.NewUniversalClient(rOptions)
+	if metrics.Enabled() {
+		rClient.AddHook(metrics.RedisHook{})
+	}
 	if err := ensureRedisAcceptsWrites(ctx, rClient); err != nil {
 		panic(errors.Tag(err, "ensure redis accepts writes"))
 	}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2022-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2022-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,14 +18,19 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/user"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web"
 	webTypes "github.com/das7pad/overleaf-go/services/web/pkg/types"
@@ -41,9 +46,31 @@ func main() {
 	flag.StringVar(&initiatorUserIdRaw, "initiator-user-id", sharedTypes.AllZeroUUID, "optional user-id of the command line operator for leaving an audit log trail")
 	var quiet bool
 	flag.BoolVar(&quiet, "quiet", false, "just print the url on success")
+	var tokenExpiry time.Duration
+	flag.DurationVar(&tokenExpiry, "token-expiry", user.NewUserOneTimeTokenExpiry, "expiry of the activation token, must not exceed the default")
+	var jsonOutput bool
+	flag.BoolVar(&jsonOutput, "json", false, "print result as json, an array in csv mode")
+	var csvPath string
+	flag.StringVar(&csvPath, "csv", "", "register users from csv file with rows of 'email[,first name[,last name]]', prints csv of 'email,url'")
 
 	flag.Parse()
-	if err := email.Validate(); err != nil {
+	if tokenExpiry <= 0 || tokenExpiry > user.NewUserOneTimeTokenExpiry {
+		_, _ = fmt.Fprintf(
+			os.Stderr, "ERR: token-expiry must be in (0, %s]\n",
+			user.NewUserOneTimeTokenExpiry,
+		)
+		flag.Usage()
+		os.Exit(1)
+	}
+	var rows []csvRow
+	if csvPath != "" {
+		var err error
+		if rows, err = readCSV(csvPath); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERR: %s\n", err.Error())
+			flag.Usage()
+			os.Exit(1)
+		}
+	} else if err := email.Validate(); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "ERR: %s\n", err.Error())
 		flag.Usage()
 		os.Exit(1)
@@ -72,11 +99,27 @@ func main() {
 		panic(errors.Tag(err, "web setup"))
 	}
 
-	req := webTypes.NewCMDCreateUserRequest(email, initiatorUserId)
+	if csvPath != "" {
+		registerFromCSV(
+			ctx, webManager, rows, initiatorUserId, tokenExpiry, jsonOutput,
+		)
+		return
+	}
+
+	req := webTypes.NewCMDCreateUserRequest(
+		email, initiatorUserId, tokenExpiry,
+	)
 	res := webTypes.CMDCreateUserResponse{}
 	if err = webManager.CMDCreateUser(ctx, &req, &res); err != nil {
 		panic(errors.Tag(err, "create user"))
 	}
+	if jsonOutput {
+		printJSON(registration{
+			Email:             req.Email,
+			SetNewPasswordURL: res.SetNewPasswordURL,
+		})
+		return
+	}
 	if quiet {
 		fmt.Println(res.SetNewPasswordURL)
 		return
@@ -89,7 +132,7 @@ func main() {
     You can also manually send them the below URL to allow them to set their
      password and log in for the first time.
 
-    (User activate tokens will expire after one week and the user will need
+    (User activate tokens will expire after %s and the user will need
      to request a new password via the "forgot password" process, which they
      can initiate from the login screen.)
 
@@ -97,5 +140,119 @@ func main() {
     URL:   %s
 
 -------------------------------------------------------------------------------
-`, email, res.SetNewPasswordURL)
+`, formatExpiry(tokenExpiry), email, res.SetNewPasswordURL)
+}
+
+type registration struct {
+	Email             sharedTypes.Email `json:"email"`
+	SetNewPasswordURL *sharedTypes.URL  `json:"setNewPasswordUrl"`
+}
+
+func printJSON(v interface{}) {
+	blob, err := json.Marshal(v)
+	if err != nil {
+		panic(errors.Tag(err, "serialize output"))
+	}
+	fmt.Println(string(blob))
+}
+
+type csvRow struct {
+	email     sharedTypes.Email
+	firstName string
+	lastName  string
+}
+
+func readCSV(p string) ([]csvRow, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, errors.Tag(err, "open csv")
+	}
+	defer func() { _ = f.Close() }()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, errors.Tag(err, "parse csv")
+	}
+	rows := make([]csvRow, 0, len(records))
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(record[0], "email") {
+			// Skip header.
+			continue
+		}
+		row := csvRow{email: sharedTypes.Email(record[0])}
+		if len(record) > 1 {
+			row.firstName = record[1]
+		}
+		if len(record) > 2 {
+			row.lastName = record[2]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func registerFromCSV(ctx context.Context, wm web.Manager, rows []csvRow, initiatorUserId sharedTypes.UUID, tokenExpiry time.Duration, jsonOutput bool) {
+	done := make([]registration, 0, len(rows))
+	var failed []string
+	for _, row := range rows {
+		req := webTypes.NewCMDCreateUserRequest(
+			row.email, initiatorUserId, tokenExpiry,
+		)
+		req.FirstName = row.firstName
+		req.LastName = row.lastName
+		res := webTypes.CMDCreateUserResponse{}
+		if err := wm.CMDCreateUser(ctx, &req, &res); err != nil {
+			failed = append(failed, fmt.Sprintf(
+				"%s: %s", row.email, err.Error(),
+			))
+			continue
+		}
+		done = append(done, registration{
+			Email:             req.Email,
+			SetNewPasswordURL: res.SetNewPasswordURL,
+		})
+	}
+	if jsonOutput {
+		printJSON(done)
+	} else {
+		printCSV(done)
+	}
+	if len(failed) > 0 {
+		_, _ = fmt.Fprintf(
+			os.Stderr, "ERR: %d of %d users failed:\n",
+			len(failed), len(rows),
+		)
+		for _, s := range failed {
+			_, _ = fmt.Fprintf(os.Stderr, "  %s\n", s)
+		}
+		os.Exit(1)
+	}
+}
+
+func printCSV(done []registration) {
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write([]string{"email", "setNewPasswordUrl"})
+	for _, r := range done {
+		_ = w.Write([]string{string(r.Email), r.SetNewPasswordURL.String()})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		panic(errors.Tag(err, "write csv"))
+	}
+}
+
+func formatExpiry(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d == 7*day:
+		return "one week"
+	case d == day:
+		return "one day"
+	case d%day == 0:
+		return fmt.Sprintf("%d days", d/day)
+	default:
+		return d.String()
+	}
 }
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2023-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,7 +18,9 @@ package main
 
 import (
 	"context"
+	"flag"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
@@ -45,3 +47,31 @@ func TestMainFn(t *testing.T) {
 		t.Fatalf("find user by email: %s", err)
 	}
 }
+
+func TestMainFnCSV(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "users.csv")
+	blob := []byte("email,first name,last name\ncsv-1@bar.com\ncsv-2@bar.com, Foo, Bar\n")
+	if err := os.WriteFile(p, blob, 0o600); err != nil {
+		t.Fatalf("write csv: %s", err)
+	}
+
+	os.Args = []string{"exec", "--csv=" + p}
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	main()
+
+	ctx := context.Background()
+
+	db := utils.MustConnectPostgres(ctx)
+	um := user.New(db)
+	u := user.WithPublicInfo{}
+	if err := um.GetUserByEmail(ctx, "csv-1@bar.com", &u); err != nil {
+		t.Fatalf("find first user by email: %s", err)
+	}
+	u = user.WithPublicInfo{}
+	if err := um.GetUserByEmail(ctx, "csv-2@bar.com", &u); err != nil {
+		t.Fatalf("find second user by email: %s", err)
+	}
+	if u.FirstName != "Foo" || u.LastName != "Bar" {
+		t.Errorf("names = %q %q, want %q %q", u.FirstName, u.LastName, "Foo", "Bar")
+	}
+}
@@ -0,0 +1,69 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+)
+
+func main() {
+	ctx, triggerExit := signal.NotifyContext(
+		context.Background(), syscall.SIGINT, syscall.SIGTERM,
+	)
+	defer triggerExit()
+
+	var dryRun bool
+	flag.BoolVar(&dryRun, "dry-run", true, "only list projects without a root folder")
+	flag.Parse()
+
+	db := utils.MustConnectPostgres(ctx)
+	pm := project.New(db, nil)
+
+	ids, err := pm.ListProjectsWithoutRootFolder(ctx)
+	if err != nil {
+		panic(errors.Tag(err, "list projects without root folder"))
+	}
+	log.Printf("found %d projects without root folder", len(ids))
+	if dryRun {
+		for _, id := range ids {
+			log.Printf("project %s", id)
+		}
+		if len(ids) > 0 {
+			os.Exit(2)
+		}
+		return
+	}
+	for _, id := range ids {
+		if err = pm.RepairRootFolder(ctx, id); err != nil {
+			if errors.IsNotFoundError(err) {
+				continue
+			}
+			panic(errors.Tag(err, "repair root folder of "+id.String()))
+		}
+		log.Printf("repaired project %s", id)
+	}
+	log.Println("done.")
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -25,16 +25,29 @@ import (
 	"time"
 
 	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/models/systemMessage"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
 func main() {
 	clear := flag.Bool("clear", false, "clear system messages")
 	msg := flag.String("message", "", "create new system message")
+	rawProjectId := flag.String("project-id", "", "limit new system message to given project")
+	expiresIn := flag.Duration("expires-in", 0, "expire new system message after given duration, 0 = never")
 	timeout := flag.Duration("timout", 10*time.Second, "timeout for operation")
 	flag.Parse()
 	*msg = strings.TrimSpace(*msg)
 
+	var projectId *sharedTypes.UUID
+	if *rawProjectId != "" {
+		id, err := sharedTypes.ParseUUID(*rawProjectId)
+		if err != nil {
+			panic(errors.Tag(err, "parse project-id"))
+		}
+		projectId = &id
+	}
+
 	ctx, done := context.WithTimeout(context.Background(), *timeout)
 	defer done()
 
@@ -48,13 +61,27 @@ func main() {
 		err = smm.DeleteAll(ctx)
 	case *msg != "":
 		log.Println("Creating new system message.")
-		err = smm.Create(ctx, *msg)
+		var expiresAt *time.Time
+		if *expiresIn > 0 {
+			t := time.Now().Add(*expiresIn)
+			expiresAt = &t
+		}
+		err = smm.Create(ctx, *msg, projectId, expiresAt)
 	default:
 		var messages []systemMessage.Full
 		messages, err = smm.GetAll(ctx)
 		if err == nil {
 			for i, message := range messages {
-				fmt.Printf("%d: %s: %s\n", i, message.Id, message.Content)
+				if message.IsGlobal() {
+					fmt.Printf(
+						"%d: %s: %s\n", i, message.Id, message.Content,
+					)
+				} else {
+					fmt.Printf(
+						"%d: %s: project %s: %s\n",
+						i, message.Id, *message.ProjectId, message.Content,
+					)
+				}
 			}
 		}
 	}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2023-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -21,6 +21,7 @@ import (
 	"flag"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
 	"github.com/das7pad/overleaf-go/pkg/integrationTests"
@@ -59,3 +60,26 @@ func TestMainFn(t *testing.T) {
 		t.Fatalf("get all again: %#v", m)
 	}
 }
+
+func TestMainFnExpiresIn(t *testing.T) {
+	os.Args = []string{"exec", "--message=MSG", "--expires-in=1ms"}
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	main()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx := context.Background()
+
+	db := utils.MustConnectPostgres(ctx)
+	smm := systemMessage.New(db)
+	m, err := smm.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all: %s", err)
+	}
+	if len(m) != 0 {
+		t.Fatalf("get all: expected expired message to be hidden: %#v", m)
+	}
+
+	os.Args = []string{"exec", "--clear"}
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	main()
+}
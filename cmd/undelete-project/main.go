@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2023-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -57,7 +57,7 @@ func main() {
 	}
 
 	db := utils.MustConnectPostgres(ctx)
-	pm := project.New(db)
+	pm := project.New(db, nil)
 
 	name, err := pm.GetDeletedProjectsName(ctx, projectId, userId)
 	if err != nil {
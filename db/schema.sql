@@ -1,5 +1,5 @@
 --  Golang port of Overleaf
---  Copyright (C) 2022-2023 Jakob Ackermann <das7pad@outlook.com>
+--  Copyright (C) 2022-2024 Jakob Ackermann <das7pad@outlook.com>
 --
 --  This program is free software: you can redistribute it and/or modify
 --  it under the terms of the GNU Affero General Public License as published
@@ -60,41 +60,54 @@ CREATE TABLE user_audit_log
 );
 
 CREATE TYPE PublicAccessLevel AS ENUM ('private', 'tokenBased');
+CREATE TYPE AccessSource AS ENUM ('token', 'invite', 'owner');
+CREATE TYPE PrivilegeLevel AS ENUM ('readOnly', 'readAndWrite', 'owner');
 
 CREATE TABLE projects
 (
-  compiler             TEXT              NOT NULL,
-  content_locked_at    TIMESTAMP         NULL,
-  created_at           TIMESTAMP         NOT NULL,
-  deleted_at           TIMESTAMP         NULL,
-  epoch                INTEGER           NOT NULL,
-  editable             BOOLEAN GENERATED ALWAYS AS (content_locked_at IS NULL AND deleted_at IS NULL) STORED,
-  id                   UUID              NOT NULL PRIMARY KEY,
-  image_name           TEXT              NOT NULL,
-  last_opened_at       TIMESTAMP         NULL,
-  last_updated_at      TIMESTAMP         NULL,
-  last_updated_by      UUID              NULL REFERENCES users ON DELETE SET NULL,
-  name                 TEXT              NOT NULL,
-  owner_id             UUID              NOT NULL REFERENCES users ON DELETE RESTRICT,
-  public_access_level  PublicAccessLevel NOT NULL,
-  spell_check_language TEXT              NOT NULL,
-  token_ro             TEXT              NULL UNIQUE,
-  token_rw             TEXT              NULL,    -- implicit UNIQUE via token_rw_prefix
-  token_rw_prefix      TEXT              NULL UNIQUE,
-  tree_version         INTEGER           NOT NULL -- TODO: rename to version, it is used for cache invalidation of ForBootstrapWS in real-time
+  compile_count            INTEGER           NOT NULL DEFAULT 0,
+  compiler                 TEXT              NOT NULL,
+  -- Inherited compilers follow changes of the site default compiler.
+  compiler_inherited       BOOLEAN           NOT NULL DEFAULT FALSE,
+  content_locked_at        TIMESTAMP         NULL,
+  created_at               TIMESTAMP         NOT NULL,
+  deleted_at               TIMESTAMP         NULL,
+  epoch                    INTEGER           NOT NULL,
+  editable                 BOOLEAN GENERATED ALWAYS AS (content_locked_at IS NULL AND deleted_at IS NULL) STORED,
+  -- Override in days for how long doc history is retained.
+  history_retain_for       INTEGER           NULL,
+  id                       UUID              NOT NULL PRIMARY KEY,
+  image_name               TEXT              NOT NULL,
+  last_opened_at           TIMESTAMP         NULL,
+  last_updated_at          TIMESTAMP         NULL,
+  last_updated_by          UUID              NULL REFERENCES users ON DELETE SET NULL,
+  -- Override in seconds for how long edits may stay in redis unflushed.
+  max_unflushed_age        INTEGER           NULL,
+  name                     TEXT              NOT NULL,
+  owner_id                 UUID              NOT NULL REFERENCES users ON DELETE RESTRICT,
+  public_access_level      PublicAccessLevel NOT NULL,
+  spell_check_language     TEXT              NOT NULL,
+  token_ro                 TEXT              NULL UNIQUE,
+  token_rw                 TEXT              NULL,    -- implicit UNIQUE via token_rw_prefix
+  token_rw_prefix          TEXT              NULL UNIQUE,
+  -- Cap for the privilege level granted via the readAndWrite token.
+  token_rw_privilege_level PrivilegeLevel    NULL,
+  tree_version             INTEGER           NOT NULL -- TODO: rename to version, it is used for cache invalidation of ForBootstrapWS in real-time
 );
 
-CREATE TYPE AccessSource AS ENUM ('token', 'invite', 'owner');
-CREATE TYPE PrivilegeLevel AS ENUM ('readOnly', 'readAndWrite', 'owner');
+CREATE INDEX ON projects (compiler) WHERE (compiler_inherited = TRUE);
 
 CREATE TABLE project_members
 (
-  project_id      UUID           NOT NULL REFERENCES projects ON DELETE CASCADE,
-  user_id         UUID           NOT NULL REFERENCES users ON DELETE CASCADE,
-  access_source   AccessSource   NOT NULL,
-  privilege_level PrivilegeLevel NOT NULL,
-  archived        BOOLEAN        NOT NULL,
-  trashed         BOOLEAN        NOT NULL,
+  project_id            UUID           NOT NULL REFERENCES projects ON DELETE CASCADE,
+  user_id               UUID           NOT NULL REFERENCES users ON DELETE CASCADE,
+  access_source         AccessSource   NOT NULL,
+  privilege_level       PrivilegeLevel NOT NULL,
+  archived              BOOLEAN        NOT NULL,
+  trashed               BOOLEAN        NOT NULL,
+  access_expires_at     TIMESTAMP      NULL,
+  -- Kind of token used for joining: readOnly or readAndWrite.
+  token_privilege_level PrivilegeLevel NULL,
 
   PRIMARY KEY (project_id, user_id)
 );
@@ -109,6 +122,16 @@ CREATE TABLE project_audit_log
   operation    TEXT      NOT NULL,
   project_id   UUID      NOT NULL REFERENCES projects ON DELETE CASCADE
 );
+CREATE INDEX ON project_audit_log (project_id, created_at DESC);
+
+CREATE TABLE project_snippets
+(
+  body       TEXT NOT NULL,
+  name       TEXT NOT NULL,
+  project_id UUID NOT NULL REFERENCES projects ON DELETE CASCADE,
+
+  PRIMARY KEY (project_id, name)
+);
 
 CREATE TABLE project_invites
 (
@@ -118,6 +141,7 @@ CREATE TABLE project_invites
   id              UUID           NOT NULL PRIMARY KEY,
   privilege_level PrivilegeLevel NOT NULL,
   project_id      UUID           NOT NULL REFERENCES projects ON DELETE CASCADE,
+  resent_at       TIMESTAMP      NULL,
   sending_user_id UUID           NOT NULL REFERENCES users ON DELETE CASCADE,
   token           TEXT           NOT NULL,
 
@@ -212,9 +236,11 @@ ALTER TABLE tree_nodes
 
 CREATE TABLE docs
 (
-  id       UUID    NOT NULL PRIMARY KEY REFERENCES tree_nodes ON DELETE CASCADE,
-  snapshot TEXT    NOT NULL,
-  version  INTEGER NOT NULL,
+  -- Highest version of pruned doc_history entries, 0 when none got pruned.
+  history_pruned_version INTEGER NOT NULL DEFAULT 0,
+  id                     UUID    NOT NULL PRIMARY KEY REFERENCES tree_nodes ON DELETE CASCADE,
+  snapshot               TEXT    NOT NULL,
+  version                INTEGER NOT NULL,
 
   CHECK (is_tree_node_kind(id, 'doc'))
 );
@@ -228,10 +254,14 @@ CREATE TABLE doc_history
   doc_id         UUID      NOT NULL REFERENCES docs ON DELETE CASCADE,
   user_id        UUID      NULL REFERENCES users ON DELETE SET NULL,
   version        INTEGER   NOT NULL,
-  op             JSON      NOT NULL,
+  op             JSON      NULL,
+  -- gzip compressed JSON of op, when enabled and smaller.
+  op_gzip        BYTEA     NULL,
   has_big_delete BOOLEAN   NOT NULL,
   start_at       TIMESTAMP NOT NULL,
-  end_at         TIMESTAMP NOT NULL
+  end_at         TIMESTAMP NOT NULL,
+
+  CHECK ((op IS NULL) <> (op_gzip IS NULL))
 );
 CREATE UNIQUE INDEX ON doc_history (doc_id, version DESC);
 
@@ -241,6 +271,8 @@ CREATE TABLE files
   hash             TEXT    NOT NULL,
   linked_file_data JSON    NULL,
   size             INTEGER NOT NULL,
+  -- number of pages of PDF files, 0 for other files or when unknown.
+  page_count       INTEGER NOT NULL DEFAULT 0,
   pending          BOOLEAN NOT NULL,
 
   CHECK (is_tree_node_kind(id, 'file'))
@@ -289,8 +321,10 @@ CREATE TABLE tag_entries
 
 CREATE TABLE system_messages
 (
-  content TEXT NOT NULL,
-  id      UUID NOT NULL PRIMARY KEY
+  content    TEXT      NOT NULL,
+  expires_at TIMESTAMP NULL,
+  id         UUID      NOT NULL PRIMARY KEY,
+  project_id UUID      NULL REFERENCES projects ON DELETE CASCADE
 );
 
 CREATE TABLE chat_messages
@@ -299,6 +333,31 @@ CREATE TABLE chat_messages
   project_id UUID      NOT NULL REFERENCES projects ON DELETE CASCADE,
   content    TEXT      NOT NULL,
   created_at TIMESTAMP NOT NULL,
-  user_id    UUID      NULL REFERENCES users ON DELETE SET NULL
+  user_id    UUID      NULL REFERENCES users ON DELETE SET NULL,
+  edited_at  TIMESTAMP NULL
 );
 CREATE INDEX ON chat_messages (project_id, created_at DESC);
+
+CREATE TABLE chat_message_reactions
+(
+  emoji      TEXT NOT NULL,
+  message_id UUID NOT NULL REFERENCES chat_messages ON DELETE CASCADE,
+  user_id    UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+
+  PRIMARY KEY (message_id, emoji, user_id)
+);
+
+CREATE FUNCTION chat_message_reactions_of(message UUID)
+  RETURNS JSON
+  LANGUAGE SQL
+  STABLE
+  STRICT
+  PARALLEL SAFE
+AS
+$$
+SELECT json_object_agg(r.emoji, r.user_ids)
+FROM (SELECT emoji, array_agg(user_id ORDER BY user_id) AS user_ids
+      FROM chat_message_reactions
+      WHERE message_id = message
+      GROUP BY emoji) r
+$$;
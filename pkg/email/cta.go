@@ -74,7 +74,9 @@ func (c *CTAContent) PlainText() string {
 			helpLink.After + "\n"
 	}
 
-	s := "Hi," + "\n" +
+	s := c.Title + "\n" +
+		"\n" +
+		"Hi," + "\n" +
 		"\n" +
 		c.Message.String() + "\n" +
 		helpLinks +
@@ -0,0 +1,152 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package email
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+)
+
+type DKIMOptions struct {
+	Domain     string `json:"domain"`
+	Selector   string `json:"selector"`
+	PrivateKey string `json:"private_key"`
+}
+
+func (o *DKIMOptions) IsConfigured() bool {
+	return o.Domain != "" || o.Selector != "" || o.PrivateKey != ""
+}
+
+func (o *DKIMOptions) Validate() error {
+	_, err := o.NewSigner()
+	return err
+}
+
+func (o *DKIMOptions) NewSigner() (*DKIMSigner, error) {
+	if o.Domain == "" {
+		return nil, &errors.ValidationError{Msg: "missing domain"}
+	}
+	if o.Selector == "" {
+		return nil, &errors.ValidationError{Msg: "missing selector"}
+	}
+	block, _ := pem.Decode([]byte(o.PrivateKey))
+	if block == nil {
+		return nil, &errors.ValidationError{Msg: "private_key is not PEM"}
+	}
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		k, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Tag(err, "parse private_key")
+		}
+		key = k
+	case "PRIVATE KEY":
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Tag(err, "parse private_key")
+		}
+		rsaKey, ok := k.(*rsa.PrivateKey)
+		if !ok {
+			return nil, &errors.ValidationError{
+				Msg: "private_key must be a RSA key",
+			}
+		}
+		key = rsaKey
+	default:
+		return nil, &errors.ValidationError{
+			Msg: "unexpected private_key type: " + block.Type,
+		}
+	}
+	return &DKIMSigner{
+		domain:   o.Domain,
+		selector: o.Selector,
+		key:      key,
+	}, nil
+}
+
+type DKIMSigner struct {
+	domain   string
+	selector string
+	key      *rsa.PrivateKey
+}
+
+// sign produces the value of the DKIM-Signature header using the
+// relaxed/relaxed canonicalization from RFC 6376.
+func (s *DKIMSigner) sign(headers map[string]string, body []byte, now time.Time) (string, error) {
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	bodyHash := sha256.Sum256(canonicalizeBodyRelaxed(body))
+	v := "v=1; a=rsa-sha256; c=relaxed/relaxed" +
+		"; d=" + s.domain +
+		"; s=" + s.selector +
+		"; t=" + strconv.FormatInt(now.Unix(), 10) +
+		"; h=" + strings.ToLower(strings.Join(names, ":")) +
+		"; bh=" + base64.StdEncoding.EncodeToString(bodyHash[:]) +
+		"; b="
+
+	h := sha256.New()
+	for _, k := range names {
+		h.Write([]byte(canonicalizeHeaderRelaxed(k, headers[k])))
+		h.Write([]byte(crlf))
+	}
+	h.Write([]byte(canonicalizeHeaderRelaxed("DKIM-Signature", v)))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, h.Sum(nil))
+	if err != nil {
+		return "", errors.Tag(err, "sign")
+	}
+	return v + base64.StdEncoding.EncodeToString(signature), nil
+}
+
+func canonicalizeHeaderRelaxed(k, v string) string {
+	v = strings.NewReplacer("\r\n", "", "\t", " ").Replace(v)
+	return strings.ToLower(strings.TrimSpace(k)) + ":" +
+		strings.Join(strings.Fields(v), " ")
+}
+
+func canonicalizeBodyRelaxed(body []byte) []byte {
+	lines := strings.Split(string(body), crlf)
+	for i, l := range lines {
+		l = strings.ReplaceAll(l, "\t", " ")
+		for strings.Contains(l, "  ") {
+			l = strings.ReplaceAll(l, "  ", " ")
+		}
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, crlf) + crlf)
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -24,10 +24,12 @@ import (
 )
 
 type Email struct {
-	Content Content
-	ReplyTo Identity
-	Subject string
-	To      Identity
+	AdditionalTo []Identity
+	CC           []Identity
+	Content      Content
+	ReplyTo      Identity
+	Subject      string
+	To           Identity
 
 	// for tests
 	boundary string
@@ -46,12 +48,22 @@ func (e *Email) Validate() error {
 	if len(e.Subject) == 0 {
 		return errors.New("missing subject")
 	}
-	if err := e.To.Validate(); err != nil {
-		return errors.New("invalid recipient: " + err.Error())
+	for _, to := range e.recipients() {
+		if err := to.Validate(); err != nil {
+			return errors.New("invalid recipient: " + err.Error())
+		}
 	}
 	return nil
 }
 
+func (e *Email) recipients() []Identity {
+	r := make([]Identity, 0, 1+len(e.AdditionalTo)+len(e.CC))
+	r = append(r, e.To)
+	r = append(r, e.AdditionalTo...)
+	r = append(r, e.CC...)
+	return r
+}
+
 func (e *Email) writeHTML(w io.Writer) error {
 	return e.Content.Template().Execute(w, e.Content)
 }
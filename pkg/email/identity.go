@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,6 +18,7 @@ package email
 
 import (
 	"net/mail"
+	"strings"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
@@ -36,6 +37,14 @@ func (i *Identity) String() string {
 	return a.String()
 }
 
+func joinIdentities(identities []Identity) string {
+	s := make([]string, len(identities))
+	for i := range identities {
+		s[i] = identities[i].String()
+	}
+	return strings.Join(s, ", ")
+}
+
 func (i *Identity) Validate() error {
 	if i == nil {
 		return errors.New("missing identity")
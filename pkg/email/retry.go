@@ -0,0 +1,88 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package email
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"net/textproto"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int           `json:"max_attempts"`
+	BaseDelay   time.Duration `json:"base_delay"`
+	Jitter      time.Duration `json:"jitter"`
+
+	// OnAttempt is called after each attempt, err is nil on success.
+	OnAttempt func(attempt int, err error) `json:"-"`
+}
+
+func (p *RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return &errors.ValidationError{Msg: "max_attempts must not be negative"}
+	}
+	if p.BaseDelay < 0 {
+		return &errors.ValidationError{Msg: "base_delay must not be negative"}
+	}
+	if p.Jitter < 0 {
+		return &errors.ValidationError{Msg: "jitter must not be negative"}
+	}
+	return nil
+}
+
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if p.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(p.Jitter)))
+	}
+	return d
+}
+
+func isTransientSMTPError(err error) bool {
+	switch e := errors.GetCause(err).(type) {
+	case *textproto.Error:
+		return e.Code >= 400 && e.Code < 500
+	case net.Error:
+		return e.Timeout()
+	default:
+		return false
+	}
+}
+
+func (p *RetryPolicy) run(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if p.OnAttempt != nil {
+			p.OnAttempt(attempt, err)
+		}
+		if err == nil || attempt >= p.MaxAttempts ||
+			!isTransientSMTPError(err) {
+			return err
+		}
+		t := time.NewTimer(p.delay(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
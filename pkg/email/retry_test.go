@@ -0,0 +1,98 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package email
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEmail_SendRetry(t *testing.T) {
+	newEmail := func() *Email {
+		return &Email{
+			Content: &NoCTAContent{
+				PublicOptions: &PublicOptions{
+					AppName: "Test App Name",
+					SiteURL: "https://example.com",
+				},
+				Message: Message{"line1"},
+				Title:   "Email Title",
+			},
+			Subject: "Email Subject",
+			To:      Identity{Address: "to@example.com"},
+		}
+	}
+	ctx, done := context.WithTimeout(context.Background(), 10*time.Second)
+	defer done()
+
+	t.Run("transient", func(t *testing.T) {
+		srv := newFakeSMTPServer(t)
+		srv.failMail("451 4.3.0 try again", "421 4.4.2 busy")
+		var attempts []error
+		so := SendOptions{
+			From: Identity{Address: "from@example.com"},
+			Retry: RetryPolicy{
+				MaxAttempts: 3,
+				BaseDelay:   time.Millisecond,
+				Jitter:      time.Millisecond,
+				OnAttempt: func(_ int, err error) {
+					attempts = append(attempts, err)
+				},
+			},
+			Sender: NewSender(SMTPAddress(srv.l.Addr().String()), "localhost", nil),
+		}
+		if err := newEmail().Send(ctx, &so); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if len(attempts) != 3 {
+			t.Fatalf("expected 3 attempts, got %v", attempts)
+		}
+		if attempts[0] == nil || attempts[1] == nil || attempts[2] != nil {
+			t.Errorf("expected success on third attempt, got %v", attempts)
+		}
+		if _, messages := srv.stats(); len(messages) != 1 {
+			t.Errorf("expected 1 message, got %d", len(messages))
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		srv := newFakeSMTPServer(t)
+		srv.failMail("550 5.1.1 no such user")
+		n := 0
+		so := SendOptions{
+			From: Identity{Address: "from@example.com"},
+			Retry: RetryPolicy{
+				MaxAttempts: 3,
+				BaseDelay:   time.Millisecond,
+				OnAttempt: func(int, error) {
+					n++
+				},
+			},
+			Sender: NewSender(SMTPAddress(srv.l.Addr().String()), "localhost", nil),
+		}
+		if err := newEmail().Send(ctx, &so); err == nil {
+			t.Fatalf("Send() expected error")
+		}
+		if n != 1 {
+			t.Errorf("expected 1 attempt, got %d", n)
+		}
+		if _, messages := srv.stats(); len(messages) != 0 {
+			t.Errorf("expected no message, got %d", len(messages))
+		}
+	})
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -60,8 +60,10 @@ func (a SMTPAddress) Validate() error {
 }
 
 type SendOptions struct {
+	DKIM            *DKIMSigner
 	From            Identity
 	FallbackReplyTo Identity
+	Retry           RetryPolicy
 	Sender          Sender
 }
 
@@ -97,9 +99,26 @@ func writePart(m *multipart.Writer, contentType string, gen generator) error {
 }
 
 func (e *Email) Send(ctx context.Context, o *SendOptions) error {
-	if err := e.Validate(); err != nil {
+	blob, err := e.Render(o)
+	if err != nil {
 		return err
 	}
+	err = o.Retry.run(ctx, func() error {
+		return o.Sender.Send(ctx, o.From, e.recipients(), blob)
+	})
+	if err != nil {
+		log.Printf("send email: %s", err)
+		// Ensure that we do not expose details on the email infrastructure.
+		return errors.New("internal error sending email")
+	}
+	return nil
+}
+
+// Render produces the MIME encoded email without sending it.
+func (e *Email) Render(o *SendOptions) ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
 
 	replyTo := o.FallbackReplyTo
 	if e.ReplyTo.Address != "" {
@@ -120,7 +139,7 @@ func (e *Email) Send(ctx context.Context, o *SendOptions) error {
 	//  encodes the literal character '=' as '=3D'.
 	// It is hence impossible to get the sequence '==' in the encoded output.
 	if err := m.SetBoundary("==" + rndHex + "=="); err != nil {
-		return errors.Tag(err, "set robust boundary")
+		return nil, errors.Tag(err, "set robust boundary")
 	}
 
 	now := e.now
@@ -139,7 +158,12 @@ func (e *Email) Send(ctx context.Context, o *SendOptions) error {
 		"MIME-Version": "1.0",
 		"Reply-To":     replyTo.String(),
 		"Subject":      mime.QEncoding.Encode("UTF-8", e.Subject),
-		"To":           e.To.String(),
+		"To": joinIdentities(
+			append([]Identity{e.To}, e.AdditionalTo...),
+		),
+	}
+	if len(e.CC) > 0 {
+		headers["Cc"] = joinIdentities(e.CC)
 	}
 	for k, s := range headers {
 		b.WriteString(k)
@@ -149,23 +173,28 @@ func (e *Email) Send(ctx context.Context, o *SendOptions) error {
 	}
 
 	if _, err := b.WriteString(crlf); err != nil {
-		return errors.Tag(err, "write start of body")
+		return nil, errors.Tag(err, "write start of body")
 	}
+	bodyStart := b.Len()
 	if err := writePart(m, plainTextContent, e.writePlainText); err != nil {
-		return errors.Tag(err, "write plain text part")
+		return nil, errors.Tag(err, "write plain text part")
 	}
 	if err := writePart(m, htmlContent, e.writeHTML); err != nil {
-		return errors.Tag(err, "write html part")
+		return nil, errors.Tag(err, "write html part")
 	}
 
 	if err := m.Close(); err != nil {
-		return errors.Tag(err, "finalize body")
+		return nil, errors.Tag(err, "finalize body")
+	}
+	if o.DKIM == nil {
+		return b.Bytes(), nil
 	}
 
-	if err := o.Sender.Send(ctx, o.From, e.To, b.Bytes()); err != nil {
-		log.Printf("send email: %s", err)
-		// Ensure that we do not expose details on the email infrastructure.
-		return errors.New("internal error sending email")
+	s, err := o.DKIM.sign(headers, b.Bytes()[bodyStart:], now)
+	if err != nil {
+		return nil, errors.Tag(err, "dkim")
 	}
-	return nil
+	signed := make([]byte, 0, len("DKIM-Signature: ")+len(s)+2+b.Len())
+	signed = append(signed, "DKIM-Signature"+colonSpace+s+crlf...)
+	return append(signed, b.Bytes()...), nil
 }
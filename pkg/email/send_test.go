@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2023-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -20,6 +20,7 @@ import (
 	"context"
 	"net/mail"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -165,7 +166,7 @@ func TestEmail_Send(t *testing.T) {
 					"Subject": []string{"Email Subject"},
 					"To":      []string{`"To-Name" <to@example.com>`},
 				},
-				PlainText: "Hi,\r\n\r\nprimary\r\n\r\nmessage\r\n\r\nBefore Help LinkHelp Link Label (https://example.com/learn)After Help Link\r\n\r\nCTA-Text: https://example.com/cta\r\n\r\nsecondary\r\n\r\nmessage\r\n\r\nRegards,\r\nThe Test App Name Team - https://example.com\r\n\r\nCustom Plain Text Footer",
+				PlainText: "Email Title\r\n\r\nHi,\r\n\r\nprimary\r\n\r\nmessage\r\n\r\nBefore Help LinkHelp Link Label (https://example.com/learn)After Help Link\r\n\r\nCTA-Text: https://example.com/cta\r\n\r\nsecondary\r\n\r\nmessage\r\n\r\nRegards,\r\nThe Test App Name Team - https://example.com\r\n\r\nCustom Plain Text Footer",
 			},
 		},
 		{
@@ -280,3 +281,149 @@ func TestEmail_Send(t *testing.T) {
 		})
 	}
 }
+
+func TestEmail_Render(t *testing.T) {
+	ctaURL, _ := sharedTypes.ParseAndValidateURL("https://example.com/cta")
+	cs := collectingSender{}
+	so := SendOptions{
+		From:   Identity{Address: "from@example.com"},
+		Sender: &cs,
+	}
+	e := Email{
+		Content: &CTAContent{
+			PublicOptions: &PublicOptions{
+				AppName: "Test App Name",
+				SiteURL: "https://example.com",
+			},
+			Message: Message{"line1"},
+			Title:   "Title",
+			CTAText: "CTA Text",
+			CTAURL:  ctaURL,
+		},
+		Subject:  "Email Subject",
+		To:       Identity{Address: "to@example.com"},
+		boundary: "boundary",
+		now:      time.Unix(1, 0),
+	}
+	blob, err := e.Render(&so)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if len(cs.blobs) != 0 {
+		t.Fatalf("Render() must not send, n = %v", len(cs.blobs))
+	}
+	if err = e.Send(context.Background(), &so); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	sent, err := cs.Parse()
+	if err != nil {
+		t.Fatalf("Send() parse output, err = %v", err)
+	}
+	rendered, err := (&collectingSender{blobs: [][]byte{blob}}).Parse()
+	if err != nil {
+		t.Fatalf("Render() parse output, err = %v", err)
+	}
+	if !reflect.DeepEqual(rendered, sent) {
+		t.Errorf("Render() mismatch: %#v != %#v", rendered, sent)
+	}
+}
+
+func TestEmail_SendMultipleRecipients(t *testing.T) {
+	cs := collectingSender{}
+	so := SendOptions{
+		From:   Identity{Address: "from@example.com"},
+		Sender: &cs,
+	}
+	to := Identity{Address: "to@example.com"}
+	to2 := Identity{Address: "to2@example.com"}
+	cc := Identity{Address: "cc@example.com"}
+	e := Email{
+		AdditionalTo: []Identity{to2},
+		CC:           []Identity{cc},
+		Content: &NoCTAContent{
+			PublicOptions: &PublicOptions{
+				AppName: "Test App Name",
+				SiteURL: "https://example.com",
+			},
+			Message: Message{"line1"},
+			Title:   "Title",
+		},
+		Subject: "Email Subject",
+		To:      to,
+	}
+	if err := e.Send(context.Background(), &so); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := [][]Identity{{to, to2, cc}}; !reflect.DeepEqual(cs.recipients, want) {
+		t.Errorf("Send() recipients = %v, want %v", cs.recipients, want)
+	}
+	emails, err := cs.Parse()
+	if err != nil {
+		t.Fatalf("Send() parse output, err = %v", err)
+	}
+	h := emails[0].Header
+	if s := h.Get("To"); s != "<to@example.com>, <to2@example.com>" {
+		t.Errorf("Send() To header = %q", s)
+	}
+	if s := h.Get("Cc"); s != "<cc@example.com>" {
+		t.Errorf("Send() Cc header = %q", s)
+	}
+
+	e.CC = []Identity{{Address: "invalid"}}
+	if err = e.Send(context.Background(), &so); err == nil {
+		t.Errorf("Send() expected error for invalid cc")
+	}
+}
+
+func TestEmail_SendCTAPlainTextPart(t *testing.T) {
+	// Exceed the quoted-printable line length for forcing soft line breaks.
+	ctaURL, _ := sharedTypes.ParseAndValidateURL(
+		"https://example.com/cta/" + strings.Repeat("x", 100),
+	)
+	cs := collectingSender{}
+	so := SendOptions{
+		From:   Identity{Address: "from@example.com"},
+		Sender: &cs,
+	}
+	e := Email{
+		Content: &CTAContent{
+			PublicOptions: &PublicOptions{
+				AppName: "Test App Name",
+				SiteURL: "https://example.com",
+			},
+			Message: Message{"line1"},
+			Title:   "Email Title",
+			CTAText: "CTA-Text",
+			CTAURL:  ctaURL,
+		},
+		Subject: "Email Subject",
+		To:      Identity{Address: "to@example.com"},
+	}
+	if err := e.Send(context.Background(), &so); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	emails, err := cs.Parse()
+	if err != nil {
+		t.Fatalf("Send() parse output, err = %v", err)
+	}
+	ct := emails[0].Header.Get("Content-Type")
+	if !strings.HasPrefix(ct, "multipart/alternative;") {
+		t.Errorf("Send() Content-Type = %q, want multipart/alternative", ct)
+	}
+	if _, ok := emails[0].Parts[htmlContent]; !ok {
+		t.Errorf("Send() missing html part")
+	}
+	s, ok := emails[0].Parts[plainTextContent]
+	if !ok {
+		t.Fatalf("Send() missing plain text part")
+	}
+	for _, want := range []string{
+		"Email Title",
+		"line1",
+		"CTA-Text: " + ctaURL.String(),
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("Send() plain text %q does not contain %q", s, want)
+		}
+	}
+}
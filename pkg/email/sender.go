@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -27,12 +27,14 @@ import (
 	"net"
 	"net/mail"
 	"net/smtp"
+	"sync"
+	"time"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
 )
 
 type Sender interface {
-	Send(ctx context.Context, from, to Identity, blob []byte) error
+	Send(ctx context.Context, from Identity, to []Identity, blob []byte) error
 }
 
 func NewSender(address SMTPAddress, smtpHello string, smtpAuth smtp.Auth) Sender {
@@ -52,17 +54,34 @@ func NewSender(address SMTPAddress, smtpHello string, smtpAuth smtp.Auth) Sender
 	}
 }
 
+// NewPooledSender is like NewSender, but keeps the SMTP connection open
+// between emails. A broken connection is replaced on the next Send.
+func NewPooledSender(address SMTPAddress, smtpHello string, smtpAuth smtp.Auth) Sender {
+	if address.IsSpecial() {
+		return NewSender(address, smtpHello, smtpAuth)
+	}
+	return &pooledSMTPSender{
+		smtpSender: smtpSender{
+			addr:  address,
+			auth:  smtpAuth,
+			hello: smtpHello,
+		},
+	}
+}
+
 type ParsedEmail struct {
 	mail.Header
 	Parts map[string]string
 }
 
 type collectingSender struct {
-	blobs [][]byte
+	blobs      [][]byte
+	recipients [][]Identity
 }
 
-func (c *collectingSender) Send(_ context.Context, _, _ Identity, blob []byte) error {
+func (c *collectingSender) Send(_ context.Context, _ Identity, to []Identity, blob []byte) error {
 	c.blobs = append(c.blobs, blob)
+	c.recipients = append(c.recipients, to)
 	return nil
 }
 
@@ -101,14 +120,14 @@ func (c *collectingSender) Parse() ([]ParsedEmail, error) {
 type discardSender struct {
 }
 
-func (discardSender) Send(_ context.Context, _, _ Identity, _ []byte) error {
+func (discardSender) Send(_ context.Context, _ Identity, _ []Identity, _ []byte) error {
 	return nil
 }
 
 type loggingSender struct {
 }
 
-func (l loggingSender) Send(_ context.Context, _, _ Identity, blob []byte) error {
+func (l loggingSender) Send(_ context.Context, _ Identity, _ []Identity, blob []byte) error {
 	log.Println(string(blob))
 	return nil
 }
@@ -119,45 +138,68 @@ type smtpSender struct {
 	hello string
 }
 
-func (s smtpSender) Send(ctx context.Context, from, to Identity, blob []byte) error {
+func (s smtpSender) Send(ctx context.Context, from Identity, to []Identity, blob []byte) error {
 	var d net.Dialer
 	conn, err := d.DialContext(ctx, "tcp", string(s.addr))
 	if err != nil {
 		return errors.Tag(err, "connect")
 	}
-	ctx, done := context.WithCancel(ctx)
-	defer done()
-	go func() {
-		<-ctx.Done()
+	stop := context.AfterFunc(ctx, func() {
 		_ = conn.Close()
-	}()
-	c, err := smtp.NewClient(conn, s.addr.Host())
+	})
+	defer stop()
+	c, err := s.handshake(conn)
 	if err != nil {
-		return errors.Tag(err, "create client")
+		_ = conn.Close()
+		return err
 	}
 	defer func() { _ = c.Close() }()
 
+	if err = transmit(c, from, to, blob); err != nil {
+		return err
+	}
+	if err = c.Quit(); err != nil {
+		return errors.Tag(err, "quit")
+	}
+	return nil
+}
+
+func (s smtpSender) handshake(conn net.Conn) (*smtp.Client, error) {
+	c, err := smtp.NewClient(conn, s.addr.Host())
+	if err != nil {
+		return nil, errors.Tag(err, "create client")
+	}
 	if err = c.Hello(s.hello); err != nil {
-		return errors.Tag(err, "hello")
+		_ = c.Close()
+		return nil, errors.Tag(err, "hello")
 	}
 	if ok, _ := c.Extension("STARTTLS"); ok {
 		if err = c.StartTLS(&tls.Config{ServerName: s.addr.Host()}); err != nil {
-			return errors.Tag(err, "starttls")
+			_ = c.Close()
+			return nil, errors.Tag(err, "starttls")
 		}
 	}
 	if s.auth != nil {
 		if ok, _ := c.Extension("AUTH"); !ok {
-			return errors.New("expected AUTH support")
+			_ = c.Close()
+			return nil, errors.New("expected AUTH support")
 		}
 		if err = c.Auth(s.auth); err != nil {
-			return errors.Tag(err, "auth")
+			_ = c.Close()
+			return nil, errors.Tag(err, "auth")
 		}
 	}
-	if err = c.Mail(string(from.Address)); err != nil {
+	return c, nil
+}
+
+func transmit(c *smtp.Client, from Identity, to []Identity, blob []byte) error {
+	if err := c.Mail(string(from.Address)); err != nil {
 		return errors.Tag(err, "mail")
 	}
-	if err = c.Rcpt(string(to.Address)); err != nil {
-		return errors.Tag(err, "receipt")
+	for _, identity := range to {
+		if err := c.Rcpt(string(identity.Address)); err != nil {
+			return errors.Tag(err, "receipt")
+		}
 	}
 	w, err := c.Data()
 	if err != nil {
@@ -169,8 +211,84 @@ func (s smtpSender) Send(ctx context.Context, from, to Identity, blob []byte) er
 	if err = w.Close(); err != nil {
 		return errors.Tag(err, "flush write")
 	}
-	if err = c.Quit(); err != nil {
-		return errors.Tag(err, "quit")
+	return nil
+}
+
+type pooledSMTPSender struct {
+	smtpSender
+
+	mu   sync.Mutex
+	c    *smtp.Client
+	conn net.Conn
+}
+
+func (s *pooledSMTPSender) Send(ctx context.Context, from Identity, to []Identity, blob []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.c != nil {
+		// Detect connections that were closed by the server while idle.
+		if err := s.withDeadline(ctx, s.c.Reset); err != nil {
+			s.closeConn()
+		}
+	}
+	if s.c == nil {
+		if err := s.connect(ctx); err != nil {
+			return err
+		}
+	}
+	err := s.withDeadline(ctx, func() error {
+		return transmit(s.c, from, to, blob)
+	})
+	if err != nil {
+		s.closeConn()
+		return err
 	}
 	return nil
 }
+
+func (s *pooledSMTPSender) connect(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", string(s.addr))
+	if err != nil {
+		return errors.Tag(err, "connect")
+	}
+	s.conn = conn
+	err = s.withDeadline(ctx, func() error {
+		c, err2 := s.handshake(conn)
+		s.c = c
+		return err2
+	})
+	if err != nil {
+		s.closeConn()
+		return err
+	}
+	return nil
+}
+
+func (s *pooledSMTPSender) withDeadline(ctx context.Context, fn func() error) error {
+	if d, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetDeadline(d)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = s.conn.SetDeadline(time.Unix(1, 0))
+	})
+	err := fn()
+	if !stop() {
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	_ = s.conn.SetDeadline(time.Time{})
+	return err
+}
+
+func (s *pooledSMTPSender) closeConn() {
+	if s.c != nil {
+		_ = s.c.Close()
+		s.c = nil
+	} else if s.conn != nil {
+		_ = s.conn.Close()
+	}
+	s.conn = nil
+}
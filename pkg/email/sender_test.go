@@ -0,0 +1,172 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package email
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSMTPServer struct {
+	l net.Listener
+
+	mu          sync.Mutex
+	connections int
+	messages    []string
+	conns       []net.Conn
+	mailReplies []string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+	s := &fakeSMTPServer{l: l}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err2 := l.Accept()
+			if err2 != nil {
+				return
+			}
+			s.mu.Lock()
+			s.connections++
+			s.conns = append(s.conns, conn)
+			s.mu.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(s string) bool {
+		_, err := conn.Write([]byte(s + "\r\n"))
+		return err == nil
+	}
+	if !reply("220 localhost ready") {
+		return
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.Fields(line + " x")[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			reply(s.nextMailReply())
+		case "RCPT", "RSET", "NOOP":
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err2 := r.ReadString('\n')
+				if err2 != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, b.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// failMail queues replies for upcoming MAIL commands.
+func (s *fakeSMTPServer) failMail(replies ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mailReplies = append(s.mailReplies, replies...)
+}
+
+func (s *fakeSMTPServer) nextMailReply() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.mailReplies) == 0 {
+		return "250 OK"
+	}
+	r := s.mailReplies[0]
+	s.mailReplies = s.mailReplies[1:]
+	return r
+}
+
+func (s *fakeSMTPServer) stats() (int, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections, append([]string(nil), s.messages...)
+}
+
+func TestNewPooledSender(t *testing.T) {
+	srv := newFakeSMTPServer(t)
+	sender := NewPooledSender(SMTPAddress(srv.l.Addr().String()), "localhost", nil)
+	from := Identity{Address: "from@example.com"}
+	to := []Identity{{Address: "to@example.com"}}
+	ctx, done := context.WithTimeout(context.Background(), 10*time.Second)
+	defer done()
+
+	for _, body := range []string{"one", "two"} {
+		if err := sender.Send(ctx, from, to, []byte(body+"\r\n")); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if n, messages := srv.stats(); n != 1 || len(messages) != 2 {
+		t.Fatalf("expected 2 messages on 1 connection, got %d on %d", len(messages), n)
+	}
+
+	srv.dropConnections()
+	if err := sender.Send(ctx, from, to, []byte("three\r\n")); err != nil {
+		t.Fatalf("Send() after reconnect error = %v", err)
+	}
+	n, messages := srv.stats()
+	if n != 2 || len(messages) != 3 {
+		t.Fatalf("expected 3 messages on 2 connections, got %d on %d", len(messages), n)
+	}
+	if messages[2] != "three\r\n" {
+		t.Errorf("unexpected message after reconnect: %q", messages[2])
+	}
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -21,12 +21,25 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
 )
 
 type CORSOptions struct {
 	SiteOrigin      string
 	AllowOrigins    []string
 	AllowWebsockets bool
+
+	// MaxAge controls how long browsers may cache preflight responses.
+	// The Access-Control-Max-Age header is omitted when MaxAge is 0.
+	MaxAge time.Duration
+}
+
+func (o *CORSOptions) Validate() error {
+	if o.MaxAge < 0 {
+		return &errors.ValidationError{Msg: "max age must not be negative"}
+	}
+	return nil
 }
 
 func (o *CORSOptions) originValid(origin string) bool {
@@ -46,7 +59,7 @@ func CORS(options CORSOptions) MiddlewareFunc {
 		http.MethodPost,
 		http.MethodPut,
 	}, ",")
-	maxAge := strconv.FormatInt(int64(time.Hour.Seconds()), 10)
+	maxAge := strconv.FormatInt(int64(options.MaxAge.Seconds()), 10)
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) {
 			origin := c.Request.Header.Get("Origin")
@@ -58,7 +71,6 @@ func CORS(options CORSOptions) MiddlewareFunc {
 				return
 			}
 			h := c.Writer.Header()
-			h.Set("Access-Control-Max-Age", maxAge)
 			h.Set("Vary", "Origin")
 			if !options.originValid(origin) {
 				c.Writer.WriteHeader(http.StatusForbidden)
@@ -69,6 +81,9 @@ func CORS(options CORSOptions) MiddlewareFunc {
 			h.Set("Access-Control-Allow-Methods", methods)
 			h.Set("Access-Control-Allow-Origin", origin)
 			if c.Request.Method == http.MethodOptions {
+				if options.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", maxAge)
+				}
 				c.Writer.WriteHeader(http.StatusNoContent)
 				return
 			}
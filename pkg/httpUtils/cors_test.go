@@ -0,0 +1,62 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package httpUtils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS_MaxAge(t *testing.T) {
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		method string
+		want   string
+	}{
+		{"preflight", time.Hour, http.MethodOptions, "3600"},
+		{"preflight custom", 10 * time.Minute, http.MethodOptions, "600"},
+		{"preflight disabled", 0, http.MethodOptions, ""},
+		{"actual request", time.Hour, http.MethodGet, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := CORS(CORSOptions{
+				AllowOrigins: []string{"https://app.example.com"},
+				MaxAge:       tt.maxAge,
+			})(func(c *Context) {
+				c.Writer.WriteHeader(http.StatusOK)
+			})
+			r := httptest.NewRequest(tt.method, "https://api.example.com/", nil)
+			r.Header.Set("Origin", "https://app.example.com")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if got := w.Header().Get("Access-Control-Max-Age"); got != tt.want {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSOptions_Validate(t *testing.T) {
+	o := CORSOptions{MaxAge: -time.Second}
+	if err := o.Validate(); err == nil {
+		t.Error("expected error for negative max age")
+	}
+}
@@ -0,0 +1,48 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package httpUtils
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/das7pad/overleaf-go/pkg/metrics"
+)
+
+// ServeMetrics exposes /metrics on a separate listener, which defaults to
+// the given port. It is a no-op unless enabled via ENABLE_METRICS.
+func ServeMetrics(ctx context.Context, do func(func() error), port int) {
+	if !metrics.Enabled() {
+		return
+	}
+	ServeInternal(ctx, do, port, http.NewServeMux())
+}
+
+// ServeInternal exposes the given handlers on the listener for /metrics,
+// which defaults to the given port. /metrics is only added when enabled via
+// ENABLE_METRICS. The listener must not be reachable from the outside.
+func ServeInternal(ctx context.Context, do func(func() error), port int, mux *http.ServeMux) {
+	if metrics.Enabled() {
+		mux.Handle("/metrics", metrics.Handler())
+	}
+	server := &http.Server{Handler: mux}
+	ListenAndServeEach(do, server, metrics.ListenAddress(port))
+	do(func() error {
+		<-ctx.Done()
+		return server.Shutdown(context.Background())
+	})
+}
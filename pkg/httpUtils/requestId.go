@@ -0,0 +1,106 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package httpUtils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+)
+
+const HeaderXRequestId = "X-Request-Id"
+
+type requestIdKey struct{}
+
+// RequestId returns the id of the request that ctx belongs to, if any.
+func RequestId(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+func WithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+// TagRequestId adds the id of the request that ctx belongs to, if any, to
+// err for correlating logs across services.
+func TagRequestId(ctx context.Context, err error) error {
+	if id := RequestId(ctx); id != "" && err != nil {
+		return errors.Tag(err, "request "+id)
+	}
+	return err
+}
+
+func validRequestId(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestIdMiddleware accepts the X-Request-Id of upstream services or
+// generates a new one.
+func requestIdMiddleware(next HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		id := c.Request.Header.Get(HeaderXRequestId)
+		if !validRequestId(id) {
+			id = newRequestId()
+		}
+		c.Writer.Header().Set(HeaderXRequestId, id)
+		next(c.AddValue(requestIdKey{}, id))
+	}
+}
+
+type forwardRequestId struct {
+	next http.RoundTripper
+}
+
+func (t forwardRequestId) RoundTrip(r *http.Request) (*http.Response, error) {
+	id := RequestId(r.Context())
+	if id != "" && r.Header.Get(HeaderXRequestId) == "" {
+		r = r.Clone(r.Context())
+		r.Header.Set(HeaderXRequestId, id)
+	}
+	return t.next.RoundTrip(r)
+}
+
+// ForwardRequestId wraps the transport of clients for internal services.
+// It sets the X-Request-Id header from the context of outgoing requests.
+// A nil transport defaults to http.DefaultTransport.
+func ForwardRequestId(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return forwardRequestId{next: next}
+}
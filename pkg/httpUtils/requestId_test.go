@@ -0,0 +1,119 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package httpUtils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+)
+
+func TestRequestIdPropagation(t *testing.T) {
+	downstreamIds := make(chan string, 1)
+	downstream := NewRouter(&RouterOptions{})
+	downstream.GET("/downstream", func(c *Context) {
+		downstreamIds <- RequestId(c)
+		c.Writer.WriteHeader(http.StatusNoContent)
+	})
+	downstreamServer := httptest.NewServer(downstream)
+	defer downstreamServer.Close()
+
+	client := &http.Client{Transport: ForwardRequestId(nil)}
+	upstream := NewRouter(&RouterOptions{})
+	upstream.GET("/upstream", func(c *Context) {
+		r, err := http.NewRequestWithContext(
+			c, http.MethodGet, downstreamServer.URL+"/downstream", nil,
+		)
+		if err != nil {
+			RespondErr(c, err)
+			return
+		}
+		res, err := client.Do(r)
+		if err != nil {
+			RespondErr(c, err)
+			return
+		}
+		_ = res.Body.Close()
+		c.Writer.WriteHeader(res.StatusCode)
+	})
+	upstreamServer := httptest.NewServer(upstream)
+	defer upstreamServer.Close()
+
+	tests := []struct {
+		name     string
+		incoming string
+		want     string
+	}{
+		{"forwards incoming", "abc-123", "abc-123"},
+		{"generates missing", "", ""},
+		{"replaces invalid", "foo bar", ""},
+		{"replaces too long", strings.Repeat("a", 65), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequest(
+				http.MethodGet, upstreamServer.URL+"/upstream", nil,
+			)
+			if err != nil {
+				t.Fatalf("create request: %s", err)
+			}
+			if tt.incoming != "" {
+				r.Header.Set(HeaderXRequestId, tt.incoming)
+			}
+			res, err := http.DefaultClient.Do(r)
+			if err != nil {
+				t.Fatalf("send request: %s", err)
+			}
+			_ = res.Body.Close()
+			if res.StatusCode != http.StatusNoContent {
+				t.Fatalf("status = %d, want %d", res.StatusCode, http.StatusNoContent)
+			}
+			got := res.Header.Get(HeaderXRequestId)
+			if tt.want != "" && got != tt.want {
+				t.Errorf("response id = %q, want %q", got, tt.want)
+			}
+			if tt.want == "" && (got == "" || got == tt.incoming) {
+				t.Errorf("response id = %q, want generated id", got)
+			}
+			if d := <-downstreamIds; d != got {
+				t.Errorf("downstream id = %q, want %q", d, got)
+			}
+		})
+	}
+}
+
+func TestTagRequestId(t *testing.T) {
+	err := errors.New("foo")
+	if got := TagRequestId(context.Background(), err); got != err {
+		t.Errorf("TagRequestId() without id = %v, want %v", got, err)
+	}
+	ctx := WithRequestId(context.Background(), "abc-123")
+	got := TagRequestId(ctx, err)
+	if want := "request abc-123: foo"; got.Error() != want {
+		t.Errorf("TagRequestId() = %q, want %q", got.Error(), want)
+	}
+	if errors.GetCause(got) != err {
+		t.Errorf("TagRequestId() cause = %v, want %v", errors.GetCause(got), err)
+	}
+	if got = TagRequestId(ctx, nil); got != nil {
+		t.Errorf("TagRequestId(nil) = %v, want nil", got)
+	}
+}
@@ -142,7 +142,7 @@ func GetAndLogErrResponseDetails(c *Context, err error) (int, string) {
 	default:
 		log.Printf(
 			"%s %s: %s",
-			c.Request.Method, c.Request.URL.Path, err.Error(),
+			c.Request.Method, c.Request.URL.Path, TagRequestId(c, err).Error(),
 		)
 		code = http.StatusInternalServerError
 	}
@@ -140,6 +140,7 @@ func NewRouter(options *RouterOptions) *Router {
 	router := Router{
 		Router: mux.NewRouter(),
 	}
+	router.Use(requestIdMiddleware)
 	router.OmitRouteFromContext(true)
 	statusHandler := func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -19,6 +19,8 @@ package httpUtils
 import (
 	"strconv"
 	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/metrics"
 )
 
 func TimeStage(c *Context, label string) func() {
@@ -34,6 +36,7 @@ func EndTotalTimer(c *Context) {
 
 func endTimer(c *Context, label string, t0 time.Time) {
 	diff := time.Since(t0)
+	metrics.ObserveHTTPStage(c.Request.Method, label, diff)
 	ms := int64(diff / time.Millisecond)
 	micro := int64(diff % time.Millisecond / time.Microsecond)
 	c.Writer.Header().Add(
@@ -0,0 +1,93 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package integrationTests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/das7pad/overleaf-go/pkg/models/oneTimeToken"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/models/user"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+// CreateUser persists a new user with a random email address.
+func CreateUser(t testing.TB, ctx context.Context, db *pgxpool.Pool) sharedTypes.UUID {
+	t.Helper()
+	token, err := oneTimeToken.GenerateNewToken()
+	if err != nil {
+		t.Fatalf("generate token: %s", err)
+	}
+	u := user.NewUser(sharedTypes.Email(string(token) + "@foo.bar"))
+	if err = u.Id.Populate(); err != nil {
+		t.Fatalf("generate user id: %s", err)
+	}
+	u.AuditLog = []user.AuditLogEntry{{
+		CreatedAt: u.CreatedAt,
+		Operation: user.AuditLogOperationLogin,
+	}}
+	u.HashedPassword = "not-a-bcrypt-hash"
+	u.OneTimeToken = token
+	u.OneTimeTokenUse = oneTimeToken.EmailConfirmationUse
+	if err = user.New(db).CreateUser(ctx, &u); err != nil {
+		t.Fatalf("create user: %s", err)
+	}
+	return u.Id
+}
+
+// CreateProject persists a project with a main.tex root doc and additional
+// empty docs for the given names. It returns the project id and the doc ids,
+// starting with the root doc.
+func CreateProject(t testing.TB, ctx context.Context, db *pgxpool.Pool, ownerId sharedTypes.UUID, names ...sharedTypes.Filename) (sharedTypes.UUID, sharedTypes.UUIDs) {
+	t.Helper()
+	p := project.NewProject()
+	if err := p.Id.Populate(); err != nil {
+		t.Fatalf("generate project id: %s", err)
+	}
+	p.CreatedAt = time.Now().Truncate(time.Microsecond)
+	p.Name = "foo"
+	p.OwnerId = ownerId
+	d := project.NewDoc("main.tex")
+	d.Snapshot = "\\documentclass{article}\n\\begin{document}\n\\end{document}\n"
+	p.RootFolder.Docs = append(p.RootFolder.Docs, d)
+	for _, name := range names {
+		p.RootFolder.Docs = append(p.RootFolder.Docs, project.NewDoc(name))
+	}
+	b, err := sharedTypes.GenerateUUIDBulk(p.RootFolder.CountNodes())
+	if err != nil {
+		t.Fatalf("generate tree ids: %s", err)
+	}
+	p.RootFolder.PopulateIds(b)
+	p.RootDoc.Doc = p.RootFolder.Docs[0]
+
+	pm := project.New(db, nil)
+	if err = pm.PrepareProjectCreation(ctx, &p); err != nil {
+		t.Fatalf("prepare project: %s", err)
+	}
+	if err = pm.FinalizeProjectCreation(ctx, &p); err != nil {
+		t.Fatalf("finalize project: %s", err)
+	}
+	docIds := make(sharedTypes.UUIDs, len(p.RootFolder.Docs))
+	for i, doc := range p.RootFolder.Docs {
+		docIds[i] = doc.Id
+	}
+	return p.Id, docIds
+}
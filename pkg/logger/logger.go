@@ -0,0 +1,126 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/options/env"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatText Format = "text"
+)
+
+func (f Format) Validate() error {
+	switch f {
+	case FormatJSON, FormatText:
+		return nil
+	default:
+		return &errors.ValidationError{
+			Msg: "unknown log format, expected json or text",
+		}
+	}
+}
+
+// Logger writes leveled messages with tags. Tags are alternating keys and
+// values, like in log/slog.
+// The text format matches the plain log.Println output of the past and
+// omits the level.
+type Logger struct {
+	json *slog.Logger
+	text *log.Logger
+}
+
+func New(format Format, w io.Writer) (*Logger, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if format == FormatJSON {
+		return &Logger{json: slog.New(slog.NewJSONHandler(w, nil))}, nil
+	}
+	return &Logger{text: log.New(w, "", log.LstdFlags)}, nil
+}
+
+var std = newFromEnv()
+
+func newFromEnv() *Logger {
+	format := Format(env.GetString("LOG_FORMAT", string(FormatText)))
+	if format == FormatText {
+		// Share the output of the log package.
+		return &Logger{text: log.Default()}
+	}
+	l, err := New(format, os.Stderr)
+	if err != nil {
+		panic(errors.Tag(err, "LOG_FORMAT"))
+	}
+	return l
+}
+
+func (l *Logger) Info(msg string, tags ...any) {
+	l.write(slog.LevelInfo, msg, nil, tags)
+}
+
+func (l *Logger) Warn(msg string, tags ...any) {
+	l.write(slog.LevelWarn, msg, nil, tags)
+}
+
+func (l *Logger) Error(msg string, err error, tags ...any) {
+	l.write(slog.LevelError, msg, err, tags)
+}
+
+func (l *Logger) write(level slog.Level, msg string, err error, tags []any) {
+	if l.json != nil {
+		if err != nil {
+			tags = append(tags, "error", err.Error())
+		}
+		l.json.Log(context.Background(), level, msg, tags...)
+		return
+	}
+	b := strings.Builder{}
+	b.WriteString(msg)
+	for i := 0; i+1 < len(tags); i += 2 {
+		_, _ = fmt.Fprintf(&b, " %v=%v", tags[i], tags[i+1])
+	}
+	if err != nil {
+		b.WriteString(": ")
+		b.WriteString(err.Error())
+	}
+	_ = l.text.Output(3, b.String())
+}
+
+func Info(msg string, tags ...any) {
+	std.Info(msg, tags...)
+}
+
+func Warn(msg string, tags ...any) {
+	std.Warn(msg, tags...)
+}
+
+func Error(msg string, err error, tags ...any) {
+	std.Error(msg, err, tags...)
+}
@@ -0,0 +1,71 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+)
+
+func TestLoggerJSON(t *testing.T) {
+	buf := bytes.Buffer{}
+	l, err := New(FormatJSON, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	l.Error("flush failed", errors.New("boom"), "projectId", "p1")
+
+	var entry map[string]any
+	if err = json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decode %q: %s", buf.String(), err)
+	}
+	want := map[string]any{
+		"level":     "ERROR",
+		"msg":       "flush failed",
+		"projectId": "p1",
+		"error":     "boom",
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("entry[%q] = %v, want %v", k, entry[k], v)
+		}
+	}
+}
+
+func TestLoggerText(t *testing.T) {
+	buf := bytes.Buffer{}
+	l, err := New(FormatText, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	l.Error("flush failed", errors.New("boom"), "projectId", "p1")
+
+	want := "flush failed projectId=p1: boom\n"
+	if got := buf.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("output = %q, want suffix %q", got, want)
+	}
+}
+
+func TestNewRejectsUnknownFormat(t *testing.T) {
+	if _, err := New("xml", &bytes.Buffer{}); !errors.IsValidationError(err) {
+		t.Errorf("New() error = %v, want validation error", err)
+	}
+}
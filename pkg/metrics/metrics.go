@@ -0,0 +1,80 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/options/env"
+	"github.com/das7pad/overleaf-go/pkg/options/listenAddress"
+)
+
+var enabled = env.GetBool("ENABLE_METRICS")
+
+// Enabled reports whether ENABLE_METRICS=true is set. All the recording
+// helpers are no-ops otherwise.
+func Enabled() bool {
+	return enabled
+}
+
+var Default = NewRegistry()
+
+var (
+	httpRequestDuration = Default.NewHistogramVec(
+		"http_request_duration_seconds",
+		"Duration of HTTP requests per stage of the Server-Timing header.",
+		DefaultDurationBuckets,
+		"method", "stage",
+	)
+	postgresQueries = Default.NewCounterVec(
+		"postgres_queries_total",
+		"Number of postgres queries.",
+		"status",
+	)
+	redisCommands = Default.NewCounterVec(
+		"redis_commands_total",
+		"Number of redis commands, including the ones in pipelines.",
+		"command", "status",
+	)
+)
+
+func ObserveHTTPStage(method, stage string, d time.Duration) {
+	if !enabled {
+		return
+	}
+	httpRequestDuration.ObserveDuration(d, method, stage)
+}
+
+func statusLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+func Handler() http.Handler {
+	return Default
+}
+
+// ListenAddress returns the addresses of the internal listener, which serves
+// /metrics, from METRICS_LISTEN_ADDRESS and METRICS_PORT.
+func ListenAddress(port int) []string {
+	return listenAddress.ParseOverride(
+		"METRICS_LISTEN_ADDRESS", "METRICS_PORT", port,
+	)
+}
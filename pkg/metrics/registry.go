@@ -0,0 +1,287 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+type Registry struct {
+	mu         sync.Mutex
+	collectors map[string]collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.collectors[name]; exists {
+		panic("metrics: duplicate registration of " + name)
+	}
+	r.collectors[name] = c
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	collectors := r.collectors
+	r.mu.Unlock()
+	sort.Strings(names)
+
+	cw := countingWriter{w: w}
+	bw := bufio.NewWriter(&cw)
+	for _, name := range names {
+		collectors[name].write(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = r.WriteTo(w)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type series struct {
+	name   string
+	labels []string
+	mu     sync.Mutex
+	values map[string]*seriesEntry
+}
+
+type seriesEntry struct {
+	labelValues []string
+	counter     atomic.Uint64
+	mu          sync.Mutex
+	buckets     []uint64
+	sum         float64
+	count       uint64
+}
+
+func (s *series) get(labelValues []string, nBuckets int) *seriesEntry {
+	if len(labelValues) != len(s.labels) {
+		panic("metrics: label mismatch for " + s.name)
+	}
+	key := strings.Join(labelValues, "\x00")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, exists := s.values[key]
+	if !exists {
+		e = &seriesEntry{
+			labelValues: append([]string(nil), labelValues...),
+			buckets:     make([]uint64, nBuckets),
+		}
+		s.values[key] = e
+	}
+	return e
+}
+
+func (s *series) sorted() []*seriesEntry {
+	s.mu.Lock()
+	entries := make([]*seriesEntry, 0, len(s.values))
+	for _, e := range s.values {
+		entries = append(entries, e)
+	}
+	s.mu.Unlock()
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].labelValues, entries[j].labelValues
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+	return entries
+}
+
+func (s *series) writeLabels(w *bufio.Writer, values []string, extra ...string) {
+	if len(values) == 0 && len(extra) == 0 {
+		return
+	}
+	_ = w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			_ = w.WriteByte(',')
+		}
+		writeLabel(w, s.labels[i], v)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if len(values) > 0 || i > 0 {
+			_ = w.WriteByte(',')
+		}
+		writeLabel(w, extra[i], extra[i+1])
+	}
+	_ = w.WriteByte('}')
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeLabel(w *bufio.Writer, name, value string) {
+	_, _ = w.WriteString(name)
+	_, _ = w.WriteString(`="`)
+	_, _ = labelValueEscaper.WriteString(w, value)
+	_ = w.WriteByte('"')
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	_, _ = w.WriteString("# HELP " + name + " " + help + "\n")
+	_, _ = w.WriteString("# TYPE " + name + " " + kind + "\n")
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+type CounterVec struct {
+	series
+	help string
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		series: series{
+			name:   name,
+			labels: labels,
+			values: make(map[string]*seriesEntry),
+		},
+		help: help,
+	}
+	r.register(name, c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.get(labelValues, 0).counter.Add(1)
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	for _, e := range c.sorted() {
+		_, _ = w.WriteString(c.name)
+		c.writeLabels(w, e.labelValues)
+		_, _ = w.WriteString(" " + strconv.FormatUint(e.counter.Load(), 10))
+		_ = w.WriteByte('\n')
+	}
+}
+
+// DefaultDurationBuckets are upper bounds in seconds.
+var DefaultDurationBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30,
+}
+
+type HistogramVec struct {
+	series
+	help    string
+	buckets []float64
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		series: series{
+			name:   name,
+			labels: labels,
+			values: make(map[string]*seriesEntry),
+		},
+		help:    help,
+		buckets: buckets,
+	}
+	r.register(name, h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	e := h.get(labelValues, len(h.buckets))
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, upper := range h.buckets {
+		if v <= upper {
+			e.buckets[i]++
+		}
+	}
+	e.sum += v
+	e.count++
+}
+
+func (h *HistogramVec) ObserveDuration(d time.Duration, labelValues ...string) {
+	h.Observe(d.Seconds(), labelValues...)
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+	for _, e := range h.sorted() {
+		e.mu.Lock()
+		buckets := append([]uint64(nil), e.buckets...)
+		sum, count := e.sum, e.count
+		e.mu.Unlock()
+
+		for i, upper := range h.buckets {
+			_, _ = w.WriteString(h.name + "_bucket")
+			h.writeLabels(w, e.labelValues, "le", formatFloat(upper))
+			_, _ = w.WriteString(" " + strconv.FormatUint(buckets[i], 10))
+			_ = w.WriteByte('\n')
+		}
+		_, _ = w.WriteString(h.name + "_bucket")
+		h.writeLabels(w, e.labelValues, "le", "+Inf")
+		_, _ = w.WriteString(" " + strconv.FormatUint(count, 10))
+		_ = w.WriteByte('\n')
+
+		_, _ = w.WriteString(h.name + "_sum")
+		h.writeLabels(w, e.labelValues)
+		_, _ = w.WriteString(" " + formatFloat(sum))
+		_ = w.WriteByte('\n')
+
+		_, _ = w.WriteString(h.name + "_count")
+		h.writeLabels(w, e.labelValues)
+		_, _ = w.WriteString(" " + strconv.FormatUint(count, 10))
+		_ = w.WriteByte('\n')
+	}
+}
@@ -0,0 +1,68 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRegistryWriteTo(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("ops_total", "Number of ops.", "kind")
+	h := r.NewHistogramVec("op_seconds", "Op duration.", []float64{1, 5})
+
+	c.Inc("write")
+	c.Inc("read")
+	c.Inc("read")
+	c.Inc(`a"b`)
+	h.Observe(0.5)
+	h.Observe(3)
+	h.Observe(10)
+
+	buf := bytes.Buffer{}
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	want := `# HELP op_seconds Op duration.
+# TYPE op_seconds histogram
+op_seconds_bucket{le="1"} 1
+op_seconds_bucket{le="5"} 2
+op_seconds_bucket{le="+Inf"} 3
+op_seconds_sum 13.5
+op_seconds_count 3
+# HELP ops_total Number of ops.
+# TYPE ops_total counter
+ops_total{kind="a\"b"} 1
+ops_total{kind="read"} 2
+ops_total{kind="write"} 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteTo() = %s, want %s", got, want)
+	}
+}
+
+func TestRegistryRejectsDuplicates(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("ops_total", "Number of ops.")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic on duplicate registration")
+		}
+	}()
+	r.NewCounterVec("ops_total", "Number of ops.")
+}
@@ -0,0 +1,117 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package doc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+// encryptedSnapshotPrefix marks encrypted snapshots. Snapshots without it
+// are read as-is, which keeps snapshots written prior to enabling the
+// encryption readable.
+const encryptedSnapshotPrefix = "\x01enc:v1:"
+
+type EncryptionOptions struct {
+	// Key is a hex encoded AES-256 key for encrypting doc snapshots at rest.
+	//  Leave empty for storing snapshots in plaintext.
+	Key string `json:"key"`
+}
+
+func (o EncryptionOptions) Validate() error {
+	if o.Key == "" {
+		return nil
+	}
+	if b, err := hex.DecodeString(o.Key); err != nil || len(b) != 32 {
+		return &errors.ValidationError{
+			Msg: "key must be a hex encoded 32 bytes key",
+		}
+	}
+	return nil
+}
+
+// SnapshotCipher encrypts doc snapshots before storing them in the db.
+// A nil SnapshotCipher passes snapshots through in plaintext.
+type SnapshotCipher struct {
+	aead cipher.AEAD
+}
+
+func NewSnapshotCipher(o EncryptionOptions) (*SnapshotCipher, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	if o.Key == "" {
+		return nil, nil
+	}
+	key, _ := hex.DecodeString(o.Key)
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Tag(err, "init cipher")
+	}
+	aead, err := cipher.NewGCM(b)
+	if err != nil {
+		return nil, errors.Tag(err, "init gcm")
+	}
+	return &SnapshotCipher{aead: aead}, nil
+}
+
+// Seal encrypts the snapshot of the given doc. The docId is authenticated
+// as well, which prevents moving the encrypted snapshot to another doc.
+func (c *SnapshotCipher) Seal(docId sharedTypes.UUID, s string) (string, error) {
+	if c == nil {
+		return s, nil
+	}
+	n := c.aead.NonceSize()
+	nonce := make([]byte, n, n+len(s)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Tag(err, "generate nonce")
+	}
+	blob := c.aead.Seal(nonce, nonce, []byte(s), docId[:])
+	return encryptedSnapshotPrefix +
+		base64.RawStdEncoding.EncodeToString(blob), nil
+}
+
+func (c *SnapshotCipher) Open(docId sharedTypes.UUID, s string) (string, error) {
+	if !strings.HasPrefix(s, encryptedSnapshotPrefix) {
+		return s, nil
+	}
+	if c == nil {
+		return "", errors.New("snapshot is encrypted, but missing key")
+	}
+	blob, err := base64.RawStdEncoding.DecodeString(
+		s[len(encryptedSnapshotPrefix):],
+	)
+	if err != nil {
+		return "", errors.Tag(err, "decode encrypted snapshot")
+	}
+	n := c.aead.NonceSize()
+	if len(blob) < n {
+		return "", errors.New("encrypted snapshot is too short")
+	}
+	raw, err := c.aead.Open(nil, blob[:n], blob[n:], docId[:])
+	if err != nil {
+		return "", errors.Tag(err, "decrypt snapshot")
+	}
+	return string(raw), nil
+}
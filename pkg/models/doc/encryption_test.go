@@ -0,0 +1,100 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package doc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func newTestCipher(t *testing.T) *SnapshotCipher {
+	c, err := NewSnapshotCipher(EncryptionOptions{
+		Key: strings.Repeat("42", 32),
+	})
+	if err != nil {
+		t.Fatalf("NewSnapshotCipher() error = %s", err)
+	}
+	return c
+}
+
+func TestSnapshotCipher_RoundTrip(t *testing.T) {
+	c := newTestCipher(t)
+	docId := sharedTypes.UUID{1}
+	s := "\\documentclass{article}\n\\begin{document}\nsecret\n\\end{document}"
+
+	stored, err := c.Seal(docId, s)
+	if err != nil {
+		t.Fatalf("Seal() error = %s", err)
+	}
+	if strings.Contains(stored, "secret") {
+		t.Errorf("Seal() = %q, contains plaintext", stored)
+	}
+	if !strings.HasPrefix(stored, encryptedSnapshotPrefix) {
+		t.Errorf("Seal() = %q, missing prefix", stored)
+	}
+	got, err := c.Open(docId, stored)
+	if err != nil {
+		t.Fatalf("Open() error = %s", err)
+	}
+	if got != s {
+		t.Errorf("Open() = %q, want %q", got, s)
+	}
+}
+
+func TestSnapshotCipher_OpenOtherDoc(t *testing.T) {
+	c := newTestCipher(t)
+	ids := []sharedTypes.UUID{{1}, {2}}
+	stored, err := c.Seal(ids[0], "secret")
+	if err != nil {
+		t.Fatalf("Seal() error = %s", err)
+	}
+	if _, err = c.Open(ids[1], stored); err == nil {
+		t.Errorf("Open() of other doc succeeded, want error")
+	}
+	if _, err = (*SnapshotCipher)(nil).Open(ids[0], stored); err == nil {
+		t.Errorf("Open() without key succeeded, want error")
+	}
+}
+
+func TestSnapshotCipher_Plaintext(t *testing.T) {
+	docId := sharedTypes.UUID{1}
+	for _, c := range []*SnapshotCipher{nil, newTestCipher(t)} {
+		got, err := c.Open(docId, "plain")
+		if err != nil || got != "plain" {
+			t.Errorf("Open() = %q, %v, want %q", got, err, "plain")
+		}
+	}
+	got, err := (*SnapshotCipher)(nil).Seal(docId, "plain")
+	if err != nil || got != "plain" {
+		t.Errorf("Seal() = %q, %v, want %q", got, err, "plain")
+	}
+}
+
+func TestEncryptionOptions_Validate(t *testing.T) {
+	for _, key := range []string{"", strings.Repeat("ab", 32)} {
+		if err := (EncryptionOptions{Key: key}).Validate(); err != nil {
+			t.Errorf("Validate(%q) error = %s", key, err)
+		}
+	}
+	for _, key := range []string{"abc", strings.Repeat("ab", 16), "zz"} {
+		if err := (EncryptionOptions{Key: key}).Validate(); err == nil {
+			t.Errorf("Validate(%q) succeeded, want error", key)
+		}
+	}
+}
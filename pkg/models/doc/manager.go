@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -29,8 +29,8 @@ type Manager interface {
 	UpdateDoc(ctx context.Context, projectId, docId sharedTypes.UUID, update ForDocUpdate) error
 }
 
-func New(db *pgxpool.Pool) Manager {
-	return &manager{db: db}
+func New(db *pgxpool.Pool, c *SnapshotCipher) Manager {
+	return &manager{db: db, c: c}
 }
 
 func getErr(_ pgconn.CommandTag, err error) error {
@@ -39,12 +39,17 @@ func getErr(_ pgconn.CommandTag, err error) error {
 
 type manager struct {
 	db *pgxpool.Pool
+	c  *SnapshotCipher
 }
 
 func (m *manager) UpdateDoc(ctx context.Context, projectId, docId sharedTypes.UUID, update ForDocUpdate) error {
 	if err := update.Snapshot.Validate(); err != nil {
 		return err
 	}
+	s, err := m.c.Seal(docId, string(update.Snapshot))
+	if err != nil {
+		return err
+	}
 
 	return getErr(m.db.Exec(ctx, `
 WITH d AS (
@@ -61,7 +66,7 @@ FROM d
 WHERE id = d.project_id
   AND last_updated_at < $5
 `,
-		projectId, docId, string(update.Snapshot), int64(update.Version),
+		projectId, docId, s, int64(update.Version),
 		update.LastUpdatedAt, update.LastUpdatedBy,
 	))
 }
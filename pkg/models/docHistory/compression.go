@@ -0,0 +1,88 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docHistory
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+type Options struct {
+	// CompressOps stores ops gzip compressed when that saves space.
+	CompressOps bool `json:"compress_ops"`
+
+	// RetainFor is the age after which history entries get pruned, unless
+	// overridden per project.
+	// The latest entry of each doc is kept for tracking its version.
+	// Zero keeps the history forever.
+	RetainFor time.Duration `json:"retain_for"`
+}
+
+func (o *Options) Validate() error {
+	if o.RetainFor < 0 {
+		return &errors.ValidationError{
+			Msg: "retain_for must be greater than or equal to 0",
+		}
+	}
+	return nil
+}
+
+func encodeOp(op sharedTypes.Op, compress bool) (json.RawMessage, []byte, error) {
+	raw, err := json.Marshal(op)
+	if err != nil {
+		return nil, nil, errors.Tag(err, "serialize op")
+	}
+	if !compress {
+		return raw, nil, nil
+	}
+	b := bytes.Buffer{}
+	w := gzip.NewWriter(&b)
+	if _, err = w.Write(raw); err != nil {
+		return nil, nil, errors.Tag(err, "compress op")
+	}
+	if err = w.Close(); err != nil {
+		return nil, nil, errors.Tag(err, "finalize compressed op")
+	}
+	if b.Len() >= len(raw) {
+		return raw, nil, nil
+	}
+	return nil, b.Bytes(), nil
+}
+
+func decodeOp(raw, compressed []byte) (sharedTypes.Op, error) {
+	if compressed != nil {
+		r, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, errors.Tag(err, "init decompression of op")
+		}
+		raw, err = io.ReadAll(r)
+		if err != nil {
+			return nil, errors.Tag(err, "decompress op")
+		}
+	}
+	var op sharedTypes.Op
+	if err := json.Unmarshal(raw, &op); err != nil {
+		return nil, errors.Tag(err, "deserialize op")
+	}
+	return op, nil
+}
@@ -0,0 +1,75 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docHistory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func Test_encodeOpDecodeOp(t *testing.T) {
+	small := sharedTypes.Op{
+		{Insertion: sharedTypes.Snippet("a"), Position: 1},
+	}
+	big := sharedTypes.Op{
+		{
+			Insertion: sharedTypes.Snippet(strings.Repeat("foo bar ", 100)),
+			Position:  42,
+		},
+		{Deletion: sharedTypes.Snippet("baz"), Position: 7},
+	}
+	tests := []struct {
+		name           string
+		op             sharedTypes.Op
+		compress       bool
+		wantCompressed bool
+	}{
+		{name: "small plain", op: small},
+		{name: "small compress", op: small, compress: true},
+		{name: "big plain", op: big},
+		{
+			name:           "big compress",
+			op:             big,
+			compress:       true,
+			wantCompressed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, compressed, err := encodeOp(tt.op, tt.compress)
+			if err != nil {
+				t.Fatalf("encodeOp() error = %v", err)
+			}
+			if (compressed != nil) != tt.wantCompressed {
+				t.Errorf("encodeOp() compressed = %v, want %v", compressed != nil, tt.wantCompressed)
+			}
+			if (raw == nil) == (compressed == nil) {
+				t.Fatalf("encodeOp() must return either raw or compressed")
+			}
+			got, err := decodeOp(raw, compressed)
+			if err != nil {
+				t.Fatalf("decodeOp() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.op) {
+				t.Errorf("decodeOp() = %v, want %v", got, tt.op)
+			}
+		})
+	}
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2022-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2022-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -32,16 +32,21 @@ import (
 type Manager interface {
 	InsertBulk(ctx context.Context, docId sharedTypes.UUID, dh []ForInsert) error
 	GetLastVersion(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error)
+	GetLastVersions(ctx context.Context, projectId sharedTypes.UUID, docIds sharedTypes.UUIDs) (map[sharedTypes.UUID]sharedTypes.Version, error)
+	GetVersionsAt(ctx context.Context, projectId sharedTypes.UUID, at time.Time) (map[sharedTypes.UUID]sharedTypes.Version, error)
 	GetForDoc(ctx context.Context, projectId, userId, docId sharedTypes.UUID, from, to sharedTypes.Version, r *GetForDocResult) error
 	GetForProject(ctx context.Context, projectId, userId sharedTypes.UUID, before time.Time, limit int64, r *GetForProjectResult) error
+	Prune(ctx context.Context, now time.Time, defaultRetainFor time.Duration, dryRun bool) (int64, error)
+	GetPrunedVersion(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error)
 }
 
-func New(db *pgxpool.Pool) Manager {
-	return &manager{db: db}
+func New(db *pgxpool.Pool, o Options) Manager {
+	return &manager{db: db, compressOps: o.CompressOps}
 }
 
 type manager struct {
-	db *pgxpool.Pool
+	db          *pgxpool.Pool
+	compressOps bool
 }
 
 func (m *manager) InsertBulk(ctx context.Context, docId sharedTypes.UUID, dh []ForInsert) error {
@@ -60,7 +65,7 @@ func (m *manager) InsertBulk(ctx context.Context, docId sharedTypes.UUID, dh []F
 		ctx,
 		pgx.Identifier{"doc_history"},
 		[]string{
-			"id", "doc_id", "user_id", "version", "op",
+			"id", "doc_id", "user_id", "version", "op", "op_gzip",
 			"has_big_delete", "start_at", "end_at",
 		},
 		pgx.CopyFromSlice(len(dh), func(i int) ([]interface{}, error) {
@@ -70,12 +75,23 @@ func (m *manager) InsertBulk(ctx context.Context, docId sharedTypes.UUID, dh []F
 					break
 				}
 			}
+			var op, opGzip interface{}
+			raw, compressed, err2 := encodeOp(dh[i].Op, m.compressOps)
+			if err2 != nil {
+				return nil, err2
+			}
+			if compressed != nil {
+				opGzip = compressed
+			} else {
+				op = raw
+			}
 			return []interface{}{
 				b.Next(),
 				docId,
 				dh[i].UserId,
 				dh[i].Version,
-				dh[i].Op,
+				op,
+				opGzip,
 				dh[i].HasBigDelete,
 				dh[i].StartAt,
 				dh[i].EndAt,
@@ -113,6 +129,69 @@ LIMIT 1
 	return v, err
 }
 
+func (m *manager) GetLastVersions(ctx context.Context, projectId sharedTypes.UUID, docIds sharedTypes.UUIDs) (map[sharedTypes.UUID]sharedTypes.Version, error) {
+	// NOTE: Docs without history map to -1, like in GetLastVersion.
+	//       Docs from another project are absent.
+	r, err := m.db.Query(ctx, `
+SELECT d.id, coalesce(max(dh.version), -1)
+FROM tree_nodes t
+         INNER JOIN docs d ON t.id = d.id
+         LEFT JOIN doc_history dh ON d.id = dh.doc_id
+WHERE t.project_id = $1
+  AND t.id = ANY ($2)
+GROUP BY d.id
+`, projectId, docIds)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	versions := make(map[sharedTypes.UUID]sharedTypes.Version, len(docIds))
+	for r.Next() {
+		var id sharedTypes.UUID
+		var v sharedTypes.Version
+		if err = r.Scan(&id, &v); err != nil {
+			return nil, err
+		}
+		versions[id] = v
+	}
+	if err = r.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (m *manager) GetVersionsAt(ctx context.Context, projectId sharedTypes.UUID, at time.Time) (map[sharedTypes.UUID]sharedTypes.Version, error) {
+	// NOTE: Only docs with changes after the given time are included.
+	//       Docs without history at that time map to 0.
+	r, err := m.db.Query(ctx, `
+SELECT d.id, coalesce(max(dh.version) FILTER (WHERE dh.end_at <= $2), 0)
+FROM tree_nodes t
+         INNER JOIN docs d ON t.id = d.id
+         INNER JOIN doc_history dh ON d.id = dh.doc_id
+WHERE t.project_id = $1
+  AND t.deleted_at = '1970-01-01'
+GROUP BY d.id
+HAVING max(dh.end_at) > $2
+`, projectId, at)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	versions := make(map[sharedTypes.UUID]sharedTypes.Version)
+	for r.Next() {
+		var id sharedTypes.UUID
+		var v sharedTypes.Version
+		if err = r.Scan(&id, &v); err != nil {
+			return nil, err
+		}
+		versions[id] = v
+	}
+	if err = r.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
 type GetForDocResult struct {
 	History []DocHistory
 	Users   user.BulkFetched
@@ -126,6 +205,7 @@ SELECT dh.version,
        dh.start_at,
        dh.end_at,
        dh.op,
+       dh.op_gzip,
        coalesce(dh.user_id, '00000000-0000-0000-0000-000000000000'::UUID)
 FROM doc_history dh
          INNER JOIN docs d ON d.id = dh.doc_id
@@ -147,18 +227,23 @@ ORDER BY dh.version
 		defer r.Close()
 		h := res.History
 
+		var raw, compressed []byte
 		for i := 0; r.Next(); i++ {
 			h = append(h, DocHistory{})
 			err = r.Scan(
 				&h[i].Version,
 				&h[i].StartAt,
 				&h[i].EndAt,
-				&h[i].Op,
+				&raw,
+				&compressed,
 				&h[i].UserId,
 			)
 			if err != nil {
 				return err
 			}
+			if h[i].Op, err = decodeOp(raw, compressed); err != nil {
+				return err
+			}
 		}
 		res.History = h
 		return err
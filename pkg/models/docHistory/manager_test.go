@@ -0,0 +1,275 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docHistory_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/integrationTests"
+	"github.com/das7pad/overleaf-go/pkg/models/docHistory"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func TestMain(m *testing.M) {
+	integrationTests.Setup(m)
+}
+
+func TestDocHistory_GetLastVersions(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	dhm := docHistory.New(db, docHistory.Options{})
+
+	ownerId := integrationTests.CreateUser(t, ctx, db)
+	projectId, docIds := integrationTests.CreateProject(
+		t, ctx, db, ownerId, "b.tex", "c.tex",
+	)
+
+	now := time.Now().UTC()
+	history := func(versions ...sharedTypes.Version) []docHistory.ForInsert {
+		dh := make([]docHistory.ForInsert, len(versions))
+		for i, v := range versions {
+			dh[i] = docHistory.ForInsert{
+				UserId:  ownerId,
+				Version: v,
+				StartAt: now,
+				EndAt:   now,
+				Op: sharedTypes.Op{
+					{Insertion: sharedTypes.Snippet("x"), Position: 0},
+				},
+			}
+		}
+		return dh
+	}
+	err := dhm.InsertBulk(ctx, docIds[0], history(1, 2, 3))
+	if err != nil {
+		t.Fatalf("insert history a: %s", err)
+	}
+	if err = dhm.InsertBulk(ctx, docIds[1], history(1)); err != nil {
+		t.Fatalf("insert history b: %s", err)
+	}
+
+	_, otherDocIds := integrationTests.CreateProject(t, ctx, db, ownerId)
+	ids := append(docIds, otherDocIds[0])
+	got, err := dhm.GetLastVersions(ctx, projectId, ids)
+	if err != nil {
+		t.Fatalf("GetLastVersions(): %s", err)
+	}
+	want := map[sharedTypes.UUID]sharedTypes.Version{
+		docIds[0]: 3,
+		docIds[1]: 1,
+		docIds[2]: -1,
+	}
+	if len(got) != len(want) {
+		t.Errorf("GetLastVersions() = %v, want %v", got, want)
+	}
+	for id, v := range want {
+		if got[id] != v {
+			t.Errorf("GetLastVersions()[%s] = %d, want %d", id, got[id], v)
+		}
+		single, err2 := dhm.GetLastVersion(ctx, projectId, id)
+		if err2 != nil {
+			t.Fatalf("GetLastVersion(%s): %s", id, err2)
+		}
+		if single != v {
+			t.Errorf("GetLastVersion(%s) = %d, want %d", id, single, v)
+		}
+	}
+}
+
+func TestDocHistory_CompressOps(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	plain := docHistory.New(db, docHistory.Options{})
+	compressing := docHistory.New(db, docHistory.Options{CompressOps: true})
+
+	ownerId := integrationTests.CreateUser(t, ctx, db)
+	projectId, docIds := integrationTests.CreateProject(t, ctx, db, ownerId)
+	docId := docIds[0]
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	small := docHistory.ForInsert{
+		UserId:  ownerId,
+		Version: 1,
+		StartAt: now,
+		EndAt:   now,
+		Op: sharedTypes.Op{
+			{Insertion: sharedTypes.Snippet("x"), Position: 0},
+		},
+	}
+	big := docHistory.ForInsert{
+		UserId:  ownerId,
+		Version: 2,
+		StartAt: now,
+		EndAt:   now,
+		Op: sharedTypes.Op{
+			{
+				Insertion: sharedTypes.Snippet(strings.Repeat("foo ", 100)),
+				Position:  1,
+			},
+		},
+	}
+	bigPlain := big
+	bigPlain.Version = 3
+	err := compressing.InsertBulk(ctx, docId, []docHistory.ForInsert{small, big})
+	if err != nil {
+		t.Fatalf("insert compressed: %s", err)
+	}
+	err = plain.InsertBulk(ctx, docId, []docHistory.ForInsert{bigPlain})
+	if err != nil {
+		t.Fatalf("insert plain: %s", err)
+	}
+
+	var nCompressed int
+	err = db.QueryRow(ctx, `
+SELECT count(*)
+FROM doc_history
+WHERE doc_id = $1
+  AND op_gzip IS NOT NULL
+`, docId).Scan(&nCompressed)
+	if err != nil {
+		t.Fatalf("count compressed: %s", err)
+	}
+	if nCompressed != 1 {
+		t.Errorf("expected 1 compressed entry, got %d", nCompressed)
+	}
+
+	res := docHistory.GetForDocResult{}
+	err = plain.GetForDoc(ctx, projectId, ownerId, docId, 1, 3, &res)
+	if err != nil {
+		t.Fatalf("GetForDoc(): %s", err)
+	}
+	want := []docHistory.ForInsert{small, big, bigPlain}
+	if len(res.History) != len(want) {
+		t.Fatalf("GetForDoc() = %v, want %d entries", res.History, len(want))
+	}
+	for i, h := range res.History {
+		if h.Version != want[i].Version {
+			t.Errorf("GetForDoc()[%d].Version = %d, want %d", i, h.Version, want[i].Version)
+		}
+		if !reflect.DeepEqual(h.Op, want[i].Op) {
+			t.Errorf("GetForDoc()[%d].Op = %v, want %v", i, h.Op, want[i].Op)
+		}
+	}
+}
+
+func TestDocHistory_Prune(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	dhm := docHistory.New(db, docHistory.Options{})
+
+	ownerId := integrationTests.CreateUser(t, ctx, db)
+	projectId, docIds := integrationTests.CreateProject(t, ctx, db, ownerId)
+	docId := docIds[0]
+	keptProjectId, keptDocIds := integrationTests.CreateProject(
+		t, ctx, db, ownerId,
+	)
+	keptDocId := keptDocIds[0]
+	_, err := db.Exec(ctx, `
+UPDATE projects
+SET history_retain_for = 3
+WHERE id = $1
+`, keptProjectId)
+	if err != nil {
+		t.Fatalf("set project retention: %s", err)
+	}
+
+	now := time.Now().UTC()
+	old := now.Add(-48 * time.Hour)
+	for _, id := range []sharedTypes.UUID{docId, keptDocId} {
+		dh := make([]docHistory.ForInsert, 0, 4)
+		for i, at := range []time.Time{old, old, now, old} {
+			dh = append(dh, docHistory.ForInsert{
+				UserId:  ownerId,
+				Version: sharedTypes.Version(i + 1),
+				StartAt: at,
+				EndAt:   at,
+				Op: sharedTypes.Op{
+					{Insertion: sharedTypes.Snippet("x"), Position: 0},
+				},
+			})
+		}
+		if err = dhm.InsertBulk(ctx, id, dh); err != nil {
+			t.Fatalf("insert history: %s", err)
+		}
+	}
+	getVersions := func(id sharedTypes.UUID) []sharedTypes.Version {
+		var v []sharedTypes.Version
+		err2 := db.QueryRow(ctx, `
+SELECT array_agg(version ORDER BY version)
+FROM doc_history
+WHERE doc_id = $1
+`, id).Scan(&v)
+		if err2 != nil {
+			t.Fatalf("get versions: %s", err2)
+		}
+		return v
+	}
+
+	retainFor := 24 * time.Hour
+	n, err := dhm.Prune(ctx, now, retainFor, true)
+	if err != nil {
+		t.Fatalf("Prune(dryRun): %s", err)
+	}
+	if n < 2 {
+		t.Errorf("Prune(dryRun) = %d, want at least 2", n)
+	}
+	want := []sharedTypes.Version{1, 2, 3, 4}
+	if got := getVersions(docId); !reflect.DeepEqual(got, want) {
+		t.Errorf("dry-run: versions = %v, want %v", got, want)
+	}
+
+	if _, err = dhm.Prune(ctx, now, retainFor, false); err != nil {
+		t.Fatalf("Prune(): %s", err)
+	}
+	want = []sharedTypes.Version{3, 4}
+	if got := getVersions(docId); !reflect.DeepEqual(got, want) {
+		t.Errorf("versions = %v, want %v", got, want)
+	}
+	want = []sharedTypes.Version{1, 2, 3, 4}
+	if got := getVersions(keptDocId); !reflect.DeepEqual(got, want) {
+		t.Errorf("project override: versions = %v, want %v", got, want)
+	}
+	v, err := dhm.GetLastVersion(ctx, projectId, docId)
+	if err != nil {
+		t.Fatalf("GetLastVersion(): %s", err)
+	}
+	if v != 4 {
+		t.Errorf("GetLastVersion() = %d, want 4", v)
+	}
+	v, err = dhm.GetPrunedVersion(ctx, projectId, docId)
+	if err != nil {
+		t.Fatalf("GetPrunedVersion(): %s", err)
+	}
+	if v != 2 {
+		t.Errorf("GetPrunedVersion() = %d, want 2", v)
+	}
+	v, err = dhm.GetPrunedVersion(ctx, keptProjectId, keptDocId)
+	if err != nil {
+		t.Fatalf("GetPrunedVersion(): %s", err)
+	}
+	if v != 0 {
+		t.Errorf("project override: GetPrunedVersion() = %d, want 0", v)
+	}
+}
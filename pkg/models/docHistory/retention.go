@@ -0,0 +1,117 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docHistory
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+const pruneBatchSize = 10_000
+
+// Prune deletes history entries that are older than the retention period of
+// their project, falling back to defaultRetainFor. A retention period of
+// zero keeps the history forever. The highest pruned version is recorded per
+// doc, as history access before it is not possible anymore.
+func (m *manager) Prune(ctx context.Context, now time.Time, defaultRetainFor time.Duration, dryRun bool) (int64, error) {
+	// NOTE: The doc version is derived from the latest history entry.
+	//       Keep it around, regardless of its age.
+	seconds := defaultRetainFor.Seconds()
+	if dryRun {
+		n := int64(0)
+		err := m.db.QueryRow(ctx, `
+WITH r AS (SELECT p.id,
+                  coalesce(make_interval(days => p.history_retain_for),
+                           make_interval(secs => $2)) AS retain_for
+           FROM projects p)
+SELECT count(*)
+FROM doc_history dh
+         INNER JOIN tree_nodes t ON dh.doc_id = t.id
+         INNER JOIN r ON t.project_id = r.id
+WHERE r.retain_for > INTERVAL '0'
+  AND dh.end_at < $1::TIMESTAMP - r.retain_for
+  AND dh.version < (SELECT max(l.version)
+                    FROM doc_history l
+                    WHERE l.doc_id = dh.doc_id)
+`, now, seconds).Scan(&n)
+		return n, err
+	}
+	total := int64(0)
+	for {
+		n := int64(0)
+		err := m.db.QueryRow(ctx, `
+WITH r AS (SELECT p.id,
+                  coalesce(make_interval(days => p.history_retain_for),
+                           make_interval(secs => $2)) AS retain_for
+           FROM projects p),
+     d AS (SELECT dh.id
+           FROM doc_history dh
+                    INNER JOIN tree_nodes t ON dh.doc_id = t.id
+                    INNER JOIN r ON t.project_id = r.id
+           WHERE r.retain_for > INTERVAL '0'
+             AND dh.end_at < $1::TIMESTAMP - r.retain_for
+             AND dh.version < (SELECT max(l.version)
+                               FROM doc_history l
+                               WHERE l.doc_id = dh.doc_id)
+           LIMIT $3),
+     deleted AS (
+         DELETE
+             FROM doc_history dh
+                 USING d
+             WHERE dh.id = d.id
+             RETURNING dh.doc_id, dh.version),
+     boundary AS (
+         UPDATE docs
+             SET history_pruned_version = greatest(
+                     docs.history_pruned_version, b.version)
+             FROM (SELECT doc_id, max(version) AS version
+                   FROM deleted
+                   GROUP BY doc_id) b
+             WHERE docs.id = b.doc_id
+             RETURNING TRUE)
+SELECT count(*)
+FROM deleted
+`, now, seconds, pruneBatchSize).Scan(&n)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < pruneBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (m *manager) GetPrunedVersion(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error) {
+	var v sharedTypes.Version
+	err := m.db.QueryRow(ctx, `
+SELECT d.history_pruned_version
+FROM docs d
+         INNER JOIN tree_nodes t ON d.id = t.id
+WHERE t.project_id = $1
+  AND t.id = $2
+`, projectId, docId).Scan(&v)
+	if err == pgx.ErrNoRows {
+		return 0, &errors.NotFoundError{}
+	}
+	return v, err
+}
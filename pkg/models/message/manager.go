@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -20,6 +20,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
@@ -27,8 +28,15 @@ import (
 )
 
 type Manager interface {
-	GetGlobalMessages(ctx context.Context, projectId sharedTypes.UUID, limit int64, before sharedTypes.Timestamp, target *[]Message) error
+	GetGlobalMessages(ctx context.Context, projectId sharedTypes.UUID, limit int64, before sharedTypes.Timestamp, beforeId sharedTypes.UUID, target *[]Message) error
+	GetGlobalMessagesBefore(ctx context.Context, projectId sharedTypes.UUID, limit int64, before time.Time, beforeId sharedTypes.UUID, target *[]Message) error
+	GetGlobalMessagesAfter(ctx context.Context, projectId sharedTypes.UUID, limit int64, after time.Time, afterId sharedTypes.UUID, target *[]Message) error
+	SearchGlobalMessages(ctx context.Context, projectId sharedTypes.UUID, query string, limit int64, target *[]Message) error
 	SendGlobalMessage(ctx context.Context, projectId sharedTypes.UUID, msg *Message) error
+	EditGlobalMessage(ctx context.Context, projectId, messageId, userId sharedTypes.UUID, content string) error
+	DeleteGlobalMessage(ctx context.Context, projectId, messageId, userId sharedTypes.UUID) error
+	AddReaction(ctx context.Context, projectId, messageId, userId sharedTypes.UUID, emoji Emoji) error
+	RemoveReaction(ctx context.Context, projectId, messageId, userId sharedTypes.UUID, emoji Emoji) error
 }
 
 func New(db *pgxpool.Pool) Manager {
@@ -39,17 +47,34 @@ type manager struct {
 	db *pgxpool.Pool
 }
 
-func (m *manager) GetGlobalMessages(ctx context.Context, projectId sharedTypes.UUID, limit int64, before sharedTypes.Timestamp, messages *[]Message) error {
+func (m *manager) GetGlobalMessages(ctx context.Context, projectId sharedTypes.UUID, limit int64, before sharedTypes.Timestamp, beforeId sharedTypes.UUID, messages *[]Message) error {
 	var t time.Time
 	if before == 0 {
 		t = time.Now()
 	} else {
 		t = before.ToTime()
 	}
+	cursorAt := t
+	if !beforeId.IsZero() {
+		err := m.db.QueryRow(ctx, `
+SELECT created_at
+FROM chat_messages
+WHERE id = $2
+  AND project_id = $1
+`, projectId, beforeId).Scan(&cursorAt)
+		if err == pgx.ErrNoRows {
+			return &errors.ValidationError{Msg: "unknown before_id"}
+		}
+		if err != nil {
+			return errors.Tag(err, "get cursor")
+		}
+	}
 	r, err := m.db.Query(ctx, `
 SELECT cm.id,
        cm.content,
        cm.created_at,
+       cm.edited_at,
+       chat_message_reactions_of(cm.id),
        coalesce(u.id, '00000000-0000-0000-0000-000000000000'::UUID),
        coalesce(u.email, ''),
        coalesce(u.first_name, ''),
@@ -58,22 +83,110 @@ FROM chat_messages cm
          LEFT JOIN users u ON cm.user_id = u.id
 WHERE cm.project_id = $1
   AND cm.created_at < $2
+  AND (cm.created_at, cm.id) < ($5, $4)
   AND u.deleted_at IS NULL
-ORDER BY cm.created_at DESC
+ORDER BY cm.created_at DESC, cm.id DESC
 LIMIT $3
-`, projectId, t, limit)
+`, projectId, t, limit, beforeId, cursorAt)
+	if err != nil {
+		return err
+	}
+	return scanMessages(r, messages)
+}
+
+// GetGlobalMessagesBefore pages by the creation time in millisecond
+// precision and then by id, both descending. Pass a zero beforeId for
+// skipping all the messages created in the same millisecond as before.
+func (m *manager) GetGlobalMessagesBefore(ctx context.Context, projectId sharedTypes.UUID, limit int64, before time.Time, beforeId sharedTypes.UUID, messages *[]Message) error {
+	r, err := m.db.Query(ctx, `
+SELECT cm.id,
+       cm.content,
+       cm.created_at,
+       cm.edited_at,
+       chat_message_reactions_of(cm.id),
+       coalesce(u.id, '00000000-0000-0000-0000-000000000000'::UUID),
+       coalesce(u.email, ''),
+       coalesce(u.first_name, ''),
+       coalesce(u.last_name, '')
+FROM chat_messages cm
+         LEFT JOIN users u ON cm.user_id = u.id
+WHERE cm.project_id = $1
+  AND cm.created_at < $2::TIMESTAMP + INTERVAL '1 millisecond'
+  AND (date_trunc('milliseconds', cm.created_at), cm.id) < ($2, $3)
+  AND u.deleted_at IS NULL
+ORDER BY date_trunc('milliseconds', cm.created_at) DESC, cm.id DESC
+LIMIT $4
+`, projectId, before, beforeId, limit)
 	if err != nil {
 		return err
 	}
+	return scanMessages(r, messages)
+}
+
+// GetGlobalMessagesAfter pages by the creation time and then by id, both
+// ascending. Pass a zero after for starting with the oldest message.
+func (m *manager) GetGlobalMessagesAfter(ctx context.Context, projectId sharedTypes.UUID, limit int64, after time.Time, afterId sharedTypes.UUID, messages *[]Message) error {
+	r, err := m.db.Query(ctx, `
+SELECT cm.id,
+       cm.content,
+       cm.created_at,
+       cm.edited_at,
+       chat_message_reactions_of(cm.id),
+       coalesce(u.id, '00000000-0000-0000-0000-000000000000'::UUID),
+       coalesce(u.email, ''),
+       coalesce(u.first_name, ''),
+       coalesce(u.last_name, '')
+FROM chat_messages cm
+         LEFT JOIN users u ON cm.user_id = u.id
+WHERE cm.project_id = $1
+  AND (cm.created_at, cm.id) > ($2, $3)
+  AND u.deleted_at IS NULL
+ORDER BY cm.created_at, cm.id
+LIMIT $4
+`, projectId, after, afterId, limit)
+	if err != nil {
+		return err
+	}
+	return scanMessages(r, messages)
+}
+
+func (m *manager) SearchGlobalMessages(ctx context.Context, projectId sharedTypes.UUID, query string, limit int64, messages *[]Message) error {
+	r, err := m.db.Query(ctx, `
+SELECT cm.id,
+       cm.content,
+       cm.created_at,
+       cm.edited_at,
+       chat_message_reactions_of(cm.id),
+       coalesce(u.id, '00000000-0000-0000-0000-000000000000'::UUID),
+       coalesce(u.email, ''),
+       coalesce(u.first_name, ''),
+       coalesce(u.last_name, '')
+FROM chat_messages cm
+         LEFT JOIN users u ON cm.user_id = u.id
+WHERE cm.project_id = $1
+  AND strpos(lower(cm.content), lower($2)) > 0
+  AND u.deleted_at IS NULL
+ORDER BY cm.created_at DESC, cm.id DESC
+LIMIT $3
+`, projectId, query, limit)
+	if err != nil {
+		return err
+	}
+	return scanMessages(r, messages)
+}
+
+func scanMessages(r pgx.Rows, messages *[]Message) error {
 	defer r.Close()
 
 	acc := make([]Message, 0)
 	for i := 0; r.Next(); i++ {
 		acc = append(acc, Message{})
-		err = r.Scan(
+		err := r.Scan(
 			&acc[i].Id,
 			&acc[i].Content,
 			&acc[i].CreatedAt,
+			&acc[i].EditedAt,
+			&acc[i].Reactions,
 			&acc[i].User.Id,
 			&acc[i].User.Email,
 			&acc[i].User.FirstName,
@@ -84,7 +197,7 @@ LIMIT $3
 		}
 		acc[i].User.IdNoUnderscore = acc[i].User.Id
 	}
-	if err = r.Err(); err != nil {
+	if err := r.Err(); err != nil {
 		return err
 	}
 	*messages = acc
@@ -114,6 +227,112 @@ WHERE users.id = msg.user_id
 	return err
 }
 
+func (m *manager) EditGlobalMessage(ctx context.Context, projectId, messageId, userId sharedTypes.UUID, content string) error {
+	if err := checkContent(content); err != nil {
+		return err
+	}
+	isAuthor := false
+	err := m.db.QueryRow(ctx, `
+WITH msg AS (SELECT id, user_id
+             FROM chat_messages
+             WHERE id = $2
+               AND project_id = $1),
+     updated AS (
+         UPDATE chat_messages cm
+             SET content = $4,
+                 edited_at = transaction_timestamp()
+             FROM msg
+             WHERE cm.id = msg.id
+               AND msg.user_id = $3
+             RETURNING TRUE)
+SELECT coalesce(msg.user_id = $3, FALSE)
+FROM msg
+`, projectId, messageId, userId, content).Scan(&isAuthor)
+	return checkAuthor(isAuthor, err)
+}
+
+func (m *manager) DeleteGlobalMessage(ctx context.Context, projectId, messageId, userId sharedTypes.UUID) error {
+	isAuthor := false
+	err := m.db.QueryRow(ctx, `
+WITH msg AS (SELECT id, user_id
+             FROM chat_messages
+             WHERE id = $2
+               AND project_id = $1),
+     deleted AS (
+         DELETE
+             FROM chat_messages cm
+                 USING msg
+             WHERE cm.id = msg.id
+               AND msg.user_id = $3
+             RETURNING TRUE)
+SELECT coalesce(msg.user_id = $3, FALSE)
+FROM msg
+`, projectId, messageId, userId).Scan(&isAuthor)
+	return checkAuthor(isAuthor, err)
+}
+
+func (m *manager) AddReaction(ctx context.Context, projectId, messageId, userId sharedTypes.UUID, emoji Emoji) error {
+	if err := emoji.Validate(); err != nil {
+		return err
+	}
+	return checkMessageExists(m.db.QueryRow(ctx, `
+WITH msg AS (SELECT id
+             FROM chat_messages
+             WHERE id = $2
+               AND project_id = $1),
+     inserted AS (
+         INSERT INTO chat_message_reactions (emoji, message_id, user_id)
+             SELECT $4, msg.id, $3
+             FROM msg
+             ON CONFLICT DO NOTHING
+             RETURNING TRUE)
+SELECT TRUE
+FROM msg
+`, projectId, messageId, userId, string(emoji)))
+}
+
+func (m *manager) RemoveReaction(ctx context.Context, projectId, messageId, userId sharedTypes.UUID, emoji Emoji) error {
+	return checkMessageExists(m.db.QueryRow(ctx, `
+WITH msg AS (SELECT id
+             FROM chat_messages
+             WHERE id = $2
+               AND project_id = $1),
+     deleted AS (
+         DELETE
+             FROM chat_message_reactions r
+                 USING msg
+             WHERE r.message_id = msg.id
+               AND r.user_id = $3
+               AND r.emoji = $4
+             RETURNING TRUE)
+SELECT TRUE
+FROM msg
+`, projectId, messageId, userId, string(emoji)))
+}
+
+func checkMessageExists(r pgx.Row) error {
+	exists := false
+	if err := r.Scan(&exists); err == pgx.ErrNoRows {
+		return &errors.NotFoundError{}
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func checkAuthor(isAuthor bool, err error) error {
+	if err == pgx.ErrNoRows {
+		return &errors.NotFoundError{}
+	}
+	if err != nil {
+		return err
+	}
+	if !isAuthor {
+		return &errors.NotAuthorizedError{}
+	}
+	return nil
+}
+
 const MaxMessageLength = 10 * 1024
 
 var (
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -19,6 +19,7 @@ package message
 import (
 	"time"
 
+	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/models/user"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
@@ -27,5 +28,30 @@ type Message struct {
 	Id        sharedTypes.UUID                    `json:"id"`
 	Content   string                              `json:"content"`
 	CreatedAt time.Time                           `json:"timestamp"`
+	EditedAt  *time.Time                          `json:"edited_at,omitempty"`
+	Reactions Reactions                           `json:"reactions,omitempty"`
 	User      user.WithPublicInfoAndNonStandardId `json:"user,omitempty"`
 }
+
+type Emoji string
+
+var allowedEmoji = map[Emoji]bool{
+	"👍":  true,
+	"👎":  true,
+	"😄":  true,
+	"🎉":  true,
+	"😕":  true,
+	"❤️": true,
+	"🚀":  true,
+	"👀":  true,
+}
+
+func (e Emoji) Validate() error {
+	if !allowedEmoji[e] {
+		return &errors.ValidationError{Msg: "emoji is not allowed"}
+	}
+	return nil
+}
+
+// Reactions maps each emoji to the ids of the users that reacted with it.
+type Reactions map[Emoji][]sharedTypes.UUID
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -27,6 +27,7 @@ import (
 )
 
 type Manager interface {
+	Create(ctx context.Context, notification Notification) error
 	GetAllForUser(ctx context.Context, userId sharedTypes.UUID, notifications *[]Notification) error
 	Resend(ctx context.Context, notification Notification) error
 	RemoveById(ctx context.Context, userId sharedTypes.UUID, notificationId sharedTypes.UUID) error
@@ -44,6 +45,20 @@ type manager struct {
 	db *pgxpool.Pool
 }
 
+func (m *manager) Create(ctx context.Context, n Notification) error {
+	if n.Key == "" {
+		return &errors.ValidationError{Msg: "add notification: missing key"}
+	}
+	return getErr(m.db.Exec(ctx, `
+INSERT INTO notifications
+(expires_at, id, key, message_options, template_key, user_id)
+VALUES ($1, gen_random_uuid(), $2, $3, $4, $5)
+ON CONFLICT (key) DO UPDATE SET expires_at      = excluded.expires_at,
+                                message_options = excluded.message_options,
+                                template_key    = excluded.template_key
+`, n.Expires, n.Key, n.MessageOptions, n.TemplateKey, n.UserId))
+}
+
 func (m *manager) GetAllForUser(ctx context.Context, userId sharedTypes.UUID, notifications *[]Notification) error {
 	r, err := m.db.Query(ctx, `
 SELECT id, key, expires_at, template_key, message_options
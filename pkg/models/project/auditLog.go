@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -23,8 +23,12 @@ import (
 )
 
 type AuditLogEntry struct {
-	CreatedAt   time.Time
-	Info        interface{}
-	InitiatorId sharedTypes.UUID
-	Operation   string
+	CreatedAt time.Time        `json:"createdAt"`
+	Id        sharedTypes.UUID `json:"id"`
+	// Info may contain sensitive details, like the IP of the initiator.
+	Info        interface{}      `json:"-"`
+	InitiatorId sharedTypes.UUID `json:"initiatorId"`
+	Operation   string           `json:"operation"`
+	// UserId is the member that is affected by a membership change.
+	UserId *sharedTypes.UUID `json:"userId,omitempty"`
 }
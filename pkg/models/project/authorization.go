@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,6 +17,8 @@
 package project
 
 import (
+	"time"
+
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
@@ -76,6 +78,14 @@ func (a *AuthorizationDetails) IsRestrictedUser() IsRestrictedUser {
 	)
 }
 
+func (p *ForAuthorizationDetails) capTokenPrivilegeLevel(l sharedTypes.PrivilegeLevel) sharedTypes.PrivilegeLevel {
+	limit := p.TokenReadAndWritePrivilegeLevel
+	if limit != "" && l.IsHigherThan(limit) {
+		return limit
+	}
+	return l
+}
+
 func (p *ForAuthorizationDetails) GetPrivilegeLevelAnonymous(accessToken AccessToken) (*AuthorizationDetails, error) {
 	if p.PublicAccessLevel == TokenBasedAccess && accessToken != "" {
 		switch accessToken[0] {
@@ -83,9 +93,11 @@ func (p *ForAuthorizationDetails) GetPrivilegeLevelAnonymous(accessToken AccessT
 			// ReadAndWrite tokens start with numeric characters.
 			if p.Tokens.ReadAndWrite.EqualsTimingSafe(accessToken) {
 				return &AuthorizationDetails{
-					Epoch:          p.Epoch,
-					AccessSource:   AccessSourceToken,
-					PrivilegeLevel: sharedTypes.PrivilegeLevelReadAndWrite,
+					Epoch:        p.Epoch,
+					AccessSource: AccessSourceToken,
+					PrivilegeLevel: p.capTokenPrivilegeLevel(
+						sharedTypes.PrivilegeLevelReadAndWrite,
+					),
 				}, nil
 			}
 		default:
@@ -104,6 +116,10 @@ func (p *ForAuthorizationDetails) GetPrivilegeLevelAnonymous(accessToken AccessT
 
 func (p *ForAuthorizationDetails) GetPrivilegeLevelAuthenticated() (*AuthorizationDetails, error) {
 	switch {
+	case p.HasExpiredAccess(time.Now()):
+		return &AuthorizationDetails{
+			Epoch: p.Epoch,
+		}, &errors.NotAuthorizedError{}
 	case
 		p.AccessSource == AccessSourceOwner,
 		p.AccessSource == AccessSourceInvite,
@@ -111,10 +127,14 @@ func (p *ForAuthorizationDetails) GetPrivilegeLevelAuthenticated() (*Authorizati
 			// Access details remain as is in db when disabling link sharing,
 			//  we need to check in app code for validity.
 			p.PublicAccessLevel == TokenBasedAccess:
+		privilegeLevel := p.Member.PrivilegeLevel
+		if p.Member.AccessSource == AccessSourceToken {
+			privilegeLevel = p.capTokenPrivilegeLevel(privilegeLevel)
+		}
 		return &AuthorizationDetails{
 			Epoch:          p.Epoch,
 			AccessSource:   p.Member.AccessSource,
-			PrivilegeLevel: p.Member.PrivilegeLevel,
+			PrivilegeLevel: privilegeLevel,
 		}, nil
 	default:
 		return &AuthorizationDetails{
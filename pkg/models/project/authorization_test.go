@@ -0,0 +1,144 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func TestForAuthorizationDetails_GetPrivilegeLevelAuthenticated(t *testing.T) {
+	inOneHour := time.Now().Add(time.Hour)
+	oneHourAgo := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name    string
+		member  Member
+		wantErr bool
+	}{
+		{
+			name: "no expiry",
+			member: Member{
+				AccessSource:   AccessSourceInvite,
+				PrivilegeLevel: sharedTypes.PrivilegeLevelReadAndWrite,
+			},
+			wantErr: false,
+		},
+		{
+			name: "active",
+			member: Member{
+				AccessSource:    AccessSourceInvite,
+				PrivilegeLevel:  sharedTypes.PrivilegeLevelReadAndWrite,
+				AccessExpiresAt: &inOneHour,
+			},
+			wantErr: false,
+		},
+		{
+			name: "expired",
+			member: Member{
+				AccessSource:    AccessSourceInvite,
+				PrivilegeLevel:  sharedTypes.PrivilegeLevelReadAndWrite,
+				AccessExpiresAt: &oneHourAgo,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ForAuthorizationDetails{Member: tt.member}
+			d, err := p.GetPrivilegeLevelAuthenticated()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetPrivilegeLevelAuthenticated() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if d.PrivilegeLevel != tt.member.PrivilegeLevel {
+				t.Errorf("GetPrivilegeLevelAuthenticated() PrivilegeLevel = %v, want %v", d.PrivilegeLevel, tt.member.PrivilegeLevel)
+			}
+		})
+	}
+}
+
+func TestForAuthorizationDetails_GetPrivilegeLevelAnonymous(t *testing.T) {
+	tokens := Tokens{
+		ReadOnly:     "bcdfghjkmnpq",
+		ReadAndWrite: "1234567890bcdfghjkmnpq",
+	}
+	tests := []struct {
+		name        string
+		limit       sharedTypes.PrivilegeLevel
+		accessToken AccessToken
+		want        sharedTypes.PrivilegeLevel
+	}{
+		{
+			name:        "rw token",
+			accessToken: tokens.ReadAndWrite,
+			want:        sharedTypes.PrivilegeLevelReadAndWrite,
+		},
+		{
+			name:        "ro token",
+			accessToken: tokens.ReadOnly,
+			want:        sharedTypes.PrivilegeLevelReadOnly,
+		},
+		{
+			name:        "rw token with readOnly limit",
+			limit:       sharedTypes.PrivilegeLevelReadOnly,
+			accessToken: tokens.ReadAndWrite,
+			want:        sharedTypes.PrivilegeLevelReadOnly,
+		},
+		{
+			name:        "rw token with readAndWrite limit",
+			limit:       sharedTypes.PrivilegeLevelReadAndWrite,
+			accessToken: tokens.ReadAndWrite,
+			want:        sharedTypes.PrivilegeLevelReadAndWrite,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ForAuthorizationDetails{}
+			p.PublicAccessLevel = TokenBasedAccess
+			p.Tokens = tokens
+			p.TokenReadAndWritePrivilegeLevel = tt.limit
+			d, err := p.GetPrivilegeLevelAnonymous(tt.accessToken)
+			if err != nil {
+				t.Fatalf("GetPrivilegeLevelAnonymous() error = %v", err)
+			}
+			if d.PrivilegeLevel != tt.want {
+				t.Errorf("GetPrivilegeLevelAnonymous() PrivilegeLevel = %v, want %v", d.PrivilegeLevel, tt.want)
+			}
+		})
+	}
+}
+
+func TestForAuthorizationDetails_GetPrivilegeLevelAuthenticatedTokenLimit(t *testing.T) {
+	p := &ForAuthorizationDetails{}
+	p.PublicAccessLevel = TokenBasedAccess
+	p.TokenReadAndWritePrivilegeLevel = sharedTypes.PrivilegeLevelReadOnly
+	p.Member = Member{
+		AccessSource:   AccessSourceToken,
+		PrivilegeLevel: sharedTypes.PrivilegeLevelReadAndWrite,
+	}
+	d, err := p.GetPrivilegeLevelAuthenticated()
+	if err != nil {
+		t.Fatalf("GetPrivilegeLevelAuthenticated() error = %v", err)
+	}
+	if d.PrivilegeLevel != sharedTypes.PrivilegeLevelReadOnly {
+		t.Errorf("GetPrivilegeLevelAuthenticated() PrivilegeLevel = %v, want %v", d.PrivilegeLevel, sharedTypes.PrivilegeLevelReadOnly)
+	}
+}
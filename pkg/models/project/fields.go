@@ -25,16 +25,25 @@ import (
 )
 
 type Member struct {
-	AccessSource   AccessSource
-	PrivilegeLevel sharedTypes.PrivilegeLevel
-	Archived       bool
-	Trashed        bool
+	AccessSource    AccessSource
+	PrivilegeLevel  sharedTypes.PrivilegeLevel
+	Archived        bool
+	Trashed         bool
+	AccessExpiresAt *time.Time
+}
+
+func (m *Member) HasExpiredAccess(now time.Time) bool {
+	return m.AccessExpiresAt != nil && !now.Before(*m.AccessExpiresAt)
 }
 
 type CompilerField struct {
 	Compiler sharedTypes.Compiler `json:"compiler"`
 }
 
+type CompilerInheritedField struct {
+	CompilerInherited bool `json:"-"`
+}
+
 type ContentLockedAtField struct {
 	ContentLockedAt *time.Time `json:"contentLockedAt"`
 }
@@ -107,10 +116,18 @@ type RootDocField struct {
 	RootDoc RootDoc `json:"root_doc"`
 }
 
+type SnippetsField struct {
+	Snippets []Snippet `json:"snippets"`
+}
+
 type SpellCheckLanguageField struct {
 	SpellCheckLanguage spellingTypes.SpellCheckLanguage `json:"spellCheckLanguage"`
 }
 
+type TokenReadAndWritePrivilegeLevelField struct {
+	TokenReadAndWritePrivilegeLevel sharedTypes.PrivilegeLevel
+}
+
 type TokensField struct {
 	Tokens Tokens `json:"tokens"`
 }
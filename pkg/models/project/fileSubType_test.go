@@ -0,0 +1,69 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func TestClassifyFile(t *testing.T) {
+	tests := []struct {
+		name string
+		p    sharedTypes.PathName
+		want FileSubType
+	}{
+		{
+			name: "png",
+			p:    "figures/foo.png",
+			want: FileSubTypeImage,
+		},
+		{
+			name: "upper case png",
+			p:    "foo.PNG",
+			want: FileSubTypeImage,
+		},
+		{
+			name: "pdf",
+			p:    "foo.pdf",
+			want: FileSubTypePDF,
+		},
+		{
+			name: "bib",
+			p:    "refs.bib",
+			want: FileSubTypeBib,
+		},
+		{
+			name: "unknown extension",
+			p:    "foo.xyz",
+			want: FileSubTypeOther,
+		},
+		{
+			name: "no extension",
+			p:    "foo",
+			want: FileSubTypeOther,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyFile(tt.p); got != tt.want {
+				t.Errorf("ClassifyFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/doc"
 	"github.com/das7pad/overleaf-go/pkg/models/notification"
 	"github.com/das7pad/overleaf-go/pkg/models/tag"
 	"github.com/das7pad/overleaf-go/pkg/models/user"
@@ -56,36 +57,48 @@ type Manager interface {
 	GetForClone(ctx context.Context, projectId, userId sharedTypes.UUID) (*ForClone, error)
 	GetForProjectInvite(ctx context.Context, projectId, actorId sharedTypes.UUID, email sharedTypes.Email) (*ForProjectInvite, error)
 	GetForProjectJWT(ctx context.Context, projectId, userId sharedTypes.UUID, accessToken AccessToken) (*ForProjectJWT, int64, error)
-	GetForZip(ctx context.Context, projectId sharedTypes.UUID, userId sharedTypes.UUID, accessToken AccessToken) (*ForZip, error)
+	GetForZip(ctx context.Context, projectId sharedTypes.UUID, userId sharedTypes.UUID, accessToken AccessToken, prefix sharedTypes.DirName) (*ForZip, error)
 	ValidateProjectJWTEpochs(ctx context.Context, projectId, userId sharedTypes.UUID, projectEpoch, userEpoch int64) error
 	BumpLastOpened(ctx context.Context, projectId sharedTypes.UUID) error
+	BumpCompileCount(ctx context.Context, projectId sharedTypes.UUID) error
 	GetDoc(ctx context.Context, projectId, docId sharedTypes.UUID) (*time.Time, *Doc, error)
 	GetFile(ctx context.Context, projectId, userId sharedTypes.UUID, accessToken AccessToken, fileId sharedTypes.UUID) (*FileWithParent, error)
 	GetElementByPath(ctx context.Context, projectId, userId sharedTypes.UUID, path sharedTypes.PathName) (sharedTypes.UUID, bool, error)
 	GetBootstrapWSDetails(ctx context.Context, projectId, userId sharedTypes.UUID, projectEpoch, userEpoch int64, source AccessSource, p *ForBootstrapWS, u *user.WithPublicInfo) error
 	GetBootstrapWSUser(ctx context.Context, projectId, userId sharedTypes.UUID, projectEpoch, userEpoch int64, u *user.WithPublicInfo, treeVersion *sharedTypes.Version) error
 	GetLastUpdatedAt(ctx context.Context, projectId sharedTypes.UUID) (time.Time, error)
+	GetRootDocId(ctx context.Context, projectId sharedTypes.UUID) (sharedTypes.UUID, error)
 	GetLoadEditorDetails(ctx context.Context, projectId, userId sharedTypes.UUID, accessToken AccessToken) (*LoadEditorDetails, error)
+	GetStatistics(ctx context.Context, projectId, userId sharedTypes.UUID) (*Statistics, error)
 	GetProjectWithContent(ctx context.Context, projectId sharedTypes.UUID) ([]Doc, []FileRef, error)
 	GetTokenAccessDetails(ctx context.Context, userId sharedTypes.UUID, privilegeLevel sharedTypes.PrivilegeLevel, accessToken AccessToken) (*ForTokenAccessDetails, *AuthorizationDetails, error)
 	GetTreeEntities(ctx context.Context, projectId, userId sharedTypes.UUID) ([]TreeEntity, error)
 	GetProjectMembers(ctx context.Context, projectId sharedTypes.UUID) ([]user.AsProjectMember, error)
+	GetProjectTokenMembers(ctx context.Context, projectId sharedTypes.UUID) ([]user.AsProjectTokenMember, error)
 	GrantTokenAccess(ctx context.Context, projectId, userId sharedTypes.UUID, accessToken AccessToken, privilegeLevel sharedTypes.PrivilegeLevel) error
 	GrantMemberAccess(ctx context.Context, projectId, ownerId, userId sharedTypes.UUID, privilegeLevel sharedTypes.PrivilegeLevel) error
+	SetMemberAccessExpiresAt(ctx context.Context, projectId, ownerId, userId sharedTypes.UUID, accessExpiresAt *time.Time) error
 	GetAccessTokens(ctx context.Context, projectId, userId sharedTypes.UUID, tokens *Tokens) error
 	PopulateTokens(ctx context.Context, projectId, userId sharedTypes.UUID) (*Tokens, error)
 	GetProjectNames(ctx context.Context, userId sharedTypes.UUID) (Names, error)
+	GetProjectOwners(ctx context.Context, projectIds sharedTypes.UUIDs) (map[sharedTypes.UUID]user.WithPublicInfo, error)
+	GetAuditLog(ctx context.Context, projectId sharedTypes.UUID, before time.Time, beforeId sharedTypes.UUID, limit int64) ([]AuditLogEntry, error)
 	SetCompiler(ctx context.Context, projectId, userId sharedTypes.UUID, compiler sharedTypes.Compiler) error
+	SetInheritedCompiler(ctx context.Context, compiler sharedTypes.Compiler, dryRun bool) (sharedTypes.UUIDs, error)
 	SetImageName(ctx context.Context, projectId, userId sharedTypes.UUID, imageName sharedTypes.ImageName) error
-	SetSpellCheckLanguage(ctx context.Context, projectId, userId sharedTypes.UUID, spellCheckLanguage spellingTypes.SpellCheckLanguage) error
+	SetSpellCheckLanguage(ctx context.Context, projectId, userId sharedTypes.UUID, spellCheckLanguage spellingTypes.SpellCheckLanguage) (spellingTypes.SpellCheckLanguage, error)
+	SetSpellCheckLanguageForOwnedProjects(ctx context.Context, userId sharedTypes.UUID, spellCheckLanguage spellingTypes.SpellCheckLanguage) (sharedTypes.UUIDs, error)
 	SetRootDoc(ctx context.Context, projectId, userId, rooDocId sharedTypes.UUID) error
 	SetPublicAccessLevel(ctx context.Context, projectId, userId sharedTypes.UUID, level PublicAccessLevel) error
+	SetTokenReadAndWritePrivilegeLevel(ctx context.Context, projectId, userId sharedTypes.UUID, privilegeLevel sharedTypes.PrivilegeLevel) error
 	ArchiveForUser(ctx context.Context, projectId, userId sharedTypes.UUID) error
 	UnArchiveForUser(ctx context.Context, projectId, userId sharedTypes.UUID) error
 	TrashForUser(ctx context.Context, projectId, userId sharedTypes.UUID) error
 	UnTrashForUser(ctx context.Context, projectId, userId sharedTypes.UUID) error
 	Rename(ctx context.Context, projectId, userId sharedTypes.UUID, name Name) error
 	RemoveMember(ctx context.Context, projectId sharedTypes.UUID, actor, userId sharedTypes.UUID) error
+	ProcessExpiredMembers(ctx context.Context, cutOff time.Time, fn func(projectId, userId sharedTypes.UUID)) error
+	RemoveExpiredMember(ctx context.Context, projectId, userId sharedTypes.UUID, cutOff time.Time) error
 	TransferOwnership(ctx context.Context, projectId, previousOwnerId, newOwnerId sharedTypes.UUID) (*user.WithPublicInfo, *user.WithPublicInfo, Name, error)
 	CreateDoc(ctx context.Context, projectId, userId, folderId sharedTypes.UUID, d *Doc) (sharedTypes.Version, error)
 	EnsureIsDoc(ctx context.Context, projectId, userId, folderId sharedTypes.UUID, d *Doc) (sharedTypes.UUID, bool, sharedTypes.Version, error)
@@ -95,12 +108,21 @@ type Manager interface {
 	PurgeStaleFileUpload(ctx context.Context, projectId, fileId sharedTypes.UUID) error
 	ListProjectsWithName(ctx context.Context, userId sharedTypes.UUID) ([]WithIdAndName, error)
 	GetOwnedProjects(ctx context.Context, userId sharedTypes.UUID) ([]sharedTypes.UUID, error)
+	CountOwnedProjects(ctx context.Context, userId sharedTypes.UUID) (int64, error)
+	ListProjectsWithoutRootFolder(ctx context.Context) ([]sharedTypes.UUID, error)
+	RepairRootFolder(ctx context.Context, projectId sharedTypes.UUID) error
 	GetProjectListDetails(ctx context.Context, userId sharedTypes.UUID, r *ForProjectList) error
 	SetContentLockedAt(ctx context.Context, projectId, userId sharedTypes.UUID, contentLocked *time.Time) (bool, error)
+	GetMaxUnFlushedAge(ctx context.Context, projectId sharedTypes.UUID) (time.Duration, error)
+	SetMaxUnFlushedAge(ctx context.Context, projectId, userId sharedTypes.UUID, maxUnFlushedAge time.Duration) error
+	SetHistoryRetainForDays(ctx context.Context, projectId, userId sharedTypes.UUID, days int64) error
+	ListSnippets(ctx context.Context, projectId sharedTypes.UUID) ([]Snippet, error)
+	SetSnippet(ctx context.Context, projectId, userId sharedTypes.UUID, s *Snippet) error
+	DeleteSnippet(ctx context.Context, projectId, userId sharedTypes.UUID, name SnippetName) error
 }
 
-func New(db *pgxpool.Pool) Manager {
-	return &manager{db: db}
+func New(db *pgxpool.Pool, c *doc.SnapshotCipher) Manager {
+	return &manager{db: db, c: c}
 }
 
 func getErr(_ pgconn.CommandTag, err error) error {
@@ -127,6 +149,7 @@ type queryRunner interface {
 
 type manager struct {
 	db *pgxpool.Pool
+	c  *doc.SnapshotCipher
 }
 
 func (m *manager) PrepareProjectCreation(ctx context.Context, p *ForCreation) error {
@@ -146,11 +169,12 @@ func (m *manager) PrepareProjectCreation(ctx context.Context, p *ForCreation) er
 		`
 WITH p AS (
     INSERT INTO projects
-        (created_at, compiler, deleted_at, epoch, id, image_name,
-         last_opened_at, last_updated_at, last_updated_by, name, owner_id,
-         public_access_level, spell_check_language, tree_version)
+        (created_at, compiler, compiler_inherited, deleted_at, epoch, id,
+         image_name, last_opened_at, last_updated_at, last_updated_by, name,
+         owner_id, public_access_level, spell_check_language, tree_version)
         SELECT $7,
                $3,
+               $8,
                $4,
                1,
                $5,
@@ -177,7 +201,7 @@ SELECT p.id, p.owner_id, 'owner', 'owner', FALSE, FALSE
 FROM p
 `,
 		p.OwnerId, p.SpellCheckLanguage, p.Compiler, p.DeletedAt, p.Id,
-		p.ImageName, p.CreatedAt,
+		p.ImageName, p.CreatedAt, p.CompilerInherited,
 	)
 	if err != nil {
 		return err
@@ -225,12 +249,19 @@ FROM p
 	}
 
 	rows = rows[:0]
-	_ = t.WalkFolders(func(f *Folder) error {
+	err = t.WalkFolders(func(f *Folder) error {
 		for _, d := range f.Docs {
-			rows = append(rows, []interface{}{d.Id, d.Snapshot, d.Version})
+			s, err2 := m.c.Seal(d.Id, d.Snapshot)
+			if err2 != nil {
+				return err2
+			}
+			rows = append(rows, []interface{}{d.Id, s, d.Version})
 		}
 		return nil
 	})
+	if err != nil {
+		return errors.Tag(err, "encrypt docs")
+	}
 	_, err = tx.CopyFrom(
 		ctx,
 		pgx.Identifier{"docs"},
@@ -245,7 +276,8 @@ FROM p
 	_ = t.WalkFolders(func(f *Folder) error {
 		for i, ff := range f.FileRefs {
 			rows = append(rows, []interface{}{
-				ff.Id, ff.Hash, f.FileRefs[i].LinkedFileData, ff.Size, false,
+				ff.Id, ff.Hash, f.FileRefs[i].LinkedFileData, ff.Size,
+				ff.PageCount, false,
 			})
 		}
 		return nil
@@ -253,7 +285,9 @@ FROM p
 	_, err = tx.CopyFrom(
 		ctx,
 		pgx.Identifier{"files"},
-		[]string{"id", "hash", "linked_file_data", "size", "pending"},
+		[]string{
+			"id", "hash", "linked_file_data", "size", "page_count", "pending",
+		},
 		pgx.CopyFromRows(rows),
 	)
 	if err != nil {
@@ -343,8 +377,9 @@ RETURNING token_ro, token_rw
 func (m *manager) SetCompiler(ctx context.Context, projectId, userId sharedTypes.UUID, compiler sharedTypes.Compiler) error {
 	return getErr(m.db.Exec(ctx, `
 UPDATE projects p
-SET compiler     = $3,
-    tree_version = tree_version + 1
+SET compiler           = $3,
+    compiler_inherited = FALSE,
+    tree_version       = tree_version + 1
 FROM project_members pm
 WHERE p.id = $1
   AND p.editable
@@ -354,6 +389,46 @@ WHERE p.id = $1
 `, projectId, userId, compiler))
 }
 
+// SetInheritedCompiler updates the compiler of projects that did not set
+// their compiler explicitly. It returns the ids of the affected projects.
+func (m *manager) SetInheritedCompiler(ctx context.Context, compiler sharedTypes.Compiler, dryRun bool) (sharedTypes.UUIDs, error) {
+	q := `
+UPDATE projects
+SET compiler     = $1,
+    tree_version = tree_version + 1
+WHERE compiler_inherited = TRUE
+  AND compiler != $1
+  AND deleted_at IS NULL
+RETURNING id
+`
+	if dryRun {
+		q = `
+SELECT id
+FROM projects
+WHERE compiler_inherited = TRUE
+  AND compiler != $1
+  AND deleted_at IS NULL
+`
+	}
+	r, err := m.db.Query(ctx, q, compiler)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var ids sharedTypes.UUIDs
+	for r.Next() {
+		var id sharedTypes.UUID
+		if err = r.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = r.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 func (m *manager) SetImageName(ctx context.Context, projectId, userId sharedTypes.UUID, imageName sharedTypes.ImageName) error {
 	return getErr(m.db.Exec(ctx, `
 UPDATE projects p
@@ -368,18 +443,65 @@ WHERE p.id = $1
 `, projectId, userId, imageName))
 }
 
-func (m *manager) SetSpellCheckLanguage(ctx context.Context, projectId, userId sharedTypes.UUID, spellCheckLanguage spellingTypes.SpellCheckLanguage) error {
-	return getErr(m.db.Exec(ctx, `
+// SetSpellCheckLanguage updates the spell check language of the project.
+// "inherit" resolves to the language of the project owner. It returns the
+// new language.
+func (m *manager) SetSpellCheckLanguage(ctx context.Context, projectId, userId sharedTypes.UUID, spellCheckLanguage spellingTypes.SpellCheckLanguage) (spellingTypes.SpellCheckLanguage, error) {
+	if err := spellCheckLanguage.ValidateForProject(); err != nil {
+		return "", err
+	}
+	var l spellingTypes.SpellCheckLanguage
+	err := m.db.QueryRow(ctx, `
 UPDATE projects p
-SET spell_check_language = $3,
+SET spell_check_language = coalesce(
+        nullif($3, 'inherit'),
+        (o.editor_config ->> 'spellCheckLanguage')
+    ),
     tree_version = tree_version + 1
-FROM project_members pm
+FROM project_members pm,
+     users o
 WHERE p.id = $1
-  AND p.editable,
+  AND p.editable
   AND p.id = pm.project_id
   AND pm.user_id = $2
   AND pm.privilege_level >= 'readAndWrite'
-`, projectId, userId, spellCheckLanguage))
+  AND o.id = p.owner_id
+RETURNING p.spell_check_language
+`, projectId, userId, spellCheckLanguage).Scan(&l)
+	if err == pgx.ErrNoRows {
+		return "", &errors.NotAuthorizedError{}
+	}
+	return l, err
+}
+
+// SetSpellCheckLanguageForOwnedProjects updates all editable projects of
+// the given owner. It returns the ids of the affected projects.
+func (m *manager) SetSpellCheckLanguageForOwnedProjects(ctx context.Context, userId sharedTypes.UUID, spellCheckLanguage spellingTypes.SpellCheckLanguage) (sharedTypes.UUIDs, error) {
+	r, err := m.db.Query(ctx, `
+UPDATE projects
+SET spell_check_language = $2,
+    tree_version = tree_version + 1
+WHERE owner_id = $1
+  AND editable
+  AND spell_check_language != $2
+RETURNING id
+`, userId, spellCheckLanguage)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var ids sharedTypes.UUIDs
+	for r.Next() {
+		var id sharedTypes.UUID
+		if err = r.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = r.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
 func (m *manager) SetRootDoc(ctx context.Context, projectId, userId, rootDocId sharedTypes.UUID) error {
@@ -420,6 +542,116 @@ RETURNING editable
 	return editable, err
 }
 
+func (m *manager) GetMaxUnFlushedAge(ctx context.Context, projectId sharedTypes.UUID) (time.Duration, error) {
+	var seconds int64
+	err := m.db.QueryRow(ctx, `
+SELECT coalesce(max_unflushed_age, 0)
+FROM projects
+WHERE id = $1
+  AND deleted_at IS NULL
+`, projectId).Scan(&seconds)
+	if err == pgx.ErrNoRows {
+		return 0, &errors.NotFoundError{}
+	}
+	return time.Duration(seconds) * time.Second, err
+}
+
+func (m *manager) SetMaxUnFlushedAge(ctx context.Context, projectId, userId sharedTypes.UUID, maxUnFlushedAge time.Duration) error {
+	return getErr(m.db.Exec(ctx, `
+UPDATE projects
+SET max_unflushed_age = nullif($3, 0)
+WHERE id = $1
+  AND owner_id = $2
+  AND deleted_at IS NULL
+`, projectId, userId, int64(maxUnFlushedAge/time.Second)))
+}
+
+func (m *manager) SetHistoryRetainForDays(ctx context.Context, projectId, userId sharedTypes.UUID, days int64) error {
+	return getErr(m.db.Exec(ctx, `
+UPDATE projects
+SET history_retain_for = nullif($3, 0)
+WHERE id = $1
+  AND owner_id = $2
+  AND deleted_at IS NULL
+`, projectId, userId, days))
+}
+
+func (m *manager) ListSnippets(ctx context.Context, projectId sharedTypes.UUID) ([]Snippet, error) {
+	r, err := m.db.Query(ctx, `
+SELECT name, body
+FROM project_snippets
+WHERE project_id = $1
+ORDER BY name
+`, projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	snippets := make([]Snippet, 0)
+	for r.Next() {
+		s := Snippet{}
+		if err = r.Scan(&s.Name, &s.Body); err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, s)
+	}
+	if err = r.Err(); err != nil {
+		return nil, err
+	}
+	return snippets, nil
+}
+
+func (m *manager) SetSnippet(ctx context.Context, projectId, userId sharedTypes.UUID, s *Snippet) error {
+	others := 0
+	err := m.db.QueryRow(ctx, `
+WITH p AS (SELECT p.id,
+                  (SELECT count(*)
+                   FROM project_snippets s
+                   WHERE s.project_id = p.id
+                     AND s.name != $4) AS others
+           FROM projects p
+                    INNER JOIN project_members pm ON p.id = pm.project_id
+           WHERE p.id = $1
+             AND p.editable
+             AND pm.user_id = $2
+             AND pm.privilege_level >= 'readAndWrite'),
+     ins AS (
+         INSERT INTO project_snippets (body, name, project_id)
+             SELECT $3, $4, p.id
+             FROM p
+             WHERE p.others < $5
+             ON CONFLICT (project_id, name) DO UPDATE SET body = excluded.body)
+SELECT others
+FROM p
+`, projectId, userId, s.Body, s.Name, MaxSnippetsPerProject).Scan(&others)
+	if err == pgx.ErrNoRows {
+		return &errors.NotAuthorizedError{}
+	}
+	if err != nil {
+		return err
+	}
+	if others >= MaxSnippetsPerProject {
+		return ErrTooManySnippets
+	}
+	return nil
+}
+
+func (m *manager) DeleteSnippet(ctx context.Context, projectId, userId sharedTypes.UUID, name SnippetName) error {
+	return getErr(m.db.Exec(ctx, `
+DELETE
+FROM project_snippets s
+    USING projects p, project_members pm
+WHERE s.project_id = $1
+  AND s.name = $3
+  AND p.id = s.project_id
+  AND p.editable
+  AND p.id = pm.project_id
+  AND pm.user_id = $2
+  AND pm.privilege_level >= 'readAndWrite'
+`, projectId, userId, name))
+}
+
 func (m *manager) SetPublicAccessLevel(ctx context.Context, projectId, userId sharedTypes.UUID, publicAccessLevel PublicAccessLevel) error {
 	return getErr(m.db.Exec(ctx, `
 UPDATE projects
@@ -432,6 +664,17 @@ WHERE id = $1
 `, projectId, userId, publicAccessLevel))
 }
 
+func (m *manager) SetTokenReadAndWritePrivilegeLevel(ctx context.Context, projectId, userId sharedTypes.UUID, privilegeLevel sharedTypes.PrivilegeLevel) error {
+	return getErr(m.db.Exec(ctx, `
+UPDATE projects
+SET token_rw_privilege_level = nullif($3, '')::PrivilegeLevel,
+    epoch                    = epoch + 1
+WHERE id = $1
+  AND owner_id = $2
+  AND deleted_at IS NULL
+`, projectId, userId, string(privilegeLevel)))
+}
+
 func (m *manager) TransferOwnership(ctx context.Context, projectId, previousOwnerId, newOwnerId sharedTypes.UUID) (*user.WithPublicInfo, *user.WithPublicInfo, Name, error) {
 	previousOwner := user.WithPublicInfo{}
 	previousOwner.Id = previousOwnerId
@@ -954,15 +1197,87 @@ WHERE user_id = $1
 	return names, nil
 }
 
+func (m *manager) GetProjectOwners(ctx context.Context, projectIds sharedTypes.UUIDs) (map[sharedTypes.UUID]user.WithPublicInfo, error) {
+	r, err := m.db.Query(ctx, `
+SELECT p.id, u.id, u.email, u.first_name, u.last_name
+FROM projects p
+         INNER JOIN users u ON p.owner_id = u.id
+WHERE p.id = ANY ($1)
+`, projectIds)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	owners := make(map[sharedTypes.UUID]user.WithPublicInfo, len(projectIds))
+	for r.Next() {
+		var projectId sharedTypes.UUID
+		u := user.WithPublicInfo{}
+		err = r.Scan(&projectId, &u.Id, &u.Email, &u.FirstName, &u.LastName)
+		if err != nil {
+			return nil, err
+		}
+		owners[projectId] = u
+	}
+	if err = r.Err(); err != nil {
+		return nil, err
+	}
+	return owners, nil
+}
+
+// GetAuditLog pages by the creation time in millisecond precision and then
+// by id, both descending. Pass a zero beforeId for skipping all the entries
+// created in the same millisecond as before.
+func (m *manager) GetAuditLog(ctx context.Context, projectId sharedTypes.UUID, before time.Time, beforeId sharedTypes.UUID, limit int64) ([]AuditLogEntry, error) {
+	r, err := m.db.Query(ctx, `
+SELECT created_at,
+       id,
+       info,
+       coalesce(initiator_id, '00000000-0000-0000-0000-000000000000'::UUID),
+       operation,
+       (info ->> 'userId')::UUID
+FROM project_audit_log
+WHERE project_id = $1
+  AND created_at < $2::TIMESTAMP + INTERVAL '1 millisecond'
+  AND (date_trunc('milliseconds', created_at), id) < ($2, $3)
+ORDER BY date_trunc('milliseconds', created_at) DESC, id DESC
+LIMIT $4
+`, projectId, before, beforeId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	entries := make([]AuditLogEntry, 0)
+	for i := 0; r.Next(); i++ {
+		entries = append(entries, AuditLogEntry{})
+		err = r.Scan(
+			&entries[i].CreatedAt,
+			&entries[i].Id,
+			&entries[i].Info,
+			&entries[i].InitiatorId,
+			&entries[i].Operation,
+			&entries[i].UserId,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = r.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 func (m *manager) GetAuthorizationDetails(ctx context.Context, projectId, userId sharedTypes.UUID, accessToken AccessToken) (*AuthorizationDetails, error) {
 	p := ForAuthorizationDetails{}
 	err := m.db.QueryRow(ctx, `
 SELECT coalesce(pm.access_source::TEXT, ''),
        coalesce(pm.privilege_level::TEXT, ''),
+       pm.access_expires_at,
        p.epoch,
        p.public_access_level,
        coalesce(p.token_ro, ''),
-       coalesce(p.token_rw, '')
+       coalesce(p.token_rw, ''),
+       coalesce(p.token_rw_privilege_level::TEXT, '')
 FROM projects p
          LEFT JOIN project_members pm ON (p.id = pm.project_id AND
                                           pm.user_id = $2)
@@ -974,8 +1289,10 @@ WHERE p.id = $1
          (pm.access_source = 'token' OR p.token_ro = $3))
     )
 `, projectId, userId, accessToken).Scan(
-		&p.Member.AccessSource, &p.Member.PrivilegeLevel, &p.Epoch,
+		&p.Member.AccessSource, &p.Member.PrivilegeLevel,
+		&p.Member.AccessExpiresAt, &p.Epoch,
 		&p.PublicAccessLevel, &p.Tokens.ReadOnly, &p.Tokens.ReadAndWrite,
+		&p.TokenReadAndWritePrivilegeLevel,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -1033,11 +1350,13 @@ func (m *manager) GetForProjectJWT(ctx context.Context, projectId, userId shared
 	err := m.db.QueryRow(ctx, `
 SELECT coalesce(pm.access_source::TEXT, ''),
        coalesce(pm.privilege_level::TEXT, ''),
+       pm.access_expires_at,
        p.editable,
        p.epoch,
        p.public_access_level,
        coalesce(p.token_ro, ''),
        coalesce(p.token_rw, ''),
+       coalesce(p.token_rw_privilege_level::TEXT, ''),
        o.features,
        coalesce(u.epoch, 0)
 FROM projects p
@@ -1057,11 +1376,13 @@ WHERE p.id = $1
 `, projectId, userId, accessToken).Scan(
 		&p.Member.AccessSource,
 		&p.Member.PrivilegeLevel,
+		&p.Member.AccessExpiresAt,
 		&p.Editable,
 		&p.Epoch,
 		&p.PublicAccessLevel,
 		&p.Tokens.ReadOnly,
 		&p.Tokens.ReadAndWrite,
+		&p.TokenReadAndWritePrivilegeLevel,
 		&p.OwnerFeatures,
 		&userEpoch,
 	)
@@ -1112,6 +1433,9 @@ WHERE d.id = $2
 	if err == pgx.ErrNoRows {
 		return nil, nil, &errors.DocNotFoundError{}
 	}
+	if err == nil {
+		d.Snapshot, err = m.c.Open(docId, d.Snapshot)
+	}
 	d.Id = docId
 	d.Name = d.Path.Filename()
 	return cl.ContentLockedAt, &d, err
@@ -1156,7 +1480,13 @@ RETURNING p.tree_version, restored.parent_id
 func (m *manager) GetFile(ctx context.Context, projectId, userId sharedTypes.UUID, accessToken AccessToken, fileId sharedTypes.UUID) (*FileWithParent, error) {
 	f := FileWithParent{}
 	err := m.db.QueryRow(ctx, `
-SELECT t.path, t.parent_id, f.linked_file_data, f.size
+SELECT t.path,
+       t.parent_id,
+       t.created_at,
+       f.linked_file_data,
+       f.size,
+       f.hash,
+       f.page_count
 FROM files f
          INNER JOIN tree_nodes t ON f.id = t.id
          INNER JOIN projects p ON t.project_id = p.id
@@ -1172,7 +1502,8 @@ WHERE f.id = $4
          (pm.access_source = 'token' OR p.token_ro = $3))
     )
 `, projectId, userId, accessToken, fileId).Scan(
-		&f.Path, &f.ParentId, &f.LinkedFileData, &f.Size,
+		&f.Path, &f.ParentId, &f.CreatedAt, &f.LinkedFileData, &f.Size,
+		&f.Hash, &f.PageCount,
 	)
 	f.Id = fileId
 	f.Name = f.Path.Filename()
@@ -1260,6 +1591,10 @@ ORDER BY t.kind
 		if err != nil {
 			return nil, nil, err
 		}
+		nodes[i].Snapshot, err = m.c.Open(nodes[i].Id, nodes[i].Snapshot)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 	if err = r.Err(); err != nil {
 		return nil, nil, err
@@ -1281,9 +1616,11 @@ ORDER BY t.kind
 	return nodes[:len(nodes)-len(files)], files, nil
 }
 
-func (m *manager) GetForZip(ctx context.Context, projectId sharedTypes.UUID, userId sharedTypes.UUID, accessToken AccessToken) (*ForZip, error) {
+// GetForZip gets the tree for downloading a project. A non-empty prefix
+// limits the tree to the nodes in the given folder.
+func (m *manager) GetForZip(ctx context.Context, projectId sharedTypes.UUID, userId sharedTypes.UUID, accessToken AccessToken, prefix sharedTypes.DirName) (*ForZip, error) {
 	p := ForZip{}
-	return &p, m.db.QueryRow(ctx, `
+	err := m.db.QueryRow(ctx, `
 WITH tree AS
          (SELECT t.project_id,
                  array_agg(t.id)                     AS ids,
@@ -1295,6 +1632,7 @@ WITH tree AS
           WHERE t.project_id = $1
             AND t.deleted_at = '1970-01-01'
             AND t.parent_id IS NOT NULL
+            AND starts_with(t.path, $4)
           GROUP BY t.project_id)
 
 SELECT p.name,
@@ -1313,13 +1651,20 @@ WHERE p.id = $1
         (p.public_access_level = 'tokenBased' AND
          (pm.access_source = 'token' OR p.token_ro = $3))
     )
-`, projectId, userId, accessToken).Scan(
+`, projectId, userId, accessToken, prefix).Scan(
 		&p.Name,
 		&p.treeIds,
 		&p.treeKinds,
 		&p.treePaths,
 		&p.docSnapshots,
 	)
+	if err != nil {
+		return &p, err
+	}
+	if prefix != "" && len(p.treeIds) == 0 {
+		return &p, &errors.NotFoundError{}
+	}
+	return &p, m.openTreeSnapshots(&p.ForTree)
 }
 
 func (m *manager) GetBootstrapWSDetails(ctx context.Context, projectId, userId sharedTypes.UUID, projectEpoch, userEpoch int64, source AccessSource, p *ForBootstrapWS, u *user.WithPublicInfo) error {
@@ -1333,12 +1678,13 @@ func (m *manager) GetBootstrapWSDetails(ctx context.Context, projectId, userId s
 	err := m.db.QueryRow(ctx, `
 WITH tree AS
          (SELECT t.project_id,
-                 array_agg(t.id)                AS ids,
-                 array_agg(t.kind::TEXT)        AS kinds,
-                 array_agg(t.path)              AS paths,
-                 array_agg(t.created_at)        AS created_ats,
-                 array_agg(f.linked_file_data)  AS linked_file_data,
-                 array_agg(coalesce(f.size, 0)) AS sizes
+                 array_agg(t.id)                      AS ids,
+                 array_agg(t.kind::TEXT)              AS kinds,
+                 array_agg(t.path)                    AS paths,
+                 array_agg(t.created_at)              AS created_ats,
+                 array_agg(f.linked_file_data)        AS linked_file_data,
+                 array_agg(coalesce(f.size, 0))       AS sizes,
+                 array_agg(coalesce(f.page_count, 0)) AS page_counts
           FROM tree_nodes t
                    LEFT JOIN files f ON t.id = f.id
           WHERE t.project_id = $1
@@ -1377,6 +1723,7 @@ SELECT p.compiler,
        tree.created_ats,
        tree.linked_file_data,
        tree.sizes,
+       tree.page_counts,
        deleted_docs.ids,
        deleted_docs.names
 FROM projects p
@@ -1418,6 +1765,7 @@ WHERE p.id = $1
 		&p.createdAts,
 		&p.linkedFileData,
 		&p.sizes,
+		&p.pageCounts,
 		&deletedDocIds,
 		&deletedDocNames,
 	)
@@ -1470,6 +1818,14 @@ WHERE id = $1
 `, projectId))
 }
 
+func (m *manager) BumpCompileCount(ctx context.Context, projectId sharedTypes.UUID) error {
+	return getErr(m.db.Exec(ctx, `
+UPDATE projects
+SET compile_count = compile_count + 1
+WHERE id = $1
+`, projectId))
+}
+
 func (m *manager) GetLoadEditorDetails(ctx context.Context, projectId, userId sharedTypes.UUID, accessToken AccessToken) (*LoadEditorDetails, error) {
 	if userId.IsZero() && accessToken == "" {
 		// skip overhead of db query
@@ -1479,6 +1835,7 @@ func (m *manager) GetLoadEditorDetails(ctx context.Context, projectId, userId sh
 	err := m.db.QueryRow(ctx, `
 SELECT coalesce(pm.access_source::TEXT, ''),
        coalesce(pm.privilege_level::TEXT, ''),
+       pm.access_expires_at,
        p.compiler,
        p.editable,
        p.epoch,
@@ -1487,6 +1844,7 @@ SELECT coalesce(pm.access_source::TEXT, ''),
        p.public_access_level,
        coalesce(p.token_ro, ''),
        coalesce(p.token_rw, ''),
+       coalesce(p.token_rw_privilege_level::TEXT, ''),
        p.tree_version,
        coalesce(d.id, '00000000-0000-0000-0000-000000000000'::UUID),
        coalesce(d.path, ''),
@@ -1496,7 +1854,12 @@ SELECT coalesce(pm.access_source::TEXT, ''),
        coalesce(u.epoch, 0),
        coalesce(u.first_name, ''),
        coalesce(u.last_name, ''),
-       coalesce(u.learned_words, ARRAY []::TEXT[])
+       coalesce(u.learned_words, ARRAY []::TEXT[]),
+       coalesce((SELECT json_agg(json_build_object('name', s.name,
+                                                   'body', s.body)
+                                 ORDER BY s.name)
+                 FROM project_snippets s
+                 WHERE s.project_id = p.id), '[]')
 FROM projects p
          INNER JOIN users o ON p.owner_id = o.id
          LEFT JOIN tree_nodes d ON p.root_doc_id = d.id
@@ -1515,6 +1878,7 @@ WHERE p.id = $1
 `, projectId, userId, accessToken).Scan(
 		&d.Project.Member.AccessSource,
 		&d.Project.Member.PrivilegeLevel,
+		&d.Project.Member.AccessExpiresAt,
 		&d.Project.Compiler,
 		&d.Project.Editable,
 		&d.Project.Epoch,
@@ -1523,6 +1887,7 @@ WHERE p.id = $1
 		&d.Project.PublicAccessLevel,
 		&d.Project.Tokens.ReadOnly,
 		&d.Project.Tokens.ReadAndWrite,
+		&d.Project.TokenReadAndWritePrivilegeLevel,
 		&d.Project.Version,
 		&d.Project.RootDoc.Id,
 		&d.Project.RootDoc.Path,
@@ -1533,6 +1898,7 @@ WHERE p.id = $1
 		&d.User.FirstName,
 		&d.User.LastName,
 		&d.User.LearnedWords,
+		&d.Project.Snippets,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -1555,18 +1921,69 @@ WHERE id = $1 AND deleted_at IS NULL
 `, projectId).Scan(&at)
 }
 
+func (m *manager) GetRootDocId(ctx context.Context, projectId sharedTypes.UUID) (sharedTypes.UUID, error) {
+	id := sharedTypes.UUID{}
+	return id, m.db.QueryRow(ctx, `
+SELECT coalesce(root_doc_id, '00000000-0000-0000-0000-000000000000'::UUID)
+FROM projects
+WHERE id = $1 AND deleted_at IS NULL
+`, projectId).Scan(&id)
+}
+
+func (m *manager) GetStatistics(ctx context.Context, projectId, userId sharedTypes.UUID) (*Statistics, error) {
+	s := Statistics{}
+	err := m.db.QueryRow(ctx, `
+SELECT (SELECT count(*)
+        FROM project_members c
+        WHERE c.project_id = p.id
+          AND c.access_source = 'invite'
+          AND (c.access_expires_at IS NULL OR
+               c.access_expires_at > transaction_timestamp())),
+       p.compile_count,
+       count(d.id),
+       count(f.id),
+       coalesce(p.last_updated_at, p.created_at),
+       coalesce(sum(octet_length(d.snapshot)), 0) + coalesce(sum(f.size), 0)
+FROM projects p
+         INNER JOIN project_members pm ON (p.id = pm.project_id AND
+                                           pm.user_id = $2)
+         LEFT JOIN tree_nodes t ON (p.id = t.project_id AND
+                                    t.deleted_at = '1970-01-01')
+         LEFT JOIN docs d ON t.id = d.id
+         LEFT JOIN files f ON (t.id = f.id AND NOT f.pending)
+WHERE p.id = $1
+  AND p.deleted_at IS NULL
+GROUP BY p.id
+`, projectId, userId).Scan(
+		&s.Collaborators,
+		&s.Compiles,
+		&s.Docs,
+		&s.Files,
+		&s.LastUpdatedAt,
+		&s.TotalSize,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, &errors.NotFoundError{}
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
 func (m *manager) GetForClone(ctx context.Context, projectId, userId sharedTypes.UUID) (*ForClone, error) {
 	p := ForClone{}
-	return &p, m.db.QueryRow(ctx, `
+	err := m.db.QueryRow(ctx, `
 WITH tree AS
          (SELECT t.project_id,
-                 array_agg(t.id)                     AS ids,
-                 array_agg(t.kind::TEXT)             AS kinds,
-                 array_agg(t.path)                   AS paths,
-                 array_agg(coalesce(d.snapshot, '')) AS snapshots,
-                 array_agg(t.created_at)             AS created_ats,
-                 array_agg(f.linked_file_data)       AS linked_file_data,
-                 array_agg(coalesce(f.size, 0))      AS sizes
+                 array_agg(t.id)                      AS ids,
+                 array_agg(t.kind::TEXT)              AS kinds,
+                 array_agg(t.path)                    AS paths,
+                 array_agg(coalesce(d.snapshot, ''))  AS snapshots,
+                 array_agg(t.created_at)              AS created_ats,
+                 array_agg(f.linked_file_data)        AS linked_file_data,
+                 array_agg(coalesce(f.size, 0))       AS sizes,
+                 array_agg(coalesce(f.page_count, 0)) AS page_counts
           FROM tree_nodes t
                    LEFT JOIN docs d ON t.id = d.id
                    LEFT JOIN files f ON t.id = f.id
@@ -1585,7 +2002,8 @@ SELECT p.compiler,
        tree.snapshots,
        tree.created_ats,
        tree.linked_file_data,
-       tree.sizes
+       tree.sizes,
+       tree.page_counts
 FROM projects p
          INNER JOIN project_members pm ON (p.id = pm.project_id AND
                                            pm.user_id = $2)
@@ -1609,12 +2027,31 @@ WHERE p.id = $1
 		&p.createdAts,
 		&p.linkedFileData,
 		&p.sizes,
+		&p.pageCounts,
 	)
+	if err != nil {
+		return &p, err
+	}
+	return &p, m.openTreeSnapshots(&p.ForTree)
+}
+
+func (m *manager) openTreeSnapshots(p *ForTree) error {
+	for i, s := range p.docSnapshots {
+		if s == "" {
+			continue
+		}
+		var err error
+		if p.docSnapshots[i], err = m.c.Open(p.treeIds[i], s); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type TreeEntity struct {
-	Path string `json:"path"`
-	Type string `json:"type"`
+	Path    string      `json:"path"`
+	Type    string      `json:"type"`
+	SubType FileSubType `json:"subType,omitempty"`
 }
 
 func (m *manager) GetTreeEntities(ctx context.Context, projectId, userId sharedTypes.UUID) ([]TreeEntity, error) {
@@ -1683,19 +2120,90 @@ WHERE p.id = $1
 	return c, nil
 }
 
+func (m *manager) GetProjectTokenMembers(ctx context.Context, projectId sharedTypes.UUID) ([]user.AsProjectTokenMember, error) {
+	r, err := m.db.Query(ctx, `
+SELECT u.id,
+       u.email,
+       u.first_name,
+       u.last_name,
+       pm.privilege_level,
+       coalesce(pm.token_privilege_level::TEXT, '')
+FROM project_members pm
+         INNER JOIN projects p ON p.id = pm.project_id
+         INNER JOIN users u ON pm.user_id = u.id
+WHERE p.id = $1
+  AND p.deleted_at IS NULL
+  AND pm.access_source = 'token'
+  AND u.deleted_at IS NULL
+`, projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	c := make([]user.AsProjectTokenMember, 0)
+	for i := 0; r.Next(); i++ {
+		c = append(c, user.AsProjectTokenMember{})
+		err = r.Scan(
+			&c[i].Id, &c[i].Email, &c[i].FirstName, &c[i].LastName,
+			&c[i].PrivilegeLevel, &c[i].TokenPrivilegeLevel,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = r.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 func (m *manager) GrantMemberAccess(ctx context.Context, projectId, ownerId, userId sharedTypes.UUID, privilegeLevel sharedTypes.PrivilegeLevel) error {
 	return getErr(m.db.Exec(ctx, `
-UPDATE project_members pm
-SET privilege_level = $4
-FROM projects p
-WHERE p.id = $1
-  AND p.owner_id = $2
-  AND p.id = pm.project_id
-  AND pm.user_id = $3
-  AND pm.access_source = 'invite'
+WITH pm AS (
+    UPDATE project_members pm
+        SET privilege_level = $4
+        FROM projects p
+        WHERE p.id = $1
+            AND p.owner_id = $2
+            AND p.id = pm.project_id
+            AND pm.user_id = $3
+            AND pm.access_source = 'invite'
+        RETURNING pm.project_id, pm.user_id, pm.privilege_level)
+INSERT
+INTO project_audit_log
+(created_at, id, info, initiator_id, operation, project_id)
+SELECT transaction_timestamp(),
+       gen_random_uuid(),
+       json_build_object(
+               'privilegeLevel', pm.privilege_level,
+               'userId', pm.user_id
+           ),
+       $2,
+       'set-member-privilege-level',
+       pm.project_id
+FROM pm
 `, projectId, ownerId, userId, privilegeLevel))
 }
 
+func (m *manager) SetMemberAccessExpiresAt(ctx context.Context, projectId, ownerId, userId sharedTypes.UUID, accessExpiresAt *time.Time) error {
+	return getErr(m.db.Exec(ctx, `
+WITH pm AS (
+    UPDATE project_members pm
+        SET access_expires_at = $4
+        FROM projects p
+        WHERE p.id = $1
+            AND p.owner_id = $2
+            AND p.id = pm.project_id
+            AND pm.user_id = $3
+            AND pm.access_source != 'owner'
+        RETURNING pm.project_id)
+UPDATE projects
+SET epoch = epoch + 1
+FROM pm
+WHERE id = pm.project_id
+`, projectId, ownerId, userId, accessExpiresAt))
+}
+
 func (m *manager) GetTokenAccessDetails(ctx context.Context, userId sharedTypes.UUID, privilegeLevel sharedTypes.PrivilegeLevel, accessToken AccessToken) (*ForTokenAccessDetails, *AuthorizationDetails, error) {
 	p := ForTokenAccessDetails{}
 	q, err := accessToken.toQueryParameters(privilegeLevel)
@@ -1705,11 +2213,14 @@ func (m *manager) GetTokenAccessDetails(ctx context.Context, userId sharedTypes.
 	err = m.db.QueryRow(ctx, `
 SELECT coalesce(pm.access_source::TEXT, ''),
        coalesce(pm.privilege_level::TEXT, ''),
+       pm.access_expires_at,
        p.id,
        p.epoch,
        p.name,
+       p.owner_id,
        coalesce(p.token_ro, ''),
-       coalesce(p.token_rw, '')
+       coalesce(p.token_rw, ''),
+       coalesce(p.token_rw_privilege_level::TEXT, '')
 FROM projects p
          LEFT JOIN project_members pm ON (p.id = pm.project_id AND
                                           pm.user_id = $1)
@@ -1718,7 +2229,10 @@ WHERE (p.token_ro = $2 OR p.token_rw_prefix = $3)
   AND p.deleted_at IS NULL
 `, userId, q.tokenRO, q.tokenRWPrefix).Scan(
 		&p.Member.AccessSource, &p.Member.PrivilegeLevel,
-		&p.Id, &p.Epoch, &p.Name, &p.Tokens.ReadOnly, &p.Tokens.ReadAndWrite,
+		&p.Member.AccessExpiresAt,
+		&p.Id, &p.Epoch, &p.Name, &p.OwnerId,
+		&p.Tokens.ReadOnly, &p.Tokens.ReadAndWrite,
+		&p.TokenReadAndWritePrivilegeLevel,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -1739,20 +2253,53 @@ func (m *manager) GrantTokenAccess(ctx context.Context, projectId, userId shared
 		return err
 	}
 	return getErr(m.db.Exec(ctx, `
-INSERT INTO project_members
-(project_id, user_id, access_source, privilege_level, archived, trashed)
-SELECT p.id, $2, 'token', $5, FALSE, FALSE
-FROM projects p
-WHERE id = $1
-  AND deleted_at IS NULL
-  AND public_access_level = 'tokenBased'
-  AND (token_ro = $3 OR token_rw_prefix = $4)
-
-ON CONFLICT (project_id, user_id)
-WHERE privilege_level < $5
-    DO
-UPDATE
-SET privilege_level = $5
+WITH pm AS (
+    INSERT INTO project_members
+        (project_id, user_id, access_source, privilege_level, archived,
+         trashed, token_privilege_level)
+        SELECT p.id,
+               $2,
+               'token',
+               least($5, coalesce(p.token_rw_privilege_level, $5)),
+               FALSE,
+               FALSE,
+               $5
+        FROM projects p
+        WHERE id = $1
+          AND deleted_at IS NULL
+          AND public_access_level = 'tokenBased'
+          AND (token_ro = $3 OR token_rw_prefix = $4)
+
+        ON CONFLICT (project_id, user_id)
+            DO UPDATE
+                SET privilege_level = excluded.privilege_level,
+                    token_privilege_level = excluded.token_privilege_level,
+                    access_source = CASE
+                                        WHEN project_members.access_expires_at IS NULL
+                                            THEN project_members.access_source
+                                        ELSE excluded.access_source
+                                    END,
+                    access_expires_at = NULL
+            -- Never downgrade a member with active access.
+            WHERE project_members.privilege_level < excluded.privilege_level
+               OR (project_members.access_expires_at IS NOT NULL AND
+                   (project_members.privilege_level = excluded.privilege_level
+                       OR project_members.access_expires_at <=
+                          transaction_timestamp()))
+        RETURNING project_id, user_id, privilege_level)
+INSERT
+INTO project_audit_log
+(created_at, id, info, initiator_id, operation, project_id)
+SELECT transaction_timestamp(),
+       gen_random_uuid(),
+       json_build_object(
+               'privilegeLevel', pm.privilege_level,
+               'userId', pm.user_id
+           ),
+       pm.user_id,
+       'join-via-token',
+       pm.project_id
+FROM pm
 `, projectId, userId, q.tokenRO, q.tokenRWPrefix, privilegeLevel))
 }
 
@@ -1765,7 +2312,21 @@ WITH pm AS (
             AND pm.user_id = $3
             AND p.owner_id != $3
             AND (p.owner_id = $2 OR $2 = $3)
-        RETURNING project_id)
+        RETURNING project_id, user_id),
+     log AS (
+         INSERT
+             INTO project_audit_log
+                 (created_at, id, info, initiator_id, operation, project_id)
+                 SELECT transaction_timestamp(),
+                        gen_random_uuid(),
+                        json_build_object('userId', pm.user_id),
+                        $2,
+                        CASE
+                            WHEN $2 = $3 THEN 'leave-project'
+                            ELSE 'remove-member'
+                        END,
+                        pm.project_id
+                 FROM pm)
 UPDATE projects
 SET epoch = epoch + 1
 FROM pm
@@ -1773,6 +2334,74 @@ WHERE id = pm.project_id
 `, projectId, actor, userId))
 }
 
+func (m *manager) ProcessExpiredMembers(ctx context.Context, cutOff time.Time, fn func(projectId, userId sharedTypes.UUID)) error {
+	projectIds := make(sharedTypes.UUIDs, 0, 100)
+	userIds := make(sharedTypes.UUIDs, 0, 100)
+	expiresAt := make([]time.Time, 0, 100)
+	var lastExpiresAt *time.Time
+	var lastProjectId, lastUserId sharedTypes.UUID
+	for {
+		projectIds = projectIds[:0]
+		userIds = userIds[:0]
+		expiresAt = expiresAt[:0]
+		r := m.db.QueryRow(ctx, `
+WITH ids AS (SELECT project_id, user_id, access_expires_at
+             FROM project_members
+             WHERE access_expires_at <= $1
+               AND access_source != 'owner'
+               AND ($2::TIMESTAMP IS NULL OR
+                    (access_expires_at, project_id, user_id) > ($2, $3, $4))
+             ORDER BY access_expires_at, project_id, user_id
+             LIMIT 100)
+SELECT array_agg(project_id ORDER BY access_expires_at, project_id, user_id),
+       array_agg(user_id ORDER BY access_expires_at, project_id, user_id),
+       array_agg(access_expires_at ORDER BY access_expires_at, project_id,
+                 user_id)
+FROM ids
+`, cutOff, lastExpiresAt, lastProjectId, lastUserId)
+		if err := r.Scan(&projectIds, &userIds, &expiresAt); err != nil {
+			return errors.Tag(err, "get ids")
+		}
+		if len(projectIds) == 0 {
+			return nil
+		}
+		for i, projectId := range projectIds {
+			fn(projectId, userIds[i])
+		}
+		last := len(projectIds) - 1
+		lastExpiresAt = &expiresAt[last]
+		lastProjectId = projectIds[last]
+		lastUserId = userIds[last]
+	}
+}
+
+func (m *manager) RemoveExpiredMember(ctx context.Context, projectId, userId sharedTypes.UUID, cutOff time.Time) error {
+	return getErr(m.db.Exec(ctx, `
+WITH pm AS (
+    DELETE FROM project_members pm
+        WHERE pm.project_id = $1
+            AND pm.user_id = $2
+            AND pm.access_source != 'owner'
+            AND pm.access_expires_at <= $3
+        RETURNING project_id, user_id),
+     log AS (
+         INSERT
+             INTO project_audit_log
+                 (created_at, id, info, initiator_id, operation, project_id)
+                 SELECT transaction_timestamp(),
+                        gen_random_uuid(),
+                        json_build_object('userId', pm.user_id),
+                        NULL,
+                        'member-access-expired',
+                        pm.project_id
+                 FROM pm)
+UPDATE projects
+SET epoch = epoch + 1
+FROM pm
+WHERE id = pm.project_id
+`, projectId, userId, cutOff))
+}
+
 func (m *manager) SoftDelete(ctx context.Context, projectIds sharedTypes.UUIDs, userId sharedTypes.UUID, ipAddress string) error {
 	blob, err := json.Marshal(map[string]string{
 		"ipAddress": ipAddress,
@@ -1890,6 +2519,10 @@ func (m *manager) CreateDoc(ctx context.Context, projectId, userId, folderId sha
 }
 
 func (m *manager) createDocVia(ctx context.Context, projectId, userId, folderId sharedTypes.UUID, d *Doc, runner queryRunner) (sharedTypes.Version, error) {
+	s, err := m.c.Seal(d.Id, d.Snapshot)
+	if err != nil {
+		return 0, err
+	}
 	var v sharedTypes.Version
 	return v, rewritePostgresErr(runner.QueryRow(ctx, `
 WITH f AS (SELECT t.id, t.path
@@ -1929,7 +2562,7 @@ SET last_updated_by = $2,
 FROM inserted_doc
 WHERE p.id = $1
 RETURNING p.tree_version
-`, projectId, userId, folderId, d.Id, d.Name, d.Snapshot).Scan(&v))
+`, projectId, userId, folderId, d.Id, d.Name, s).Scan(&v))
 }
 
 func (m *manager) EnsureIsDoc(ctx context.Context, projectId, userId, folderId sharedTypes.UUID, d *Doc) (sharedTypes.UUID, bool, sharedTypes.Version, error) {
@@ -2001,13 +2634,13 @@ WITH f AS (SELECT t.id, t.path
              RETURNING id)
 INSERT
 INTO files
-    (id, hash, linked_file_data, size, pending)
-SELECT inserted_tree_node.id, $8, $9, $10, TRUE
+    (id, hash, linked_file_data, size, page_count, pending)
+SELECT inserted_tree_node.id, $8, $9, $10, $11, TRUE
 FROM inserted_tree_node
 `,
 		projectId, userId, folderId,
 		f.Id, f.Name, f.CreatedAt, f.CreatedAt.Add(-time.Microsecond), f.Hash,
-		f.LinkedFileData, f.Size,
+		f.LinkedFileData, f.Size, f.PageCount,
 	))
 }
 
@@ -2126,6 +2759,90 @@ WHERE pm.user_id = $1
 `, userId).Scan(&ids)
 }
 
+func (m *manager) CountOwnedProjects(ctx context.Context, userId sharedTypes.UUID) (int64, error) {
+	var n int64
+	return n, m.db.QueryRow(ctx, `
+SELECT count(*)
+FROM projects p
+WHERE p.owner_id = $1
+  AND p.deleted_at IS NULL
+`, userId).Scan(&n)
+}
+
+func (m *manager) ListProjectsWithoutRootFolder(ctx context.Context) ([]sharedTypes.UUID, error) {
+	ids := make([]sharedTypes.UUID, 0)
+	return ids, m.db.QueryRow(ctx, `
+SELECT array_agg(id)
+FROM projects
+WHERE deleted_at IS NULL
+  AND root_folder_id IS NULL
+`).Scan(&ids)
+}
+
+func (m *manager) RepairRootFolder(ctx context.Context, projectId sharedTypes.UUID) error {
+	ok := false
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if !ok {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// Prefer linking an orphaned root folder over creating a new one.
+	var rootFolderId sharedTypes.UUID
+	err = tx.QueryRow(ctx, `
+SELECT coalesce(t.id, '00000000-0000-0000-0000-000000000000'::UUID)
+FROM projects p
+         LEFT JOIN tree_nodes t ON (p.id = t.project_id AND
+                                    t.parent_id IS NULL AND
+                                    t.kind = 'folder' AND
+                                    t.deleted_at = '1970-01-01')
+WHERE p.id = $1
+  AND p.deleted_at IS NULL
+  AND p.root_folder_id IS NULL
+LIMIT 1
+FOR UPDATE OF p
+`, projectId).Scan(&rootFolderId)
+	if err == pgx.ErrNoRows {
+		return &errors.NotFoundError{}
+	}
+	if err != nil {
+		return errors.Tag(err, "get root folder")
+	}
+
+	if rootFolderId.IsZero() {
+		if err = rootFolderId.Populate(); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+INSERT INTO tree_nodes
+(created_at, deleted_at, id, kind, parent_id, path, project_id)
+VALUES (transaction_timestamp(), '1970-01-01', $2, 'folder', NULL, '', $1)
+`, projectId, rootFolderId)
+		if err != nil {
+			return errors.Tag(err, "create root folder")
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+UPDATE projects
+SET root_folder_id = $2,
+    tree_version   = tree_version + 1
+WHERE id = $1
+`, projectId, rootFolderId)
+	if err != nil {
+		return errors.Tag(err, "set root folder")
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	ok = true
+	return nil
+}
+
 func (m *manager) ListProjectsWithName(ctx context.Context, userId sharedTypes.UUID) ([]WithIdAndName, error) {
 	r, err := m.db.Query(ctx, `
 SELECT p.id, name
@@ -0,0 +1,227 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package project_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/integrationTests"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func TestMain(m *testing.M) {
+	integrationTests.Setup(m)
+}
+
+func TestManager_GetProjectOwners(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	pm := project.New(db, nil)
+
+	alice := integrationTests.CreateUser(t, ctx, db)
+	bob := integrationTests.CreateUser(t, ctx, db)
+	want := make(map[sharedTypes.UUID]sharedTypes.UUID, 3)
+	for _, ownerId := range []sharedTypes.UUID{alice, alice, bob} {
+		projectId, _ := integrationTests.CreateProject(t, ctx, db, ownerId)
+		want[projectId] = ownerId
+	}
+	projectIds := make(sharedTypes.UUIDs, 0, len(want)+1)
+	for projectId := range want {
+		projectIds = append(projectIds, projectId)
+	}
+	missing := sharedTypes.UUID{42}
+	projectIds = append(projectIds, missing)
+
+	owners, err := pm.GetProjectOwners(ctx, projectIds)
+	if err != nil {
+		t.Fatalf("GetProjectOwners() error = %s", err)
+	}
+	if len(owners) != len(want) {
+		t.Errorf("GetProjectOwners() = %v, want %d owners", owners, len(want))
+	}
+	for projectId, ownerId := range want {
+		u, ok := owners[projectId]
+		if !ok || u.Id != ownerId {
+			t.Errorf("owner of %s = %s, want %s", projectId, u.Id, ownerId)
+		}
+		if u.Email == "" {
+			t.Errorf("owner of %s is missing email", projectId)
+		}
+	}
+	if _, ok := owners[missing]; ok {
+		t.Errorf("GetProjectOwners() returned owner for missing project")
+	}
+}
+
+func TestManager_RepairRootFolder(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	pm := project.New(db, nil)
+
+	ownerId := integrationTests.CreateUser(t, ctx, db)
+	unlinked, _ := integrationTests.CreateProject(t, ctx, db, ownerId)
+	missing, _ := integrationTests.CreateProject(t, ctx, db, ownerId)
+	healthy, _ := integrationTests.CreateProject(t, ctx, db, ownerId)
+
+	getRootFolderId := func(projectId sharedTypes.UUID) sharedTypes.UUID {
+		var id *sharedTypes.UUID
+		err := db.QueryRow(ctx, `
+SELECT root_folder_id
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&id)
+		if err != nil {
+			t.Fatalf("get root folder: %s", err)
+		}
+		if id == nil {
+			return sharedTypes.UUID{}
+		}
+		return *id
+	}
+	oldRootFolderId := getRootFolderId(unlinked)
+
+	_, err := db.Exec(ctx, `
+UPDATE projects
+SET root_doc_id    = NULL,
+    root_folder_id = NULL
+WHERE id = ANY ($1)
+`, sharedTypes.UUIDs{unlinked, missing})
+	if err != nil {
+		t.Fatalf("unlink root folders: %s", err)
+	}
+	_, err = db.Exec(ctx, `
+DELETE
+FROM tree_nodes
+WHERE project_id = $1
+`, missing)
+	if err != nil {
+		t.Fatalf("delete tree: %s", err)
+	}
+
+	detect := func() map[sharedTypes.UUID]bool {
+		ids, err2 := pm.ListProjectsWithoutRootFolder(ctx)
+		if err2 != nil {
+			t.Fatalf("list projects without root folder: %s", err2)
+		}
+		m := make(map[sharedTypes.UUID]bool, len(ids))
+		for _, id := range ids {
+			m[id] = true
+		}
+		return m
+	}
+	found := detect()
+	if !found[unlinked] || !found[missing] || found[healthy] {
+		t.Fatalf("unexpected detection result: %v", found)
+	}
+
+	for _, projectId := range []sharedTypes.UUID{unlinked, missing} {
+		if err = pm.RepairRootFolder(ctx, projectId); err != nil {
+			t.Fatalf("repair %s: %s", projectId, err)
+		}
+	}
+	found = detect()
+	if found[unlinked] || found[missing] {
+		t.Errorf("projects not repaired: %v", found)
+	}
+	if id := getRootFolderId(unlinked); id != oldRootFolderId {
+		t.Errorf("expected orphaned root folder to be linked, got %s", id)
+	}
+	if id := getRootFolderId(missing); id.IsZero() {
+		t.Errorf("expected new root folder")
+	}
+	if _, _, err = pm.GetProjectWithContent(ctx, missing); err != nil {
+		t.Errorf("get repaired project: %s", err)
+	}
+}
+
+func TestManager_ProcessExpiredMembers(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	pm := project.New(db, nil)
+
+	ownerId := integrationTests.CreateUser(t, ctx, db)
+	projectId, _ := integrationTests.CreateProject(t, ctx, db, ownerId)
+	// Spans more than one batch and expires before any other test data.
+	expiresAt := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := make(map[sharedTypes.UUID]bool, 101)
+	for i := 0; i < 101; i++ {
+		userId := integrationTests.CreateUser(t, ctx, db)
+		_, err := db.Exec(ctx, `
+INSERT INTO project_members
+(project_id, user_id, access_source, privilege_level, archived, trashed,
+ access_expires_at)
+VALUES ($1, $2, 'invite', 'readOnly', FALSE, FALSE, $3)
+`, projectId, userId, expiresAt)
+		if err != nil {
+			t.Fatalf("seed expired member: %s", err)
+		}
+		want[userId] = true
+	}
+
+	got := make(map[sharedTypes.UUID]int, len(want))
+	err := pm.ProcessExpiredMembers(
+		ctx, expiresAt.Add(time.Second),
+		func(p, userId sharedTypes.UUID) {
+			if p == projectId {
+				got[userId]++
+			}
+		},
+	)
+	if err != nil {
+		t.Fatalf("ProcessExpiredMembers() error = %s", err)
+	}
+	if len(got) != len(want) {
+		t.Errorf("visited %d members, want %d", len(got), len(want))
+	}
+	for userId, n := range got {
+		if !want[userId] || n != 1 {
+			t.Errorf("visited %s %d times", userId, n)
+		}
+	}
+}
+
+func TestManager_CountOwnedProjects(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	pm := project.New(db, nil)
+
+	owner := integrationTests.CreateUser(t, ctx, db)
+	other := integrationTests.CreateUser(t, ctx, db)
+	integrationTests.CreateProject(t, ctx, db, owner)
+	integrationTests.CreateProject(t, ctx, db, owner)
+	integrationTests.CreateProject(t, ctx, db, other)
+
+	owned, err := pm.GetOwnedProjects(ctx, owner)
+	if err != nil {
+		t.Fatalf("GetOwnedProjects() error = %s", err)
+	}
+	n, err := pm.CountOwnedProjects(ctx, owner)
+	if err != nil {
+		t.Fatalf("CountOwnedProjects() error = %s", err)
+	}
+	if n != int64(len(owned)) || n != 2 {
+		t.Errorf("CountOwnedProjects() = %d, want %d", n, len(owned))
+	}
+}
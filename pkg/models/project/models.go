@@ -17,6 +17,8 @@
 package project
 
 import (
+	"time"
+
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/das7pad/overleaf-go/pkg/models/user"
@@ -73,6 +75,15 @@ type LoadEditorDetails struct {
 	User    user.WithLoadEditorInfo
 }
 
+type Statistics struct {
+	Collaborators int64     `json:"collaborators"`
+	Compiles      int64     `json:"compiles"`
+	Docs          int64     `json:"docs"`
+	Files         int64     `json:"files"`
+	LastUpdatedAt time.Time `json:"lastUpdatedAt"`
+	TotalSize     int64     `json:"totalSize"`
+}
+
 type LoadEditorViewPublic struct {
 	CompilerField
 	IdField
@@ -80,6 +91,7 @@ type LoadEditorViewPublic struct {
 	NameField
 	OwnerFeaturesField
 	RootDocIdField
+	SnippetsField
 	VersionField
 }
 
@@ -87,12 +99,14 @@ type ForAuthorizationDetails struct {
 	Member
 	EpochField
 	PublicAccessLevelField
+	TokenReadAndWritePrivilegeLevelField
 	TokensField
 }
 
 type ForTokenAccessDetails struct {
 	IdField
 	NameField
+	OwnerIdField
 	ForAuthorizationDetails
 }
 
@@ -119,6 +133,7 @@ type ForTree struct {
 	createdAts     []pgtype.Timestamp
 	hashes         []string
 	sizes          []int64
+	pageCounts     []int
 	linkedFileData []*LinkedFileData
 }
 
@@ -130,6 +145,7 @@ type ForZip struct {
 type ForCreation struct {
 	CreatedAtField
 	CompilerField
+	CompilerInheritedField
 	DeletedAtField
 	IdField
 	ImageNameField
@@ -0,0 +1,65 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package project
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSnippet_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Snippet
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			s:    Snippet{Name: "figure", Body: "\\begin{figure}\n\\end{figure}"},
+		},
+		{
+			name:    "blank name",
+			s:       Snippet{Body: "foo"},
+			wantErr: true,
+		},
+		{
+			name:    "line feed in name",
+			s:       Snippet{Name: "foo\nbar", Body: "foo"},
+			wantErr: true,
+		},
+		{
+			name:    "slash in name",
+			s:       Snippet{Name: "foo/bar", Body: "foo"},
+			wantErr: true,
+		},
+		{
+			name: "body too long",
+			s: Snippet{
+				Name: "foo",
+				Body: strings.Repeat("x", maxSnippetBodyLength+1),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -109,6 +109,7 @@ type FileRef struct {
 	LinkedFileData *LinkedFileData  `json:"linkedFileData,omitempty"`
 	Hash           sharedTypes.Hash `json:"hash,omitempty"`
 	Size           int64            `json:"size"`
+	PageCount      int              `json:"pageCount,omitempty"`
 }
 
 func NewFileRef(name sharedTypes.Filename, hash sharedTypes.Hash, size int64) FileRef {
@@ -270,6 +271,9 @@ func (p *ForTree) GetRootFolder() *Folder {
 			if p.sizes != nil {
 				e.Size = p.sizes[i]
 			}
+			if p.pageCounts != nil {
+				e.PageCount = p.pageCounts[i]
+			}
 			f.FileRefs = append(f.FileRefs, e)
 		case TreeNodeKindFolder:
 			// NOTE: The paths of folders have a trailing slash in the DB.
@@ -316,6 +320,9 @@ func (p *ForClone) BuildTreeElements() (sharedTypes.PathName, []TreeElement, []s
 			if p.sizes != nil {
 				e.Size = p.sizes[i]
 			}
+			if p.pageCounts != nil {
+				e.PageCount = p.pageCounts[i]
+			}
 			elements = append(elements, e)
 		case TreeNodeKindFolder:
 			folders = append(folders, sharedTypes.DirName(path))
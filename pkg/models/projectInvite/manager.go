@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,6 +18,7 @@ package projectInvite
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -34,6 +35,8 @@ type Manager interface {
 	Create(ctx context.Context, pi *WithToken) error
 	GetById(ctx context.Context, projectId, inviteId, actorId sharedTypes.UUID) (*WithToken, error)
 	GetAllForProject(ctx context.Context, projectId, userId sharedTypes.UUID) ([]ForListing, error)
+	GetResentAt(ctx context.Context, projectId, inviteId sharedTypes.UUID) (time.Time, error)
+	MarkResent(ctx context.Context, projectId, inviteId sharedTypes.UUID) error
 }
 
 func New(db *pgxpool.Pool) Manager {
@@ -163,6 +166,37 @@ WHERE pi.id = $2
 	)
 }
 
+// GetResentAt returns the time of the last resend of the invite, if any.
+func (m *manager) GetResentAt(ctx context.Context, projectId, inviteId sharedTypes.UUID) (time.Time, error) {
+	var resentAt *time.Time
+	err := m.db.QueryRow(ctx, `
+SELECT resent_at
+FROM project_invites
+WHERE id = $2
+  AND project_id = $1
+  AND expires_at > transaction_timestamp()
+`, projectId, inviteId).Scan(&resentAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return time.Time{}, &errors.NotFoundError{}
+		}
+		return time.Time{}, err
+	}
+	if resentAt == nil {
+		return time.Time{}, nil
+	}
+	return *resentAt, nil
+}
+
+func (m *manager) MarkResent(ctx context.Context, projectId, inviteId sharedTypes.UUID) error {
+	return getErr(m.db.Exec(ctx, `
+UPDATE project_invites
+SET resent_at = transaction_timestamp()
+WHERE id = $2
+  AND project_id = $1
+`, projectId, inviteId))
+}
+
 func (m *manager) GetAllForProject(ctx context.Context, projectId, userId sharedTypes.UUID) ([]ForListing, error) {
 	r, err := m.db.Query(ctx, `
 SELECT pi.email, pi.id, pi.privilege_level
@@ -229,6 +263,20 @@ WITH pi AS (
                  ON CONFLICT (a, b) DO UPDATE
                      SET connections = excluded.connections,
                          last_touched_at = transaction_timestamp()),
+     log AS (
+         INSERT
+             INTO project_audit_log
+                 (created_at, id, info, initiator_id, operation, project_id)
+                 SELECT transaction_timestamp(),
+                        gen_random_uuid(),
+                        json_build_object(
+                                'privilegeLevel', pi.privilege_level,
+                                'userId', $2::UUID
+                            ),
+                        $2,
+                        'accept-invite',
+                        pi.project_id
+                 FROM pi),
      new_entry AS (
          INSERT INTO project_members
              (project_id, user_id, access_source, privilege_level, archived,
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,12 +18,16 @@ package systemMessage
 
 import (
 	"context"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
 type Manager interface {
-	Create(ctx context.Context, content string) error
+	Create(ctx context.Context, content string, projectId *sharedTypes.UUID, expiresAt *time.Time) error
 	DeleteAll(ctx context.Context) error
 	GetAll(ctx context.Context) ([]Full, error)
 }
@@ -36,13 +40,13 @@ type manager struct {
 	db *pgxpool.Pool
 }
 
-func (m *manager) Create(ctx context.Context, content string) error {
+func (m *manager) Create(ctx context.Context, content string, projectId *sharedTypes.UUID, expiresAt *time.Time) error {
 	_, err := m.db.Exec(
 		ctx,
 		`
-INSERT INTO system_messages (content, id)
-VALUES ($1, gen_random_uuid())
-`, content)
+INSERT INTO system_messages (content, expires_at, id, project_id)
+VALUES ($1, $2, gen_random_uuid(), $3)
+`, content, expiresAt, projectId)
 	return err
 }
 
@@ -58,21 +62,31 @@ func (m *manager) GetAll(ctx context.Context) ([]Full, error) {
 	r, err := m.db.Query(
 		ctx,
 		`
-SELECT id, content FROM system_messages
+SELECT id, content, expires_at, project_id
+FROM system_messages
+WHERE expires_at IS NULL
+   OR expires_at > transaction_timestamp()
 `)
 	if err != nil {
 		return nil, err
 	}
+	return scanMessages(r)
+}
+
+func scanMessages(r pgx.Rows) ([]Full, error) {
 	defer r.Close()
 	out := make([]Full, 0)
 	for r.Next() {
 		out = append(out, Full{})
 		i := len(out) - 1
-		if err = r.Scan(&out[i].Id, &out[i].Content); err != nil {
+		err := r.Scan(
+			&out[i].Id, &out[i].Content, &out[i].ExpiresAt, &out[i].ProjectId,
+		)
+		if err != nil {
 			return nil, err
 		}
 	}
-	if err = r.Err(); err != nil {
+	if err := r.Err(); err != nil {
 		return nil, err
 	}
 	return out, nil
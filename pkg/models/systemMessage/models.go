@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,10 +17,18 @@
 package systemMessage
 
 import (
+	"time"
+
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
 type Full struct {
-	Id      sharedTypes.UUID `json:"_id"`
-	Content string           `json:"content"`
+	Id        sharedTypes.UUID  `json:"_id"`
+	Content   string            `json:"content"`
+	ExpiresAt *time.Time        `json:"-"`
+	ProjectId *sharedTypes.UUID `json:"-"`
+}
+
+func (f *Full) IsGlobal() bool {
+	return f.ProjectId == nil
 }
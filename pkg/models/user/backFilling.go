@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -32,6 +32,11 @@ type AsProjectMember struct {
 	PrivilegeLevel sharedTypes.PrivilegeLevel `json:"privileges"`
 }
 
+type AsProjectTokenMember struct {
+	AsProjectMember
+	TokenPrivilegeLevel sharedTypes.PrivilegeLevel `json:"tokenPrivileges"`
+}
+
 type BulkFetched []WithPublicInfo
 
 func (b *BulkFetched) ScanFrom(r pgx.Rows) error {
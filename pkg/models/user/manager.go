@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -49,6 +49,7 @@ type Manager interface {
 	ChangePassword(ctx context.Context, change ForPasswordChange, ip, operation string, newHashedPassword string) error
 	DeleteDictionary(ctx context.Context, userId sharedTypes.UUID) error
 	LearnWord(ctx context.Context, userId sharedTypes.UUID, word string) error
+	LearnWords(ctx context.Context, userId sharedTypes.UUID, words []string) error
 	UnlearnWord(ctx context.Context, userId sharedTypes.UUID, word string) error
 	GetByPasswordResetToken(ctx context.Context, token oneTimeToken.OneTimeToken, u *ForPasswordChange) error
 }
@@ -83,7 +84,7 @@ WITH u AS (
         (beta_program, created_at, editor_config, email, email_created_at,
          epoch, features, first_name, id, last_login_at, last_login_ip,
          last_name, learned_words, login_count, must_reconfirm, password_hash)
-        VALUES (FALSE, $2, $3, $1, $2, 1, $4, '', $5, $6, $7, '',
+        VALUES (FALSE, $2, $3, $1, $2, 1, $4, $16, $5, $6, $7, $17,
                 ARRAY []::TEXT[], $8, FALSE, $9)
         RETURNING id),
      log AS (
@@ -112,9 +113,11 @@ FROM u
 		u.AuditLog[0].InitiatorId,
 		u.AuditLog[0].IPAddress,
 		u.AuditLog[0].Operation,
-		u.CreatedAt.Add(7*24*time.Hour),
+		u.CreatedAt.Add(u.OneTimeTokenExpiresIn),
 		u.OneTimeToken,
 		u.OneTimeTokenUse,
+		u.FirstName,
+		u.LastName,
 	)
 	if err != nil {
 		if e, ok := err.(*pgconn.PgError); ok {
@@ -545,6 +548,20 @@ WHERE id = $1
 `, userId, word))
 }
 
+func (m *manager) LearnWords(ctx context.Context, userId sharedTypes.UUID, words []string) error {
+	return getErr(m.db.Exec(ctx, `
+UPDATE users
+SET learned_words = array_cat(
+        learned_words,
+        ARRAY(SELECT w
+              FROM unnest($2::TEXT[]) WITH ORDINALITY AS x(w, i)
+              WHERE w != ALL (learned_words)
+              GROUP BY w
+              ORDER BY min(i)))
+WHERE id = $1
+`, userId, words))
+}
+
 func (m *manager) UnlearnWord(ctx context.Context, userId sharedTypes.UUID, word string) error {
 	return getErr(m.db.Exec(ctx, `
 UPDATE users
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,6 +17,8 @@
 package user
 
 import (
+	"time"
+
 	"github.com/das7pad/overleaf-go/pkg/models/oneTimeToken"
 )
 
@@ -70,7 +72,8 @@ type ForCreation struct {
 	CreatedAtField
 
 	oneTimeToken.OneTimeToken
-	OneTimeTokenUse string
+	OneTimeTokenUse       string
+	OneTimeTokenExpiresIn time.Duration
 }
 
 type ForEmailChange struct {
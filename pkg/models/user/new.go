@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -22,6 +22,10 @@ import (
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
+// NewUserOneTimeTokenExpiry is the default and maximum lifetime of the
+// one-time token for setting the password of a new user.
+const NewUserOneTimeTokenExpiry = 7 * 24 * time.Hour
+
 func NewUser(email sharedTypes.Email) ForCreation {
 	return ForCreation{
 		ForSession: ForSession{
@@ -43,5 +47,6 @@ func NewUser(email sharedTypes.Email) ForCreation {
 		CreatedAtField: CreatedAtField{
 			CreatedAt: time.Now().Truncate(time.Microsecond),
 		},
+		OneTimeTokenExpiresIn: NewUserOneTimeTokenExpiry,
 	}
 }
@@ -34,6 +34,44 @@ type Options struct {
 	Key             string        `json:"key"`
 	Secret          string        `json:"secret"`
 	SignedURLExpiry time.Duration `json:"signed_url_expiry_in_ns"`
+
+	// SSEMode sets the server-side encryption of new objects.
+	//  - "": use the bucket default
+	//  - "S3": SSE-S3, supported by AWS S3 and by MinIO with a KMS configured
+	//  - "KMS": SSE-KMS with SSEKMSKeyId, supported by AWS S3 and by MinIO
+	//     with a KES/KMS backend. MinIO without KMS rejects both modes.
+	SSEMode     SSEMode `json:"sse_mode"`
+	SSEKMSKeyId string  `json:"sse_kms_key_id"`
+
+	// MultipartThreshold enables multipart uploads for objects of at least
+	//  the given size, smaller objects use a single PUT. Parts are streamed
+	//  from the reader in chunks of MultipartPartSize.
+	MultipartThreshold int64  `json:"multipart_threshold"`
+	MultipartPartSize  uint64 `json:"multipart_part_size"`
+
+	// AllowRedirects permits sending clients to pre-signed URLs for
+	//  downloads instead of proxying the content. Enable it only when the
+	//  Endpoint is reachable from browsers, e.g. for AWS S3.
+	AllowRedirects bool `json:"allow_redirects"`
+}
+
+const minMultipartPartSize = 5 * 1024 * 1024
+
+type SSEMode string
+
+const (
+	SSEModeNone = SSEMode("")
+	SSEModeS3   = SSEMode("S3")
+	SSEModeKMS  = SSEMode("KMS")
+)
+
+func (m SSEMode) Validate() error {
+	switch m {
+	case SSEModeNone, SSEModeS3, SSEModeKMS:
+		return nil
+	default:
+		return &errors.ValidationError{Msg: "unknown sse_mode: " + string(m)}
+	}
 }
 
 func (o Options) Validate() error {
@@ -50,19 +88,56 @@ func (o Options) Validate() error {
 				Msg: "missing signed_url_expiry_in_ns",
 			}
 		}
+		if err := o.SSEMode.Validate(); err != nil {
+			return err
+		}
+		if o.SSEMode == SSEModeKMS && o.SSEKMSKeyId == "" {
+			return &errors.ValidationError{Msg: "missing sse_kms_key_id"}
+		}
+		if o.SSEMode != SSEModeKMS && o.SSEKMSKeyId != "" {
+			return &errors.ValidationError{
+				Msg: "sse_kms_key_id requires sse_mode=KMS",
+			}
+		}
+		if o.MultipartThreshold < 0 {
+			return &errors.ValidationError{
+				Msg: "multipart_threshold must not be negative",
+			}
+		}
+		if o.MultipartThreshold > 0 {
+			if o.MultipartPartSize < minMultipartPartSize {
+				return &errors.ValidationError{
+					Msg: "multipart_part_size must be at least 5MiB",
+				}
+			}
+			if o.MultipartPartSize > uint64(o.MultipartThreshold) {
+				return &errors.ValidationError{
+					Msg: "multipart_part_size must not exceed multipart_threshold",
+				}
+			}
+		}
 	default:
 		return &errors.ValidationError{Msg: "unknown provider: " + o.Provider}
 	}
 	return nil
 }
 
+type ObjectInfo struct {
+	Key          string
+	LastModified time.Time
+	Size         int64
+}
+
 type Backend interface {
+	AbortStaleMultipartUploads(ctx context.Context, prefix string, cutOff time.Time) error
 	CopyObject(ctx context.Context, dst string, src string) error
 	DeleteObject(ctx context.Context, key string) error
 	DeletePrefix(ctx context.Context, prefix string) error
 	GetObjectSize(ctx context.Context, key string) (int64, error)
 	GetReadStream(ctx context.Context, key string) (int64, io.ReadSeekCloser, error)
 	GetRedirectURLForGET(ctx context.Context, key string) (*url.URL, error)
+	IterateObjects(ctx context.Context, prefix string, fn func(o ObjectInfo) error) error
+	PresignGET(ctx context.Context, key string, ttl time.Duration, filename string) (*url.URL, error)
 	SendFromStream(ctx context.Context, key string, reader io.Reader, size int64) error
 }
 
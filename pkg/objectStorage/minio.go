@@ -19,11 +19,13 @@ package objectStorage
 import (
 	"context"
 	"io"
+	"mime"
 	"net/url"
 	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
 )
@@ -37,17 +39,33 @@ func initMinioBackend(o Options) (Backend, error) {
 	if err != nil {
 		return nil, err
 	}
+	var sse encrypt.ServerSide
+	switch o.SSEMode {
+	case SSEModeS3:
+		sse = encrypt.NewSSE()
+	case SSEModeKMS:
+		sse, err = encrypt.NewSSEKMS(o.SSEKMSKeyId, nil)
+		if err != nil {
+			return nil, errors.Tag(err, "init sse-kms")
+		}
+	}
 	return &minioBackend{
-		bucket:          o.Bucket,
-		mc:              mc,
-		signedURLExpiry: o.SignedURLExpiry,
+		bucket:             o.Bucket,
+		mc:                 mc,
+		multipartPartSize:  o.MultipartPartSize,
+		multipartThreshold: o.MultipartThreshold,
+		signedURLExpiry:    o.SignedURLExpiry,
+		sse:                sse,
 	}, nil
 }
 
 type minioBackend struct {
-	bucket          string
-	mc              *minio.Client
-	signedURLExpiry time.Duration
+	bucket             string
+	mc                 *minio.Client
+	multipartPartSize  uint64
+	multipartThreshold int64
+	signedURLExpiry    time.Duration
+	sse                encrypt.ServerSide
 }
 
 func rewriteError(err error) error {
@@ -55,19 +73,60 @@ func rewriteError(err error) error {
 		return nil
 	}
 	minioError, isMinioError := err.(minio.ErrorResponse)
-	if isMinioError && minioError.Code == "NoSuchKey" {
+	if isMinioError &&
+		(minioError.Code == "NoSuchKey" || minioError.Code == "NoSuchUpload") {
 		return &errors.NotFoundError{}
 	}
 	return err
 }
 
 func (m *minioBackend) SendFromStream(ctx context.Context, key string, reader io.Reader, size int64) error {
-	_, err := m.mc.PutObject(ctx, m.bucket, key, reader, size, minio.PutObjectOptions{
-		SendContentMd5: true,
-	})
+	o := minio.PutObjectOptions{
+		SendContentMd5:       true,
+		ServerSideEncryption: m.sse,
+	}
+	if m.multipartThreshold > 0 {
+		if size >= 0 && size < m.multipartThreshold {
+			o.DisableMultipart = true
+		} else {
+			o.PartSize = m.multipartPartSize
+		}
+	}
+	_, err := m.mc.PutObject(ctx, m.bucket, key, reader, size, o)
 	return err
 }
 
+func (m *minioBackend) AbortStaleMultipartUploads(ctx context.Context, prefix string, cutOff time.Time) error {
+	c := minio.Core{Client: m.mc}
+	keyMarker := ""
+	uploadIdMarker := ""
+	for {
+		r, err := c.ListMultipartUploads(
+			ctx, m.bucket, prefix, keyMarker, uploadIdMarker, "", 1000,
+		)
+		if err != nil {
+			return errors.Tag(rewriteError(err), "list multipart uploads")
+		}
+		for _, u := range r.Uploads {
+			if !u.Initiated.Before(cutOff) {
+				continue
+			}
+			err = c.AbortMultipartUpload(ctx, m.bucket, u.Key, u.UploadID)
+			if err = rewriteError(err); err != nil {
+				if errors.IsNotFoundError(err) {
+					continue
+				}
+				return errors.Tag(err, "abort multipart upload "+u.Key)
+			}
+		}
+		if !r.IsTruncated {
+			return nil
+		}
+		keyMarker = r.NextKeyMarker
+		uploadIdMarker = r.NextUploadIDMarker
+	}
+}
+
 func (m *minioBackend) GetReadStream(ctx context.Context, key string) (int64, io.ReadSeekCloser, error) {
 	r, err := m.mc.GetObject(ctx, m.bucket, key, minio.GetObjectOptions{})
 	if err != nil {
@@ -96,16 +155,21 @@ func (m *minioBackend) GetReadStream(ctx context.Context, key string) (int64, io
 }
 
 func (m *minioBackend) GetRedirectURLForGET(ctx context.Context, key string) (*url.URL, error) {
+	return m.presignGET(ctx, key, m.signedURLExpiry, "attachment")
+}
+
+func (m *minioBackend) PresignGET(ctx context.Context, key string, ttl time.Duration, filename string) (*url.URL, error) {
+	cd := mime.FormatMediaType("attachment", map[string]string{
+		"filename": filename,
+	})
+	return m.presignGET(ctx, key, ttl, cd)
+}
+
+func (m *minioBackend) presignGET(ctx context.Context, key string, ttl time.Duration, contentDisposition string) (*url.URL, error) {
 	params := make(url.Values)
-	params.Set("Response-Content-Disposition", "attachment")
-	params.Set("Response-Content-Type", "application/octet-stream")
-	return m.mc.PresignedGetObject(
-		ctx,
-		m.bucket,
-		key,
-		m.signedURLExpiry,
-		params,
-	)
+	params.Set("response-content-disposition", contentDisposition)
+	params.Set("response-content-type", "application/octet-stream")
+	return m.mc.PresignedGetObject(ctx, m.bucket, key, ttl, params)
 }
 
 func (m *minioBackend) GetObjectSize(ctx context.Context, key string) (int64, error) {
@@ -133,12 +197,36 @@ func (m *minioBackend) DeletePrefix(ctx context.Context, prefix string) error {
 	return nil
 }
 
+func (m *minioBackend) IterateObjects(ctx context.Context, prefix string, fn func(o ObjectInfo) error) error {
+	ctx, done := context.WithCancel(ctx)
+	defer done()
+	objects := m.mc.ListObjects(ctx, m.bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+	for o := range objects {
+		if o.Err != nil {
+			return errors.Tag(rewriteError(o.Err), "list objects")
+		}
+		err := fn(ObjectInfo{
+			Key:          o.Key,
+			LastModified: o.LastModified,
+			Size:         o.Size,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *minioBackend) CopyObject(ctx context.Context, dst string, src string) error {
 	_, err := m.mc.CopyObject(
 		ctx,
 		minio.CopyDestOptions{
-			Bucket: m.bucket,
-			Object: dst,
+			Bucket:     m.bucket,
+			Object:     dst,
+			Encryption: m.sse,
 		},
 		minio.CopySrcOptions{
 			Bucket: m.bucket,
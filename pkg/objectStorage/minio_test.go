@@ -0,0 +1,334 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package objectStorage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMinioBackend_SendFromStreamSSE(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      SSEMode
+		keyId     string
+		wantSSE   string
+		wantKeyId string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:    "S3",
+			mode:    SSEModeS3,
+			wantSSE: "AES256",
+		},
+		{
+			name:      "KMS",
+			mode:      SSEModeKMS,
+			keyId:     "my-key",
+			wantSSE:   "aws:kms",
+			wantKeyId: "my-key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var h http.Header
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPut {
+					mu.Lock()
+					h = r.Header.Clone()
+					mu.Unlock()
+				}
+				w.Header().Set("ETag", `"etag"`)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+			u, _ := url.Parse(srv.URL)
+
+			b, err := FromOptions(Options{
+				Bucket:          "bucket",
+				Provider:        "minio",
+				Endpoint:        u.Host,
+				Region:          "us-east-1",
+				Key:             "key",
+				Secret:          "secret",
+				SignedURLExpiry: time.Minute,
+				SSEMode:         tt.mode,
+				SSEKMSKeyId:     tt.keyId,
+			})
+			if err != nil {
+				t.Fatalf("FromOptions() error = %v", err)
+			}
+			blob := []byte("blob")
+			err = b.SendFromStream(
+				context.Background(), "key", bytes.NewReader(blob),
+				int64(len(blob)),
+			)
+			if err != nil {
+				t.Fatalf("SendFromStream() error = %v", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if h == nil {
+				t.Fatal("SendFromStream() did not PUT")
+			}
+			if got := h.Get("X-Amz-Server-Side-Encryption"); got != tt.wantSSE {
+				t.Errorf("SSE header = %q, want %q", got, tt.wantSSE)
+			}
+			got := h.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id")
+			if got != tt.wantKeyId {
+				t.Errorf("SSE key id header = %q, want %q", got, tt.wantKeyId)
+			}
+		})
+	}
+}
+
+func TestOptions_ValidateSSE(t *testing.T) {
+	base := Options{
+		Bucket:          "bucket",
+		Provider:        "minio",
+		Endpoint:        "localhost:9000",
+		SignedURLExpiry: time.Minute,
+	}
+	tests := []struct {
+		name    string
+		mode    SSEMode
+		keyId   string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "S3", mode: SSEModeS3},
+		{name: "KMS", mode: SSEModeKMS, keyId: "my-key"},
+		{name: "KMS without key", mode: SSEModeKMS, wantErr: true},
+		{name: "key without KMS", mode: SSEModeS3, keyId: "k", wantErr: true},
+		{name: "unknown", mode: "foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := base
+			o.SSEMode = tt.mode
+			o.SSEKMSKeyId = tt.keyId
+			if err := o.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type fakeS3Request struct {
+	method string
+	query  url.Values
+	size   int64
+}
+
+func newFakeS3(t *testing.T, o Options, listing string) (Backend, func() []fakeS3Request) {
+	var mu sync.Mutex
+	var requests []fakeS3Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		if d := r.Header.Get("X-Amz-Decoded-Content-Length"); d != "" {
+			// Skip the overhead of signed chunks.
+			n, _ = strconv.ParseInt(d, 10, 64)
+		}
+		mu.Lock()
+		requests = append(requests, fakeS3Request{
+			method: r.Method,
+			query:  r.URL.Query(),
+			size:   n,
+		})
+		mu.Unlock()
+		q := r.URL.Query()
+		w.Header().Set("ETag", `"etag"`)
+		switch {
+		case r.Method == http.MethodPost && q.Has("uploads"):
+			_, _ = io.WriteString(w, `<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPost && q.Has("uploadId"):
+			_, _ = io.WriteString(w, `<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><ETag>"etag"</ETag></CompleteMultipartUploadResult>`)
+		case r.Method == http.MethodGet && q.Has("uploads"):
+			_, _ = io.WriteString(w, listing)
+		case r.Method == http.MethodGet && q.Get("list-type") == "2":
+			_, _ = io.WriteString(w, listing)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	o.Bucket = "bucket"
+	o.Provider = "minio"
+	o.Endpoint = u.Host
+	o.Region = "us-east-1"
+	o.Key = "key"
+	o.Secret = "secret"
+	o.SignedURLExpiry = time.Minute
+	b, err := FromOptions(o)
+	if err != nil {
+		t.Fatalf("FromOptions() error = %v", err)
+	}
+	return b, func() []fakeS3Request {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]fakeS3Request(nil), requests...)
+	}
+}
+
+func TestMinioBackend_SendFromStreamMultipart(t *testing.T) {
+	const partSize = minMultipartPartSize
+	o := Options{
+		MultipartThreshold: 2 * partSize,
+		MultipartPartSize:  partSize,
+	}
+	tests := []struct {
+		name      string
+		size      int64
+		wantParts int
+	}{
+		{name: "small", size: 1024},
+		{name: "below threshold", size: 2*partSize - 1},
+		{name: "at threshold", size: 2 * partSize, wantParts: 2},
+		{name: "above threshold", size: 2*partSize + 1, wantParts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, requests := newFakeS3(t, o, "")
+			err := b.SendFromStream(
+				context.Background(), "key",
+				bytes.NewReader(make([]byte, tt.size)), tt.size,
+			)
+			if err != nil {
+				t.Fatalf("SendFromStream() error = %v", err)
+			}
+			parts := 0
+			var sent int64
+			for _, r := range requests() {
+				if r.method != http.MethodPut {
+					continue
+				}
+				if r.query.Has("partNumber") {
+					parts++
+				}
+				sent += r.size
+			}
+			if parts != tt.wantParts {
+				t.Errorf("SendFromStream() parts = %d, want %d", parts, tt.wantParts)
+			}
+			if sent != tt.size {
+				t.Errorf("SendFromStream() sent %d bytes, want %d", sent, tt.size)
+			}
+		})
+	}
+}
+
+func TestMinioBackend_AbortStaleMultipartUploads(t *testing.T) {
+	cutOff := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	b, requests := newFakeS3(t, Options{}, `<ListMultipartUploadsResult>
+<Bucket>bucket</Bucket>
+<IsTruncated>false</IsTruncated>
+<Upload><Key>p/stale</Key><UploadId>stale-1</UploadId><Initiated>2024-01-01T00:00:00.000Z</Initiated></Upload>
+<Upload><Key>p/fresh</Key><UploadId>fresh-1</UploadId><Initiated>2024-01-03T00:00:00.000Z</Initiated></Upload>
+</ListMultipartUploadsResult>`)
+	if err := b.AbortStaleMultipartUploads(context.Background(), "", cutOff); err != nil {
+		t.Fatalf("AbortStaleMultipartUploads() error = %v", err)
+	}
+	var aborted []string
+	for _, r := range requests() {
+		if r.method == http.MethodDelete {
+			aborted = append(aborted, r.query.Get("uploadId"))
+		}
+	}
+	if len(aborted) != 1 || aborted[0] != "stale-1" {
+		t.Errorf("AbortStaleMultipartUploads() aborted %v, want [stale-1]", aborted)
+	}
+}
+
+func TestMinioBackend_PresignGET(t *testing.T) {
+	b, requests := newFakeS3(t, Options{}, "")
+	u, err := b.PresignGET(
+		context.Background(), "p/f", 30*time.Second, "main file.pdf",
+	)
+	if err != nil {
+		t.Fatalf("PresignGET() error = %v", err)
+	}
+	q := u.Query()
+	if got := q.Get("X-Amz-Expires"); got != "30" {
+		t.Errorf("PresignGET() X-Amz-Expires = %q, want %q", got, "30")
+	}
+	want := `attachment; filename="main file.pdf"`
+	if got := q.Get("response-content-disposition"); got != want {
+		t.Errorf("PresignGET() content-disposition = %q, want %q", got, want)
+	}
+	if u.Path != "/bucket/p/f" {
+		t.Errorf("PresignGET() path = %q, want %q", u.Path, "/bucket/p/f")
+	}
+	if got := requests(); len(got) != 0 {
+		t.Errorf("PresignGET() sent requests: %v", got)
+	}
+}
+
+func TestMinioBackend_IterateObjects(t *testing.T) {
+	b, _ := newFakeS3(t, Options{}, `<ListBucketResult>
+<Name>bucket</Name>
+<IsTruncated>false</IsTruncated>
+<Contents><Key>p/a</Key><LastModified>2024-01-01T00:00:00.000Z</LastModified><Size>3</Size></Contents>
+<Contents><Key>p/b</Key><LastModified>2024-01-02T00:00:00.000Z</LastModified><Size>5</Size></Contents>
+</ListBucketResult>`)
+	var got []ObjectInfo
+	err := b.IterateObjects(context.Background(), "", func(o ObjectInfo) error {
+		got = append(got, o)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateObjects() error = %v", err)
+	}
+	want := []ObjectInfo{
+		{
+			Key:          "p/a",
+			LastModified: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Size:         3,
+		},
+		{
+			Key:          "p/b",
+			LastModified: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			Size:         5,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("IterateObjects() = %v, want %v", got, want)
+	}
+
+	errStop := errors.New("stop")
+	n := 0
+	err = b.IterateObjects(context.Background(), "", func(o ObjectInfo) error {
+		n++
+		return errStop
+	})
+	if err != errStop || n != 1 {
+		t.Errorf("IterateObjects() = %v after %d calls, want stop after 1", err, n)
+	}
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,7 +18,9 @@ package corsOptions
 
 import (
 	"strings"
+	"time"
 
+	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/httpUtils"
 	"github.com/das7pad/overleaf-go/pkg/options/env"
 )
@@ -30,8 +32,13 @@ func Parse() httpUtils.CORSOptions {
 		",",
 	)
 
-	return httpUtils.CORSOptions{
+	o := httpUtils.CORSOptions{
 		AllowOrigins: allowOrigins,
+		MaxAge:       env.GetDuration("CORS_MAX_AGE", time.Hour),
 		SiteOrigin:   siteURL,
 	}
+	if err := o.Validate(); err != nil {
+		panic(errors.Tag(err, "CORS_MAX_AGE"))
+	}
+	return o
 }
@@ -35,7 +35,6 @@ func ParseOverride(l, p string, port int) []string {
 }
 
 func parse(raw string, port int) []string {
-	port = env.GetInt("PORT", port)
 	o := strings.Split(raw, ",")
 	for i, addr := range o {
 		if strings.HasPrefix(addr, "/") {
@@ -25,6 +25,7 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/logger"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
@@ -37,7 +38,11 @@ func Periodic(ctx context.Context, uc redis.UniversalClient, prefix string, pc P
 		t0 := time.Now()
 		ok, err := Each(ctx, uc, prefix, pc.Count, fn)
 		d := time.Since(t0)
-		log.Printf("%s: ok=%t d=%s err=%v", msg, ok, d, err)
+		if err != nil {
+			logger.Error(msg, err, "ok", ok, "duration", d)
+		} else {
+			logger.Info(msg, "ok", ok, "duration", d)
+		}
 		if ok && err == nil {
 			t.Reset(inter)
 		} else {
@@ -27,12 +27,19 @@ import (
 type EditorEventMessage string
 
 const (
+	AddChatMessageReaction          = EditorEventMessage("add-chat-message-reaction")
 	Bootstrap                       = EditorEventMessage("bootstrap")
 	BroadcastDocMeta                = EditorEventMessage("broadcastDocMeta")
 	ClientTrackingBatch             = EditorEventMessage("clientTracking.batch")
+	ClientTrackingStoppedTyping     = EditorEventMessage("clientTracking.stoppedTyping")
+	ClientTrackingTyping            = EditorEventMessage("clientTracking.typing")
 	ClientTrackingUpdated           = EditorEventMessage("clientTracking.clientUpdated")
 	CompilerUpdated                 = EditorEventMessage("compilerUpdated")
 	ConnectionRejected              = EditorEventMessage("connectionRejected")
+	DeleteChatMessage               = EditorEventMessage("delete-chat-message")
+	EditChatMessage                 = EditorEventMessage("edit-chat-message")
+	FollowCursor                    = EditorEventMessage("followCursor")
+	FollowRequest                   = EditorEventMessage("followRequest")
 	ForceDisconnect                 = EditorEventMessage("forceDisconnect")
 	ImageNameUpdated                = EditorEventMessage("imageNameUpdated")
 	NewChatMessage                  = EditorEventMessage("new-chat-message")
@@ -47,6 +54,7 @@ const (
 	ReceiveNewDoc                   = EditorEventMessage("receiveNewDoc")
 	ReceiveNewFile                  = EditorEventMessage("receiveNewFile")
 	ReceiveNewFolder                = EditorEventMessage("receiveNewFolder")
+	RemoveChatMessageReaction       = EditorEventMessage("remove-chat-message-reaction")
 	RemoveEntity                    = EditorEventMessage("removeEntity")
 	RootDocUpdated                  = EditorEventMessage("rootDocUpdated")
 	SpellCheckLanguageUpdated       = EditorEventMessage("spellCheckLanguageUpdated")
@@ -59,18 +67,32 @@ func (e *EditorEventMessage) UnmarshalJSON(p []byte) error {
 		return errBadEditorEventMessage
 	}
 	switch EditorEventMessage(p[1 : len(p)-1]) {
+	case AddChatMessageReaction:
+		*e = AddChatMessageReaction
 	case Bootstrap:
 		*e = Bootstrap
 	case BroadcastDocMeta:
 		*e = BroadcastDocMeta
 	case ClientTrackingBatch:
 		*e = ClientTrackingBatch
+	case ClientTrackingStoppedTyping:
+		*e = ClientTrackingStoppedTyping
+	case ClientTrackingTyping:
+		*e = ClientTrackingTyping
 	case ClientTrackingUpdated:
 		*e = ClientTrackingUpdated
 	case CompilerUpdated:
 		*e = CompilerUpdated
 	case ConnectionRejected:
 		*e = ConnectionRejected
+	case DeleteChatMessage:
+		*e = DeleteChatMessage
+	case EditChatMessage:
+		*e = EditChatMessage
+	case FollowCursor:
+		*e = FollowCursor
+	case FollowRequest:
+		*e = FollowRequest
 	case ForceDisconnect:
 		*e = ForceDisconnect
 	case ImageNameUpdated:
@@ -99,6 +121,8 @@ func (e *EditorEventMessage) UnmarshalJSON(p []byte) error {
 		*e = ReceiveNewFile
 	case ReceiveNewFolder:
 		*e = ReceiveNewFolder
+	case RemoveChatMessageReaction:
+		*e = RemoveChatMessageReaction
 	case RemoveEntity:
 		*e = RemoveEntity
 	case RootDocUpdated:
@@ -111,6 +135,15 @@ func (e *EditorEventMessage) UnmarshalJSON(p []byte) error {
 	return nil
 }
 
+// ForceDisconnectPayload is the scoped variant of the ForceDisconnect
+// payload. The plain variant consists of the delay in seconds only.
+// Clients reconnect uniformly across [Delay, Delay+Jitter].
+type ForceDisconnectPayload struct {
+	Delay  float64 `json:"delay"`
+	Jitter float64 `json:"jitter"`
+	UserId UUID    `json:"userId"`
+}
+
 type EditorEvent struct {
 	/* "h" is a virtual field indicating the length of Payload */
 	Payload     json.RawMessage    `json:"payload"`
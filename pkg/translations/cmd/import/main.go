@@ -98,10 +98,10 @@ func processLocale(key, v string) string {
 	case "user_wants_you_to_see_project":
 		v = strings.ReplaceAll(v, "__username__", "{{ .SharedProjectData.UserName }}")
 		v = strings.ReplaceAll(v, "__projectname__", "<em>{{ .SharedProjectData.ProjectName }}</em>")
-	case "notification_project_invite":
-		// NOTE: This is a virtual key used for displaying the CTA notification
-		//        in the project dashboard. Other locales take over the actual
-		//        display.
+	case "notification_project_invite", "notification_token_access_granted":
+		// NOTE: These are virtual keys used for displaying the CTA
+		//        notifications in the project dashboard. Other locales take
+		//        over the actual display.
 		v = "-"
 	case "account_with_email_exists":
 		v = strings.ReplaceAll(v, "the email <b>__email__</b>", "the provided email")
@@ -66,6 +66,7 @@ func main() {
 		Handler: newHTTPController(clsiManager).GetRouter(),
 	}
 	httpUtils.ListenAndServeEach(eg.Go, &server, listenAddress.Parse(3013))
+	httpUtils.ServeMetrics(ctx, eg.Go, 13013)
 	eg.Go(func() error {
 		<-ctx.Done()
 		_ = loadAgentServer.Shutdown(context.Background())
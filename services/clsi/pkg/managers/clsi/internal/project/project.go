@@ -28,6 +28,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/httpUtils"
 	"github.com/das7pad/overleaf-go/pkg/pendingOperation"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	"github.com/das7pad/overleaf-go/services/clsi/pkg/constants"
@@ -216,7 +217,8 @@ func (p *project) doCompile(ctx context.Context, request *types.CompileRequest,
 			if err3 != nil {
 				log.Printf(
 					"pdfCaching: last: %q/%q: %d: %s",
-					p.namespace, lastSuccessfulCompileBuildId, len(ranges), err3,
+					p.namespace, lastSuccessfulCompileBuildId, len(ranges),
+					httpUtils.TagRequestId(ctx, err3),
 				)
 			}
 
@@ -270,7 +272,8 @@ func (p *project) doCompile(ctx context.Context, request *types.CompileRequest,
 		if err2 != nil {
 			log.Printf(
 				"pdfCaching: %q/%q: %d: %s",
-				p.namespace, buildId, len(ranges), err2,
+				p.namespace, buildId, len(ranges),
+				httpUtils.TagRequestId(pCtx, err2),
 			)
 		}
 		return nil
@@ -18,7 +18,6 @@ package main
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os/signal"
 	"syscall"
@@ -28,6 +27,7 @@ import (
 	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/httpUtils"
+	"github.com/das7pad/overleaf-go/pkg/logger"
 	"github.com/das7pad/overleaf-go/pkg/options/listenAddress"
 	"github.com/das7pad/overleaf-go/services/document-updater/pkg/managers/documentUpdater"
 	documentUpdaterTypes "github.com/das7pad/overleaf-go/services/document-updater/pkg/types"
@@ -72,7 +72,7 @@ func main() {
 		// - Hard cut for processing in document-updater. Go-Redis does not
 		//    respect context cancellation for the blocking reads anymore :/
 		if closeErr := rClient.Close(); closeErr != nil {
-			log.Printf("close redis: %s", closeErr)
+			logger.Error("close redis", closeErr)
 		}
 		return err2
 	})
@@ -18,13 +18,15 @@ package documentUpdater
 
 import (
 	"context"
-	"log"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/logger"
+	"github.com/das7pad/overleaf-go/pkg/models/doc"
+	"github.com/das7pad/overleaf-go/pkg/models/docHistory"
 	"github.com/das7pad/overleaf-go/pkg/redisScanner"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	"github.com/das7pad/overleaf-go/services/document-updater/pkg/managers/documentUpdater/internal/dispatchManager"
@@ -42,14 +44,18 @@ type Manager interface {
 	PeriodicFlushAllHistory(ctx context.Context)
 	CheckDocExists(ctx context.Context, projectId sharedTypes.UUID, docId sharedTypes.UUID) error
 	GetDoc(ctx context.Context, projectId sharedTypes.UUID, docId sharedTypes.UUID, fromVersion sharedTypes.Version) (*types.GetDocResponse, error)
+	GetDocVersion(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error)
 	GetProjectDocsAndFlushIfOldSnapshot(ctx context.Context, projectId sharedTypes.UUID) (types.DocContentSnapshots, error)
 	FlushAll(ctx context.Context) (bool, error)
 	FlushAndDeleteDoc(ctx context.Context, projectId, docId sharedTypes.UUID) error
+	FlushDoc(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error)
 	FlushProject(ctx context.Context, projectId sharedTypes.UUID) error
 	FlushProjectInBackground(ctx context.Context, projectId sharedTypes.UUID) bool
 	FlushAndDeleteProject(ctx context.Context, projectId sharedTypes.UUID) error
+	HistoryFlushManager() flush.Manager
 	SetDoc(ctx context.Context, projectId, docId sharedTypes.UUID, request types.SetDocRequest) error
 	ProcessProjectUpdates(ctx context.Context, projectId sharedTypes.UUID, updates types.RenameDocUpdates) error
+	PruneDocHistory(ctx context.Context, dryRun bool, start time.Time) error
 }
 
 func New(options *types.Options, db *pgxpool.Pool, client redis.UniversalClient) (Manager, error) {
@@ -60,11 +66,20 @@ func New(options *types.Options, db *pgxpool.Pool, client redis.UniversalClient)
 	if err != nil {
 		return nil, err
 	}
-	tc, err := flush.NewPeriodic(db, client, options.PeriodicFlushAll)
+	sink, err := flush.NewUpdateSink(client, options.HistoryUpdateSink)
 	if err != nil {
 		return nil, err
 	}
-	dm, err := docManager.New(db, client, tc, rtRm)
+	dhm := docHistory.New(db, options.DocHistory)
+	tc, err := flush.NewPeriodic(client, dhm, options.PeriodicFlushAll, sink)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := doc.NewSnapshotCipher(options.SnapshotEncryption)
+	if err != nil {
+		return nil, err
+	}
+	dm, err := docManager.New(db, sc, client, tc, rtRm)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +87,9 @@ func New(options *types.Options, db *pgxpool.Pool, client redis.UniversalClient)
 		rc:                       client,
 		dispatcher:               dispatchManager.New(options, client, dm, rtRm),
 		dm:                       dm,
+		dhm:                      dhm,
+		historyRetainFor:         options.DocHistory.RetainFor,
+		hfm:                      tc,
 		tc:                       tc,
 		rateLimitBackgroundFlush: make(chan struct{}, 50),
 		pc:                       options.PeriodicFlushAll,
@@ -84,6 +102,9 @@ type manager struct {
 	dispatcher
 	rc                       redis.UniversalClient
 	dm                       docManager.Manager
+	dhm                      docHistory.Manager
+	historyRetainFor         time.Duration
+	hfm                      flush.Manager
 	rateLimitBackgroundFlush chan struct{}
 	pc                       redisScanner.PeriodicOptions
 	tc                       trackChanges.Manager
@@ -106,6 +127,24 @@ func (m *manager) PeriodicFlushAllHistory(ctx context.Context) {
 	m.tc.PeriodicFlushAll(ctx)
 }
 
+func (m *manager) PruneDocHistory(ctx context.Context, dryRun bool, start time.Time) error {
+	n, err := m.dhm.Prune(ctx, start, m.historyRetainFor, dryRun)
+	if err != nil {
+		return errors.Tag(err, "prune doc history")
+	}
+	if dryRun {
+		logger.Info("dry-run pruning doc history", "entries", n)
+	} else if n > 0 {
+		logger.Info("pruned doc history", "entries", n)
+	}
+	return nil
+}
+
+// HistoryFlushManager shares the history flushing, including the sink.
+func (m *manager) HistoryFlushManager() flush.Manager {
+	return m.hfm
+}
+
 func (m *manager) FlushAll(ctx context.Context) (bool, error) {
 	ok, err := redisScanner.Each(
 		ctx, m.rc, "DocsIn:{", m.pc.Count,
@@ -163,6 +202,10 @@ func (m *manager) GetDoc(ctx context.Context, projectId, docId sharedTypes.UUID,
 	return &response, nil
 }
 
+func (m *manager) GetDocVersion(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error) {
+	return m.dm.GetDocVersion(ctx, projectId, docId)
+}
+
 func (m *manager) GetProjectDocsAndFlushIfOldSnapshot(ctx context.Context, projectId sharedTypes.UUID) (types.DocContentSnapshots, error) {
 	docs, err := m.dm.GetProjectDocsAndFlushIfOld(ctx, projectId)
 	if err != nil {
@@ -183,6 +226,10 @@ func (m *manager) FlushAndDeleteDoc(ctx context.Context, projectId, docId shared
 	return m.dm.FlushAndDeleteDoc(ctx, projectId, docId)
 }
 
+func (m *manager) FlushDoc(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error) {
+	return m.dm.FlushDoc(ctx, projectId, docId)
+}
+
 func (m *manager) FlushProject(ctx context.Context, projectId sharedTypes.UUID) error {
 	return m.dm.FlushProject(ctx, projectId)
 }
@@ -194,7 +241,7 @@ func (m *manager) FlushProjectInBackground(ctx context.Context, projectId shared
 	ctx, done := context.WithTimeout(ctx, 30*time.Second)
 	defer done()
 	if err := m.FlushAndDeleteProject(ctx, projectId); err != nil {
-		log.Printf("background flush failed: %s: %s", projectId, err)
+		logger.Error("background flush failed", err, "projectId", projectId)
 		return false
 	}
 	return true
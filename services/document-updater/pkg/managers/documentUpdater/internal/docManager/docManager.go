@@ -40,18 +40,20 @@ import (
 
 type Manager interface {
 	GetDoc(ctx context.Context, projectId, docId sharedTypes.UUID) (*types.Doc, error)
+	GetDocVersion(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error)
 	GetDocAndRecentUpdates(ctx context.Context, projectId, docId sharedTypes.UUID, fromVersion sharedTypes.Version) (*types.Doc, []sharedTypes.DocumentUpdate, error)
 	GetProjectDocsAndFlushIfOld(ctx context.Context, projectId sharedTypes.UUID) ([]*types.Doc, error)
 	SetDoc(ctx context.Context, projectId, docId sharedTypes.UUID, request types.SetDocRequest) error
 	RenameDoc(ctx context.Context, projectId, docId sharedTypes.UUID, newPath sharedTypes.PathName) error
 	ProcessUpdatesForDocHeadless(ctx context.Context, projectId, docId sharedTypes.UUID) error
 	FlushAndDeleteDoc(ctx context.Context, projectId, docId sharedTypes.UUID) error
+	FlushDoc(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error)
 	FlushProject(ctx context.Context, projectId sharedTypes.UUID) error
 	FlushAndDeleteProject(ctx context.Context, projectId sharedTypes.UUID) error
 	QueueFlushAndDeleteProject(ctx context.Context, projectId sharedTypes.UUID) error
 }
 
-func New(db *pgxpool.Pool, client redis.UniversalClient, tc trackChanges.Manager, rtRm realTimeRedisManager.Manager) (Manager, error) {
+func New(db *pgxpool.Pool, sc *doc.SnapshotCipher, client redis.UniversalClient, tc trackChanges.Manager, rtRm realTimeRedisManager.Manager) (Manager, error) {
 	rl, err := redisLocker.New(client, "Blocking")
 	if err != nil {
 		return nil, err
@@ -64,8 +66,8 @@ func New(db *pgxpool.Pool, client redis.UniversalClient, tc trackChanges.Manager
 		rtRm: rtRm,
 		tc:   tc,
 		u:    u,
-		dm:   doc.New(db),
-		pm:   project.New(db),
+		dm:   doc.New(db, sc),
+		pm:   project.New(db, sc),
 	}, nil
 }
 
@@ -106,6 +108,21 @@ func (m *manager) RenameDoc(ctx context.Context, projectId, docId sharedTypes.UU
 	}
 }
 
+func (m *manager) GetDocVersion(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error) {
+	v, err := m.rm.GetDocVersionInProject(ctx, projectId, docId)
+	if err == nil {
+		return v, nil
+	}
+	if !errors.IsNotFoundError(err) {
+		return 0, err
+	}
+	d, err := m.GetDoc(ctx, projectId, docId)
+	if err != nil {
+		return 0, err
+	}
+	return d.Version, nil
+}
+
 func (m *manager) GetDocAndRecentUpdates(ctx context.Context, projectId, docId sharedTypes.UUID, fromVersion sharedTypes.Version) (*types.Doc, []sharedTypes.DocumentUpdate, error) {
 	d, err := m.GetDoc(ctx, projectId, docId)
 	if err != nil {
@@ -151,6 +168,10 @@ func (m *manager) getDoc(ctx context.Context, projectId, docId sharedTypes.UUID)
 		return nil, nil, errors.Tag(err, "get doc from db")
 	}
 	d = types.DocFromFlushedDoc(flushedDoc, projectId, docId)
+	d.MaxUnFlushedAge, err = m.pm.GetMaxUnFlushedAge(ctx, projectId)
+	if err != nil {
+		return nil, nil, errors.Tag(err, "get max unflushed age")
+	}
 	if contentLockedAt == nil {
 		if err = m.rm.PutDocInMemory(ctx, projectId, docId, d); err != nil {
 			return nil, nil, errors.Tag(err, "put doc in memory")
@@ -237,8 +258,15 @@ func (m *manager) SetDoc(ctx context.Context, projectId, docId sharedTypes.UUID,
 func (m *manager) ProcessUpdatesForDocHeadless(ctx context.Context, projectId, docId sharedTypes.UUID) error {
 	for {
 		err := m.rl.TryRunWithLock(ctx, docId, func(ctx context.Context) error {
-			_, err := m.processUpdatesForDoc(ctx, projectId, docId)
-			return err
+			d, err := m.processUpdatesForDoc(ctx, projectId, docId)
+			if err != nil {
+				return err
+			}
+			if d.MaxUnFlushedAge == 0 {
+				// Leave flushing to the periodic flush.
+				return nil
+			}
+			return m.flushIfOld(ctx, projectId, docId, d)
 		})
 		if err == redisLocker.ErrLocked {
 			// Someone else is processing updates already.
@@ -259,6 +287,24 @@ const (
 	maxUnFlushedAge = 5 * time.Minute
 )
 
+func needsFlush(d *types.Doc, now time.Time) bool {
+	if d.UnFlushedTime == 0 {
+		return false
+	}
+	maxAge := maxUnFlushedAge
+	if d.MaxUnFlushedAge != 0 {
+		maxAge = d.MaxUnFlushedAge
+	}
+	return d.UnFlushedTime < types.UnFlushedTime(now.Add(-maxAge).Unix())
+}
+
+func (m *manager) flushIfOld(ctx context.Context, projectId, docId sharedTypes.UUID, d *types.Doc) error {
+	if !needsFlush(d, time.Now()) {
+		return nil
+	}
+	return m.doFlushAndMaybeDelete(ctx, projectId, docId, d, false)
+}
+
 func (m *manager) processUpdatesForDocAndFlushOld(ctx context.Context, projectId, docId sharedTypes.UUID) (*types.Doc, error) {
 	var d *types.Doc
 
@@ -269,20 +315,7 @@ func (m *manager) processUpdatesForDocAndFlushOld(ctx context.Context, projectId
 			if err != nil {
 				return err
 			}
-			if d.UnFlushedTime != 0 {
-				maxAge := types.UnFlushedTime(
-					time.Now().Add(-maxUnFlushedAge).Unix(),
-				)
-				if d.UnFlushedTime < maxAge {
-					err = m.doFlushAndMaybeDelete(
-						ctx, projectId, docId, d, false,
-					)
-					if err != nil {
-						return err
-					}
-				}
-			}
-			return nil
+			return m.flushIfOld(ctx, projectId, docId, d)
 		})
 		if err == errPartialFlush {
 			continue
@@ -458,6 +491,27 @@ func (m *manager) FlushAndDeleteDoc(ctx context.Context, projectId, docId shared
 	return m.flushAndMaybeDeleteDoc(ctx, projectId, docId, true)
 }
 
+func (m *manager) FlushDoc(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error) {
+	for {
+		var v sharedTypes.Version
+		err := m.rl.RunWithLock(ctx, docId, func(ctx context.Context) error {
+			d, err := m.processUpdatesForDoc(ctx, projectId, docId)
+			if err != nil {
+				return err
+			}
+			v = d.Version
+			return m.doFlushAndMaybeDelete(ctx, projectId, docId, d, false)
+		})
+		if err == errPartialFlush {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return v, nil
+	}
+}
+
 func (m *manager) flushAndMaybeDeleteDoc(ctx context.Context, projectId, docId sharedTypes.UUID, deleteFromRedis bool) error {
 	for {
 		err := m.rl.RunWithLock(ctx, docId, func(ctx context.Context) error {
@@ -0,0 +1,77 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docManager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/services/document-updater/pkg/types"
+)
+
+func Test_needsFlush(t *testing.T) {
+	now := time.Now()
+	unFlushedSince := func(d time.Duration, maxAge time.Duration) *types.Doc {
+		doc := types.Doc{}
+		doc.UnFlushedTime = types.UnFlushedTime(now.Add(-d).Unix())
+		doc.MaxUnFlushedAge = maxAge
+		return &doc
+	}
+	tests := []struct {
+		name string
+		doc  *types.Doc
+		want bool
+	}{
+		{
+			name: "flushed",
+			doc:  &types.Doc{},
+			want: false,
+		},
+		{
+			name: "tighter interval flushes sooner",
+			doc:  unFlushedSince(time.Minute, 10*time.Second),
+			want: true,
+		},
+		{
+			name: "tighter interval fresh",
+			doc:  unFlushedSince(time.Second, 10*time.Second),
+			want: false,
+		},
+		{
+			name: "default interval",
+			doc:  unFlushedSince(time.Minute, 0),
+			want: false,
+		},
+		{
+			name: "default interval old",
+			doc:  unFlushedSince(10*time.Minute, 0),
+			want: true,
+		},
+		{
+			name: "relaxed interval",
+			doc:  unFlushedSince(10*time.Minute, time.Hour),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsFlush(tt.doc, now); got != tt.want {
+				t.Errorf("needsFlush() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
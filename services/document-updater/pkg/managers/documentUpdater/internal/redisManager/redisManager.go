@@ -38,6 +38,7 @@ type Manager interface {
 	RemoveDocFromProject(ctx context.Context, projectId, docId sharedTypes.UUID) error
 	GetDoc(ctx context.Context, projectId sharedTypes.UUID, docId sharedTypes.UUID) (*types.Doc, error)
 	GetDocVersion(ctx context.Context, docId sharedTypes.UUID) (sharedTypes.Version, error)
+	GetDocVersionInProject(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error)
 	GetPreviousDocUpdates(ctx context.Context, docId sharedTypes.UUID, start sharedTypes.Version, end sharedTypes.Version) ([]sharedTypes.DocumentUpdate, error)
 	GetPreviousDocUpdatesUnderLock(ctx context.Context, docId sharedTypes.UUID, begin sharedTypes.Version, end sharedTypes.Version, docVersion sharedTypes.Version) ([]sharedTypes.DocumentUpdate, error)
 	UpdateDocument(ctx context.Context, docId sharedTypes.UUID, doc *types.Doc, appliedUpdates []sharedTypes.DocumentUpdate) (int64, error)
@@ -236,6 +237,32 @@ func (m *manager) GetDocVersion(ctx context.Context, docId sharedTypes.UUID) (sh
 	return sharedTypes.Version(v), nil
 }
 
+func (m *manager) GetDocVersionInProject(ctx context.Context, projectId, docId sharedTypes.UUID) (sharedTypes.Version, error) {
+	var vRes *redis.StringCmd
+	var inProjectRes *redis.BoolCmd
+	_, err := m.rClient.Pipelined(ctx, func(p redis.Pipeliner) error {
+		vRes = p.Get(ctx, getDocVersionKey(docId))
+		inProjectRes = p.SIsMember(
+			ctx, getDocsInProjectKey(projectId), docId.String(),
+		)
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return 0, errors.Tag(err, "get version from redis")
+	}
+	if !inProjectRes.Val() {
+		return 0, &errors.NotFoundError{}
+	}
+	v, err := vRes.Int64()
+	if err != nil {
+		if err == redis.Nil {
+			err = &errors.NotFoundError{}
+		}
+		return 0, errors.Tag(err, "parse version")
+	}
+	return sharedTypes.Version(v), nil
+}
+
 var scriptGetPreviousDocUpdates = redis.NewScript(`
 local length = redis.call("LLEN", KEYS[1])
 if length == 0 then error("overleaf: length is 0") end
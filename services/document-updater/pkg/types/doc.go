@@ -38,6 +38,9 @@ type DocCore struct {
 	Hash      sharedTypes.Hash     `json:"hash"`
 	ProjectId sharedTypes.UUID     `json:"project_id"`
 	PathName  sharedTypes.PathName `json:"path_name"`
+
+	// MaxUnFlushedAge is the per-project override of the flush interval.
+	MaxUnFlushedAge time.Duration `json:"max_unflushed_age,omitempty"`
 }
 
 type Doc struct {
@@ -18,14 +18,25 @@ package types
 
 import (
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/doc"
+	"github.com/das7pad/overleaf-go/pkg/models/docHistory"
 	"github.com/das7pad/overleaf-go/pkg/options/env"
 	"github.com/das7pad/overleaf-go/pkg/redisScanner"
+	"github.com/das7pad/overleaf-go/services/track-changes/pkg/managers/trackChanges/flush"
 )
 
 type Options struct {
 	PeriodicFlushAll             redisScanner.PeriodicOptions `json:"periodic_flush_all"`
 	Workers                      int                          `json:"workers"`
 	PendingUpdatesListShardCount int                          `json:"pending_updates_list_shard_count"`
+
+	DocHistory docHistory.Options `json:"doc_history"`
+
+	// HistoryUpdateSink receives updates once persisted into the history.
+	HistoryUpdateSink flush.UpdateSinkOptions `json:"history_update_sink"`
+
+	// SnapshotEncryption must match the options of services/web.
+	SnapshotEncryption doc.EncryptionOptions `json:"snapshot_encryption"`
 }
 
 func (o *Options) FillFromEnv() {
@@ -46,5 +57,14 @@ func (o *Options) Validate() error {
 	if err := o.PeriodicFlushAll.Validate(); err != nil {
 		return errors.Tag(err, "periodic_flush_all")
 	}
+	if err := o.DocHistory.Validate(); err != nil {
+		return errors.Tag(err, "doc_history")
+	}
+	if err := o.HistoryUpdateSink.Validate(); err != nil {
+		return errors.Tag(err, "history_update_sink")
+	}
+	if err := o.SnapshotEncryption.Validate(); err != nil {
+		return errors.Tag(err, "snapshot_encryption")
+	}
 	return nil
 }
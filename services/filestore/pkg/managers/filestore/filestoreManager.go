@@ -20,18 +20,31 @@ import (
 	"context"
 	"io"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/objectStorage"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
 type Manager interface {
+	AbortStaleUploads(ctx context.Context, cutOff time.Time) error
+	AllowRedirects() bool
 	CopyProjectFile(ctx context.Context, dstProjectId, dstFileId, srcProjectId, srcFileId sharedTypes.UUID) error
 	DeleteProjectFile(ctx context.Context, projectId sharedTypes.UUID, fileId sharedTypes.UUID) error
 	DeleteProject(ctx context.Context, projectId sharedTypes.UUID) error
+	DeleteThumbnail(ctx context.Context, hash sharedTypes.Hash, maxDimension int) error
 	GetReadStreamForProjectFile(ctx context.Context, projectId sharedTypes.UUID, fileId sharedTypes.UUID) (int64, io.ReadSeekCloser, error)
 	GetRedirectURLForGETOnProjectFile(ctx context.Context, projectId sharedTypes.UUID, fileId sharedTypes.UUID) (*url.URL, error)
+	GetReadStreamForThumbnail(ctx context.Context, hash sharedTypes.Hash, maxDimension int) (int64, io.ReadSeekCloser, error)
+	IterateProjectFiles(ctx context.Context, fn func(f ProjectFileObject) error) error
+	IterateThumbnails(ctx context.Context, fn func(t ThumbnailObject) error) error
+	PresignGet(ctx context.Context, projectId sharedTypes.UUID, fileId sharedTypes.UUID, ttl time.Duration, filename sharedTypes.Filename) (string, error)
 	SendStreamForProjectFile(ctx context.Context, projectId sharedTypes.UUID, fileId sharedTypes.UUID, reader io.Reader, size int64) error
+	SendStreamForThumbnail(ctx context.Context, hash sharedTypes.Hash, maxDimension int, reader io.Reader, size int64) error
+	StatProjectFile(ctx context.Context, projectId sharedTypes.UUID, fileId sharedTypes.UUID) (int64, bool, error)
 }
 
 func New(options objectStorage.Options) (Manager, error) {
@@ -43,11 +56,12 @@ func New(options objectStorage.Options) (Manager, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &manager{b: b}, nil
+	return &manager{b: b, allowRedirects: options.AllowRedirects}, nil
 }
 
 type manager struct {
-	b objectStorage.Backend
+	b              objectStorage.Backend
+	allowRedirects bool
 }
 
 func getProjectPrefix(projectId sharedTypes.UUID) string {
@@ -58,6 +72,22 @@ func getProjectFileKey(projectId, fileId sharedTypes.UUID) string {
 	return projectId.Concat('/', fileId)
 }
 
+// getThumbnailKey addresses thumbnails by the hash of the source file, which
+// allows sharing them between projects.
+func getThumbnailKey(hash sharedTypes.Hash, maxDimension int) string {
+	return thumbnailPrefix + string(hash) + "/" + strconv.Itoa(maxDimension)
+}
+
+const thumbnailPrefix = "thumbnails/"
+
+func (m *manager) AbortStaleUploads(ctx context.Context, cutOff time.Time) error {
+	return m.b.AbortStaleMultipartUploads(ctx, "", cutOff)
+}
+
+func (m *manager) AllowRedirects() bool {
+	return m.allowRedirects
+}
+
 func (m *manager) GetReadStreamForProjectFile(ctx context.Context, projectId sharedTypes.UUID, fileId sharedTypes.UUID) (int64, io.ReadSeekCloser, error) {
 	return m.b.GetReadStream(ctx, getProjectFileKey(projectId, fileId))
 }
@@ -66,6 +96,79 @@ func (m *manager) GetRedirectURLForGETOnProjectFile(ctx context.Context, project
 	return m.b.GetRedirectURLForGET(ctx, getProjectFileKey(projectId, fileId))
 }
 
+func (m *manager) GetReadStreamForThumbnail(ctx context.Context, hash sharedTypes.Hash, maxDimension int) (int64, io.ReadSeekCloser, error) {
+	return m.b.GetReadStream(ctx, getThumbnailKey(hash, maxDimension))
+}
+
+func (m *manager) PresignGet(ctx context.Context, projectId sharedTypes.UUID, fileId sharedTypes.UUID, ttl time.Duration, filename sharedTypes.Filename) (string, error) {
+	u, err := m.b.PresignGET(
+		ctx, getProjectFileKey(projectId, fileId), ttl, string(filename),
+	)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+type ProjectFileObject struct {
+	ProjectId    sharedTypes.UUID
+	FileId       sharedTypes.UUID
+	LastModified time.Time
+	Size         int64
+}
+
+// IterateProjectFiles lists all project files in the object storage.
+// Objects with keys that do not belong to a project file are skipped.
+func (m *manager) IterateProjectFiles(ctx context.Context, fn func(f ProjectFileObject) error) error {
+	return m.b.IterateObjects(ctx, "", func(o objectStorage.ObjectInfo) error {
+		rawProjectId, rawFileId, ok := strings.Cut(o.Key, "/")
+		if !ok {
+			return nil
+		}
+		projectId, err := sharedTypes.ParseUUID(rawProjectId)
+		if err != nil {
+			return nil
+		}
+		fileId, err := sharedTypes.ParseUUID(rawFileId)
+		if err != nil {
+			return nil
+		}
+		return fn(ProjectFileObject{
+			ProjectId:    projectId,
+			FileId:       fileId,
+			LastModified: o.LastModified,
+			Size:         o.Size,
+		})
+	})
+}
+
+type ThumbnailObject struct {
+	Hash         sharedTypes.Hash
+	MaxDimension int
+	LastModified time.Time
+}
+
+// IterateThumbnails lists all cached thumbnails in the object storage.
+func (m *manager) IterateThumbnails(ctx context.Context, fn func(t ThumbnailObject) error) error {
+	return m.b.IterateObjects(ctx, thumbnailPrefix, func(o objectStorage.ObjectInfo) error {
+		rawHash, rawMaxDimension, ok := strings.Cut(
+			strings.TrimPrefix(o.Key, thumbnailPrefix), "/",
+		)
+		if !ok {
+			return nil
+		}
+		maxDimension, err := strconv.Atoi(rawMaxDimension)
+		if err != nil {
+			return nil
+		}
+		return fn(ThumbnailObject{
+			Hash:         sharedTypes.Hash(rawHash),
+			MaxDimension: maxDimension,
+			LastModified: o.LastModified,
+		})
+	})
+}
+
 func (m *manager) CopyProjectFile(ctx context.Context, dstProjectId, dstFileId, srcProjectId, srcFileId sharedTypes.UUID) error {
 	return m.b.CopyObject(
 		ctx,
@@ -82,6 +185,10 @@ func (m *manager) DeleteProject(ctx context.Context, projectId sharedTypes.UUID)
 	return m.b.DeletePrefix(ctx, getProjectPrefix(projectId))
 }
 
+func (m *manager) DeleteThumbnail(ctx context.Context, hash sharedTypes.Hash, maxDimension int) error {
+	return m.b.DeleteObject(ctx, getThumbnailKey(hash, maxDimension))
+}
+
 func (m *manager) SendStreamForProjectFile(ctx context.Context, projectId sharedTypes.UUID, fileId sharedTypes.UUID, reader io.Reader, size int64) error {
 	return m.b.SendFromStream(
 		ctx,
@@ -90,3 +197,23 @@ func (m *manager) SendStreamForProjectFile(ctx context.Context, projectId shared
 		size,
 	)
 }
+
+func (m *manager) SendStreamForThumbnail(ctx context.Context, hash sharedTypes.Hash, maxDimension int, reader io.Reader, size int64) error {
+	return m.b.SendFromStream(
+		ctx,
+		getThumbnailKey(hash, maxDimension),
+		reader,
+		size,
+	)
+}
+
+func (m *manager) StatProjectFile(ctx context.Context, projectId sharedTypes.UUID, fileId sharedTypes.UUID) (int64, bool, error) {
+	size, err := m.b.GetObjectSize(ctx, getProjectFileKey(projectId, fileId))
+	if err != nil {
+		if errors.IsNotFoundError(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return size, true, nil
+}
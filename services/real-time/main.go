@@ -28,6 +28,7 @@ import (
 	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/httpUtils"
+	"github.com/das7pad/overleaf-go/pkg/logger"
 	"github.com/das7pad/overleaf-go/pkg/options/env"
 	"github.com/das7pad/overleaf-go/pkg/options/listenAddress"
 	"github.com/das7pad/overleaf-go/pkg/pendingOperation"
@@ -86,6 +87,7 @@ func main() {
 		server = &http.Server{Handler: router.New(rtm, &realTimeOptions)}
 	}
 	httpUtils.ListenAndServeEach(eg.Go, server, listenAddress.Parse(3026))
+	httpUtils.ServeInternal(ctx, eg.Go, 13026, router.Internal(rtm))
 	eg.Go(func() error {
 		<-ctx.Done()
 		rtm.InitiateGracefulShutdown()
@@ -96,6 +98,9 @@ func main() {
 		})
 		rtm.TriggerGracefulReconnect()
 		err2 := pendingShutdown.Wait(ctx2)
+		if n := rtm.WaitForClientsToDrain(ctx2); n > 0 {
+			logger.Warn("clients did not reconnect in time", "clients", n)
+		}
 		rtm.DisconnectAll()
 		dum.WaitForBackgroundFlush()
 		return err2
@@ -199,6 +199,7 @@ var connectFn realTime.ConnectFn
 func setup() {
 	pickTransport()
 	setLimits()
+	fatalIf(os.Setenv("WS_COMPRESSION", "true"))
 	go main()
 
 	f, done := jwtFactory(context.Background())
@@ -403,6 +404,25 @@ func TestPing(t *testing.T) {
 	fatalIf(err)
 }
 
+func TestPerMessageDeflate(t *testing.T) {
+	for _, offer := range []bool{false, true} {
+		c := realTime.Client{OfferPerMessageDeflate: offer}
+		_, err := c.Connect(
+			context.Background(), uri, bootstrapSharded[0], connectFn,
+		)
+		if err != nil {
+			t.Fatalf("Connect() returned %v", err)
+		}
+		if got := c.PerMessageDeflate(); got != offer {
+			t.Errorf("PerMessageDeflate() = %v, want %v", got, offer)
+		}
+		if err = c.Ping(); err != nil {
+			t.Errorf("Ping() returned %v", err)
+		}
+		c.Close()
+	}
+}
+
 func BenchmarkPing(b *testing.B) {
 	_, c := singleClientSetup()
 	defer c.Close()
@@ -518,3 +538,127 @@ func TestConnectedClients(t *testing.T) {
 		t.Fatal("id2 not removed after disconnect")
 	}
 }
+
+func TestTyping(t *testing.T) {
+	_, c := singleClientSetup()
+	defer c.Close()
+	_, o := singleClientSetup()
+	defer o.Close()
+
+	var typing, stopped int
+	c.On(sharedTypes.ClientTrackingTyping, func(_ types.RPCResponse) {
+		typing++
+	})
+	c.On(sharedTypes.ClientTrackingStoppedTyping, func(_ types.RPCResponse) {
+		stopped++
+	})
+
+	for _, state := range []bool{true, true, false} {
+		body, err := json.Marshal(types.TypingUpdate{Typing: state})
+		fatalIf(err)
+		r := types.RPCRequest{Action: types.UpdateTyping, Body: body}
+		fatalIf(o.RPCAsyncWrite(&types.RPCResponse{}, &r))
+	}
+
+	if err := c.SetDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatalf("set deadline: %s", err)
+	}
+	for stopped == 0 {
+		if err := c.ReadOnce(); err != nil {
+			t.Fatalf("waiting for stopped typing: %s", err)
+		}
+	}
+	if typing != 1 {
+		t.Errorf("typing = %d, want 1 after debounce", typing)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	_, c := singleClientSetup()
+	defer c.Close()
+	_, o := singleClientSetup()
+	defer o.Close()
+
+	var requested bool
+	var cursor types.FollowCursorNotification
+	c.On(sharedTypes.FollowRequest, func(_ types.RPCResponse) {
+		requested = true
+	})
+	c.On(sharedTypes.FollowCursor, func(r types.RPCResponse) {
+		fatalIf(json.Unmarshal(r.Body, &cursor))
+	})
+
+	optIn, err := json.Marshal(types.FollowToggle{Active: true})
+	fatalIf(err)
+	if err = c.SetDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatalf("set deadline: %s", err)
+	}
+	fatalIf(c.RPC(&types.RPCResponse{}, &types.RPCRequest{
+		Action: types.SetFollowing, Body: optIn,
+	}))
+
+	position, err := json.Marshal(types.FollowCursorPosition{
+		ClientPosition: types.ClientPosition{Row: 42, Column: 2},
+		ScrollTop:      13.5,
+	})
+	fatalIf(err)
+	for _, r := range []types.RPCRequest{
+		{Action: types.FollowRequest, Body: optIn},
+		{Action: types.FollowCursor, Body: position},
+	} {
+		fatalIf(o.RPCAsyncWrite(&types.RPCResponse{}, &r))
+	}
+
+	for cursor.Row == 0 {
+		if err = c.ReadOnce(); err != nil {
+			t.Fatalf("waiting for cursor: %s", err)
+		}
+	}
+	if !requested {
+		t.Error("follow request not received")
+	}
+	if cursor.Column != 2 || cursor.ScrollTop != 13.5 {
+		t.Errorf("cursor mismatch: %+v", cursor)
+	}
+}
+
+func TestBatchRPC(t *testing.T) {
+	c := realTime.Client{OfferBatchRPC: true}
+	_, err := c.Connect(
+		context.Background(), uri, bootstrapSharded[0], connectFn,
+	)
+	if err != nil {
+		t.Fatalf("Connect() returned %v", err)
+	}
+	defer c.Close()
+	if !c.BatchRPC() {
+		t.Fatalf("BatchRPC() = false, want true")
+	}
+	if err = c.SetDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatalf("set deadline: %s", err)
+	}
+
+	res := make([]types.RPCResponse, 3)
+	err = c.RPCBatch(res, types.RPCBatchRequest{
+		{Action: types.GetConnectedUsers},
+		{Action: "unknown"},
+		{Action: types.Ping},
+	})
+	if err != nil {
+		t.Fatalf("RPCBatch() returned %v", err)
+	}
+	if res[0].Error != nil || len(res[0].Body) == 0 {
+		t.Errorf("getConnectedUsers: %+v", res[0])
+	}
+	if res[1].Error == nil {
+		t.Error("unknown action: expected error")
+	}
+	if res[2].Error != nil {
+		t.Errorf("ping: %+v", res[2])
+	}
+
+	// Plain RPCs keep working on the same connection.
+	if err = c.Ping(); err != nil {
+		t.Errorf("Ping() returned %v", err)
+	}
+}
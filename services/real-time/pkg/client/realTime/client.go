@@ -22,6 +22,7 @@ import (
 	"context"
 	"crypto/sha1"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -40,6 +41,11 @@ import (
 )
 
 type Client struct {
+	// OfferPerMessageDeflate advertises support for compression on connect.
+	OfferPerMessageDeflate bool
+	// OfferBatchRPC advertises support for batches of RPCs on connect.
+	OfferBatchRPC bool
+
 	conn           websocket.LeanConn
 	mu             sync.Mutex
 	nextCB         types.Callback
@@ -48,6 +54,7 @@ type Client struct {
 	listenerExtra  []listener
 	stopPingTicker func()
 	buf            *readBuffer
+	batchRPC       bool
 }
 
 type listener struct {
@@ -115,6 +122,8 @@ var (
 	headerKeyWSProtocol   = []byte("Sec-Websocket-Protocol")
 	headerValueWSProtocol = []byte("v8.real-time.overleaf.com")
 	headerKeyWSAccept     = []byte("Sec-Websocket-Accept")
+	headerKeyWSExtensions = []byte("Sec-Websocket-Extensions")
+	headerValueWSDeflate  = []byte("permessage-deflate")
 	responseLine          = []byte("HTTP/1.1 101 Switching Protocols\r\n")
 )
 
@@ -181,7 +190,14 @@ func (c *Client) connect(ctx context.Context, uri *url.URL, bootstrap string, di
 	p = append(p, "Sec-Websocket-Version: 13\r\n"...)
 	p = append(p, "Sec-Websocket-Protocol: v8.real-time.overleaf.com, "...)
 	p = append(p, bootstrap...)
-	p = append(p, ".bootstrap.v8.real-time.overleaf.com\r\n"...)
+	p = append(p, ".bootstrap.v8.real-time.overleaf.com"...)
+	if c.OfferBatchRPC {
+		p = append(p, ", "+types.ProtocolBatchRPC...)
+	}
+	p = append(p, "\r\n"...)
+	if c.OfferPerMessageDeflate {
+		p = append(p, "Sec-Websocket-Extensions: permessage-deflate; client_no_context_takeover; server_no_context_takeover\r\n"...)
+	}
 	p = append(p, "Sec-Websocket-Key: "...)
 	p = append(p, key...)
 	p = append(p, "\r\n\r\n"...)
@@ -201,6 +217,8 @@ func (c *Client) connect(ctx context.Context, uri *url.URL, bootstrap string, di
 
 	accept := appendSecWebSocketAccept(p[:0], key)
 	var checks [4]bool
+	deflate := false
+	batchRPC := false
 
 	for {
 		l, err = c.buf.ReadSlice('\n')
@@ -231,6 +249,10 @@ func (c *Client) connect(ctx context.Context, uri *url.URL, bootstrap string, di
 			checks[1] = equalFoldASCII(value, headerValueUpgrade)
 		case !checks[2] && equalFoldASCII(name, headerKeyWSProtocol):
 			checks[2] = bytes.Equal(value, headerValueWSProtocol)
+			if !checks[2] && c.OfferBatchRPC {
+				batchRPC = string(value) == types.ProtocolBatchRPC
+				checks[2] = batchRPC
+			}
 		case !checks[3] && equalFoldASCII(name, headerKeyWSAccept):
 			checks[3] = bytes.Equal(value, accept)
 			if !checks[3] {
@@ -238,6 +260,13 @@ func (c *Client) connect(ctx context.Context, uri *url.URL, bootstrap string, di
 					"accept want=%s got=%s", string(accept), string(value),
 				)
 			}
+		case equalFoldASCII(name, headerKeyWSExtensions):
+			ext, _, _ := bytes.Cut(value, []byte(";"))
+			deflate = c.OfferPerMessageDeflate &&
+				equalFoldASCII(bytes.TrimSpace(ext), headerValueWSDeflate)
+			if !deflate {
+				return fmt.Errorf("unexpected extension: %s", string(value))
+			}
 		}
 	}
 
@@ -254,12 +283,23 @@ func (c *Client) connect(ctx context.Context, uri *url.URL, bootstrap string, di
 		ReadLimit:                   -1,
 		CompressionLevel:            websocket.DisableCompression,
 		IsServer:                    false,
-		NegotiatedPerMessageDeflate: false,
+		NegotiatedPerMessageDeflate: deflate,
 	}
+	c.batchRPC = batchRPC
 	c.mu.Unlock()
 	return nil
 }
 
+// PerMessageDeflate reports whether compression got negotiated on connect.
+func (c *Client) PerMessageDeflate() bool {
+	return c.conn.NegotiatedPerMessageDeflate
+}
+
+// BatchRPC reports whether batches of RPCs got negotiated on connect.
+func (c *Client) BatchRPC() bool {
+	return c.batchRPC
+}
+
 func (c *Client) Connect(ctx context.Context, uri *url.URL, bootstrap string, dial ConnectFn) (*types.RPCResponse, error) {
 	id := nextId.Add(1)
 	if err := c.connect(ctx, uri, bootstrap, dial); err != nil {
@@ -277,6 +317,14 @@ func (c *Client) Connect(ctx context.Context, uri *url.URL, bootstrap string, di
 	})
 	c.On(sharedTypes.ClientTrackingUpdated, func(_ types.RPCResponse) {
 	})
+	c.On(sharedTypes.ClientTrackingTyping, func(_ types.RPCResponse) {
+	})
+	c.On(sharedTypes.ClientTrackingStoppedTyping, func(_ types.RPCResponse) {
+	})
+	c.On(sharedTypes.FollowRequest, func(_ types.RPCResponse) {
+	})
+	c.On(sharedTypes.FollowCursor, func(_ types.RPCResponse) {
+	})
 
 	if deadline, ok := ctx.Deadline(); ok {
 		if err := c.conn.SetReadDeadline(deadline); err != nil {
@@ -394,6 +442,42 @@ func (c *Client) RPC(res *types.RPCResponse, r *types.RPCRequest) error {
 	return c.RPCAsyncRead(r)
 }
 
+func (c *Client) RPCBatch(res []types.RPCResponse, r types.RPCBatchRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn.Conn == nil {
+		return errors.New("closed")
+	}
+	if !c.batchRPC {
+		return errors.New("batch rpc not negotiated")
+	}
+	for i := range r {
+		c.nextCB++
+		if len(c.callbacks) == 0 {
+			if c.callbacks == nil {
+				c.callbacks = make(map[types.Callback]func(response types.RPCResponse), len(r))
+			}
+			c.nextCB = 1
+		}
+		r[i].Callback = c.nextCB
+		j := i
+		c.callbacks[r[i].Callback] = func(response types.RPCResponse) {
+			res[j] = response
+		}
+	}
+	if err := c.conn.WriteJSON(r); err != nil {
+		return err
+	}
+	for i := range r {
+		for c.callbacks[r[i].Callback] != nil {
+			if err := c.ReadOnce(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (c *Client) ReadOnce() error {
 	if c.conn.Conn == nil {
 		return errors.New("closed")
@@ -402,8 +486,25 @@ func (c *Client) ReadOnce() error {
 	if _, _, err := c.conn.NextReadIntoBuffer(c.buf.ReadBuffer); err != nil {
 		return err
 	}
+	p := c.buf.ReadBuffer.Bytes()
+	if len(p) > 0 && p[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(p, &batch); err != nil {
+			return err
+		}
+		for _, b := range batch {
+			if err := c.handleResponse(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return c.handleResponse(p)
+}
+
+func (c *Client) handleResponse(p []byte) error {
 	res := types.RPCResponse{}
-	if err := res.FastUnmarshalJSON(c.buf.ReadBuffer.Bytes()); err != nil {
+	if err := res.FastUnmarshalJSON(p); err != nil {
 		return err
 	}
 	matched := false
@@ -36,13 +36,22 @@ type Manager interface {
 	GetConnectedClients(ctx context.Context, client *types.Client) (json.RawMessage, error)
 	RefreshClientPositions(ctx context.Context, rooms editorEvents.Rooms) error
 	UpdatePosition(ctx context.Context, client *types.Client, position types.ClientPosition) error
+	UpdateTyping(ctx context.Context, client *types.Client, update types.TypingUpdate) error
+	UpdateFollowRequest(ctx context.Context, client *types.Client, toggle types.FollowToggle) error
+	UpdateFollowCursor(ctx context.Context, client *types.Client, p types.FollowCursorPosition) error
 	FlushRoomChanges(projectId sharedTypes.UUID, rc types.RoomChanges)
 }
 
-func New(client redis.UniversalClient, c channel.Writer) Manager {
+const DefaultTypingDebounce = 3 * time.Second
+
+func New(client redis.UniversalClient, c channel.Writer, typingDebounce time.Duration) Manager {
+	if typingDebounce <= 0 {
+		typingDebounce = DefaultTypingDebounce
+	}
 	m := manager{
-		redisClient: client,
-		c:           c,
+		redisClient:    client,
+		c:              c,
+		typingDebounce: typingDebounce,
 	}
 	for i := 0; i < 256; i++ {
 		m.pcc[i].pending = make(map[sharedTypes.UUID]*pendingConnectedClients)
@@ -51,9 +60,10 @@ func New(client redis.UniversalClient, c channel.Writer) Manager {
 }
 
 type manager struct {
-	redisClient redis.UniversalClient
-	c           channel.Writer
-	pcc         [256]pendingConnectedClientsManager
+	redisClient    redis.UniversalClient
+	c              channel.Writer
+	pcc            [256]pendingConnectedClientsManager
+	typingDebounce time.Duration
 }
 
 type flushRoomChangesCached struct {
@@ -254,6 +264,27 @@ func (m *manager) UpdatePosition(ctx context.Context, client *types.Client, p ty
 	return nil
 }
 
+// UpdateTyping relays the typing state to other clients. Unlike positions,
+// the typing state is not persisted.
+func (m *manager) UpdateTyping(ctx context.Context, client *types.Client, u types.TypingUpdate) error {
+	if !client.DebounceTyping(u.Typing, m.typingDebounce) {
+		return nil
+	}
+	return m.notifyTyping(ctx, client, u)
+}
+
+// UpdateFollowRequest starts/stops broadcasting the cursor of the client.
+func (m *manager) UpdateFollowRequest(ctx context.Context, client *types.Client, t types.FollowToggle) error {
+	client.SetSpotlighting(t.Active)
+	return m.notifyFollowRequest(ctx, client, t)
+}
+
+// UpdateFollowCursor relays the cursor of a spotlighting client to
+// followers. Like typing, the cursor is not persisted.
+func (m *manager) UpdateFollowCursor(ctx context.Context, client *types.Client, p types.FollowCursorPosition) error {
+	return m.notifyFollowCursor(ctx, client, p)
+}
+
 type pendingConnectedClientsManager struct {
 	mu      sync.RWMutex
 	pending map[sharedTypes.UUID]*pendingConnectedClients
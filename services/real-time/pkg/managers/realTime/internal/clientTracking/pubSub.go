@@ -44,3 +44,66 @@ func (m *manager) notifyUpdated(ctx context.Context, client *types.Client, p typ
 	}
 	return nil
 }
+
+func (m *manager) notifyTyping(ctx context.Context, client *types.Client, u types.TypingUpdate) error {
+	body, err := json.Marshal(types.ClientTyping{
+		ClientId: client.PublicId,
+		EntityId: u.EntityId,
+	})
+	if err != nil {
+		return errors.Tag(err, "encode notification")
+	}
+	msg := sharedTypes.EditorEvent{
+		Source:  client.PublicId,
+		RoomId:  client.ProjectId,
+		Message: sharedTypes.ClientTrackingStoppedTyping,
+		Payload: body,
+	}
+	if u.Typing {
+		msg.Message = sharedTypes.ClientTrackingTyping
+	}
+	if err = m.c.Publish(ctx, &msg); err != nil {
+		return errors.Tag(err, "send notification for typing")
+	}
+	return nil
+}
+
+func (m *manager) notifyFollowRequest(ctx context.Context, client *types.Client, t types.FollowToggle) error {
+	body, err := json.Marshal(types.FollowRequestNotification{
+		ClientId: client.PublicId,
+		Active:   t.Active,
+	})
+	if err != nil {
+		return errors.Tag(err, "encode notification")
+	}
+	msg := sharedTypes.EditorEvent{
+		Source:  client.PublicId,
+		RoomId:  client.ProjectId,
+		Message: sharedTypes.FollowRequest,
+		Payload: body,
+	}
+	if err = m.c.Publish(ctx, &msg); err != nil {
+		return errors.Tag(err, "send notification for follow request")
+	}
+	return nil
+}
+
+func (m *manager) notifyFollowCursor(ctx context.Context, client *types.Client, p types.FollowCursorPosition) error {
+	body, err := json.Marshal(types.FollowCursorNotification{
+		ClientId:             client.PublicId,
+		FollowCursorPosition: p,
+	})
+	if err != nil {
+		return errors.Tag(err, "encode notification")
+	}
+	msg := sharedTypes.EditorEvent{
+		Source:  client.PublicId,
+		RoomId:  client.ProjectId,
+		Message: sharedTypes.FollowCursor,
+		Payload: body,
+	}
+	if err = m.c.Publish(ctx, &msg); err != nil {
+		return errors.Tag(err, "send notification for follow cursor")
+	}
+	return nil
+}
@@ -31,6 +31,7 @@ import (
 
 type Manager interface {
 	BroadcastGracefulReconnect(suffix uint8) int
+	CountClients() (int, map[sharedTypes.UUID]int)
 	GetRooms() Rooms
 	GetRoomsFlat() RoomsFlat
 	Join(ctx context.Context, client *types.Client) error
@@ -322,6 +323,24 @@ func (m *manager) BroadcastGracefulReconnect(suffix uint8) int {
 	return len(m.rooms)
 }
 
+func (m *manager) CountClients() (int, map[sharedTypes.UUID]int) {
+	m.roomsMux.RLock()
+	defer m.roomsMux.RUnlock()
+	total := 0
+	perProject := make(map[sharedTypes.UUID]int, len(m.rooms))
+	for projectId, r := range m.rooms {
+		clients := r.Clients()
+		n := len(clients.All) - clients.Removed.Len()
+		clients.Done()
+		if n == 0 {
+			continue
+		}
+		perProject[projectId] = n
+		total += n
+	}
+	return total, perProject
+}
+
 func (m *manager) GetRooms() Rooms {
 	m.roomsMux.RLock()
 	defer m.roomsMux.RUnlock()
@@ -0,0 +1,61 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package editorEvents
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/real-time/pkg/types"
+)
+
+func Test_manager_CountClients(t *testing.T) {
+	fRc := func(sharedTypes.UUID, types.RoomChanges) {}
+	fP := func(context.Context, sharedTypes.UUID) bool { return true }
+	newTestRoom := func(clients ...*types.Client) *room {
+		r := newRoom(sharedTypes.UUID{}, fRc, fP)
+		close(r.c)
+		r.roomChangesFlush.Stop()
+		for _, client := range clients {
+			r.add(client)
+		}
+		return r
+	}
+	a := &types.Client{PublicId: "a"}
+	b := &types.Client{PublicId: "b"}
+	c := &types.Client{PublicId: "c"}
+	d := &types.Client{PublicId: "d"}
+
+	withRemoved := newTestRoom(b, c, d)
+	withRemoved.remove(c)
+	m := manager{rooms: map[sharedTypes.UUID]*room{
+		{1}: newTestRoom(a),
+		{2}: withRemoved,
+		{3}: newTestRoom(),
+	}}
+
+	total, perProject := m.CountClients()
+	if total != 3 {
+		t.Errorf("CountClients() total = %d, want 3", total)
+	}
+	want := map[sharedTypes.UUID]int{{1}: 1, {2}: 2}
+	if !reflect.DeepEqual(perProject, want) {
+		t.Errorf("CountClients() perProject = %v, want %v", perProject, want)
+	}
+}
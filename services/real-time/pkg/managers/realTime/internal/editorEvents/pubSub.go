@@ -19,6 +19,7 @@ package editorEvents
 import (
 	"encoding/json"
 	"log"
+	"math/rand"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/models/project"
@@ -56,6 +57,8 @@ func (r *room) Handle(raw string) {
 		err = r.handleProjectMembershipChanged(msg)
 	case sharedTypes.ProjectEditableUpdated:
 		err = r.handleMessage(msg, r.Clients(), disconnectAfterHandling)
+	case sharedTypes.ForceDisconnect:
+		err = r.handleForceDisconnect(msg)
 	default:
 		err = r.handleMessage(msg, r.Clients(), keepConnected)
 	}
@@ -113,6 +116,64 @@ func (r *room) handleProjectMembershipChanged(msg sharedTypes.EditorEvent) error
 	return r.handleMessage(msg, clients, keepConnected)
 }
 
+func (r *room) handleForceDisconnect(msg sharedTypes.EditorEvent) error {
+	if len(msg.Payload) == 0 || msg.Payload[0] != '{' {
+		// Plain delay for all clients.
+		return r.handleMessage(msg, r.Clients(), keepConnected)
+	}
+	p := sharedTypes.ForceDisconnectPayload{}
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		return errors.Tag(err, "deserialize payload")
+	}
+	if p.UserId.IsZero() && p.Jitter <= 0 {
+		body, err := json.Marshal(p.Delay)
+		if err != nil {
+			return errors.Tag(err, "serialize delay")
+		}
+		msg.Payload = body
+		return r.handleMessage(msg, r.Clients(), keepConnected)
+	}
+	clients := r.Clients()
+	defer clients.Done()
+	requiredCapability := getRequiredCapabilityForMessage(msg.Message)
+	var bulkMessage types.WriteQueueEntry
+	for i, client := range clients.All {
+		if clients.Removed.Has(i) {
+			continue
+		}
+		if client.PublicId == msg.Source {
+			continue
+		}
+		if !client.HasCapability(requiredCapability) {
+			continue
+		}
+		if !p.UserId.IsZero() && client.UserId != p.UserId {
+			continue
+		}
+		if bulkMessage.Msg == nil || p.Jitter > 0 {
+			// Spread the reconnect of individual clients.
+			delay := p.Delay
+			if p.Jitter > 0 {
+				delay += rand.Float64() * p.Jitter
+			}
+			body, err := json.Marshal(delay)
+			if err != nil {
+				return errors.Tag(err, "serialize delay")
+			}
+			resp := types.RPCResponse{
+				Name:        msg.Message,
+				Body:        body,
+				ProcessedBy: msg.ProcessedBy,
+			}
+			if bulkMessage, err = types.PrepareBulkMessage(&resp); err != nil {
+				return err
+			}
+		}
+		client.EnsureQueueMessage(bulkMessage)
+	}
+	return nil
+}
+
 func (r *room) handleMessage(msg sharedTypes.EditorEvent, clients Clients, isFinal isFinalEvent) error {
 	defer clients.Done()
 	var requiredCapability types.CapabilityComponent
@@ -130,6 +191,10 @@ func (r *room) handleMessage(msg sharedTypes.EditorEvent, clients Clients, isFin
 		if !client.HasCapability(requiredCapability) {
 			continue
 		}
+		if msg.Message == sharedTypes.FollowCursor && !client.IsFollowing() {
+			// Only clients that opted in to following receive cursors.
+			continue
+		}
 		if bulkMessage.Msg == nil {
 			resp := types.RPCResponse{
 				Name:        msg.Message,
@@ -141,12 +206,28 @@ func (r *room) handleMessage(msg sharedTypes.EditorEvent, clients Clients, isFin
 			if bulkMessage, err = types.PrepareBulkMessage(&resp); err != nil {
 				return err
 			}
+			bulkMessage.Droppable = isDroppableMessage(msg.Message)
 		}
 		client.EnsureQueueMessage(bulkMessage)
 	}
 	return nil
 }
 
+// isDroppableMessage reports whether a newer message will supersede the
+// given one. Document updates must not be dropped.
+func isDroppableMessage(message sharedTypes.EditorEventMessage) bool {
+	switch message {
+	case sharedTypes.ClientTrackingBatch,
+		sharedTypes.ClientTrackingStoppedTyping,
+		sharedTypes.ClientTrackingTyping,
+		sharedTypes.ClientTrackingUpdated,
+		sharedTypes.FollowCursor:
+		return true
+	default:
+		return false
+	}
+}
+
 func getRequiredCapabilityForMessage(message sharedTypes.EditorEventMessage) types.CapabilityComponent {
 	switch message {
 	case
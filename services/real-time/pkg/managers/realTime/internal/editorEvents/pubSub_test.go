@@ -0,0 +1,124 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package editorEvents
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/real-time/pkg/types"
+)
+
+func Test_room_handleForceDisconnect(t *testing.T) {
+	downgradedUserId := sharedTypes.UUID{1}
+	otherUserId := sharedTypes.UUID{2}
+
+	tests := []struct {
+		name    string
+		source  sharedTypes.PublicId
+		payload sharedTypes.ForceDisconnectPayload
+		want    []string
+	}{
+		{
+			name: "downgraded user",
+			payload: sharedTypes.ForceDisconnectPayload{
+				Delay:  1,
+				UserId: downgradedUserId,
+			},
+			want: []string{"downgraded"},
+		},
+		{
+			name: "all users with jitter",
+			payload: sharedTypes.ForceDisconnectPayload{
+				Delay:  1,
+				Jitter: 1,
+			},
+			want: []string{"downgraded", "other", "anonymous"},
+		},
+		{
+			name:   "skip source",
+			source: "other",
+			payload: sharedTypes.ForceDisconnectPayload{
+				Delay:  1,
+				Jitter: 1,
+			},
+			want: []string{"downgraded", "anonymous"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduled := make(chan *types.Client, 10)
+			newClient := func(publicId sharedTypes.PublicId, userId sharedTypes.UUID, level sharedTypes.PrivilegeLevel, isRestricted project.IsRestrictedUser) *types.Client {
+				c := types.NewClient(
+					&websocket.LeanConn{}, 10, types.WriteQueuePolicy{},
+					scheduled, 0,
+				)
+				c.PublicId = publicId
+				c.UserId = userId
+				c.ResolveCapabilities(level, isRestricted, true)
+				return c
+			}
+			fRc := func(sharedTypes.UUID, types.RoomChanges) {}
+			fP := func(context.Context, sharedTypes.UUID) bool { return true }
+			r := newRoom(sharedTypes.UUID{}, fRc, fP)
+			close(r.c)
+			r.roomChangesFlush.Stop()
+			r.add(newClient(
+				"downgraded", downgradedUserId,
+				sharedTypes.PrivilegeLevelReadOnly, false,
+			))
+			r.add(newClient(
+				"other", otherUserId,
+				sharedTypes.PrivilegeLevelReadAndWrite, false,
+			))
+			r.add(newClient(
+				"anonymous", sharedTypes.UUID{},
+				sharedTypes.PrivilegeLevelReadOnly, true,
+			))
+
+			blob, err := json.Marshal(tt.payload)
+			if err != nil {
+				t.Fatalf("serialize payload: %s", err)
+			}
+			err = r.handleForceDisconnect(sharedTypes.EditorEvent{
+				Source:  tt.source,
+				Message: sharedTypes.ForceDisconnect,
+				Payload: blob,
+			})
+			if err != nil {
+				t.Fatalf("handleForceDisconnect() = %s", err)
+			}
+			got := make(map[sharedTypes.PublicId]bool)
+			for len(scheduled) > 0 {
+				got[(<-scheduled).PublicId] = true
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("notified %d clients, want %d", len(got), len(tt.want))
+			}
+			for _, id := range tt.want {
+				if !got[sharedTypes.PublicId(id)] {
+					t.Errorf("client %s was not notified", id)
+				}
+			}
+		})
+	}
+}
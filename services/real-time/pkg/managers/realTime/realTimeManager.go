@@ -43,7 +43,9 @@ import (
 type ManagerI interface {
 	InitiateGracefulShutdown()
 	TriggerGracefulReconnect()
+	WaitForClientsToDrain(ctx context.Context) int
 	DisconnectAll()
+	CountClients() (int, map[sharedTypes.UUID]int)
 	IsShuttingDown() bool
 	PeriodicCleanup(ctx context.Context)
 	BootstrapWS(ctx context.Context, resp *types.RPCResponse, client *types.Client, claims projectJWT.Claims) error
@@ -57,7 +59,7 @@ func New(ctx context.Context, options *types.Options, db *pgxpool.Pool, client r
 	}
 
 	c := channel.New(client, "editor-events")
-	ct := clientTracking.New(client, c)
+	ct := clientTracking.New(client, c, options.TypingDebounce)
 	e := editorEvents.New(c, ct.FlushRoomChanges, dum.FlushProjectInBackground)
 	if err := e.StartListening(ctx); err != nil {
 		return nil, err
@@ -67,7 +69,7 @@ func New(ctx context.Context, options *types.Options, db *pgxpool.Pool, client r
 		clientTracking:   ct,
 		editorEvents:     e,
 		dum:              dum,
-		pm:               project.New(db),
+		pm:               project.New(db, nil),
 		gracefulShutdown: options.GracefulShutdown,
 		projectCache:     pc,
 	}, nil
@@ -134,6 +136,25 @@ func (m *Manager) TriggerGracefulReconnect() {
 	}
 }
 
+// WaitForClientsToDrain returns once all clients disconnected or the
+// context expired, whichever comes first. It returns the number of clients
+// that are still connected.
+func (m *Manager) WaitForClientsToDrain(ctx context.Context) int {
+	t := time.NewTicker(100 * time.Millisecond)
+	defer t.Stop()
+	for {
+		n, _ := m.CountClients()
+		if n == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return n
+		case <-t.C:
+		}
+	}
+}
+
 func (m *Manager) DisconnectAll() {
 	defer m.editorEvents.StopListening()
 	deadLine := time.Now().Add(m.gracefulShutdown.CleanupTimeout)
@@ -155,6 +176,12 @@ func (m *Manager) DisconnectAll() {
 	}
 }
 
+// CountClients returns the number of connected clients, in total and per
+// project.
+func (m *Manager) CountClients() (int, map[sharedTypes.UUID]int) {
+	return m.editorEvents.CountClients()
+}
+
 const b64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
 
 func (m *Manager) triggerGracefulReconnectOnce() bool {
@@ -336,6 +363,48 @@ func (m *Manager) updatePosition(ctx context.Context, rpc *types.RPC) error {
 	return nil
 }
 
+func (m *Manager) updateTyping(ctx context.Context, rpc *types.RPC) error {
+	var u types.TypingUpdate
+	if err := json.Unmarshal(rpc.Request.Body, &u); err != nil {
+		return &errors.ValidationError{Msg: "bad request: " + err.Error()}
+	}
+	if err := m.clientTracking.UpdateTyping(ctx, rpc.Client, u); err != nil {
+		return errors.Tag(err, "handle typing update")
+	}
+	return nil
+}
+
+func (m *Manager) followRequest(ctx context.Context, rpc *types.RPC) error {
+	var t types.FollowToggle
+	if err := json.Unmarshal(rpc.Request.Body, &t); err != nil {
+		return &errors.ValidationError{Msg: "bad request: " + err.Error()}
+	}
+	if err := m.clientTracking.UpdateFollowRequest(ctx, rpc.Client, t); err != nil {
+		return errors.Tag(err, "handle follow request")
+	}
+	return nil
+}
+
+func (m *Manager) followCursor(ctx context.Context, rpc *types.RPC) error {
+	var p types.FollowCursorPosition
+	if err := json.Unmarshal(rpc.Request.Body, &p); err != nil {
+		return &errors.ValidationError{Msg: "bad request: " + err.Error()}
+	}
+	if err := m.clientTracking.UpdateFollowCursor(ctx, rpc.Client, p); err != nil {
+		return errors.Tag(err, "handle follow cursor")
+	}
+	return nil
+}
+
+func (m *Manager) setFollowing(rpc *types.RPC) error {
+	var t types.FollowToggle
+	if err := json.Unmarshal(rpc.Request.Body, &t); err != nil {
+		return &errors.ValidationError{Msg: "bad request: " + err.Error()}
+	}
+	rpc.Client.SetFollowing(t.Active)
+	return nil
+}
+
 func (m *Manager) Disconnect(client *types.Client) {
 	client.TriggerDisconnect()
 	if client.ProjectId.IsZero() {
@@ -362,6 +431,14 @@ func (m *Manager) rpc(ctx context.Context, rpc *types.RPC) error {
 		return m.getConnectedUsers(ctx, rpc)
 	case types.UpdatePosition:
 		return m.updatePosition(ctx, rpc)
+	case types.UpdateTyping:
+		return m.updateTyping(ctx, rpc)
+	case types.FollowRequest:
+		return m.followRequest(ctx, rpc)
+	case types.FollowCursor:
+		return m.followCursor(ctx, rpc)
+	case types.SetFollowing:
+		return m.setFollowing(rpc)
 	default:
 		return &errors.ValidationError{
 			Msg: "unknown action: " + string(rpc.Request.Action),
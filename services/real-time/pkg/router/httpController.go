@@ -17,6 +17,8 @@
 package router
 
 import (
+	"bufio"
+	"compress/flate"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -57,7 +59,10 @@ func Add(r *httpUtils.Router, rtm *realTime.Manager, options *types.Options) {
 			// Validation is performed as part of the bootstrap process.
 			nil,
 		),
-		writeQueueDepth: options.WriteQueueDepth,
+		writeQueueDepth:        options.WriteQueueDepth,
+		writeQueuePolicy:       options.WriteQueuePolicy,
+		wsCompression:          options.WSCompression,
+		wsCompressionThreshold: options.WSCompressionThreshold,
 	}
 	h.startWorker(options)
 	h.addRoutes(r)
@@ -71,7 +76,10 @@ func WS(rtm *realTime.Manager, options *types.Options) *WSServer {
 			// Validation is performed as part of the bootstrap process.
 			nil,
 		),
-		writeQueueDepth: options.WriteQueueDepth,
+		writeQueueDepth:        options.WriteQueueDepth,
+		writeQueuePolicy:       options.WriteQueuePolicy,
+		wsCompression:          options.WSCompression,
+		wsCompressionThreshold: options.WSCompressionThreshold,
 	}
 	h.startWorker(options)
 	srv := WSServer{h: &h}
@@ -79,15 +87,26 @@ func WS(rtm *realTime.Manager, options *types.Options) *WSServer {
 	return &srv
 }
 
+// defaultWSCompressionThreshold skips compressing messages that fit into a
+// single TCP segment.
+const defaultWSCompressionThreshold = 1024
+
 type httpController struct {
-	rtm                *realTime.Manager
-	jwtProject         *jwtHandler.JWTHandler[*projectJWT.Claims]
-	bootstrapQueue     chan *bootstrapWSDetails
-	scheduleWriteQueue chan *types.Client
-	writeQueueDepth    int
+	rtm                    *realTime.Manager
+	jwtProject             *jwtHandler.JWTHandler[*projectJWT.Claims]
+	bootstrapQueue         chan *bootstrapWSDetails
+	scheduleWriteQueue     chan *types.Client
+	writeQueueDepth        int
+	writeQueuePolicy       types.WriteQueuePolicy
+	wsCompression          bool
+	wsCompressionThreshold int
 }
 
 func (h *httpController) startWorker(options *types.Options) {
+	if h.wsCompressionThreshold <= 0 {
+		h.wsCompressionThreshold = defaultWSCompressionThreshold
+	}
+
 	n := options.BootstrapWorker
 	if n <= 0 {
 		n = 60
@@ -127,32 +146,40 @@ func sendAndForget(conn *websocket.LeanConn, entry types.WriteQueueEntry) {
 	putBuffer(conn.BR)
 }
 
+func newLeanConn(c net.Conn, br *bufio.Reader, deflate bool) websocket.LeanConn {
+	cl := int8(websocket.DisableCompression)
+	if deflate {
+		cl = flate.BestSpeed
+	}
+	return websocket.LeanConn{
+		Conn:                        c,
+		BR:                          br,
+		ReadLimit:                   -1,
+		CompressionLevel:            cl,
+		IsServer:                    true,
+		NegotiatedPerMessageDeflate: deflate,
+	}
+}
+
 func (h *httpController) wsHTTP(w http.ResponseWriter, r *http.Request) {
 	t0 := time.Now()
 	claims := projectJWT.Claims{}
 	var jwtError error
-	c, br, err := HTTPUpgrade(w, r, func(blob []byte) {
+	c, br, d, err := HTTPUpgrade(w, r, func(blob []byte) {
 		jwtError = h.jwtProject.ParseInto(&claims, blob, t0)
-	})
+	}, h.wsCompression)
 	if err != nil {
 		// A 4xx has been generated already.
 		return
 	}
 
-	conn := websocket.LeanConn{
-		Conn:                        c,
-		BR:                          br,
-		ReadLimit:                   -1,
-		CompressionLevel:            websocket.DisableCompression,
-		IsServer:                    true,
-		NegotiatedPerMessageDeflate: false,
-	}
+	conn := newLeanConn(c, br, d.PerMessageDeflate)
 	if jwtError != nil {
 		log.Println("jwt auth failed: " + jwtError.Error())
 		sendAndForget(&conn, events.ConnectionRejectedBadWsBootstrapPrepared)
 		return
 	}
-	go h.ws(&conn, t0, claims)
+	go h.ws(&conn, t0, claims, d.BatchRPC)
 }
 
 func (h *httpController) wsWsServer(c *wsConn) error {
@@ -161,37 +188,33 @@ func (h *httpController) wsWsServer(c *wsConn) error {
 	if err != nil {
 		return err
 	}
-	conn := websocket.LeanConn{
-		Conn:                        c.Conn,
-		BR:                          c.reader,
-		ReadLimit:                   -1,
-		CompressionLevel:            websocket.DisableCompression,
-		IsServer:                    true,
-		NegotiatedPerMessageDeflate: false,
-	}
+	conn := newLeanConn(c.Conn, c.reader, c.perMessageDeflate)
 	if jwtError != nil {
 		log.Println("jwt auth failed: " + jwtError.Error())
 		sendAndForget(&conn, events.ConnectionRejectedBadWsBootstrapPrepared)
 		return nil
 	}
-	h.ws(&conn, c.t0, claims)
+	h.ws(&conn, c.t0, claims, c.batchRPC)
 	return nil
 }
 
-func (h *httpController) ws(conn *websocket.LeanConn, t0 time.Time, claimsProjectJWT projectJWT.Claims) {
+func (h *httpController) ws(conn *websocket.LeanConn, t0 time.Time, claimsProjectJWT projectJWT.Claims, batchRPC bool) {
 	if h.rtm.IsShuttingDown() {
 		sendAndForget(conn, events.ConnectionRejectedRetryPrepared)
 		return
 	}
 
-	c := types.NewClient(conn, h.writeQueueDepth, h.scheduleWriteQueue)
+	c := types.NewClient(
+		conn, h.writeQueueDepth, h.writeQueuePolicy, h.scheduleWriteQueue,
+		h.wsCompressionThreshold,
+	)
 
 	if !h.bootstrap(t0, c, claimsProjectJWT) {
 		h.rtm.Disconnect(c)
 		return
 	}
 
-	h.readLoop(conn, c)
+	h.readLoop(conn, c, batchRPC)
 }
 
 type bootstrapWSDetails struct {
@@ -259,7 +282,7 @@ func (h *httpController) bootstrap(t0 time.Time, c *types.Client, claimsProjectJ
 	return c.TryWriteResponseOrQueue(d.resp)
 }
 
-func (h *httpController) readLoop(conn *websocket.LeanConn, c *types.Client) {
+func (h *httpController) readLoop(conn *websocket.LeanConn, c *types.Client, batchRPC bool) {
 	defer putBuffer(conn.BR)
 	defer h.rtm.Disconnect(c)
 	if conn.SetDeadline(time.Now().Add(idleTime)) != nil {
@@ -273,7 +296,22 @@ func (h *httpController) readLoop(conn *websocket.LeanConn, c *types.Client) {
 			return
 		}
 		var request types.RPCRequest
-		if err = json.NewDecoder(r).Decode(&request); err != nil {
+		var batch types.RPCBatchRequest
+		if batchRPC {
+			var raw json.RawMessage
+			if err = json.NewDecoder(r).Decode(&raw); err == nil {
+				if len(raw) > 0 && raw[0] == '[' {
+					if err = json.Unmarshal(raw, &batch); err == nil {
+						err = batch.Validate()
+					}
+				} else {
+					err = json.Unmarshal(raw, &request)
+				}
+			}
+		} else {
+			err = json.NewDecoder(r).Decode(&request)
+		}
+		if err != nil {
 			if shouldTriggerDisconnect(err) {
 				_ = conn.Close()
 				return
@@ -281,7 +319,9 @@ func (h *httpController) readLoop(conn *websocket.LeanConn, c *types.Client) {
 			c.EnsureQueueMessage(events.BadRequestBulkMessage)
 			return
 		}
-		if request.Action == types.Ping {
+		if batch != nil {
+			ok = h.processBatch(conn, c, batch)
+		} else if request.Action == types.Ping {
 			if conn.SetDeadline(time.Now().Add(idleTime)) != nil {
 				_ = conn.Close()
 				return
@@ -294,25 +334,59 @@ func (h *httpController) readLoop(conn *websocket.LeanConn, c *types.Client) {
 				ok = c.TryWriteResponseOrQueue(response)
 			}
 		} else {
-			t0 := time.Now()
-			response := types.RPCResponse{Callback: request.Callback}
-			response.Latency.SetBegin(t0)
-			rpc := types.RPC{
-				Client:   c,
-				Request:  &request,
-				Response: &response,
-			}
-			ctx, finishedRPC := context.WithDeadline(
-				context.Background(), t0.Add(time.Second*10),
-			)
-			h.rtm.RPC(ctx, &rpc)
-			finishedRPC()
-			response.Latency.End()
+			response := h.processRPC(c, &request)
 			ok = c.TryWriteResponseOrQueue(response) && !response.FatalError
 		}
 	}
 }
 
+func (h *httpController) processRPC(c *types.Client, request *types.RPCRequest) types.RPCResponse {
+	t0 := time.Now()
+	response := types.RPCResponse{Callback: request.Callback}
+	response.Latency.SetBegin(t0)
+	rpc := types.RPC{
+		Client:   c,
+		Request:  request,
+		Response: &response,
+	}
+	ctx, finishedRPC := context.WithDeadline(
+		context.Background(), t0.Add(time.Second*10),
+	)
+	h.rtm.RPC(ctx, &rpc)
+	finishedRPC()
+	response.Latency.End()
+	return response
+}
+
+// processBatch processes the RPCs in order and sends all responses in a
+// single frame. Errors are reported per RPC, only a fatal error stops the
+// processing of the remaining RPCs.
+func (h *httpController) processBatch(conn *websocket.LeanConn, c *types.Client, batch types.RPCBatchRequest) bool {
+	responses := make([]types.RPCResponse, 0, len(batch))
+	for i := range batch {
+		var response types.RPCResponse
+		if batch[i].Action == types.Ping {
+			if conn.SetDeadline(time.Now().Add(idleTime)) != nil {
+				_ = conn.Close()
+				return false
+			}
+			response = types.RPCResponse{Callback: batch[i].Callback}
+		} else {
+			response = h.processRPC(c, &batch[i])
+		}
+		responses = append(responses, response)
+		if response.FatalError {
+			break
+		}
+	}
+	entry, err := types.PrepareBatchMessage(responses)
+	if err != nil {
+		log.Println("prepare batch response: " + err.Error())
+		return false
+	}
+	return c.EnsureQueueMessage(entry) && !entry.FatalError
+}
+
 func (h *httpController) writeWorker() {
 	for client := range h.scheduleWriteQueue {
 		client.ProcessQueuedMessages()
@@ -0,0 +1,45 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/das7pad/overleaf-go/services/real-time/pkg/managers/realTime"
+	"github.com/das7pad/overleaf-go/services/real-time/pkg/types"
+)
+
+// Internal returns the handlers for the internal listener, see
+// httpUtils.ServeInternal. The per project client counts must not be
+// exposed publicly.
+func Internal(rtm *realTime.Manager) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status/clients", func(w http.ResponseWriter, _ *http.Request) {
+		total, perProject := rtm.CountClients()
+		resp := types.CountClientsResponse{
+			Total:    total,
+			Projects: make(map[string]int, len(perProject)),
+		}
+		for projectId, n := range perProject {
+			resp.Projects[projectId.String()] = n
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	return mux
+}
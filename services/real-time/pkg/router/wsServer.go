@@ -34,6 +34,7 @@ import (
 	"time"
 
 	"github.com/das7pad/overleaf-go/pkg/jwt/projectJWT"
+	"github.com/das7pad/overleaf-go/services/real-time/pkg/types"
 )
 
 type ClaimParser[T any] func([]byte) (T, error)
@@ -178,12 +179,14 @@ func (s *WSServer) decrementN() {
 
 type wsConn struct {
 	net.Conn
-	reader      *bufio.Reader
-	reads       uint8
-	hijacked    bool
-	noKeepalive bool
-	t0          time.Time
-	s           *WSServer
+	reader            *bufio.Reader
+	reads             uint8
+	hijacked          bool
+	noKeepalive       bool
+	perMessageDeflate bool
+	batchRPC          bool
+	t0                time.Time
+	s                 *WSServer
 }
 
 func (c *wsConn) writeTimeout(p []byte, d time.Duration) (int, error) {
@@ -336,12 +339,20 @@ var (
 	headerKeyWSProtocol     = []byte("Sec-Websocket-Protocol")
 	headerValueWSProtocol   = []byte("v8.real-time.overleaf.com")
 	headerValueWSProtocolBS = []byte(".bootstrap.v8.real-time.overleaf.com")
+	headerValueWSProtocolBR = []byte(types.ProtocolBatchRPC)
 	headerKeyWSKey          = []byte("Sec-Websocket-Key")
-	responseWS              = []byte("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Protocol: v8.real-time.overleaf.com\r\nSec-WebSocket-Accept: ")
+	headerKeyWSExtensions   = []byte("Sec-Websocket-Extensions")
+	responseWS              = []byte("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Accept: ")
+	responseWSProtocol      = []byte("\r\nSec-WebSocket-Protocol: ")
+	responseWSDeflate       = []byte("\r\nSec-WebSocket-Extensions: permessage-deflate; server_no_context_takeover; client_no_context_takeover")
 	responseBodyStart       = []byte("\r\n\r\n")
 
+	extensionPerMessageDeflate = []byte("permessage-deflate")
+	separatorSemicolon         = []byte(";")
+	separatorEqual             = []byte("=")
+
 	writeBufPool = sync.Pool{New: func() any {
-		return &writeBuf{p: make([]byte, 256)}
+		return &writeBuf{p: make([]byte, 384)}
 	}}
 )
 
@@ -356,6 +367,37 @@ func equalFoldASCII(a, b []byte) bool {
 	return bytes.EqualFold(a, b)
 }
 
+// acceptPerMessageDeflate checks for a permessage-deflate offer that is
+// compatible with compression without context takeover and the default
+// window size on our end.
+func acceptPerMessageDeflate(value []byte) bool {
+	var offer []byte
+	for ok := true; ok && len(value) > 0; {
+		offer, value, ok = bytes.Cut(value, separatorComma)
+		name, params, _ := bytes.Cut(offer, separatorSemicolon)
+		if !equalFoldASCII(bytes.TrimSpace(name), extensionPerMessageDeflate) {
+			continue
+		}
+		compatible := true
+		var param []byte
+		for more := true; more && len(params) > 0; {
+			param, params, more = bytes.Cut(params, separatorSemicolon)
+			param, _, _ = bytes.Cut(param, separatorEqual)
+			switch string(bytes.ToLower(bytes.TrimSpace(param))) {
+			case "server_no_context_takeover",
+				"client_no_context_takeover",
+				"client_max_window_bits":
+			default:
+				compatible = false
+			}
+		}
+		if compatible {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *wsConn) parseWsRequest(claims *projectJWT.Claims) (error, error) {
 	checks := [6]bool{}
 	var buf *writeBuf
@@ -403,6 +445,10 @@ func (c *wsConn) parseWsRequest(claims *projectJWT.Claims) (error, error) {
 					checks[3] = true
 					continue
 				}
+				if bytes.Equal(next, headerValueWSProtocolBR) {
+					c.batchRPC = true
+					continue
+				}
 				if checks[4] {
 					continue // parse JWT once
 				}
@@ -419,9 +465,10 @@ func (c *wsConn) parseWsRequest(claims *projectJWT.Claims) (error, error) {
 			}
 			p = append(p, responseWS...)
 			p = appendSecWebSocketAccept(p, value)
-			p = append(p, responseBodyStart...)
 			buf.p = p
 			checks[5] = true
+		case c.s.h.wsCompression && !c.perMessageDeflate && equalFoldASCII(name, headerKeyWSExtensions):
+			c.perMessageDeflate = acceptPerMessageDeflate(value)
 		}
 	}
 	if buf != nil {
@@ -435,6 +482,11 @@ func (c *wsConn) parseWsRequest(claims *projectJWT.Claims) (error, error) {
 			return nil, httpStatusError(http.StatusBadRequest)
 		}
 	}
+	buf.p = appendWSProtocol(buf.p, c.batchRPC)
+	if c.perMessageDeflate {
+		buf.p = append(buf.p, responseWSDeflate...)
+	}
+	buf.p = append(buf.p, responseBodyStart...)
 	if _, err := c.Write(buf.p); err != nil {
 		return nil, err
 	}
@@ -443,19 +495,25 @@ func (c *wsConn) parseWsRequest(claims *projectJWT.Claims) (error, error) {
 	return jwtError, nil
 }
 
-func HTTPUpgrade(w http.ResponseWriter, r *http.Request, parseJWT func([]byte)) (net.Conn, *bufio.Reader, error) {
-	conn, br, err := tryHTTPUpgrade(w, r, parseJWT)
+type UpgradeDetails struct {
+	PerMessageDeflate bool
+	BatchRPC          bool
+}
+
+func HTTPUpgrade(w http.ResponseWriter, r *http.Request, parseJWT func([]byte), allowDeflate bool) (net.Conn, *bufio.Reader, UpgradeDetails, error) {
+	conn, br, d, err := tryHTTPUpgrade(w, r, parseJWT, allowDeflate)
 	if err != nil {
 		if code, ok := err.(httpStatusError); ok {
 			w.WriteHeader(int(code))
 		}
-		return nil, br, err
+		return nil, br, UpgradeDetails{}, err
 	}
-	return conn, br, nil
+	return conn, br, d, nil
 }
 
-func tryHTTPUpgrade(w http.ResponseWriter, r *http.Request, parseJWT func([]byte)) (net.Conn, *bufio.Reader, error) {
+func tryHTTPUpgrade(w http.ResponseWriter, r *http.Request, parseJWT func([]byte), allowDeflate bool) (net.Conn, *bufio.Reader, UpgradeDetails, error) {
 	h := r.Header
+	d := UpgradeDetails{}
 	ok := false
 	for _, v := range h["Connection"] {
 		var next string
@@ -468,13 +526,13 @@ func tryHTTPUpgrade(w http.ResponseWriter, r *http.Request, parseJWT func([]byte
 		}
 	}
 	if !ok {
-		return nil, nil, httpStatusError(http.StatusBadRequest)
+		return nil, nil, d, httpStatusError(http.StatusBadRequest)
 	}
 	if u := h["Upgrade"]; len(u) == 0 || !strings.EqualFold(u[0], "websocket") {
-		return nil, nil, httpStatusError(http.StatusBadRequest)
+		return nil, nil, d, httpStatusError(http.StatusBadRequest)
 	}
 	if u := h["Sec-Websocket-Version"]; len(u) == 0 || !strings.EqualFold(u[0], "13") {
-		return nil, nil, httpStatusError(http.StatusBadRequest)
+		return nil, nil, d, httpStatusError(http.StatusBadRequest)
 	}
 	ok = false
 	jwtParsed := false
@@ -487,6 +545,10 @@ func tryHTTPUpgrade(w http.ResponseWriter, r *http.Request, parseJWT func([]byte
 				ok = true
 				continue
 			}
+			if next == types.ProtocolBatchRPC {
+				d.BatchRPC = true
+				continue
+			}
 			if jwtParsed {
 				continue
 			}
@@ -497,39 +559,61 @@ func tryHTTPUpgrade(w http.ResponseWriter, r *http.Request, parseJWT func([]byte
 		}
 	}
 	if !ok || !jwtParsed {
-		return nil, nil, httpStatusError(http.StatusBadRequest)
+		return nil, nil, d, httpStatusError(http.StatusBadRequest)
 	}
 	if k := h["Sec-Websocket-Key"]; len(k) != 1 || len(k[0]) != 24 {
-		return nil, nil, httpStatusError(http.StatusBadRequest)
+		return nil, nil, d, httpStatusError(http.StatusBadRequest)
 	}
 	key := []byte(h["Sec-Websocket-Key"][0])
 	{
 		buf := [18]byte{}
 		if _, err := base64.StdEncoding.Decode(buf[0:18], key); err != nil {
-			return nil, nil, httpStatusError(http.StatusBadRequest)
+			return nil, nil, d, httpStatusError(http.StatusBadRequest)
 		}
 	}
 
 	c, brw, err := w.(http.Hijacker).Hijack()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, d, err
 	}
 
 	if brw.Reader.Buffered() > 0 {
-		return nil, nil, httpStatusError(http.StatusBadRequest)
+		return nil, nil, d, httpStatusError(http.StatusBadRequest)
+	}
+
+	if allowDeflate {
+		for _, v := range h["Sec-Websocket-Extensions"] {
+			if acceptPerMessageDeflate([]byte(v)) {
+				d.PerMessageDeflate = true
+				break
+			}
+		}
 	}
 
 	buf := brw.AvailableBuffer()
 	buf = append(buf, responseWS...)
 	buf = appendSecWebSocketAccept(buf, key)
+	buf = appendWSProtocol(buf, d.BatchRPC)
+	if d.PerMessageDeflate {
+		buf = append(buf, responseWSDeflate...)
+	}
 	buf = append(buf, responseBodyStart...)
 
 	if _, err = c.Write(buf); err != nil {
 		_ = c.Close()
-		return nil, nil, err
+		return nil, nil, d, err
 	}
 
-	return c, brw.Reader, nil
+	return c, brw.Reader, d, nil
+}
+
+// appendWSProtocol echoes the negotiated sub-protocol.
+func appendWSProtocol(buf []byte, batchRPC bool) []byte {
+	buf = append(buf, responseWSProtocol...)
+	if batchRPC {
+		return append(buf, headerValueWSProtocolBR...)
+	}
+	return append(buf, headerValueWSProtocol...)
 }
 
 var wsKeyGUID = []byte("258EAFA5-E914-47DA-95CA-C5AB0DC85B11")
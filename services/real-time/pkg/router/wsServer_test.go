@@ -0,0 +1,158 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package router
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_acceptPerMessageDeflate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"permessage-deflate", true},
+		{"permessage-deflate; client_max_window_bits", true},
+		{"Permessage-Deflate; client_no_context_takeover; server_no_context_takeover", true},
+		{"x-webkit-deflate-frame, permessage-deflate", true},
+		{"permessage-deflate; server_max_window_bits=10", false},
+		{"permessage-deflate; server_max_window_bits=10, permessage-deflate", true},
+		{"x-webkit-deflate-frame", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := acceptPerMessageDeflate([]byte(tt.value)); got != tt.want {
+				t.Errorf("acceptPerMessageDeflate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPUpgradePerMessageDeflate(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowDeflate bool
+		extensions   string
+		want         bool
+	}{
+		{"negotiated", true, "permessage-deflate; client_max_window_bits", true},
+		{"not offered", true, "", false},
+		{"disabled", false, "permessage-deflate", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			negotiated := make(chan bool, 1)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c, _, d, err := HTTPUpgrade(w, r, func([]byte) {}, tt.allowDeflate)
+				if err != nil {
+					negotiated <- false
+					return
+				}
+				negotiated <- d.PerMessageDeflate
+				_ = c.Close()
+			}))
+			defer srv.Close()
+
+			conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+			if err != nil {
+				t.Fatalf("dial: %s", err)
+			}
+			defer func() { _ = conn.Close() }()
+			req := "GET /socket.io HTTP/1.1\r\n" +
+				"Host: localhost\r\n" +
+				"Connection: Upgrade\r\n" +
+				"Upgrade: websocket\r\n" +
+				"Sec-Websocket-Version: 13\r\n" +
+				"Sec-Websocket-Protocol: v8.real-time.overleaf.com, jwt.bootstrap.v8.real-time.overleaf.com\r\n" +
+				"Sec-Websocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"
+			if tt.extensions != "" {
+				req += "Sec-Websocket-Extensions: " + tt.extensions + "\r\n"
+			}
+			if _, err = conn.Write([]byte(req + "\r\n")); err != nil {
+				t.Fatalf("write request: %s", err)
+			}
+			res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			if err != nil {
+				t.Fatalf("read response: %s", err)
+			}
+			if res.StatusCode != http.StatusSwitchingProtocols {
+				t.Fatalf("status = %d, want 101", res.StatusCode)
+			}
+			if got := <-negotiated; got != tt.want {
+				t.Errorf("HTTPUpgrade() deflate = %v, want %v", got, tt.want)
+			}
+			ext := res.Header.Get("Sec-Websocket-Extensions")
+			if got := strings.HasPrefix(ext, "permessage-deflate;"); got != tt.want {
+				t.Errorf("Sec-WebSocket-Extensions = %q, want deflate=%v", ext, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPUpgradeBatchRPC(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		want     string
+	}{
+		{"not offered", "", "v8.real-time.overleaf.com"},
+		{"offered", ", batch.v8.real-time.overleaf.com", "batch.v8.real-time.overleaf.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c, _, _, err := HTTPUpgrade(w, r, func([]byte) {}, false)
+				if err == nil {
+					_ = c.Close()
+				}
+			}))
+			defer srv.Close()
+
+			conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+			if err != nil {
+				t.Fatalf("dial: %s", err)
+			}
+			defer func() { _ = conn.Close() }()
+			req := "GET /socket.io HTTP/1.1\r\n" +
+				"Host: localhost\r\n" +
+				"Connection: Upgrade\r\n" +
+				"Upgrade: websocket\r\n" +
+				"Sec-Websocket-Version: 13\r\n" +
+				"Sec-Websocket-Protocol: v8.real-time.overleaf.com, jwt.bootstrap.v8.real-time.overleaf.com" + tt.protocol + "\r\n" +
+				"Sec-Websocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"
+			if _, err = conn.Write([]byte(req + "\r\n")); err != nil {
+				t.Fatalf("write request: %s", err)
+			}
+			res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			if err != nil {
+				t.Fatalf("read response: %s", err)
+			}
+			if res.StatusCode != http.StatusSwitchingProtocols {
+				t.Fatalf("status = %d, want 101", res.StatusCode)
+			}
+			if got := res.Header.Get("Sec-Websocket-Protocol"); got != tt.want {
+				t.Errorf("Sec-WebSocket-Protocol = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
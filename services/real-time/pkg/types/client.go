@@ -19,6 +19,7 @@ package types
 import (
 	"encoding/binary"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -73,14 +74,46 @@ func PrepareBulkMessage(response *RPCResponse) (WriteQueueEntry, error) {
 	}
 	return WriteQueueEntry{
 		Msg:        pm,
+		MsgSize:    len(blob),
 		FatalError: response.FatalError,
 	}, nil
 }
 
+// PrepareBatchMessage serializes the responses into a single JSON array.
+func PrepareBatchMessage(responses []RPCResponse) (WriteQueueEntry, error) {
+	blob := []byte{'['}
+	fatalError := false
+	for i := range responses {
+		if i > 0 {
+			blob = append(blob, ',')
+		}
+		b, err := responses[i].MarshalJSON()
+		if err != nil {
+			return WriteQueueEntry{}, err
+		}
+		blob = append(blob, b...)
+		responses[i].ReleaseBuffer()
+		fatalError = fatalError || responses[i].FatalError
+	}
+	blob = append(blob, ']')
+	pm, err := websocket.NewPreparedMessage(websocket.TextMessage, blob)
+	if err != nil {
+		return WriteQueueEntry{}, err
+	}
+	return WriteQueueEntry{
+		Msg:        pm,
+		MsgSize:    len(blob),
+		FatalError: fatalError,
+	}, nil
+}
+
 type WriteQueueEntry struct {
 	RPCResponse *RPCResponse
 	Msg         *websocket.PreparedMessage
+	MsgSize     int
 	FatalError  bool
+	// Droppable messages, like cursor updates, are safe to drop.
+	Droppable bool
 }
 
 type WriteQueue chan WriteQueueEntry
@@ -110,12 +143,16 @@ func generatePublicId() sharedTypes.PublicId {
 	return sharedTypes.PublicId(buf[:])
 }
 
-func NewClient(conn *websocket.LeanConn, writeQueueDepth int, scheduleWriteQueue chan *Client) *Client {
+func NewClient(conn *websocket.LeanConn, writeQueueDepth int, writeQueuePolicy WriteQueuePolicy, scheduleWriteQueue chan *Client, compressionThreshold int) *Client {
 	c := Client{
-		PublicId:           generatePublicId(),
-		conn:               conn,
-		writeQueue:         make([]WriteQueueEntry, writeQueueDepth),
-		scheduleWriteQueue: scheduleWriteQueue,
+		PublicId:             generatePublicId(),
+		conn:                 conn,
+		writeQueue:           make([]WriteQueueEntry, writeQueueDepth),
+		writeQueueDroppable:  make([]atomic.Uint32, writeQueueDepth),
+		writeQueuePolicy:     writeQueuePolicy,
+		scheduleWriteQueue:   scheduleWriteQueue,
+		compressionLevel:     conn.CompressionLevel,
+		compressionThreshold: compressionThreshold,
 	}
 	c.MarkAsLeftDoc()
 	return &c
@@ -153,6 +190,10 @@ const (
 
 	disconnectAfterFlush = 1
 	forceDisconnected    = 2
+
+	notDroppable     = 0
+	droppable        = 1
+	evictedDroppable = 2
 )
 
 type Client struct {
@@ -167,16 +208,46 @@ type Client struct {
 
 	docId atomic.Pointer[sharedTypes.UUID]
 
-	lsr                []LazySuccessResponse
-	conn               *websocket.LeanConn
-	writeQueue         []WriteQueueEntry
-	scheduleWriteQueue chan *Client
+	lsr                  []LazySuccessResponse
+	typingPublishedAt    time.Time
+	spotlighting         bool
+	following            atomic.Bool
+	conn                 *websocket.LeanConn
+	writeQueue           []WriteQueueEntry
+	writeQueueDroppable  []atomic.Uint32
+	writeQueuePolicy     WriteQueuePolicy
+	overflowing          atomic.Bool
+	overflowMux          sync.Mutex
+	overflow             []WriteQueueEntry
+	overflowProgressAt   time.Time
+	scheduleWriteQueue   chan *Client
+	compressionLevel     int8
+	compressionThreshold int
 }
 
 func (c *Client) String() string {
 	return string(c.PublicId)
 }
 
+// PerMessageDeflate reports whether compression got negotiated.
+func (c *Client) PerMessageDeflate() bool {
+	return c.conn.NegotiatedPerMessageDeflate
+}
+
+// prepareCompression skips the compression of small messages.
+// Writes are serialized via writeState, which makes it safe to toggle the
+// compression level on the connection.
+func (c *Client) prepareCompression(n int) {
+	if !c.conn.NegotiatedPerMessageDeflate {
+		return
+	}
+	if n < c.compressionThreshold {
+		c.conn.CompressionLevel = websocket.DisableCompression
+	} else {
+		c.conn.CompressionLevel = c.compressionLevel
+	}
+}
+
 func (c *Client) HasJoinedDoc(id sharedTypes.UUID) bool {
 	return id == *c.docId.Load()
 }
@@ -245,11 +316,21 @@ func (c *Client) CanDo(action Action, docId sharedTypes.UUID) error {
 			return err
 		}
 		return nil
-	case UpdatePosition:
+	case UpdatePosition, UpdateTyping, SetFollowing:
 		if err := c.CheckHasCapability(CanSeeOtherClients); err != nil {
 			return err
 		}
 		return nil
+	case FollowRequest:
+		if err := c.CheckHasCapability(CanEditContent); err != nil {
+			return err
+		}
+		return c.CheckHasCapability(CanSeeOtherClients)
+	case FollowCursor:
+		if !c.spotlighting {
+			return &errors.InvalidStateError{Msg: "start spotlight first"}
+		}
+		return nil
 	default:
 		return &errors.ValidationError{
 			Msg: "unknown action: " + string(action),
@@ -257,6 +338,42 @@ func (c *Client) CanDo(action Action, docId sharedTypes.UUID) error {
 	}
 }
 
+// DebounceTyping reports whether a change in typing state should get
+// published. Typing is published at most once per window, stopping only
+// after typing got published. RPCs are processed sequentially, which makes
+// it safe to track the state on the client.
+func (c *Client) DebounceTyping(typing bool, window time.Duration) bool {
+	if !typing {
+		if c.typingPublishedAt.IsZero() {
+			return false
+		}
+		c.typingPublishedAt = time.Time{}
+		return true
+	}
+	now := time.Now()
+	if now.Sub(c.typingPublishedAt) < window {
+		return false
+	}
+	c.typingPublishedAt = now
+	return true
+}
+
+// SetSpotlighting tracks whether the client broadcasts its cursor to
+// followers. RPCs are processed sequentially, which makes it safe to track
+// the state on the client.
+func (c *Client) SetSpotlighting(active bool) {
+	c.spotlighting = active
+}
+
+// SetFollowing opts the client in/out of receiving cursor broadcasts.
+func (c *Client) SetFollowing(following bool) {
+	c.following.Store(following)
+}
+
+func (c *Client) IsFollowing() bool {
+	return c.following.Load()
+}
+
 func (c *Client) ForceDisconnect() {
 	if c.initiateDisconnect(forceDisconnected) {
 		c.scheduleWriteQueue <- c
@@ -293,6 +410,9 @@ func (c *Client) ProcessQueuedMessages() {
 			_ = c.conn.Close()
 			return
 		}
+		if c.drainOverflow(hasMore) {
+			hasMore = true
+		}
 		if !hasMore {
 			return
 		}
@@ -315,7 +435,10 @@ func (c *Client) processNextQueuedMessage() (bool, bool) {
 	r = (r + 1) % n
 	entry := c.writeQueue[r]
 	c.writeQueue[r] = WriteQueueEntry{}
-	if entry.Msg != nil {
+	if c.writeQueueDroppable[r].Swap(notDroppable) == evictedDroppable {
+		// Skip the stale message and catch up with newer ones.
+	} else if entry.Msg != nil {
+		c.prepareCompression(entry.MsgSize)
 		if err := c.conn.WritePreparedMessage(entry.Msg); err != nil {
 			return false, false
 		}
@@ -330,7 +453,10 @@ func (c *Client) processNextQueuedMessage() (bool, bool) {
 		}
 	}
 	for {
-		w = w % n
+		// Keep w ahead of r for detecting a full queue while enqueuing.
+		if w = w % n; w < r {
+			w += n
+		}
 		sRolled := closing<<24 | uint32(r)<<16 | uint32(w)<<8 | uint32(pending)
 		if c.writeState.CompareAndSwap(s, sRolled) {
 			break
@@ -354,6 +480,7 @@ func (c *Client) writeResponse(response RPCResponse) bool {
 	if err != nil {
 		return false
 	}
+	c.prepareCompression(len(blob))
 	err = c.conn.WriteMessage(websocket.TextMessage, blob)
 	response.ReleaseBuffer()
 	if err != nil {
@@ -386,25 +513,161 @@ func (c *Client) EnsureQueueResponse(response RPCResponse) bool {
 }
 
 func (c *Client) EnsureQueueMessage(msg WriteQueueEntry) bool {
+	if !c.overflowing.Load() {
+		ok, full := c.tryQueueMessage(msg)
+		if !full {
+			return ok
+		}
+	}
+	switch c.writeQueuePolicy.OnOverflow {
+	case WriteQueueOverflowDropOldest, WriteQueueOverflowBlockWithTimeout:
+		return c.queueOverflow(msg)
+	default:
+		// In dropping this message, the client went out of sync, disconnect.
+		c.ForceDisconnect()
+		return false
+	}
+}
+
+// queueOverflow holds on to a message that did not fit into the write queue.
+// It never waits for the writer, which would block the fan-out to other
+// clients.
+func (c *Client) queueOverflow(msg WriteQueueEntry) bool {
+	c.overflowMux.Lock()
+	if !c.overflowing.Load() {
+		ok, full, wasEmpty := c.queueMessage(msg)
+		if !full {
+			c.overflowMux.Unlock()
+			if wasEmpty {
+				c.scheduleWriteQueue <- c
+			}
+			return ok
+		}
+		c.overflowing.Store(true)
+		c.overflowProgressAt = time.Now()
+	}
+	ok := true
+	switch {
+	case c.writeQueuePolicy.OnOverflow == WriteQueueOverflowDropOldest &&
+		!c.evictOldestDroppable() && msg.Droppable:
+		// This is the oldest droppable message.
+	case c.writeQueuePolicy.OnOverflow == WriteQueueOverflowBlockWithTimeout &&
+		msg.Droppable:
+		// Cursor/presence messages are not worth holding on to.
+	case len(c.overflow) >= len(c.writeQueue),
+		time.Since(c.overflowProgressAt) > c.writeQueuePolicy.Timeout:
+		ok = false
+	default:
+		c.overflow = append(c.overflow, msg)
+	}
+	c.overflowMux.Unlock()
+	if !ok {
+		// In dropping this message, the client went out of sync, disconnect.
+		c.ForceDisconnect()
+	}
+	return ok
+}
+
+// evictOldestDroppable marks the oldest droppable message in the write queue
+// as evicted or removes it from the overflow.
+// The caller must hold overflowMux.
+func (c *Client) evictOldestDroppable() bool {
+	s := c.writeState.Load()
+	r, w := int(uint8(s>>16)), int(uint8(s>>8))
+	n := len(c.writeQueue)
+	if w > r+n {
+		// Ignore concurrent enqueue attempts on a full queue.
+		w = r + n
+	}
+	for i := r + 1; i <= w; i++ {
+		if c.writeQueueDroppable[i%n].CompareAndSwap(
+			droppable, evictedDroppable,
+		) {
+			return true
+		}
+	}
+	for i, entry := range c.overflow {
+		if entry.Droppable {
+			k := copy(c.overflow[i:], c.overflow[i+1:])
+			c.overflow[i+k] = WriteQueueEntry{}
+			c.overflow = c.overflow[:i+k]
+			return true
+		}
+	}
+	return false
+}
+
+// drainOverflow moves messages from the overflow into the write queue.
+// It reports whether the write queue went from empty to non-empty, in which
+// case the writer needs to keep going.
+func (c *Client) drainOverflow(hasMore bool) bool {
+	switch c.writeQueuePolicy.OnOverflow {
+	case WriteQueueOverflowDropOldest, WriteQueueOverflowBlockWithTimeout:
+	default:
+		return false
+	}
+	if hasMore && !c.overflowing.Load() {
+		// Only stopping the writer needs to synchronize with queueOverflow.
+		return false
+	}
+	c.overflowMux.Lock()
+	defer c.overflowMux.Unlock()
+	if !c.overflowing.Load() {
+		return false
+	}
+	resume := false
+	i := 0
+	for ; i < len(c.overflow); i++ {
+		ok, full, wasEmpty := c.queueMessage(c.overflow[i])
+		if full {
+			break
+		}
+		if !ok {
+			// The client is disconnecting.
+			i = len(c.overflow)
+			break
+		}
+		resume = resume || wasEmpty
+	}
+	if i > 0 {
+		k := copy(c.overflow, c.overflow[i:])
+		clear(c.overflow[k:])
+		c.overflow = c.overflow[:k]
+		c.overflowProgressAt = time.Now()
+	}
+	if len(c.overflow) == 0 {
+		c.overflowing.Store(false)
+	}
+	return resume
+}
+
+func (c *Client) tryQueueMessage(msg WriteQueueEntry) (bool, bool) {
+	ok, full, wasEmpty := c.queueMessage(msg)
+	if wasEmpty {
+		c.scheduleWriteQueue <- c
+	}
+	return ok, full
+}
+
+func (c *Client) queueMessage(msg WriteQueueEntry) (bool, bool, bool) {
 	s := c.writeState.Add(tryEnqueueWrite)
-	closing, r, w, pending := s>>24, uint8(s>>16), uint8(s>>8), uint8(s)
+	closing, r, w := s>>24, uint8(s>>16), uint8(s>>8)
 	if closing > 0 {
 		// The client is in the process of disconnecting.
 		c.writeState.Add(^uint32(tryEnqueueWrite - 1))
-		return false
+		return false, false, false
 	}
-	idx := int(w) % cap(c.writeQueue)
-	if r == w-pending+1 {
-		// The queue is full, we need to drop this message.
+	n := uint8(cap(c.writeQueue))
+	idx := w % n
+	if w-r > n {
+		// The queue is full, we cannot queue this message.
 		c.writeState.Add(^uint32(tryEnqueueWrite - 1))
-		// In dropping this message, the client went out of sync, disconnect.
-		c.ForceDisconnect()
-		return false
+		return false, true, false
 	}
 	c.writeQueue[idx] = msg
-	c.writeState.Add(^uint32(pendingWrite - 1))
-	if r == w-1 {
-		c.scheduleWriteQueue <- c
+	if msg.Droppable {
+		c.writeQueueDroppable[idx].Store(droppable)
 	}
-	return true
+	c.writeState.Add(^uint32(pendingWrite - 1))
+	return true, false, r == w-1
 }
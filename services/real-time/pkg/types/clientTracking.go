@@ -28,6 +28,35 @@ type ClientPosition struct {
 	EntityId sharedTypes.UUID `json:"e"`
 }
 
+type TypingUpdate struct {
+	Typing   bool             `json:"t"`
+	EntityId sharedTypes.UUID `json:"e"`
+}
+
+type ClientTyping struct {
+	ClientId sharedTypes.PublicId `json:"i"`
+	EntityId sharedTypes.UUID     `json:"e"`
+}
+
+type FollowToggle struct {
+	Active bool `json:"f"`
+}
+
+type FollowRequestNotification struct {
+	ClientId sharedTypes.PublicId `json:"i"`
+	Active   bool                 `json:"f"`
+}
+
+type FollowCursorPosition struct {
+	ClientPosition
+	ScrollTop float64 `json:"s,omitempty"`
+}
+
+type FollowCursorNotification struct {
+	ClientId sharedTypes.PublicId `json:"i"`
+	FollowCursorPosition
+}
+
 type ConnectedClient struct {
 	ClientId    sharedTypes.PublicId `json:"i,omitempty"`
 	DisplayName string               `json:"n,omitempty"`
@@ -0,0 +1,303 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func TestClient_compressionThreshold(t *testing.T) {
+	server, client := net.Pipe()
+	defer func() { _ = server.Close() }()
+	defer func() { _ = client.Close() }()
+	conn := websocket.LeanConn{
+		Conn:                        server,
+		ReadLimit:                   -1,
+		CompressionLevel:            flate.BestSpeed,
+		IsServer:                    true,
+		NegotiatedPerMessageDeflate: true,
+	}
+	c := NewClient(&conn, 10, WriteQueuePolicy{}, make(chan *Client, 1), 128)
+	if !c.PerMessageDeflate() {
+		t.Fatalf("PerMessageDeflate() = false, want true")
+	}
+
+	small, _ := json.Marshal("small")
+	large, _ := json.Marshal(strings.Repeat("large", 100))
+	tests := []struct {
+		name string
+		body json.RawMessage
+		want bool
+	}{
+		{"small", small, false},
+		{"large", large, true},
+		{"small again", small, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan bool)
+			go func() {
+				done <- c.writeResponse(RPCResponse{Body: tt.body})
+			}()
+			header, _ := readFrame(t, client)
+			if !<-done {
+				t.Fatalf("writeResponse() = false")
+			}
+			if got := header&0x40 != 0; got != tt.want {
+				t.Errorf("compressed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func readFrame(t *testing.T, conn net.Conn) (byte, []byte) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		t.Fatalf("read frame header: %s", err)
+	}
+	n := int(header[1] & 0x7f)
+	if n == 126 {
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(conn, ext); err != nil {
+			t.Fatalf("read frame length: %s", err)
+		}
+		n = int(binary.BigEndian.Uint16(ext))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		t.Fatalf("read frame payload: %s", err)
+	}
+	return header[0], payload
+}
+
+func TestClient_queueMessage_depth(t *testing.T) {
+	for _, depth := range []int{1, 4, MaxWriteQueueDepth} {
+		t.Run(strconv.Itoa(depth), func(t *testing.T) {
+			c := NewClient(&websocket.LeanConn{}, depth, WriteQueuePolicy{}, nil, 0)
+			msg := WriteQueueEntry{RPCResponse: &RPCResponse{}}
+			for i := 0; i < depth; i++ {
+				if ok, full, _ := c.queueMessage(msg); !ok || full {
+					t.Fatalf("queueMessage() = %v, %v for message %d", ok, full, i)
+				}
+			}
+			if ok, full, _ := c.queueMessage(msg); ok || !full {
+				t.Errorf("queueMessage() on full queue = %v, %v", ok, full)
+			}
+		})
+	}
+}
+
+func TestClient_EnsureQueueMessage_overflow(t *testing.T) {
+	const depth = 4
+	prepare := func(name sharedTypes.EditorEventMessage, droppable bool) WriteQueueEntry {
+		msg, err := PrepareBulkMessage(&RPCResponse{Name: name})
+		if err != nil {
+			t.Fatalf("PrepareBulkMessage() error = %s", err)
+		}
+		msg.Droppable = droppable
+		return msg
+	}
+	stale := prepare(sharedTypes.ClientTrackingBatch, true)
+	cursor := prepare(sharedTypes.ClientTrackingUpdated, true)
+	update := prepare(sharedTypes.OtUpdateApplied, false)
+
+	setup := func(t *testing.T, p WriteQueuePolicy) (*Client, net.Conn, func()) {
+		server, client := net.Pipe()
+		t.Cleanup(func() { _ = server.Close() })
+		t.Cleanup(func() { _ = client.Close() })
+		conn := websocket.LeanConn{
+			Conn:             server,
+			ReadLimit:        -1,
+			CompressionLevel: websocket.DisableCompression,
+			IsServer:         true,
+		}
+		schedule := make(chan *Client, depth)
+		c := NewClient(&conn, depth, p, schedule, 0)
+		startWriter := func() {
+			go func() {
+				for c := range schedule {
+					c.ProcessQueuedMessages()
+				}
+			}()
+		}
+		return c, client, startWriter
+	}
+	flood := func(t *testing.T, c *Client, msg WriteQueueEntry) {
+		for i := 0; i < depth; i++ {
+			if !c.EnsureQueueMessage(msg) {
+				t.Fatalf("EnsureQueueMessage() = false for message %d", i)
+			}
+		}
+		if _, full, _ := c.queueMessage(msg); !full {
+			t.Fatalf("queueMessage() after %d messages: full = false", depth)
+		}
+	}
+	expectName := func(t *testing.T, conn net.Conn, want sharedTypes.EditorEventMessage) {
+		_, payload := readFrame(t, conn)
+		var resp struct {
+			Name sharedTypes.EditorEventMessage `json:"n"`
+		}
+		if err := json.Unmarshal(payload, &resp); err != nil {
+			t.Fatalf("decode frame: %s", err)
+		}
+		if resp.Name != want {
+			t.Errorf("name = %q, want %q", resp.Name, want)
+		}
+	}
+
+	t.Run("disconnect", func(t *testing.T) {
+		c, _, _ := setup(t, WriteQueuePolicy{})
+		flood(t, c, cursor)
+		if c.EnsureQueueMessage(cursor) {
+			t.Errorf("EnsureQueueMessage() = true, want false")
+		}
+		if c.EnsureQueueMessage(update) {
+			t.Errorf("EnsureQueueMessage() after disconnect = true")
+		}
+	})
+	t.Run("drop-oldest", func(t *testing.T) {
+		c, conn, startWriter := setup(t, WriteQueuePolicy{
+			OnOverflow: WriteQueueOverflowDropOldest,
+			Timeout:    time.Second,
+		})
+		for _, msg := range []WriteQueueEntry{stale, update, cursor, update} {
+			if !c.EnsureQueueMessage(msg) {
+				t.Fatalf("EnsureQueueMessage() = false")
+			}
+		}
+		if !c.EnsureQueueMessage(cursor) {
+			t.Fatalf("EnsureQueueMessage(cursor) = false, want true")
+		}
+		if !c.EnsureQueueMessage(update) {
+			t.Fatalf("EnsureQueueMessage(update) = false, want true")
+		}
+		startWriter()
+		// Each overflowing message evicted the oldest queued cursor.
+		expectName(t, conn, sharedTypes.OtUpdateApplied)
+		expectName(t, conn, sharedTypes.OtUpdateApplied)
+		expectName(t, conn, sharedTypes.ClientTrackingUpdated)
+		expectName(t, conn, sharedTypes.OtUpdateApplied)
+	})
+	t.Run("drop-oldest without droppable", func(t *testing.T) {
+		c, _, _ := setup(t, WriteQueuePolicy{
+			OnOverflow: WriteQueueOverflowDropOldest,
+			Timeout:    time.Second,
+		})
+		flood(t, c, update)
+		if !c.EnsureQueueMessage(cursor) {
+			t.Errorf("EnsureQueueMessage(cursor) = false, want true")
+		}
+		for i := 0; i < depth; i++ {
+			if !c.EnsureQueueMessage(update) {
+				t.Fatalf("EnsureQueueMessage(update) = false for %d", i)
+			}
+		}
+		if c.EnsureQueueMessage(update) {
+			t.Errorf("EnsureQueueMessage(update) with full overflow = true")
+		}
+	})
+	t.Run("block-with-timeout", func(t *testing.T) {
+		c, conn, startWriter := setup(t, WriteQueuePolicy{
+			OnOverflow: WriteQueueOverflowBlockWithTimeout,
+			Timeout:    time.Second,
+		})
+		flood(t, c, update)
+		if !c.EnsureQueueMessage(cursor) {
+			t.Fatalf("EnsureQueueMessage(cursor) = false, want true")
+		}
+		if !c.EnsureQueueMessage(update) {
+			t.Fatalf("EnsureQueueMessage(update) = false, want true")
+		}
+		startWriter()
+		for i := 0; i < depth+1; i++ {
+			expectName(t, conn, sharedTypes.OtUpdateApplied)
+		}
+		if !c.EnsureQueueMessage(update) {
+			t.Fatalf("EnsureQueueMessage(update) after drain = false")
+		}
+		expectName(t, conn, sharedTypes.OtUpdateApplied)
+	})
+	t.Run("block-with-timeout expired", func(t *testing.T) {
+		c, _, _ := setup(t, WriteQueuePolicy{
+			OnOverflow: WriteQueueOverflowBlockWithTimeout,
+			Timeout:    10 * time.Millisecond,
+		})
+		flood(t, c, update)
+		if !c.EnsureQueueMessage(update) {
+			t.Fatalf("EnsureQueueMessage(update) = false, want true")
+		}
+		time.Sleep(20 * time.Millisecond)
+		if c.EnsureQueueMessage(update) {
+			t.Errorf("EnsureQueueMessage(update) = true, want false")
+		}
+	})
+}
+
+func TestClient_DebounceTyping(t *testing.T) {
+	c := Client{}
+	const window = time.Hour
+	if c.DebounceTyping(false, window) {
+		t.Errorf("DebounceTyping(false) before typing = true, want false")
+	}
+	if !c.DebounceTyping(true, window) {
+		t.Errorf("DebounceTyping(true) = false, want true")
+	}
+	if c.DebounceTyping(true, window) {
+		t.Errorf("DebounceTyping(true) within window = true, want false")
+	}
+	if !c.DebounceTyping(false, window) {
+		t.Errorf("DebounceTyping(false) after typing = false, want true")
+	}
+	if !c.DebounceTyping(true, window) {
+		t.Errorf("DebounceTyping(true) after stopping = false, want true")
+	}
+}
+
+func TestClient_CanDo_follow(t *testing.T) {
+	c := Client{}
+	c.ResolveCapabilities(sharedTypes.PrivilegeLevelReadOnly, false, true)
+	if err := c.CanDo(SetFollowing, sharedTypes.UUID{}); err != nil {
+		t.Errorf("read-only SetFollowing: %s", err)
+	}
+	if err := c.CanDo(FollowRequest, sharedTypes.UUID{}); err == nil {
+		t.Errorf("read-only FollowRequest: expected error")
+	}
+
+	c.ResolveCapabilities(sharedTypes.PrivilegeLevelReadAndWrite, false, true)
+	if err := c.CanDo(FollowCursor, sharedTypes.UUID{}); err == nil {
+		t.Errorf("FollowCursor before FollowRequest: expected error")
+	}
+	if err := c.CanDo(FollowRequest, sharedTypes.UUID{}); err != nil {
+		t.Errorf("read-and-write FollowRequest: %s", err)
+	}
+	c.SetSpotlighting(true)
+	if err := c.CanDo(FollowCursor, sharedTypes.UUID{}); err != nil {
+		t.Errorf("FollowCursor after FollowRequest: %s", err)
+	}
+}
@@ -17,6 +17,7 @@
 package types
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
@@ -42,12 +43,71 @@ func (o *GracefulShutdownOptions) Validate() error {
 	return nil
 }
 
+type WriteQueueOverflow string
+
+const (
+	// WriteQueueOverflowDisconnect disconnects the client as it went out of
+	//  sync.
+	WriteQueueOverflowDisconnect = WriteQueueOverflow("disconnect")
+	// WriteQueueOverflowDropOldest evicts the oldest queued cursor/presence
+	//  message for every message that overflows.
+	WriteQueueOverflowDropOldest = WriteQueueOverflow("drop-oldest")
+	// WriteQueueOverflowBlockWithTimeout drops cursor/presence messages and
+	//  holds on to other messages until the writer makes room.
+	WriteQueueOverflowBlockWithTimeout = WriteQueueOverflow("block-with-timeout")
+)
+
+// WriteQueuePolicy determines what happens when the write queue of a client
+// is full.
+// Messages that do not fit into the queue are held in an overflow of the same
+// depth, which never blocks the fan-out to other clients. The client gets
+// disconnected once the overflow is full or the writer did not make progress
+// within the timeout.
+type WriteQueuePolicy struct {
+	OnOverflow WriteQueueOverflow `json:"on_overflow"`
+	Timeout    time.Duration      `json:"timeout"`
+}
+
+func (o *WriteQueuePolicy) Validate() error {
+	switch o.OnOverflow {
+	case "", WriteQueueOverflowDisconnect:
+		return nil
+	case WriteQueueOverflowDropOldest, WriteQueueOverflowBlockWithTimeout:
+		if o.Timeout <= 0 {
+			return &errors.ValidationError{
+				Msg: "timeout must be greater than 0",
+			}
+		}
+		return nil
+	default:
+		return &errors.ValidationError{
+			Msg: "unknown on_overflow: " + string(o.OnOverflow),
+		}
+	}
+}
+
+// MaxWriteQueueDepth keeps the read and write index of the write queue
+// within their 8 bits of the write state, with room for concurrent enqueue
+// attempts that push the write index beyond the read index plus the depth.
+const MaxWriteQueueDepth = 64
+
 type Options struct {
 	GracefulShutdown GracefulShutdownOptions `json:"graceful_shutdown"`
 
-	WriteQueueDepth int `json:"write_queue_depth"`
-	BootstrapWorker int `json:"bootstrap_worker"`
-	WriteWorker     int `json:"write_worker"`
+	WriteQueueDepth  int              `json:"write_queue_depth"`
+	WriteQueuePolicy WriteQueuePolicy `json:"write_queue_policy"`
+	BootstrapWorker  int              `json:"bootstrap_worker"`
+	WriteWorker      int              `json:"write_worker"`
+
+	// WSCompression negotiates permessage-deflate with clients that offer
+	//  it. Messages smaller than WSCompressionThreshold bytes are sent
+	//  uncompressed.
+	WSCompression          bool `json:"ws_compression"`
+	WSCompressionThreshold int  `json:"ws_compression_threshold"`
+
+	// TypingDebounce limits typing notifications to one per window and
+	//  client.
+	TypingDebounce time.Duration `json:"typing_debounce"`
 
 	JWT struct {
 		Project jwtOptions.JWTOptions `json:"project"`
@@ -57,6 +117,9 @@ type Options struct {
 func (o *Options) FillFromEnv() {
 	env.MustParseJSON(o, "REAL_TIME_OPTIONS")
 	o.JWT.Project.FillFromEnv("JWT_WEB_VERIFY_SECRET")
+	if env.GetBool("WS_COMPRESSION") {
+		o.WSCompression = true
+	}
 }
 
 func (o *Options) Validate() error {
@@ -66,5 +129,19 @@ func (o *Options) Validate() error {
 	if o.WriteQueueDepth <= 0 {
 		return errors.New("write_queue_depth must be greater than 0")
 	}
+	if o.WriteQueueDepth > MaxWriteQueueDepth {
+		return errors.New(fmt.Sprintf(
+			"write_queue_depth must not exceed %d", MaxWriteQueueDepth,
+		))
+	}
+	if err := o.WriteQueuePolicy.Validate(); err != nil {
+		return errors.Tag(err, "write_queue_policy")
+	}
+	if o.TypingDebounce < 0 {
+		return errors.New("typing_debounce must not be negative")
+	}
+	if o.WSCompressionThreshold < 0 {
+		return errors.New("ws_compression_threshold must not be negative")
+	}
 	return nil
 }
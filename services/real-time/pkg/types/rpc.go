@@ -32,10 +32,21 @@ const (
 	JoinDoc           = Action("joinDoc")
 	GetConnectedUsers = Action("clientTracking.getConnectedUsers")
 	UpdatePosition    = Action("clientTracking.updatePosition")
+	UpdateTyping      = Action("clientTracking.updateTyping")
+	FollowRequest     = Action("follow.request")
+	FollowCursor      = Action("follow.cursor")
+	SetFollowing      = Action("follow.setFollowing")
 	ApplyUpdate       = Action("applyUpdate")
 	Ping              = Action("ping")
 )
 
+// ProtocolBatchRPC is offered as additional websocket sub-protocol by
+// clients that send batches of RPCs as JSON array in a single frame.
+// Responses for a batch are sent as JSON array in a single frame as well.
+const ProtocolBatchRPC = "batch.v8.real-time.overleaf.com"
+
+const MaxRPCBatchSize = 100
+
 type Callback int64
 
 type LazySuccessResponse struct {
@@ -50,6 +61,18 @@ type RPCRequest struct {
 	DocId    sharedTypes.UUID `json:"d"`
 }
 
+type RPCBatchRequest []RPCRequest
+
+func (r RPCBatchRequest) Validate() error {
+	if len(r) == 0 {
+		return &errors.ValidationError{Msg: "empty batch"}
+	}
+	if len(r) > MaxRPCBatchSize {
+		return &errors.ValidationError{Msg: "batch is too large"}
+	}
+	return nil
+}
+
 type RPCResponse struct {
 	/* "h" is a virtual field indicating the length of Body */
 	Body                 json.RawMessage                `json:"b,omitempty"`
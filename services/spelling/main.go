@@ -52,6 +52,10 @@ func main() {
 		Handler: router.New(sm, corsOptions.Parse()),
 	}
 	httpUtils.ListenAndServeEach(eg.Go, &server, listenAddress.Parse(3005))
+	eg.Go(func() error {
+		sm.WatchDictionaries(ctx)
+		return nil
+	})
 	eg.Go(func() error {
 		<-ctx.Done()
 		waitForSlowRequests, done := context.WithTimeout(
@@ -0,0 +1,46 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package spelling
+
+import (
+	"context"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/spelling/pkg/types"
+)
+
+func (m *manager) CheckDocs(ctx context.Context, language types.SpellCheckLanguage, docs types.BatchCheckDocs) ([]types.BatchCheckResult, error) {
+	if err := language.Validate(); err != nil {
+		return nil, err
+	}
+	if err := docs.Validate(); err != nil {
+		return nil, err
+	}
+	results := make([]types.BatchCheckResult, len(docs))
+	for i, doc := range docs {
+		misspellings, err := m.CheckWords(ctx, language, doc.Words)
+		if err != nil {
+			return nil, errors.Tag(err, "doc "+doc.DocId.String())
+		}
+		results[i] = types.BatchCheckResult{
+			DocId:        doc.DocId,
+			Flagged:      m.FlagTerms(doc.Words),
+			Misspellings: misspellings,
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,102 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package spelling
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/spelling/pkg/types"
+)
+
+type dictAspell map[string]bool
+
+func (a dictAspell) CheckWords(_ context.Context, _ types.SpellCheckLanguage, words []string) ([]types.Misspelling, error) {
+	misspellings := make([]types.Misspelling, 0)
+	for i, word := range words {
+		if !a[word] {
+			misspellings = append(misspellings, types.Misspelling{
+				Index:       i,
+				Suggestions: []string{"hello"},
+			})
+		}
+	}
+	return misspellings, nil
+}
+
+func (a dictAspell) Reload() error {
+	return nil
+}
+
+func TestManager_CheckDocs(t *testing.T) {
+	m := &manager{
+		aspellManager: dictAspell{"hello": true, "world": true},
+		results:       newResultCache(types.ResultCacheOptions{}),
+	}
+	ctx := context.Background()
+	a := sharedTypes.UUID{1}
+	b := sharedTypes.UUID{2}
+
+	got, err := m.CheckDocs(ctx, "en", types.BatchCheckDocs{
+		{DocId: a, Words: []string{"helo", "world"}},
+		{DocId: b, Words: []string{"hello", "world", "wrold"}},
+	})
+	if err != nil {
+		t.Fatalf("CheckDocs(): %s", err)
+	}
+	want := []types.BatchCheckResult{
+		{
+			DocId:   a,
+			Flagged: []types.FlaggedTerm{},
+			Misspellings: []types.Misspelling{
+				{Index: 0, Suggestions: []string{"hello"}},
+			},
+		},
+		{
+			DocId:   b,
+			Flagged: []types.FlaggedTerm{},
+			Misspellings: []types.Misspelling{
+				{Index: 2, Suggestions: []string{"hello"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckDocs() = %v, want %v", got, want)
+	}
+
+	t.Run("too many words", func(t *testing.T) {
+		_, err = m.CheckDocs(ctx, "en", types.BatchCheckDocs{
+			{DocId: a, Words: make([]string, types.MaxBatchWords)},
+			{DocId: b, Words: []string{"hello"}},
+		})
+		if !errors.IsValidationError(err) {
+			t.Errorf("expected validation error, got %v", err)
+		}
+	})
+	t.Run("duplicate doc", func(t *testing.T) {
+		_, err = m.CheckDocs(ctx, "en", types.BatchCheckDocs{
+			{DocId: a, Words: []string{"hello"}},
+			{DocId: a, Words: []string{"world"}},
+		})
+		if !errors.IsValidationError(err) {
+			t.Errorf("expected validation error, got %v", err)
+		}
+	})
+}
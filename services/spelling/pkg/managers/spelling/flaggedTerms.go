@@ -0,0 +1,45 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package spelling
+
+import (
+	"strings"
+
+	"github.com/das7pad/overleaf-go/services/spelling/pkg/types"
+)
+
+func newFlaggedTerms(terms []string) map[string]string {
+	m := make(map[string]string, len(terms))
+	for _, term := range terms {
+		m[strings.ToLower(term)] = term
+	}
+	return m
+}
+
+// FlagTerms reports the occurrences of the configured terms in words.
+func (m *manager) FlagTerms(words []string) []types.FlaggedTerm {
+	flagged := make([]types.FlaggedTerm, 0)
+	if len(m.flaggedTerms) == 0 {
+		return flagged
+	}
+	for i, word := range words {
+		if term, ok := m.flaggedTerms[strings.ToLower(word)]; ok {
+			flagged = append(flagged, types.FlaggedTerm{Index: i, Term: term})
+		}
+	}
+	return flagged
+}
@@ -0,0 +1,44 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package spelling
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/services/spelling/pkg/types"
+)
+
+func TestManager_FlagTerms(t *testing.T) {
+	m := &manager{flaggedTerms: newFlaggedTerms([]string{"Foo", "bar"})}
+	got := m.FlagTerms([]string{"hello", "foo", "world", "BAR", "baz", "FOO"})
+	want := []types.FlaggedTerm{
+		{Index: 1, Term: "Foo"},
+		{Index: 3, Term: "bar"},
+		{Index: 5, Term: "Foo"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FlagTerms() = %v, want %v", got, want)
+	}
+
+	t.Run("no terms configured", func(t *testing.T) {
+		m2 := &manager{flaggedTerms: newFlaggedTerms(nil)}
+		if got2 := m2.FlagTerms([]string{"foo"}); len(got2) != 0 {
+			t.Errorf("FlagTerms() = %v, want none", got2)
+		}
+	})
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,6 +18,7 @@ package aspell
 
 import (
 	"context"
+	"sync"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 
@@ -28,9 +29,22 @@ import (
 
 type Manager interface {
 	CheckWords(ctx context.Context, language types.SpellCheckLanguage, words []string) ([]types.Misspelling, error)
+	Reload() error
 }
 
 func New(lruSize int) (Manager, error) {
+	caches, err := newCaches(lruSize)
+	if err != nil {
+		return nil, err
+	}
+	return &manager{
+		caches:  caches,
+		lruSize: lruSize,
+		wp:      aspellRunner.NewWorkerPool(),
+	}, nil
+}
+
+func newCaches(lruSize int) (map[types.SpellCheckLanguage]*lru.Cache[string, []string], error) {
 	caches := make(
 		map[types.SpellCheckLanguage]*lru.Cache[string, []string],
 		len(types.AllowedLanguages),
@@ -42,11 +56,7 @@ func New(lruSize int) (Manager, error) {
 		}
 		caches[language] = cache
 	}
-
-	return &manager{
-		caches: caches,
-		wp:     aspellRunner.NewWorkerPool(),
-	}, nil
+	return caches, nil
 }
 
 const (
@@ -54,8 +64,29 @@ const (
 )
 
 type manager struct {
-	caches map[types.SpellCheckLanguage]*lru.Cache[string, []string]
-	wp     aspellRunner.WorkerPool
+	// l guards the swapping of caches and wp in Reload.
+	l       sync.RWMutex
+	caches  map[types.SpellCheckLanguage]*lru.Cache[string, []string]
+	lruSize int
+	wp      aspellRunner.WorkerPool
+}
+
+// Reload discards all the cached suggestions and aspell processes. New
+// aspell processes load the dictionaries from disk again. Pending requests
+// finish on the old state.
+func (m *manager) Reload() error {
+	caches, err := newCaches(m.lruSize)
+	if err != nil {
+		return err
+	}
+	wp := aspellRunner.NewWorkerPool()
+	m.l.Lock()
+	old := m.wp
+	m.caches = caches
+	m.wp = wp
+	m.l.Unlock()
+	old.Close()
+	return nil
 }
 
 func (m *manager) CheckWords(ctx context.Context, language types.SpellCheckLanguage, words []string) ([]types.Misspelling, error) {
@@ -65,6 +96,8 @@ func (m *manager) CheckWords(ctx context.Context, language types.SpellCheckLangu
 	if len(words) > RequestLimit {
 		words = words[:RequestLimit]
 	}
+	m.l.RLock()
+	defer m.l.RUnlock()
 	cache := m.caches[language]
 
 	suggestions := make(aspellRunner.Suggestions, len(words))
@@ -0,0 +1,90 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package aspell
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeAspell implements the subset of the aspell pipe protocol that the
+// worker uses. Words that are not listed in $DICT are misspelled.
+const fakeAspell = `#!/bin/sh
+lang="$5"
+echo "@(#) International Ispell Version 3.1.20 (but really Fake Aspell)"
+while IFS= read -r line; do
+  case "$line" in
+    '!') ;;
+    '$$l') echo "$lang" ;;
+    '^'*)
+      for w in ${line#^}; do
+        grep -qx "$w" "$DICT" || echo "& $w 1 0: $w-fixed"
+      done
+      echo
+      ;;
+  esac
+done
+`
+
+func TestManager_Reload(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "aspell"), []byte(fakeAspell), 0o755)
+	if err != nil {
+		t.Fatalf("write fake aspell: %s", err)
+	}
+	dict := filepath.Join(dir, "dict")
+	if err = os.WriteFile(dict, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("write dict: %s", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DICT", dict)
+
+	m, err := New(10)
+	if err != nil {
+		t.Fatalf("New(): %s", err)
+	}
+	defer func() { m.(*manager).wp.Close() }()
+
+	ctx := context.Background()
+	check := func(want int) {
+		t.Helper()
+		misspellings, err2 := m.CheckWords(
+			ctx, "en", []string{"hello", "overleaf"},
+		)
+		if err2 != nil {
+			t.Fatalf("CheckWords(): %s", err2)
+		}
+		if len(misspellings) != want {
+			t.Fatalf("got %d misspellings, want %d", len(misspellings), want)
+		}
+	}
+	check(1)
+
+	err = os.WriteFile(dict, []byte("hello\noverleaf\n"), 0o644)
+	if err != nil {
+		t.Fatalf("update dict: %s", err)
+	}
+	// Served from cache.
+	check(1)
+
+	if err = m.Reload(); err != nil {
+		t.Fatalf("Reload(): %s", err)
+	}
+	check(0)
+}
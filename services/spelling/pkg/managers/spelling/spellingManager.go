@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,12 +17,25 @@
 package spelling
 
 import (
+	"context"
+	"crypto/sha256"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
 	"github.com/das7pad/overleaf-go/services/spelling/pkg/managers/spelling/internal/aspell"
 	"github.com/das7pad/overleaf-go/services/spelling/pkg/types"
 )
 
 type Manager interface {
 	aspellManager
+	CheckDocs(ctx context.Context, language types.SpellCheckLanguage, docs types.BatchCheckDocs) ([]types.BatchCheckResult, error)
+	FlagTerms(words []string) []types.FlaggedTerm
+	SupportedLanguages() []types.Language
+	WatchDictionaries(ctx context.Context)
 }
 
 func New(options *types.Options) (Manager, error) {
@@ -35,11 +48,133 @@ func New(options *types.Options) (Manager, error) {
 	}
 	return &manager{
 		aspellManager: a,
+		flaggedTerms:  newFlaggedTerms(options.FlaggedTerms),
+		results:       newResultCache(options.ResultCache),
+		watch:         options.DictionaryWatch,
 	}, nil
 }
 
 type aspellManager aspell.Manager
 
+type resultCacheKey struct {
+	language types.SpellCheckLanguage
+	hash     [sha256.Size]byte
+}
+
+type resultCache = expirable.LRU[resultCacheKey, []types.Misspelling]
+
+func newResultCache(o types.ResultCacheOptions) *resultCache {
+	if o.Size == 0 {
+		o.Size = 1000
+	}
+	if o.TTL == 0 {
+		o.TTL = 30 * time.Second
+	}
+	return expirable.NewLRU[resultCacheKey, []types.Misspelling](
+		o.Size, nil, o.TTL,
+	)
+}
+
 type manager struct {
 	aspellManager
+	flaggedTerms map[string]string
+	results      *resultCache
+	watch        types.DictionaryWatchOptions
+}
+
+// CheckWords serves repeated checks of identical text from a short-lived
+// cache.
+func (m *manager) CheckWords(ctx context.Context, language types.SpellCheckLanguage, words []string) ([]types.Misspelling, error) {
+	h := sha256.New()
+	for _, word := range words {
+		h.Write([]byte(word))
+		h.Write([]byte{0})
+	}
+	k := resultCacheKey{language: language}
+	h.Sum(k.hash[:0])
+	if misspellings, ok := m.results.Get(k); ok {
+		return misspellings, nil
+	}
+	misspellings, err := m.aspellManager.CheckWords(ctx, language, words)
+	if err != nil {
+		return nil, err
+	}
+	m.results.Add(k, misspellings)
+	return misspellings, nil
+}
+
+func (m *manager) Reload() error {
+	if err := m.aspellManager.Reload(); err != nil {
+		return err
+	}
+	m.results.Purge()
+	return nil
+}
+
+func (m *manager) SupportedLanguages() []types.Language {
+	languages := make([]types.Language, len(types.AllowedLanguages))
+	for i, l := range types.AllowedLanguages {
+		languages[i] = types.Language{Code: l, Name: l.DisplayName()}
+	}
+	return languages
+}
+
+// WatchDictionaries polls the configured paths and reloads the dictionaries
+// after changes. It returns once ctx is cancelled.
+func (m *manager) WatchDictionaries(ctx context.Context) {
+	if len(m.watch.Paths) == 0 {
+		return
+	}
+	interval := m.watch.Interval
+	if interval == 0 {
+		interval = time.Minute
+	}
+	last := fingerprintDictionaries(m.watch.Paths)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		next := fingerprintDictionaries(m.watch.Paths)
+		if next == last {
+			continue
+		}
+		if err := m.Reload(); err != nil {
+			log.Printf("reload dictionaries failed: %s", err)
+			continue
+		}
+		last = next
+		log.Println("reloaded dictionaries")
+	}
+}
+
+type dictionaryFingerprint struct {
+	n       int
+	size    int64
+	modTime time.Time
+}
+
+func fingerprintDictionaries(paths []string) dictionaryFingerprint {
+	f := dictionaryFingerprint{}
+	for _, p := range paths {
+		_ = filepath.WalkDir(p, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			f.n++
+			f.size += info.Size()
+			if t := info.ModTime(); t.After(f.modTime) {
+				f.modTime = t
+			}
+			return nil
+		})
+	}
+	return f
 }
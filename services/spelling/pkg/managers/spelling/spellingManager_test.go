@@ -0,0 +1,75 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package spelling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/services/spelling/pkg/types"
+)
+
+type countingAspell struct {
+	calls int
+}
+
+func (a *countingAspell) CheckWords(_ context.Context, _ types.SpellCheckLanguage, words []string) ([]types.Misspelling, error) {
+	a.calls++
+	return []types.Misspelling{{Index: len(words) - 1}}, nil
+}
+
+func (a *countingAspell) Reload() error {
+	return nil
+}
+
+func TestManager_CheckWords_ResultCache(t *testing.T) {
+	a := &countingAspell{}
+	m := &manager{
+		aspellManager: a,
+		results:       newResultCache(types.ResultCacheOptions{}),
+	}
+	ctx := context.Background()
+	check := func(language types.SpellCheckLanguage, words []string, wantCalls int) {
+		t.Helper()
+		if _, err := m.CheckWords(ctx, language, words); err != nil {
+			t.Fatalf("CheckWords(): %s", err)
+		}
+		if a.calls != wantCalls {
+			t.Fatalf("aspell calls = %d, want %d", a.calls, wantCalls)
+		}
+	}
+
+	check("en", []string{"foo", "bar"}, 1)
+	t.Run("hit on identical input", func(t *testing.T) {
+		check("en", []string{"foo", "bar"}, 1)
+	})
+	t.Run("miss on changed words", func(t *testing.T) {
+		check("en", []string{"foo", "baz"}, 2)
+	})
+	t.Run("miss on changed word boundaries", func(t *testing.T) {
+		check("en", []string{"foob", "ar"}, 3)
+	})
+	t.Run("miss on changed language", func(t *testing.T) {
+		check("de", []string{"foo", "bar"}, 4)
+	})
+	t.Run("miss after reload", func(t *testing.T) {
+		if err := m.Reload(); err != nil {
+			t.Fatalf("Reload(): %s", err)
+		}
+		check("en", []string{"foo", "bar"}, 5)
+	})
+}
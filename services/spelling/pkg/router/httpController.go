@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -42,6 +42,8 @@ func (h *httpController) addRoutes(router *httpUtils.Router, corsOptions httpUti
 	r := router.Group("")
 	r.Use(httpUtils.CORS(corsOptions))
 	r.POST("/spelling/api/check", h.check)
+	r.POST("/spelling/api/check/batch", h.checkBatch)
+	r.GET("/spelling/api/languages", h.getLanguages)
 }
 
 type checkRequestBody struct {
@@ -50,6 +52,7 @@ type checkRequestBody struct {
 }
 
 type checkResponseBody struct {
+	Flagged      []types.FlaggedTerm `json:"flagged"`
 	Misspellings []types.Misspelling `json:"misspellings"`
 }
 
@@ -63,6 +66,37 @@ func (h *httpController) check(c *httpUtils.Context) {
 		request.Language,
 		request.Words,
 	)
-	response := checkResponseBody{Misspellings: misspellings}
+	response := checkResponseBody{
+		Flagged:      h.sm.FlagTerms(request.Words),
+		Misspellings: misspellings,
+	}
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
+type checkBatchRequestBody struct {
+	Language types.SpellCheckLanguage `json:"language"`
+	Docs     types.BatchCheckDocs     `json:"docs"`
+}
+
+type checkBatchResponseBody struct {
+	Docs []types.BatchCheckResult `json:"docs"`
+}
+
+func (h *httpController) checkBatch(c *httpUtils.Context) {
+	request := &checkBatchRequestBody{}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	docs, err := h.sm.CheckDocs(c, request.Language, request.Docs)
+	response := checkBatchResponseBody{Docs: docs}
 	httpUtils.Respond(c, http.StatusOK, response, err)
 }
+
+type getLanguagesResponseBody struct {
+	Languages []types.Language `json:"languages"`
+}
+
+func (h *httpController) getLanguages(c *httpUtils.Context) {
+	response := getLanguagesResponseBody{Languages: h.sm.SupportedLanguages()}
+	httpUtils.Respond(c, http.StatusOK, response, nil)
+}
@@ -0,0 +1,65 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/httpUtils"
+	"github.com/das7pad/overleaf-go/services/spelling/pkg/managers/spelling"
+	"github.com/das7pad/overleaf-go/services/spelling/pkg/types"
+)
+
+func TestGetLanguages(t *testing.T) {
+	sm, err := spelling.New(&types.Options{LRUSize: 1})
+	if err != nil {
+		t.Fatalf("spelling.New(): %s", err)
+	}
+	srv := httptest.NewServer(New(sm, httpUtils.CORSOptions{}))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/spelling/api/languages")
+	if err != nil {
+		t.Fatalf("GET languages: %s", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	var body getLanguagesResponseBody
+	if err = json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %s", err)
+	}
+	names := make(map[types.SpellCheckLanguage]string, len(body.Languages))
+	for _, l := range body.Languages {
+		names[l.Code] = l.Name
+	}
+	for _, l := range types.AllowedLanguages {
+		if names[l] == "" {
+			t.Errorf("missing language %q", l)
+		}
+	}
+	if got := names["en"]; got != "English" {
+		t.Errorf("name of en = %q, want English", got)
+	}
+	if got := names["pt_BR"]; got != "Portuguese (Brazilian)" {
+		t.Errorf("name of pt_BR = %q, want Portuguese (Brazilian)", got)
+	}
+}
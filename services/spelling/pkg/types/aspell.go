@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,6 +17,8 @@
 package types
 
 import (
+	"strings"
+
 	"github.com/das7pad/overleaf-go/pkg/errors"
 )
 
@@ -25,6 +27,12 @@ type Misspelling struct {
 	Suggestions []string `json:"suggestions"`
 }
 
+// FlaggedTerm is an occurrence of a configured term at words[Index].
+type FlaggedTerm struct {
+	Index int    `json:"index"`
+	Term  string `json:"term"`
+}
+
 var AllowedLanguages = []SpellCheckLanguage{
 	"en",
 	"bg",
@@ -37,11 +45,54 @@ var AllowedLanguages = []SpellCheckLanguage{
 
 type SpellCheckLanguage string
 
+var languageNames = map[SpellCheckLanguage]string{
+	"en":    "English",
+	"bg":    "Bulgarian",
+	"de":    "German",
+	"es":    "Spanish",
+	"fr":    "French",
+	"pt_BR": "Portuguese (Brazilian)",
+	"pt_PT": "Portuguese (European)",
+}
+
+// DisplayName returns the english name of the language, falling back to the
+// language code.
+func (l SpellCheckLanguage) DisplayName() string {
+	if name, ok := languageNames[l]; ok {
+		return name
+	}
+	return string(l)
+}
+
+type Language struct {
+	Code SpellCheckLanguage `json:"code"`
+	Name string             `json:"name"`
+}
+
+// InheritLanguage resolves to the spell check language of the project owner.
+const InheritLanguage SpellCheckLanguage = "inherit"
+
 func (l SpellCheckLanguage) Validate() error {
 	for _, language := range AllowedLanguages {
 		if l == language {
 			return nil
 		}
 	}
-	return &errors.ValidationError{Msg: "non supported language specified"}
+	supported := make([]string, len(AllowedLanguages))
+	for i, language := range AllowedLanguages {
+		supported[i] = string(language)
+	}
+	return &errors.ValidationError{
+		Msg: "non supported language specified: " + string(l) +
+			", expected one of " + strings.Join(supported, ", "),
+	}
+}
+
+// ValidateForProject accepts any supported language, "inherit" and the
+// empty string for disabling spell checking.
+func (l SpellCheckLanguage) ValidateForProject() error {
+	if l == "" || l == InheritLanguage {
+		return nil
+	}
+	return l.Validate()
 }
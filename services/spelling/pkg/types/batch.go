@@ -0,0 +1,70 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"fmt"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+const (
+	MaxBatchDocs  = 100
+	MaxBatchWords = 10_000
+)
+
+type BatchCheckDoc struct {
+	DocId sharedTypes.UUID `json:"docId"`
+	Words []string         `json:"words"`
+}
+
+type BatchCheckDocs []BatchCheckDoc
+
+func (d BatchCheckDocs) Validate() error {
+	if len(d) == 0 {
+		return &errors.ValidationError{Msg: "missing docs"}
+	}
+	if len(d) > MaxBatchDocs {
+		return &errors.ValidationError{
+			Msg: fmt.Sprintf("too many docs, max %d", MaxBatchDocs),
+		}
+	}
+	n := 0
+	seen := make(map[sharedTypes.UUID]bool, len(d))
+	for _, doc := range d {
+		if seen[doc.DocId] {
+			return &errors.ValidationError{
+				Msg: "duplicate doc: " + doc.DocId.String(),
+			}
+		}
+		seen[doc.DocId] = true
+		n += len(doc.Words)
+	}
+	if n > MaxBatchWords {
+		return &errors.ValidationError{
+			Msg: fmt.Sprintf("too many words, max %d", MaxBatchWords),
+		}
+	}
+	return nil
+}
+
+type BatchCheckResult struct {
+	DocId        sharedTypes.UUID `json:"docId"`
+	Flagged      []FlaggedTerm    `json:"flagged"`
+	Misspellings []Misspelling    `json:"misspellings"`
+}
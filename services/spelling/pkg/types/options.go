@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,12 +17,65 @@
 package types
 
 import (
+	"strings"
+	"time"
+	"unicode"
+
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/options/env"
 )
 
 type Options struct {
 	LRUSize int `json:"lru_size"`
+
+	// DictionaryWatch reloads the dictionaries after changes on disk.
+	DictionaryWatch DictionaryWatchOptions `json:"dictionary_watch"`
+
+	// ResultCache holds the results of checking identical text again.
+	ResultCache ResultCacheOptions `json:"result_cache"`
+
+	// FlaggedTerms lists single words that get reported separately from
+	// misspellings, e.g. disallowed terms. Matching ignores case.
+	FlaggedTerms []string `json:"flagged_terms"`
+}
+
+// ResultCacheOptions configures the cache for full check results, keyed by
+// language and words. Size defaults to 1000 and TTL to 30s.
+type ResultCacheOptions struct {
+	Size int           `json:"size"`
+	TTL  time.Duration `json:"ttl"`
+}
+
+func (o *ResultCacheOptions) Validate() error {
+	if o.Size < 0 {
+		return &errors.ValidationError{Msg: "size must not be negative"}
+	}
+	if o.TTL < 0 {
+		return &errors.ValidationError{Msg: "ttl must not be negative"}
+	}
+	return nil
+}
+
+// DictionaryWatchOptions configures polling for changes of the aspell
+// dictionaries, e.g. in /usr/lib/aspell. Polling is disabled when Paths is
+// empty. Interval defaults to one minute.
+type DictionaryWatchOptions struct {
+	Paths    []string      `json:"paths"`
+	Interval time.Duration `json:"interval"`
+}
+
+func (o *DictionaryWatchOptions) Validate() error {
+	if o.Interval < 0 {
+		return &errors.ValidationError{
+			Msg: "interval must not be negative",
+		}
+	}
+	for _, p := range o.Paths {
+		if p == "" {
+			return &errors.ValidationError{Msg: "paths must not be empty"}
+		}
+	}
+	return nil
 }
 
 func (o *Options) FillFromEnv() {
@@ -35,5 +88,18 @@ func (o *Options) Validate() error {
 			Msg: "lru_size must be greater than 0",
 		}
 	}
+	if err := o.DictionaryWatch.Validate(); err != nil {
+		return errors.Tag(err, "dictionary_watch")
+	}
+	if err := o.ResultCache.Validate(); err != nil {
+		return errors.Tag(err, "result_cache")
+	}
+	for _, term := range o.FlaggedTerms {
+		if term == "" || strings.IndexFunc(term, unicode.IsSpace) != -1 {
+			return &errors.ValidationError{
+				Msg: "flagged_terms must be single words",
+			}
+		}
+	}
 	return nil
 }
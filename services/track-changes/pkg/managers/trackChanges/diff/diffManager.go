@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2022-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2022-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -33,6 +33,8 @@ import (
 type Manager interface {
 	GetDocDiff(ctx context.Context, request *types.GetDocDiffRequest, response *types.GetDocDiffResponse) error
 	RestoreDocVersion(ctx context.Context, request *types.RestoreDocVersionRequest) error
+	RestoreProjectToVersion(ctx context.Context, request *types.RestoreProjectToVersionRequest) error
+	VerifyDocHistory(ctx context.Context, request *types.VerifyDocHistoryRequest, response *types.VerifyDocHistoryResponse) error
 }
 
 func New(dhm docHistory.Manager, fm flush.Manager, dum documentUpdater.Manager) Manager {
@@ -49,11 +51,36 @@ type manager struct {
 	fm  flush.Manager
 }
 
+// invertOp fills rev with the inverse of op, re-using its capacity.
+func invertOp(rev, op sharedTypes.Op) sharedTypes.Op {
+	n := len(op)
+	if n > cap(rev) {
+		rev = make(sharedTypes.Op, n)
+	} else {
+		rev = rev[:n]
+	}
+	for j := 0; j < n; j++ {
+		k := n - 1 - j
+		rev[k].Position = op[j].Position
+		rev[k].Deletion, rev[k].Insertion = op[j].Insertion, op[j].Deletion
+	}
+	return rev
+}
+
 func (m *manager) getDocFrom(ctx context.Context, projectId, userId, docId sharedTypes.UUID, from, to sharedTypes.Version) (sharedTypes.Snapshot, *docHistory.GetForDocResult, error) {
 	d, err := m.dum.GetDoc(ctx, projectId, docId, -1)
 	if err != nil {
 		return nil, nil, errors.Tag(err, "get latest doc version")
 	}
+	if from > d.Version {
+		return nil, nil, &errors.ValidationError{Msg: "from is out of range"}
+	}
+	if to == 0 {
+		// Diff up to the latest version.
+		to = d.Version
+	} else if to > d.Version {
+		return nil, nil, &errors.ValidationError{Msg: "to is out of range"}
+	}
 	// NOTE: The flush could get replaced with a more complex catch-up process:
 	//       Assume that only the editor UI requests diffs, and it flushes
 	//        ahead of displaying a list of docs+version-ranges that were in
@@ -64,6 +91,15 @@ func (m *manager) getDocFrom(ctx context.Context, projectId, userId, docId share
 	if err = m.fm.FlushDoc(ctx, projectId, docId); err != nil {
 		return nil, nil, errors.Tag(err, "flush doc history")
 	}
+	pruned, err := m.dhm.GetPrunedVersion(ctx, projectId, docId)
+	if err != nil {
+		return nil, nil, errors.Tag(err, "get pruned history version")
+	}
+	if pruned > 0 && from <= pruned {
+		return nil, nil, &errors.ValidationError{
+			Msg: "from is out of range, history is not available",
+		}
+	}
 	dh := docHistory.GetForDocResult{
 		History: make([]docHistory.DocHistory, 0, 1+d.Version-from),
 		Users:   make(user.BulkFetched, 10),
@@ -72,24 +108,20 @@ func (m *manager) getDocFrom(ctx context.Context, projectId, userId, docId share
 	if err != nil {
 		return nil, nil, errors.Tag(err, "get flushed history")
 	}
+	if from < d.Version && len(dh.History) == 0 {
+		// NOTE: Merged history entries may start after from, which is fine.
+		//       Any later entry covers from, as the history is contiguous.
+		return nil, nil, &errors.ValidationError{
+			Msg: "from is out of range, history is not available",
+		}
+	}
 	s := sharedTypes.Snapshot(d.Snapshot)
 	dropFrom := len(dh.History)
 
 	// rewind the doc
 	rev := make(sharedTypes.Op, 10)
 	for i := len(dh.History) - 1; i >= 0; i-- {
-		op := dh.History[i].Op
-		n := len(op)
-		if n > cap(rev) {
-			rev = make(sharedTypes.Op, n)
-		} else {
-			rev = rev[:n]
-		}
-		for j := 0; j < n; j++ {
-			k := n - 1 - j
-			rev[k].Position = op[j].Position
-			rev[k].Deletion, rev[k].Insertion = op[j].Insertion, op[j].Deletion
-		}
+		rev = invertOp(rev, dh.History[i].Op)
 		if s, err = text.Apply(s, rev); err != nil {
 			return nil, nil, errors.Tag(
 				err,
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2022-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2022-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -43,3 +43,31 @@ func (m *manager) RestoreDocVersion(ctx context.Context, r *types.RestoreDocVers
 	}
 	return nil
 }
+
+func (m *manager) RestoreProjectToVersion(ctx context.Context, r *types.RestoreProjectToVersionRequest) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	projectId := r.ProjectId
+	if err := m.fm.FlushProject(ctx, projectId); err != nil {
+		return errors.Tag(err, "flush project history")
+	}
+	versions, err := m.dhm.GetVersionsAt(ctx, projectId, r.At.ToTime())
+	if err != nil {
+		return errors.Tag(err, "get doc versions")
+	}
+	for docId, v := range versions {
+		// Restoring re-applies the old content as a new change, which
+		//  keeps the current state in the history.
+		err = m.RestoreDocVersion(ctx, &types.RestoreDocVersionRequest{
+			ProjectId: projectId,
+			DocId:     docId,
+			UserId:    r.UserId,
+			FromV:     v + 1,
+		})
+		if err != nil {
+			return errors.Tag(err, "restore doc "+docId.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package diff
+
+import (
+	"context"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/docHistory"
+	"github.com/das7pad/overleaf-go/pkg/models/user"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/document-updater/pkg/sharejs/types/text"
+	"github.com/das7pad/overleaf-go/services/track-changes/pkg/types"
+)
+
+// replayHistory builds a doc from scratch. On error, it returns the version
+// of the history entry that failed to apply.
+func replayHistory(history []docHistory.DocHistory) (sharedTypes.Snapshot, sharedTypes.Version, error) {
+	s := sharedTypes.Snapshot("")
+	for _, h := range history {
+		var err error
+		if s, err = text.Apply(s, h.Op); err != nil {
+			return nil, h.Version, err
+		}
+	}
+	return s, 0, nil
+}
+
+func verifyReplay(snapshot sharedTypes.Snapshot, history []docHistory.DocHistory, response *types.VerifyDocHistoryResponse) {
+	response.SnapshotHash = snapshot.Hash()
+	s, brokenAt, err := replayHistory(history)
+	if err != nil {
+		response.Consistent = false
+		response.BrokenAt = brokenAt
+		return
+	}
+	response.ReplayedHash = s.Hash()
+	response.Consistent = response.ReplayedHash == response.SnapshotHash
+}
+
+// VerifyDocHistory replays the full history from version 0 and compares the
+// result against the current doc content.
+// Docs that were created with content (e.g. uploads) do not have a history
+// entry for their initial content and will not match.
+// Docs with pruned history cannot be replayed, they are reported as
+// inconsistent along with their PrunedVersion.
+func (m *manager) VerifyDocHistory(ctx context.Context, r *types.VerifyDocHistoryRequest, response *types.VerifyDocHistoryResponse) error {
+	d, err := m.dum.GetDoc(ctx, r.ProjectId, r.DocId, -1)
+	if err != nil {
+		return errors.Tag(err, "get latest doc version")
+	}
+	response.Version = d.Version
+	response.PrunedVersion, err = m.dhm.GetPrunedVersion(
+		ctx, r.ProjectId, r.DocId,
+	)
+	if err != nil {
+		return errors.Tag(err, "get pruned history version")
+	}
+	if response.PrunedVersion > 0 {
+		response.SnapshotHash = sharedTypes.Snapshot(d.Snapshot).Hash()
+		response.Consistent = false
+		return nil
+	}
+	if err = m.fm.FlushDoc(ctx, r.ProjectId, r.DocId); err != nil {
+		return errors.Tag(err, "flush doc history")
+	}
+	dh := docHistory.GetForDocResult{
+		History: make([]docHistory.DocHistory, 0, d.Version),
+		Users:   make(user.BulkFetched, 0, 10),
+	}
+	err = m.dhm.GetForDoc(ctx, r.ProjectId, r.UserId, r.DocId, 0, d.Version, &dh)
+	if err != nil {
+		return errors.Tag(err, "get flushed history")
+	}
+	verifyReplay(sharedTypes.Snapshot(d.Snapshot), dh.History, response)
+	return nil
+}
@@ -0,0 +1,127 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/models/docHistory"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/track-changes/pkg/types"
+)
+
+func Test_verifyReplay(t *testing.T) {
+	history := func() []docHistory.DocHistory {
+		return []docHistory.DocHistory{
+			{
+				Version: 1,
+				Op: sharedTypes.Op{
+					{Insertion: sharedTypes.Snippet("Hello World"), Position: 0},
+				},
+			},
+			{
+				Version: 3,
+				Op: sharedTypes.Op{
+					{Deletion: sharedTypes.Snippet("World"), Position: 6},
+					{Insertion: sharedTypes.Snippet("there"), Position: 6},
+				},
+			},
+			{
+				Version: 4,
+				Op: sharedTypes.Op{
+					{Insertion: sharedTypes.Snippet("!"), Position: 11},
+				},
+			},
+		}
+	}
+	snapshot := sharedTypes.Snapshot("Hello there!")
+
+	t.Run("consistent", func(t *testing.T) {
+		r := types.VerifyDocHistoryResponse{}
+		verifyReplay(snapshot, history(), &r)
+		if !r.Consistent {
+			t.Errorf("verifyReplay() = %#v, want consistent", r)
+		}
+		if r.ReplayedHash != snapshot.Hash() {
+			t.Errorf("verifyReplay() hash = %s, want %s", r.ReplayedHash, snapshot.Hash())
+		}
+	})
+
+	t.Run("dropped entry", func(t *testing.T) {
+		h := history()
+		h = append(h[:1], h[2:]...)
+		r := types.VerifyDocHistoryResponse{}
+		verifyReplay(snapshot, h, &r)
+		if r.Consistent {
+			t.Errorf("verifyReplay() = %#v, want inconsistent", r)
+		}
+		if r.ReplayedHash == "" || r.ReplayedHash == r.SnapshotHash {
+			t.Errorf("verifyReplay() hash = %q, want mismatch", r.ReplayedHash)
+		}
+	})
+
+	t.Run("dropped first entry", func(t *testing.T) {
+		h := history()[1:]
+		r := types.VerifyDocHistoryResponse{}
+		verifyReplay(snapshot, h, &r)
+		if r.Consistent {
+			t.Errorf("verifyReplay() = %#v, want inconsistent", r)
+		}
+		if r.BrokenAt != 3 {
+			t.Errorf("verifyReplay() broken at = %d, want 3", r.BrokenAt)
+		}
+	})
+
+	t.Run("insertion at wrong position", func(t *testing.T) {
+		h := history()
+		h[2].Op[0].Position = 5
+		r := types.VerifyDocHistoryResponse{}
+		verifyReplay(snapshot, h, &r)
+		if r.Consistent {
+			t.Errorf("verifyReplay() = %#v, want inconsistent", r)
+		}
+		if r.ReplayedHash == "" || r.ReplayedHash == r.SnapshotHash {
+			t.Errorf("verifyReplay() hash = %q, want mismatch", r.ReplayedHash)
+		}
+	})
+
+	t.Run("content mismatch", func(t *testing.T) {
+		h := history()
+		h[2].Op[0].Insertion = sharedTypes.Snippet("?")
+		r := types.VerifyDocHistoryResponse{}
+		verifyReplay(snapshot, h, &r)
+		if r.Consistent {
+			t.Errorf("verifyReplay() = %#v, want inconsistent", r)
+		}
+		if r.ReplayedHash == "" || r.ReplayedHash == r.SnapshotHash {
+			t.Errorf("verifyReplay() hash = %q, want mismatch", r.ReplayedHash)
+		}
+	})
+
+	t.Run("broken op", func(t *testing.T) {
+		h := history()
+		h[1].Op[0].Deletion = sharedTypes.Snippet("Earth")
+		r := types.VerifyDocHistoryResponse{}
+		verifyReplay(snapshot, h, &r)
+		if r.Consistent {
+			t.Errorf("verifyReplay() = %#v, want inconsistent", r)
+		}
+		if r.BrokenAt != 3 {
+			t.Errorf("verifyReplay() broken at = %d, want 3", r.BrokenAt)
+		}
+	})
+}
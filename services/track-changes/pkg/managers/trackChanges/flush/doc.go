@@ -20,11 +20,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 
 	"github.com/redis/go-redis/v9"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/logger"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
@@ -60,9 +60,10 @@ func (m *manager) FlushDocInBackground(projectId, docId sharedTypes.UUID) {
 	go func() {
 		err := m.FlushDoc(context.Background(), projectId, docId)
 		if err != nil {
-			ids := projectId.Concat('/', docId)
-			err = errors.Tag(err, "flush history for "+ids)
-			log.Println(err.Error())
+			logger.Error(
+				"flush history failed", err,
+				"projectId", projectId, "docId", docId,
+			)
 		}
 	}()
 }
@@ -163,12 +164,16 @@ func (m *manager) persistUpdates(ctx context.Context, projectId, docId sharedTyp
 		}
 	}
 	if updates[0].Version != v+1 {
-		log.Printf(
-			"%s/%s: incomplete history: version jump %d -> %d",
-			projectId, docId, v, updates[0].Version,
+		logger.Warn(
+			"incomplete history: version jump",
+			"projectId", projectId, "docId", docId,
+			"from", v, "to", updates[0].Version,
 		)
 	}
 
+	// mergeUpdates consumes the first component of the first update.
+	persisted := append([]sharedTypes.DocumentUpdate(nil), updates...)
+
 	// mergeComponents updates
 	dh := mergeInserts(mergeUpdates(updates))
 
@@ -176,5 +181,16 @@ func (m *manager) persistUpdates(ctx context.Context, projectId, docId sharedTyp
 	if err = m.dhm.InsertBulk(ctx, docId, dh); err != nil {
 		return errors.Tag(err, "insert history into db")
 	}
+
+	if m.sink != nil {
+		// The updates are persisted already. Do not block the flush on a
+		//  broken sink, consumers may observe gaps instead.
+		if err = m.sink.Emit(ctx, projectId, docId, persisted); err != nil {
+			logger.Error(
+				"emit updates to sink failed", err,
+				"projectId", projectId, "docId", docId,
+			)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,88 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package flush
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/models/docHistory"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+type fakeDocHistoryManager struct {
+	docHistory.Manager
+	lastVersion sharedTypes.Version
+	inserted    []docHistory.ForInsert
+}
+
+func (f *fakeDocHistoryManager) GetLastVersion(context.Context, sharedTypes.UUID, sharedTypes.UUID) (sharedTypes.Version, error) {
+	return f.lastVersion, nil
+}
+
+func (f *fakeDocHistoryManager) InsertBulk(_ context.Context, _ sharedTypes.UUID, dh []docHistory.ForInsert) error {
+	f.inserted = append(f.inserted, dh...)
+	return nil
+}
+
+type fakeUpdateSink struct {
+	updates []sharedTypes.DocumentUpdate
+}
+
+func (f *fakeUpdateSink) Emit(_ context.Context, _, _ sharedTypes.UUID, updates []sharedTypes.DocumentUpdate) error {
+	f.updates = append(f.updates, updates...)
+	return nil
+}
+
+func TestManager_persistUpdatesEmitsToSink(t *testing.T) {
+	dhm := &fakeDocHistoryManager{lastVersion: 2}
+	sink := &fakeUpdateSink{}
+	m := &manager{dhm: dhm, sink: sink}
+
+	update := func(v sharedTypes.Version, s string) sharedTypes.DocumentUpdate {
+		return sharedTypes.DocumentUpdate{
+			Op: sharedTypes.Op{
+				{Insertion: sharedTypes.Snippet(s), Position: 0},
+				{Insertion: sharedTypes.Snippet(s), Position: 10},
+			},
+			Version: v,
+		}
+	}
+	updates := []sharedTypes.DocumentUpdate{
+		update(2, "a"),
+		update(3, "b"),
+		update(4, "c"),
+	}
+	want := []sharedTypes.DocumentUpdate{
+		update(3, "b"),
+		update(4, "c"),
+	}
+	err := m.persistUpdates(
+		context.Background(), sharedTypes.UUID{1}, sharedTypes.UUID{2},
+		updates,
+	)
+	if err != nil {
+		t.Fatalf("persistUpdates() error = %v", err)
+	}
+	if len(dhm.inserted) == 0 {
+		t.Fatalf("persistUpdates() did not insert history")
+	}
+	if !reflect.DeepEqual(sink.updates, want) {
+		t.Errorf("persistUpdates() emitted %v, want %v", sink.updates, want)
+	}
+}
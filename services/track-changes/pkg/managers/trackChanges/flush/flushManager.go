@@ -18,12 +18,11 @@ package flush
 
 import (
 	"context"
-	"log"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 
+	"github.com/das7pad/overleaf-go/pkg/logger"
 	"github.com/das7pad/overleaf-go/pkg/models/docHistory"
 	"github.com/das7pad/overleaf-go/pkg/redisLocker"
 	"github.com/das7pad/overleaf-go/pkg/redisScanner"
@@ -41,10 +40,11 @@ type Manager interface {
 	FlushDocInBackground(projectId, docId sharedTypes.UUID)
 	FlushProject(ctx context.Context, projectId sharedTypes.UUID) error
 	RecordAndFlushHistoryOps(ctx context.Context, projectId, docId sharedTypes.UUID, nUpdates, queueDepth int64) error
+	RepairDocHistoryQueue(ctx context.Context, projectId, docId sharedTypes.UUID, dryRun bool) (*VersionRepairReport, error)
 }
 
-func NewPeriodic(db *pgxpool.Pool, client redis.UniversalClient, pc redisScanner.PeriodicOptions) (PeriodicManager, error) {
-	m, err := newManager(db, client)
+func NewPeriodic(client redis.UniversalClient, dhm docHistory.Manager, pc redisScanner.PeriodicOptions, sink UpdateSink) (PeriodicManager, error) {
+	m, err := newManager(client, dhm, sink)
 	if err != nil {
 		return nil, err
 	}
@@ -54,19 +54,16 @@ func NewPeriodic(db *pgxpool.Pool, client redis.UniversalClient, pc redisScanner
 	}, nil
 }
 
-func New(db *pgxpool.Pool, client redis.UniversalClient) (Manager, error) {
-	return newManager(db, client)
-}
-
-func newManager(db *pgxpool.Pool, client redis.UniversalClient) (*manager, error) {
+func newManager(client redis.UniversalClient, dhm docHistory.Manager, sink UpdateSink) (*manager, error) {
 	rl, err := redisLocker.New(client, "HistoryLock")
 	if err != nil {
 		return nil, err
 	}
 	return &manager{
 		client: client,
-		dhm:    docHistory.New(db),
+		dhm:    dhm,
 		rl:     rl,
+		sink:   sink,
 	}, nil
 }
 
@@ -74,6 +71,7 @@ type manager struct {
 	client redis.UniversalClient
 	dhm    docHistory.Manager
 	rl     redisLocker.Locker
+	sink   UpdateSink
 }
 
 type periodicManager struct {
@@ -99,7 +97,9 @@ func (m *manager) FlushProjectInBackground(ctx context.Context, projectId shared
 	ctx, done := context.WithTimeout(ctx, 30*time.Second)
 	defer done()
 	if err := m.FlushProject(ctx, projectId); err != nil {
-		log.Printf("history background flush failed: %s: %s", projectId, err)
+		logger.Error(
+			"history background flush failed", err, "projectId", projectId,
+		)
 		return false
 	}
 	return true
@@ -0,0 +1,147 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package flush
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/logger"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+type VersionAnomalyKind string
+
+const (
+	VersionAnomalyDuplicate  = VersionAnomalyKind("duplicate")
+	VersionAnomalyGap        = VersionAnomalyKind("gap")
+	VersionAnomalyRegression = VersionAnomalyKind("regression")
+)
+
+type VersionAnomaly struct {
+	Kind     VersionAnomalyKind  `json:"kind"`
+	Index    int                 `json:"index"`
+	Previous sharedTypes.Version `json:"previous"`
+	Version  sharedTypes.Version `json:"version"`
+}
+
+type VersionRepairReport struct {
+	Anomalies []VersionAnomaly `json:"anomalies"`
+	DryRun    bool             `json:"dryRun"`
+	Dropped   int              `json:"dropped"`
+	QueueSize int              `json:"queueSize"`
+}
+
+// detectVersionAnomalies scans the queued updates in order. Duplicated and
+// regressed versions are flagged for removal, gaps are reported only.
+func detectVersionAnomalies(updates []sharedTypes.DocumentUpdate) ([]VersionAnomaly, []bool) {
+	anomalies := make([]VersionAnomaly, 0)
+	drop := make([]bool, len(updates))
+	for i := 1; i < len(updates); i++ {
+		last := i - 1
+		for drop[last] {
+			last--
+		}
+		prev := updates[last].Version
+		v := updates[i].Version
+		var kind VersionAnomalyKind
+		switch {
+		case v == prev:
+			kind = VersionAnomalyDuplicate
+			drop[i] = true
+		case v < prev:
+			kind = VersionAnomalyRegression
+			drop[i] = true
+		case v > prev+1:
+			kind = VersionAnomalyGap
+		default:
+			continue
+		}
+		anomalies = append(anomalies, VersionAnomaly{
+			Kind:     kind,
+			Index:    i,
+			Previous: prev,
+			Version:  v,
+		})
+	}
+	return anomalies, drop
+}
+
+func (m *manager) RepairDocHistoryQueue(ctx context.Context, projectId, docId sharedTypes.UUID, dryRun bool) (*VersionRepairReport, error) {
+	queueKey := getUncompressedHistoryOpsKey(docId)
+	var r *VersionRepairReport
+	err := m.rl.RunWithLock(ctx, docId, func(ctx context.Context) error {
+		rawUpdates, err := m.client.LRange(ctx, queueKey, 0, -1).Result()
+		if err != nil {
+			return errors.Tag(err, "get updates from redis")
+		}
+		updates := make([]sharedTypes.DocumentUpdate, len(rawUpdates))
+		for i, update := range rawUpdates {
+			err = json.Unmarshal([]byte(update), &updates[i])
+			if err != nil {
+				return errors.Tag(err, fmt.Sprintf("decode update %d", i))
+			}
+		}
+
+		anomalies, drop := detectVersionAnomalies(updates)
+		r = &VersionRepairReport{
+			Anomalies: anomalies,
+			DryRun:    dryRun,
+			QueueSize: len(rawUpdates),
+		}
+		for _, a := range anomalies {
+			if a.Kind == VersionAnomalyGap {
+				logger.Warn(
+					"incomplete history: version jump",
+					"projectId", projectId, "docId", docId,
+					"from", a.Previous, "to", a.Version,
+				)
+			}
+		}
+		for _, d := range drop {
+			if d {
+				r.Dropped++
+			}
+		}
+		if dryRun || r.Dropped == 0 {
+			return nil
+		}
+
+		_, err = m.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+			// Remove from the tail for not touching the first copy of an
+			//  exact duplicate.
+			for i, d := range drop {
+				if d {
+					p.LRem(ctx, queueKey, -1, rawUpdates[i])
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.Tag(err, "remove updates from redis queue")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,116 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package flush
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func Test_detectVersionAnomalies(t *testing.T) {
+	versions := func(vs ...sharedTypes.Version) []sharedTypes.DocumentUpdate {
+		updates := make([]sharedTypes.DocumentUpdate, len(vs))
+		for i, v := range vs {
+			updates[i].Version = v
+		}
+		return updates
+	}
+	tests := []struct {
+		name          string
+		updates       []sharedTypes.DocumentUpdate
+		wantAnomalies []VersionAnomaly
+		wantDrop      []bool
+	}{
+		{
+			name:          "linear",
+			updates:       versions(1, 2, 3),
+			wantAnomalies: []VersionAnomaly{},
+			wantDrop:      []bool{false, false, false},
+		},
+		{
+			name:    "duplicate",
+			updates: versions(1, 2, 2, 3),
+			wantAnomalies: []VersionAnomaly{
+				{
+					Kind:     VersionAnomalyDuplicate,
+					Index:    2,
+					Previous: 2,
+					Version:  2,
+				},
+			},
+			wantDrop: []bool{false, false, true, false},
+		},
+		{
+			name:    "regression",
+			updates: versions(3, 4, 2, 5),
+			wantAnomalies: []VersionAnomaly{
+				{
+					Kind:     VersionAnomalyRegression,
+					Index:    2,
+					Previous: 4,
+					Version:  2,
+				},
+			},
+			wantDrop: []bool{false, false, true, false},
+		},
+		{
+			name:    "regression after duplicate",
+			updates: versions(5, 5, 4, 6),
+			wantAnomalies: []VersionAnomaly{
+				{
+					Kind:     VersionAnomalyDuplicate,
+					Index:    1,
+					Previous: 5,
+					Version:  5,
+				},
+				{
+					Kind:     VersionAnomalyRegression,
+					Index:    2,
+					Previous: 5,
+					Version:  4,
+				},
+			},
+			wantDrop: []bool{false, true, true, false},
+		},
+		{
+			name:    "gap",
+			updates: versions(1, 3),
+			wantAnomalies: []VersionAnomaly{
+				{
+					Kind:     VersionAnomalyGap,
+					Index:    1,
+					Previous: 1,
+					Version:  3,
+				},
+			},
+			wantDrop: []bool{false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies, drop := detectVersionAnomalies(tt.updates)
+			if !reflect.DeepEqual(anomalies, tt.wantAnomalies) {
+				t.Errorf("detectVersionAnomalies() anomalies = %v, want %v", anomalies, tt.wantAnomalies)
+			}
+			if !reflect.DeepEqual(drop, tt.wantDrop) {
+				t.Errorf("detectVersionAnomalies() drop = %v, want %v", drop, tt.wantDrop)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package flush
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+// UpdateSink receives updates after they got persisted into the history.
+type UpdateSink interface {
+	Emit(ctx context.Context, projectId, docId sharedTypes.UUID, updates []sharedTypes.DocumentUpdate) error
+}
+
+type UpdateSinkOptions struct {
+	// Kind is one of "" (disabled) or "redis-stream".
+	Kind   string `json:"kind"`
+	Stream string `json:"stream"`
+	MaxLen int64  `json:"max_len"`
+}
+
+func (o *UpdateSinkOptions) Validate() error {
+	switch o.Kind {
+	case "":
+		return nil
+	case "redis-stream":
+		if o.Stream == "" {
+			return &errors.ValidationError{Msg: "missing stream"}
+		}
+		if o.MaxLen < 0 {
+			return &errors.ValidationError{
+				Msg: "max_len must not be negative",
+			}
+		}
+		return nil
+	default:
+		return &errors.ValidationError{Msg: "unknown kind: " + o.Kind}
+	}
+}
+
+func NewUpdateSink(client redis.UniversalClient, o UpdateSinkOptions) (UpdateSink, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	switch o.Kind {
+	case "redis-stream":
+		return &redisStreamSink{
+			client: client,
+			maxLen: o.MaxLen,
+			stream: o.Stream,
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+type redisStreamSink struct {
+	client redis.UniversalClient
+	maxLen int64
+	stream string
+}
+
+func (s *redisStreamSink) Emit(ctx context.Context, projectId, docId sharedTypes.UUID, updates []sharedTypes.DocumentUpdate) error {
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, update := range updates {
+			blob, err := json.Marshal(update)
+			if err != nil {
+				return errors.Tag(err, "serialize update")
+			}
+			p.XAdd(ctx, &redis.XAddArgs{
+				Stream: s.stream,
+				MaxLen: s.maxLen,
+				Approx: true,
+				Values: []interface{}{
+					"projectId", projectId.String(),
+					"docId", docId.String(),
+					"update", blob,
+				},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Tag(err, "add updates to stream")
+	}
+	return nil
+}
@@ -18,7 +18,6 @@ package trackChanges
 
 import (
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/redis/go-redis/v9"
 
 	"github.com/das7pad/overleaf-go/pkg/models/docHistory"
 	"github.com/das7pad/overleaf-go/services/document-updater/pkg/managers/documentUpdater"
@@ -33,12 +32,9 @@ type Manager interface {
 	updatesManager
 }
 
-func New(db *pgxpool.Pool, client redis.UniversalClient, dum documentUpdater.Manager) (Manager, error) {
-	fm, err := flush.New(db, client)
-	if err != nil {
-		return nil, err
-	}
-	dhm := docHistory.New(db)
+func New(db *pgxpool.Pool, dum documentUpdater.Manager) (Manager, error) {
+	fm := dum.HistoryFlushManager()
+	dhm := docHistory.New(db, docHistory.Options{})
 	dfm := diff.New(dhm, fm, dum)
 	um := updates.New(dhm, fm)
 	return &manager{
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2022-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2022-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -52,6 +52,9 @@ type manager struct {
 }
 
 func (m *manager) GetProjectHistoryUpdates(ctx context.Context, r *types.GetProjectHistoryUpdatesRequest, res *types.GetProjectHistoryUpdatesResponse) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
 	if err := m.fm.FlushProject(ctx, r.ProjectId); err != nil {
 		return errors.Tag(err, "flush project")
 	}
@@ -62,6 +65,12 @@ func (m *manager) GetProjectHistoryUpdates(ctx context.Context, r *types.GetProj
 	} else {
 		before = time.Now()
 	}
+	if r.To > 0 {
+		// Both bounds are inclusive, the query has an exclusive upper one.
+		if to := r.To.ToTime().Add(time.Millisecond); to.Before(before) {
+			before = to
+		}
+	}
 	batch := docHistory.GetForProjectResult{
 		History: make([]docHistory.ProjectUpdate, 0, fetchAtLeastNUpdates),
 		Users:   make([]user.WithPublicInfo, 0, fetchAtLeastNUpdates),
@@ -86,6 +95,13 @@ func (m *manager) GetProjectHistoryUpdates(ctx context.Context, r *types.GetProj
 		}
 
 		for _, update := range batch.History {
+			if r.From > 0 && update.EndAt.Before(r.From.ToTime()) {
+				if res.Updates == nil {
+					res.Updates = make([]types.Update, 0)
+				}
+				res.NextBeforeTimestamp = 0
+				return nil
+			}
 			docId := update.DocId.String()
 			startAt := sharedTypes.Timestamp(update.StartAt.UnixMilli())
 			endAt := sharedTypes.Timestamp(update.EndAt.UnixMilli())
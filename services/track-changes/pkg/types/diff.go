@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -49,7 +49,10 @@ type GetDocDiffRequest struct {
 }
 
 func (r *GetDocDiffRequest) Validate() error {
-	if r.To < r.From {
+	if r.From < 0 || r.To < 0 {
+		return &errors.ValidationError{Msg: "negative from/to"}
+	}
+	if r.To != 0 && r.To < r.From {
 		return &errors.ValidationError{Msg: "from/to flipped"}
 	}
 	return nil
@@ -67,6 +70,12 @@ func (r *GetDocDiffRequest) FromQuery(q url.Values) error {
 	if err := r.To.ParseIfSet(q.Get("to")); err != nil {
 		return errors.Tag(err, "query parameter 'to'")
 	}
+	if err := r.From.ParseIfSet(q.Get("fromV")); err != nil {
+		return errors.Tag(err, "query parameter 'fromV'")
+	}
+	if err := r.To.ParseIfSet(q.Get("toV")); err != nil {
+		return errors.Tag(err, "query parameter 'toV'")
+	}
 	return nil
 }
 
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,6 +17,9 @@
 package types
 
 import (
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
@@ -32,3 +35,25 @@ func (r *RestoreDocVersionRequest) FromSignedProjectOptions(o sharedTypes.Projec
 	r.ProjectId = o.ProjectId
 	r.UserId = o.UserId
 }
+
+type RestoreProjectToVersionRequest struct {
+	ProjectId sharedTypes.UUID `json:"-"`
+	UserId    sharedTypes.UUID `json:"-"`
+
+	At sharedTypes.Timestamp `json:"at"`
+}
+
+func (r *RestoreProjectToVersionRequest) FromSignedProjectOptions(o sharedTypes.ProjectOptions) {
+	r.ProjectId = o.ProjectId
+	r.UserId = o.UserId
+}
+
+func (r *RestoreProjectToVersionRequest) Validate() error {
+	if r.At <= 0 {
+		return &errors.ValidationError{Msg: "missing at"}
+	}
+	if r.At.ToTime().After(time.Now()) {
+		return &errors.ValidationError{Msg: "at is in the future"}
+	}
+	return nil
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,6 +18,7 @@ package types
 
 import (
 	"net/url"
+	"time"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/models/user"
@@ -46,6 +47,27 @@ type GetProjectHistoryUpdatesRequest struct {
 	UserId    sharedTypes.UUID `json:"-"`
 
 	Before sharedTypes.Timestamp `form:"before" json:"before"`
+	From   sharedTypes.Timestamp `form:"from" json:"from"`
+	To     sharedTypes.Timestamp `form:"to" json:"to"`
+}
+
+const MaxProjectHistoryUpdatesRange = 90 * 24 * time.Hour
+
+func (r *GetProjectHistoryUpdatesRequest) Validate() error {
+	if r.From == 0 {
+		return nil
+	}
+	to := r.To
+	if to == 0 {
+		to = sharedTypes.Timestamp(time.Now().UnixMilli())
+	}
+	if to < r.From {
+		return &errors.ValidationError{Msg: "from/to flipped"}
+	}
+	if to.ToTime().Sub(r.From.ToTime()) > MaxProjectHistoryUpdatesRange {
+		return &errors.ValidationError{Msg: "from/to range exceeds 90 days"}
+	}
+	return nil
 }
 
 func (r *GetProjectHistoryUpdatesRequest) FromSignedProjectOptions(o sharedTypes.ProjectOptions) {
@@ -57,6 +79,12 @@ func (r *GetProjectHistoryUpdatesRequest) FromQuery(q url.Values) error {
 	if err := r.Before.ParseIfSet(q.Get("before")); err != nil {
 		return errors.Tag(err, "query parameter 'before'")
 	}
+	if err := r.From.ParseIfSet(q.Get("from")); err != nil {
+		return errors.Tag(err, "query parameter 'from'")
+	}
+	if err := r.To.ParseIfSet(q.Get("to")); err != nil {
+		return errors.Tag(err, "query parameter 'to'")
+	}
 	return nil
 }
 
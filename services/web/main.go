@@ -86,6 +86,7 @@ func main() {
 		Handler: router.New(webManager, corsOptions.Parse()),
 	}
 	httpUtils.ListenAndServeEach(eg.Go, &server, listenAddress.Parse(3000))
+	httpUtils.ServeMetrics(ctx, eg.Go, 13000)
 	eg.Go(func() error {
 		<-ctx.Done()
 		waitForSlowRequests, done := context.WithTimeout(
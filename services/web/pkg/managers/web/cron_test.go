@@ -0,0 +1,76 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+)
+
+func TestManager_CronOnce_RemoveExpiredMembers(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	member := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	_, err := db.Exec(ctx, `
+INSERT INTO project_members
+(project_id, user_id, access_source, privilege_level, archived, trashed,
+ access_expires_at)
+VALUES ($1, $2, 'invite', 'readAndWrite', FALSE, FALSE, $3)
+`, projectId, member.User.Id, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("seed expired member: %s", err)
+	}
+	var epochBefore int64
+	err = db.QueryRow(ctx, `
+SELECT epoch FROM projects WHERE id = $1
+`, projectId).Scan(&epochBefore)
+	if err != nil {
+		t.Fatalf("get epoch: %s", err)
+	}
+
+	if !wm.CronOnce(ctx, false) {
+		t.Fatalf("CronOnce() = false, want true")
+	}
+
+	var nMembers int
+	var epochAfter int64
+	err = db.QueryRow(ctx, `
+SELECT count(pm.user_id), p.epoch
+FROM projects p
+         LEFT JOIN project_members pm
+                   ON (p.id = pm.project_id AND pm.user_id = $2)
+WHERE p.id = $1
+GROUP BY p.epoch
+`, projectId, member.User.Id).Scan(&nMembers, &epochAfter)
+	if err != nil {
+		t.Fatalf("get membership: %s", err)
+	}
+	if nMembers != 0 {
+		t.Errorf("expired membership was not removed")
+	}
+	if epochAfter <= epochBefore {
+		t.Errorf("epoch = %d, want > %d", epochAfter, epochBefore)
+	}
+}
@@ -0,0 +1,95 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_FlushDoc(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	o := types.Options{}
+	o.FillFromEnv()
+	wm, dum := newTestManagerWithDocumentUpdater(t, ctx, &o)
+
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	page := types.ProjectEditorPageResponse{}
+	err := wm.ProjectEditorPage(ctx, &types.ProjectEditorPageRequest{
+		WithSession: types.WithSession{Session: owner},
+		ProjectId:   projectId,
+	}, &page)
+	if err != nil {
+		t.Fatalf("load editor: %s", err)
+	}
+	docId := page.Data.EditorBootstrap.Project.RootDocId
+
+	d, err := dum.GetDoc(ctx, projectId, docId, -1)
+	if err != nil {
+		t.Fatalf("get doc: %s", err)
+	}
+	err = dum.QueueUpdate(ctx, projectId, docId, sharedTypes.DocumentUpdate{
+		DocId: docId,
+		Meta:  sharedTypes.DocumentUpdateMeta{Source: "test"},
+		Op: sharedTypes.Op{
+			{Insertion: sharedTypes.Snippet("% flushed\n"), Position: 0},
+		},
+		Version: d.Version,
+	})
+	if err != nil {
+		t.Fatalf("queue update: %s", err)
+	}
+
+	res := types.FlushDocResponse{}
+	err = wm.FlushDoc(ctx, &types.FlushDocRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+		DocId: docId,
+	}, &res)
+	if err != nil {
+		t.Fatalf("flush doc: %s", err)
+	}
+	if want := d.Version + 1; res.Version != want {
+		t.Errorf("FlushDoc() version = %d, want %d", res.Version, want)
+	}
+
+	var snapshot string
+	var v sharedTypes.Version
+	err = db.QueryRow(ctx, `
+SELECT snapshot, version
+FROM docs
+WHERE id = $1
+`, docId).Scan(&snapshot, &v)
+	if err != nil {
+		t.Fatalf("get persisted doc: %s", err)
+	}
+	if v != res.Version {
+		t.Errorf("persisted version = %d, want %d", v, res.Version)
+	}
+	if want := "% flushed\n" + d.Snapshot; snapshot != want {
+		t.Errorf("persisted snapshot = %q, want %q", snapshot, want)
+	}
+}
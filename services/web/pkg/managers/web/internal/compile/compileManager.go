@@ -29,6 +29,7 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/httpUtils"
 	"github.com/das7pad/overleaf-go/pkg/models/project"
 	"github.com/das7pad/overleaf-go/pkg/models/user"
 	"github.com/das7pad/overleaf-go/pkg/pendingOperation"
@@ -62,7 +63,7 @@ func New(options *types.Options, client redis.UniversalClient, dum documentUpdat
 		dum:                      dum,
 		fm:                       fm,
 		pm:                       pm,
-		pool:                     &http.Client{},
+		pool:                     &http.Client{Transport: httpUtils.ForwardRequestId(nil)},
 		um:                       um,
 	}, nil
 }
@@ -163,7 +164,9 @@ func (m *manager) StartInBackground(ctx context.Context, options sharedTypes.Pro
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
-		log.Printf("get clsi persistence: %s", err)
+		log.Printf(
+			"get clsi persistence: %s", httpUtils.TagRequestId(ctx, err),
+		)
 	}
 	res, _, err := m.doPersistentRequest(ctx, options, clsiServerId, r)
 	if err != nil {
@@ -246,7 +249,9 @@ func (m *manager) Compile(ctx context.Context, request *types.CompileProjectRequ
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
-			log.Printf("get clsi persistence: %s", err)
+			log.Printf(
+				"get clsi persistence: %s", httpUtils.TagRequestId(ctx, err),
+			)
 		}
 		if m.bundle != nil {
 			err = m.bundle.Compile(
@@ -275,10 +280,23 @@ func (m *manager) Compile(ctx context.Context, request *types.CompileProjectRequ
 			}
 			return errors.Tag(err, "compile")
 		}
+		m.bumpCompileCount(request.ProjectId, request.UserId)
 		return nil
 	}
 }
 
+func (m *manager) bumpCompileCount(projectId, userId sharedTypes.UUID) {
+	go func() {
+		bCtx, done := context.WithTimeout(context.Background(), 3*time.Second)
+		defer done()
+		if err := m.pm.BumpCompileCount(bCtx, projectId); err != nil {
+			log.Printf(
+				"%s/%s: bump compile count: %s", projectId, userId, err,
+			)
+		}
+	}()
+}
+
 func (m *manager) fromDB(ctx context.Context, request *types.CompileProjectRequest) (clsiTypes.Resources, sharedTypes.PathName, error) {
 	err := m.dum.FlushProject(ctx, request.ProjectId)
 	if err != nil {
@@ -25,6 +25,7 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/httpUtils"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
 )
@@ -78,7 +79,10 @@ func (m *manager) populateServerIdFromResponse(ctx context.Context, res *http.Re
 		err := m.client.Set(ctx, k, string(clsiServerId), persistenceTTL).Err()
 		if err != nil {
 			// Persistence is a performance optimization and ok to fail.
-			log.Printf("update clsi persistence: %s", err.Error())
+			log.Printf(
+				"update clsi persistence: %s",
+				httpUtils.TagRequestId(ctx, err).Error(),
+			)
 		}
 	}
 	return clsiServerId
@@ -17,7 +17,10 @@
 package editor
 
 import (
+	"bytes"
 	"context"
+	"strings"
+	"time"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/models/message"
@@ -25,17 +28,85 @@ import (
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
 )
 
-const chatPageSize = 50
+const (
+	chatExportPageSize = 1000
+	chatPageSize       = 50
+	chatSearchPageSize = 20
+)
+
+func (m *manager) ExportProjectMessages(ctx context.Context, request *types.ExportProjectChatRequest, response *types.ExportProjectChatResponse) error {
+	b := bytes.Buffer{}
+	b.WriteString("# Chat transcript\n")
+	var messages []message.Message
+	after := time.Time{}
+	afterId := sharedTypes.UUID{}
+	for {
+		err := m.mm.GetGlobalMessagesAfter(
+			ctx, request.ProjectId, chatExportPageSize, after, afterId,
+			&messages,
+		)
+		if err != nil {
+			return errors.Tag(err, "get messages")
+		}
+		for _, msg := range messages {
+			b.WriteString("\n**")
+			b.WriteString(msg.CreatedAt.UTC().Format("2006-01-02 15:04:05 MST"))
+			b.WriteString("** ")
+			b.WriteString(msg.User.DisplayName())
+			if msg.EditedAt != nil {
+				b.WriteString(" (edited)")
+			}
+			b.WriteString(":\n\n")
+			for _, line := range strings.Split(msg.Content, "\n") {
+				b.WriteString("> ")
+				b.WriteString(line)
+				b.WriteString("\n")
+			}
+		}
+		if len(messages) < chatExportPageSize {
+			break
+		}
+		last := messages[len(messages)-1]
+		after, afterId = last.CreatedAt, last.Id
+	}
+	response.Filename = "chat.md"
+	response.Transcript = b.Bytes()
+	return nil
+}
 
 func (m *manager) GetProjectMessages(ctx context.Context, request *types.GetProjectChatMessagesRequest, response *types.GetProjectChatMessagesResponse) error {
 	err := m.mm.GetGlobalMessages(
-		ctx, request.ProjectId, chatPageSize, request.Before, response,
+		ctx, request.ProjectId, chatPageSize, request.Before, request.BeforeId,
+		&response.Messages,
 	)
-	res := *response
+	if err != nil {
+		return err
+	}
+	res := response.Messages
 	for i, msg := range res {
 		res[i].User.IdNoUnderscore = msg.User.Id
 	}
-	return err
+	if len(res) == chatPageSize {
+		response.NextBeforeId = &res[len(res)-1].Id
+	}
+	return nil
+}
+
+func (m *manager) SearchProjectMessages(ctx context.Context, request *types.SearchProjectChatMessagesRequest, response *types.SearchProjectChatMessagesResponse) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	err := m.mm.SearchGlobalMessages(
+		ctx, request.ProjectId, request.Query, chatSearchPageSize,
+		&response.Messages,
+	)
+	if err != nil {
+		return errors.Tag(err, "search messages")
+	}
+	for i, msg := range response.Messages {
+		response.Messages[i].User.IdNoUnderscore = msg.User.Id
+	}
+	return nil
 }
 
 func (m *manager) SendProjectMessage(ctx context.Context, request *types.SendProjectChatMessageRequest) error {
@@ -50,3 +121,92 @@ func (m *manager) SendProjectMessage(ctx context.Context, request *types.SendPro
 	go m.notifyEditor(request.ProjectId, sharedTypes.NewChatMessage, msg)
 	return nil
 }
+
+type editChatMessageBody struct {
+	MessageId sharedTypes.UUID `json:"messageId"`
+	Content   string           `json:"content"`
+}
+
+func (m *manager) EditProjectMessage(ctx context.Context, request *types.EditProjectChatMessageRequest) error {
+	err := m.mm.EditGlobalMessage(
+		ctx, request.ProjectId, request.MessageId, request.UserId,
+		request.Content,
+	)
+	if err != nil {
+		return errors.Tag(err, "edit message")
+	}
+
+	go m.notifyEditor(
+		request.ProjectId, sharedTypes.EditChatMessage, editChatMessageBody{
+			MessageId: request.MessageId,
+			Content:   request.Content,
+		},
+	)
+	return nil
+}
+
+type deleteChatMessageBody struct {
+	MessageId sharedTypes.UUID `json:"messageId"`
+}
+
+func (m *manager) DeleteProjectMessage(ctx context.Context, request *types.DeleteProjectChatMessageRequest) error {
+	err := m.mm.DeleteGlobalMessage(
+		ctx, request.ProjectId, request.MessageId, request.UserId,
+	)
+	if err != nil {
+		return errors.Tag(err, "delete message")
+	}
+
+	go m.notifyEditor(
+		request.ProjectId, sharedTypes.DeleteChatMessage, deleteChatMessageBody{
+			MessageId: request.MessageId,
+		},
+	)
+	return nil
+}
+
+type chatMessageReactionBody struct {
+	MessageId sharedTypes.UUID `json:"messageId"`
+	UserId    sharedTypes.UUID `json:"userId"`
+	Emoji     message.Emoji    `json:"emoji"`
+}
+
+func (m *manager) AddProjectMessageReaction(ctx context.Context, request *types.ReactToProjectChatMessageRequest) error {
+	err := m.mm.AddReaction(
+		ctx, request.ProjectId, request.MessageId, request.UserId,
+		request.Emoji,
+	)
+	if err != nil {
+		return errors.Tag(err, "add reaction")
+	}
+
+	go m.notifyEditor(
+		request.ProjectId, sharedTypes.AddChatMessageReaction,
+		chatMessageReactionBody{
+			MessageId: request.MessageId,
+			UserId:    request.UserId,
+			Emoji:     request.Emoji,
+		},
+	)
+	return nil
+}
+
+func (m *manager) RemoveProjectMessageReaction(ctx context.Context, request *types.ReactToProjectChatMessageRequest) error {
+	err := m.mm.RemoveReaction(
+		ctx, request.ProjectId, request.MessageId, request.UserId,
+		request.Emoji,
+	)
+	if err != nil {
+		return errors.Tag(err, "remove reaction")
+	}
+
+	go m.notifyEditor(
+		request.ProjectId, sharedTypes.RemoveChatMessageReaction,
+		chatMessageReactionBody{
+			MessageId: request.MessageId,
+			UserId:    request.UserId,
+			Emoji:     request.Emoji,
+		},
+	)
+	return nil
+}
@@ -40,26 +40,46 @@ import (
 
 type Manager interface {
 	GetProjectFile(ctx context.Context, request *types.GetProjectFileRequest, response *types.GetProjectFileResponse) error
+	GetProjectFileMetadata(ctx context.Context, request *types.GetProjectFileMetadataRequest, response *types.GetProjectFileMetadataResponse) error
 	GetProjectFileSize(ctx context.Context, request *types.GetProjectFileSizeRequest, response *types.GetProjectFileSizeResponse) error
 	GetUserContacts(ctx context.Context, request *types.GetUserContactsRequest, response *types.GetUserContactsResponse) error
 	ListProjectMembers(ctx context.Context, request *types.ListProjectMembersRequest, response *types.ListProjectMembersResponse) error
+	ListProjectTokenMembers(ctx context.Context, request *types.ListProjectTokenMembersRequest, response *types.ListProjectTokenMembersResponse) error
 	LeaveProject(ctx context.Context, request *types.LeaveProjectRequest) error
 	RemoveMemberFromProject(ctx context.Context, request *types.RemoveProjectMemberRequest) error
+	PropagateDefaultCompiler(ctx context.Context, dryRun bool, start time.Time) error
+	RemoveExpiredMembers(ctx context.Context, dryRun bool, start time.Time) error
+	SetMemberAccessExpiresAtInProject(ctx context.Context, request *types.SetMemberAccessExpiresAtInProjectRequest) error
 	SetMemberPrivilegeLevelInProject(ctx context.Context, request *types.SetMemberPrivilegeLevelInProjectRequest) error
 	TransferProjectOwnership(ctx context.Context, request *types.TransferProjectOwnershipRequest) error
 	ProjectEditorPage(ctx context.Context, request *types.ProjectEditorPageRequest, response *types.ProjectEditorPageResponse) error
 	ProjectEditorDetached(ctx context.Context, request *types.ProjectEditorDetachedPageRequest, res *types.ProjectEditorDetachedPageResponse) error
 	GetProjectJWT(ctx context.Context, request *types.GetProjectJWTRequest, response *types.GetProjectJWTResponse) error
 	GetProjectMessages(ctx context.Context, request *types.GetProjectChatMessagesRequest, response *types.GetProjectChatMessagesResponse) error
+	SearchProjectMessages(ctx context.Context, request *types.SearchProjectChatMessagesRequest, response *types.SearchProjectChatMessagesResponse) error
+	ExportProjectMessages(ctx context.Context, request *types.ExportProjectChatRequest, response *types.ExportProjectChatResponse) error
 	SendProjectMessage(ctx context.Context, request *types.SendProjectChatMessageRequest) error
+	EditProjectMessage(ctx context.Context, request *types.EditProjectChatMessageRequest) error
+	DeleteProjectMessage(ctx context.Context, request *types.DeleteProjectChatMessageRequest) error
+	AddProjectMessageReaction(ctx context.Context, request *types.ReactToProjectChatMessageRequest) error
+	RemoveProjectMessageReaction(ctx context.Context, request *types.ReactToProjectChatMessageRequest) error
 	SetCompiler(ctx context.Context, request *types.SetCompilerRequest) error
 	SetImageName(ctx context.Context, request *types.SetImageNameRequest) error
 	SetSpellCheckLanguage(ctx context.Context, request *types.SetSpellCheckLanguageRequest) error
+	SetSpellCheckLanguageForOwnedProjects(ctx context.Context, request *types.SetSpellCheckLanguageForOwnedProjectsRequest) error
 	SetRootDocId(ctx context.Context, request *types.SetRootDocIdRequest) error
 	GetAccessTokens(ctx context.Context, r *types.GetAccessTokensRequest, response *types.GetAccessTokensResponse) error
+	PreviewTokenAccess(ctx context.Context, request *types.PreviewTokenAccessRequest, response *types.PreviewTokenAccessResponse) error
 	SetPublicAccessLevel(ctx context.Context, request *types.SetPublicAccessLevelRequest, response *types.SetPublicAccessLevelResponse) error
+	SetTokenReadAndWritePrivilegeLevel(ctx context.Context, request *types.SetTokenReadAndWritePrivilegeLevelRequest) error
 	SetContentLocked(ctx context.Context, request *types.SetContentLockedRequest) error
+	SetMaxUnFlushedAge(ctx context.Context, request *types.SetMaxUnFlushedAgeRequest) error
+	SetHistoryRetainFor(ctx context.Context, request *types.SetHistoryRetainForRequest) error
 	UpdateEditorConfig(ctx context.Context, request *types.UpdateEditorConfigRequest) error
+	ListProjectSnippets(ctx context.Context, request *types.ListProjectSnippetsRequest, response *types.ListProjectSnippetsResponse) error
+	SetProjectSnippet(ctx context.Context, request *types.SetProjectSnippetRequest) error
+	DeleteProjectSnippet(ctx context.Context, request *types.DeleteProjectSnippetRequest) error
+	FlushDoc(ctx context.Context, request *types.FlushDocRequest, response *types.FlushDocResponse) error
 }
 
 func New(options *types.Options, ps *templates.PublicSettings, client redis.UniversalClient, editorEvents channel.Writer, pm project.Manager, um user.Manager, mm message.Manager, fm filestore.Manager, projectJWTHandler *projectJWT.JWTHandler, loggedInUserJWTHandler *loggedInUserJWT.JWTHandler, dum documentUpdater.Manager, cm compile.Manager, smm systemMessage.Manager) Manager {
@@ -83,8 +103,12 @@ func New(options *types.Options, ps *templates.PublicSettings, client redis.Univ
 		um:              um,
 
 		adminEmail:                options.AdminEmail,
+		defaultCompiler:           options.GetDefaultCompiler(),
+		propagateDefaultCompiler:  options.PropagateDefaultCompiler,
 		appName:                   options.AppName,
 		allowedImageNames:         options.AllowedImages,
+		allowedPublicAccessLevels: options.AllowedPublicAccessLevels,
+		anonymousTokenAccess:      !options.AnonymousTokenAccessDisabled,
 		emailOptions:              options.EmailOptions(),
 		frontendAllowedImageNames: frontendAllowedImageNames,
 		ps:                        ps,
@@ -107,8 +131,12 @@ type manager struct {
 	um              user.Manager
 
 	adminEmail                sharedTypes.Email
+	defaultCompiler           sharedTypes.Compiler
+	propagateDefaultCompiler  bool
 	appName                   string
 	allowedImageNames         []sharedTypes.ImageName
+	allowedPublicAccessLevels types.PublicAccessLevels
+	anonymousTokenAccess      bool
 	emailOptions              *types.EmailOptions
 	frontendAllowedImageNames []templates.AllowedImageName
 	ps                        *templates.PublicSettings
@@ -130,6 +158,32 @@ func (m *manager) notifyEditor(projectId sharedTypes.UUID, message sharedTypes.E
 	})
 }
 
+// notifyEditorBulkConcurrency limits the parallel publishing of an event
+// into many projects.
+const notifyEditorBulkConcurrency = 10
+
+// notifyEditorBulk publishes the same event into many projects from a
+// bounded number of background workers.
+func (m *manager) notifyEditorBulk(projectIds []sharedTypes.UUID, message sharedTypes.EditorEventMessage, payload interface{}) {
+	if len(projectIds) == 0 {
+		return
+	}
+	queue := make(chan sharedTypes.UUID)
+	for i := 0; i < min(notifyEditorBulkConcurrency, len(projectIds)); i++ {
+		go func() {
+			for projectId := range queue {
+				m.notifyEditor(projectId, message, payload)
+			}
+		}()
+	}
+	go func() {
+		defer close(queue)
+		for _, projectId := range projectIds {
+			queue <- projectId
+		}
+	}()
+}
+
 type refreshMembershipDetails struct {
 	Invites bool             `json:"invites,omitempty"`
 	Members bool             `json:"members,omitempty"`
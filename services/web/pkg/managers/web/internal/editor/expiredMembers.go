@@ -0,0 +1,72 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package editor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func (m *manager) RemoveExpiredMembers(ctx context.Context, dryRun bool, start time.Time) error {
+	nFailed := 0
+	err := m.pm.ProcessExpiredMembers(
+		ctx,
+		start,
+		func(projectId, userId sharedTypes.UUID) {
+			if dryRun {
+				log.Printf(
+					"dry-run removing expired member: %s/%s",
+					projectId, userId,
+				)
+				return
+			}
+			err := m.pm.RemoveExpiredMember(ctx, projectId, userId, start)
+			if err != nil {
+				err = errors.Tag(
+					err,
+					fmt.Sprintf(
+						"removing expired member failed: %s/%s",
+						projectId, userId,
+					),
+				)
+				nFailed++
+				log.Println(err.Error())
+				return
+			}
+			go m.notifyEditorAboutAccessChanges(
+				projectId, refreshMembershipDetails{
+					Members: true,
+					UserId:  userId,
+				},
+			)
+		},
+	)
+	if err != nil {
+		err = errors.Tag(err, "query expired members")
+	}
+	if nFailed != 0 {
+		err = errors.Merge(err, errors.New(fmt.Sprintf(
+			"removing failed for %d expired members", nFailed,
+		)))
+	}
+	return err
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,11 +18,16 @@ package editor
 
 import (
 	"context"
+	"time"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
 )
 
+// projectFileDownloadURLExpiry is short, as the client follows the
+// redirect immediately.
+const projectFileDownloadURLExpiry = time.Minute
+
 func (m *manager) GetProjectFile(ctx context.Context, request *types.GetProjectFileRequest, response *types.GetProjectFileResponse) error {
 	projectId := request.ProjectId
 	fileId := request.FileId
@@ -32,6 +37,16 @@ func (m *manager) GetProjectFile(ctx context.Context, request *types.GetProjectF
 	if err != nil {
 		return errors.Tag(err, "get file")
 	}
+	if m.fm.AllowRedirects() {
+		u, err2 := m.fm.PresignGet(
+			ctx, projectId, fileId, projectFileDownloadURLExpiry, f.Name,
+		)
+		if err2 != nil {
+			return errors.Tag(err2, "sign file download")
+		}
+		response.RedirectURL = u
+		return nil
+	}
 	s, r, err := m.fm.GetReadStreamForProjectFile(ctx, projectId, fileId)
 	if err != nil {
 		return errors.Tag(err, "get filestream")
@@ -42,6 +57,19 @@ func (m *manager) GetProjectFile(ctx context.Context, request *types.GetProjectF
 	return nil
 }
 
+func (m *manager) GetProjectFileMetadata(ctx context.Context, request *types.GetProjectFileMetadataRequest, response *types.GetProjectFileMetadataResponse) error {
+	projectId := request.ProjectId
+	fileId := request.FileId
+	userId := request.Session.User.Id
+	token := request.Session.GetAnonTokenAccess(projectId)
+	f, err := m.pm.GetFile(ctx, projectId, userId, token, fileId)
+	if err != nil {
+		return errors.Tag(err, "get file")
+	}
+	response.FileRef = f.FileRef
+	return nil
+}
+
 func (m *manager) GetProjectFileSize(ctx context.Context, request *types.GetProjectFileSizeRequest, response *types.GetProjectFileSizeResponse) error {
 	projectId := request.ProjectId
 	fileId := request.FileId
@@ -0,0 +1,33 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package editor
+
+import (
+	"context"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func (m *manager) FlushDoc(ctx context.Context, request *types.FlushDocRequest, response *types.FlushDocResponse) error {
+	v, err := m.dum.FlushDoc(ctx, request.ProjectId, request.DocId)
+	if err != nil {
+		return errors.Tag(err, "flush doc")
+	}
+	response.Version = v
+	return nil
+}
@@ -31,3 +31,12 @@ func (m *manager) ListProjectMembers(ctx context.Context, request *types.ListPro
 	response.Members = members
 	return nil
 }
+
+func (m *manager) ListProjectTokenMembers(ctx context.Context, request *types.ListProjectTokenMembersRequest, response *types.ListProjectTokenMembersResponse) error {
+	members, err := m.pm.GetProjectTokenMembers(ctx, request.ProjectId)
+	if err != nil {
+		return errors.Tag(err, "get users")
+	}
+	response.Members = members
+	return nil
+}
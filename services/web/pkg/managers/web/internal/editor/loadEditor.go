@@ -85,7 +85,9 @@ func (m *manager) ProjectEditorPage(ctx context.Context, request *types.ProjectE
 			return errors.Tag(err, "get LoggedInUserJWT")
 		}
 		response.JWTLoggedInUser = s
-		response.SystemMessages, _ = m.smm.GetAllCachedOnly(userId)
+		response.SystemMessages, _ = m.smm.GetForProjectCachedOnly(
+			userId, projectId,
+		)
 	}
 	projectOptions := sharedTypes.ProjectOptions{
 		CompileGroup: p.OwnerFeatures.CompileGroup,
@@ -18,6 +18,7 @@ package editor
 
 import (
 	"context"
+	"log"
 	"time"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
@@ -53,6 +54,27 @@ func (m *manager) SetCompiler(ctx context.Context, request *types.SetCompilerReq
 	return nil
 }
 
+// PropagateDefaultCompiler applies the site default compiler to projects
+// that inherited their compiler from a previous default.
+func (m *manager) PropagateDefaultCompiler(ctx context.Context, dryRun bool, _ time.Time) error {
+	if !m.propagateDefaultCompiler {
+		return nil
+	}
+	ids, err := m.pm.SetInheritedCompiler(ctx, m.defaultCompiler, dryRun)
+	if err != nil {
+		return errors.Tag(err, "update inherited compiler")
+	}
+	if dryRun {
+		log.Printf(
+			"dry-run updating compiler of %d projects to %s",
+			len(ids), m.defaultCompiler,
+		)
+		return nil
+	}
+	m.notifyEditorBulk(ids, sharedTypes.CompilerUpdated, m.defaultCompiler)
+	return nil
+}
+
 func (m *manager) SetImageName(ctx context.Context, request *types.SetImageNameRequest) error {
 	if err := request.ImageName.Validate(); err != nil {
 		return err
@@ -74,21 +96,35 @@ func (m *manager) SetImageName(ctx context.Context, request *types.SetImageNameR
 }
 
 func (m *manager) SetSpellCheckLanguage(ctx context.Context, request *types.SetSpellCheckLanguageRequest) error {
+	l, err := m.pm.SetSpellCheckLanguage(
+		ctx, request.ProjectId, request.UserId, request.SpellCheckLanguage,
+	)
+	if err != nil {
+		return errors.Tag(err, "update spell check language")
+	}
+	go m.notifyEditor(
+		request.ProjectId, sharedTypes.SpellCheckLanguageUpdated, l,
+	)
+	return nil
+}
+
+func (m *manager) SetSpellCheckLanguageForOwnedProjects(ctx context.Context, request *types.SetSpellCheckLanguageForOwnedProjectsRequest) error {
+	if err := request.Session.CheckIsLoggedIn(); err != nil {
+		return err
+	}
 	if request.SpellCheckLanguage == "" {
 		// disable spell checking
 	} else if err := request.SpellCheckLanguage.Validate(); err != nil {
 		return err
 	}
-	err := m.pm.SetSpellCheckLanguage(
-		ctx, request.ProjectId, request.UserId, request.SpellCheckLanguage,
+	ids, err := m.pm.SetSpellCheckLanguageForOwnedProjects(
+		ctx, request.Session.User.Id, request.SpellCheckLanguage,
 	)
 	if err != nil {
 		return errors.Tag(err, "update spell check language")
 	}
-	go m.notifyEditor(
-		request.ProjectId,
-		sharedTypes.SpellCheckLanguageUpdated,
-		request.SpellCheckLanguage,
+	m.notifyEditorBulk(
+		ids, sharedTypes.SpellCheckLanguageUpdated, request.SpellCheckLanguage,
 	)
 	return nil
 }
@@ -132,3 +168,33 @@ func (m *manager) SetContentLocked(ctx context.Context, r *types.SetContentLocke
 	}
 	return nil
 }
+
+func (m *manager) SetMaxUnFlushedAge(ctx context.Context, r *types.SetMaxUnFlushedAgeRequest) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	err := m.pm.SetMaxUnFlushedAge(
+		ctx, r.ProjectId, r.UserId, r.MaxUnFlushedAge(),
+	)
+	if err != nil {
+		return errors.Tag(err, "update max unflushed age")
+	}
+	// Docs pick up the new interval when loading them into redis again.
+	if err = m.dum.FlushAndDeleteProject(ctx, r.ProjectId); err != nil {
+		return errors.Tag(err, "flush project")
+	}
+	return nil
+}
+
+func (m *manager) SetHistoryRetainFor(ctx context.Context, r *types.SetHistoryRetainForRequest) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	err := m.pm.SetHistoryRetainForDays(
+		ctx, r.ProjectId, r.UserId, r.HistoryRetainForDays,
+	)
+	if err != nil {
+		return errors.Tag(err, "update history retention")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package editor
+
+import (
+	"context"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func (m *manager) ListProjectSnippets(ctx context.Context, request *types.ListProjectSnippetsRequest, response *types.ListProjectSnippetsResponse) error {
+	snippets, err := m.pm.ListSnippets(ctx, request.ProjectId)
+	if err != nil {
+		return errors.Tag(err, "list snippets")
+	}
+	response.Snippets = snippets
+	return nil
+}
+
+func (m *manager) SetProjectSnippet(ctx context.Context, request *types.SetProjectSnippetRequest) error {
+	if err := request.Snippet.Validate(); err != nil {
+		return err
+	}
+	err := m.pm.SetSnippet(
+		ctx, request.ProjectId, request.UserId, &request.Snippet,
+	)
+	if err != nil {
+		return errors.Tag(err, "set snippet")
+	}
+	return nil
+}
+
+func (m *manager) DeleteProjectSnippet(ctx context.Context, request *types.DeleteProjectSnippetRequest) error {
+	if err := request.Name.Validate(); err != nil {
+		return err
+	}
+	err := m.pm.DeleteSnippet(
+		ctx, request.ProjectId, request.UserId, request.Name,
+	)
+	if err != nil {
+		return errors.Tag(err, "delete snippet")
+	}
+	return nil
+}
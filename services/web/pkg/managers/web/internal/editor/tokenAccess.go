@@ -33,6 +33,11 @@ func (m *manager) SetPublicAccessLevel(ctx context.Context, request *types.SetPu
 	if err := request.PublicAccessLevel.Validate(); err != nil {
 		return err
 	}
+	if !m.allowedPublicAccessLevels.Allows(request.PublicAccessLevel) {
+		return &errors.ValidationError{
+			Msg: "PublicAccessLevel is disabled on this instance",
+		}
+	}
 
 	if request.PublicAccessLevel == project.TokenBasedAccess {
 		t, err := m.pm.PopulateTokens(ctx, request.ProjectId, request.UserId)
@@ -59,6 +64,19 @@ func (m *manager) SetPublicAccessLevel(ctx context.Context, request *types.SetPu
 	return nil
 }
 
+func (m *manager) SetTokenReadAndWritePrivilegeLevel(ctx context.Context, request *types.SetTokenReadAndWritePrivilegeLevelRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	err := m.pm.SetTokenReadAndWritePrivilegeLevel(
+		ctx, request.ProjectId, request.UserId, request.PrivilegeLevel,
+	)
+	if err != nil {
+		return errors.Tag(err, "update TokenReadAndWritePrivilegeLevel")
+	}
+	return nil
+}
+
 func (m *manager) GetAccessTokens(ctx context.Context, request *types.GetAccessTokensRequest, response *types.GetAccessTokensResponse) error {
 	t := project.Tokens{}
 	err := m.pm.GetAccessTokens(ctx, request.ProjectId, request.UserId, &t)
@@ -68,3 +86,44 @@ func (m *manager) GetAccessTokens(ctx context.Context, request *types.GetAccessT
 	response.Tokens = &t
 	return nil
 }
+
+func (m *manager) PreviewTokenAccess(ctx context.Context, request *types.PreviewTokenAccessRequest, response *types.PreviewTokenAccessResponse) error {
+	request.Preprocess()
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	d, err := m.pm.GetLoadEditorDetails(ctx, request.ProjectId, request.UserId, "")
+	if err != nil {
+		return errors.Tag(err, "get project details")
+	}
+	p := &d.Project
+	if a, err2 := p.GetPrivilegeLevelAuthenticated(); err2 != nil {
+		return err2
+	} else if a.AccessSource != project.AccessSourceOwner {
+		return &errors.NotAuthorizedError{}
+	}
+
+	// Simulate a user that opened the link sharing URL. Anonymous users
+	// get the same access as logged-in token members, unless they need to
+	// log in first.
+	token := p.Tokens.ReadOnly
+	response.RequiresLogin = !m.anonymousTokenAccess
+	if request.PrivilegeLevel == sharedTypes.PrivilegeLevelReadAndWrite {
+		token = p.Tokens.ReadAndWrite
+		response.RequiresLogin = true
+	}
+	a, err := p.GetPrivilegeLevelAnonymous(token)
+	if err != nil {
+		return &errors.UnprocessableEntityError{
+			Msg: "link sharing is not enabled",
+		}
+	}
+	response.AuthorizationDetails = *a
+	response.Editable = a.PrivilegeLevel.IsAtLeast(
+		sharedTypes.PrivilegeLevelReadAndWrite,
+	) && p.Editable
+	response.IsRestrictedUser = a.IsRestrictedUser()
+	response.Project = p.LoadEditorViewPublic
+	response.RootDocPath = p.RootDoc.Path
+	return nil
+}
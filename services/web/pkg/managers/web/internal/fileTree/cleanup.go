@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2022-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -66,6 +66,17 @@ func (m *manager) CleanupStaleFileUploads(ctx context.Context, dryRun bool, star
 			"purging failed for %d file uploads", nFailed,
 		)))
 	}
+	if !dryRun {
+		// Multipart uploads that never completed leave orphaned parts.
+		errAbort := m.fm.AbortStaleUploads(
+			ctx, start.Add(-purgeFileUploadsAfter),
+		)
+		if errAbort != nil {
+			err = errors.Merge(
+				err, errors.Tag(errAbort, "abort stale multipart uploads"),
+			)
+		}
+	}
 	return err
 }
 
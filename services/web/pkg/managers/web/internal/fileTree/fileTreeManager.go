@@ -27,6 +27,7 @@ import (
 	"github.com/das7pad/overleaf-go/services/document-updater/pkg/managers/documentUpdater"
 	"github.com/das7pad/overleaf-go/services/filestore/pkg/managers/filestore"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/projectMetadata"
+	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/uploadScanner"
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
 )
 
@@ -38,6 +39,7 @@ type Manager interface {
 	DeleteFileFromProject(ctx context.Context, request *types.DeleteFileRequest) error
 	DeleteFolderFromProject(ctx context.Context, request *types.DeleteFolderRequest) error
 	GetProjectEntities(ctx context.Context, request *types.GetProjectEntitiesRequest, response *types.GetProjectEntitiesResponse) error
+	GetProjectStatistics(ctx context.Context, request *types.GetProjectStatisticsRequest, response *types.GetProjectStatisticsResponse) error
 	MoveDocInProject(ctx context.Context, request *types.MoveDocRequest) error
 	MoveFileInProject(ctx context.Context, request *types.MoveFileRequest) error
 	MoveFolderInProject(ctx context.Context, request *types.MoveFolderRequest) error
@@ -48,13 +50,17 @@ type Manager interface {
 	UploadFile(ctx context.Context, request *types.UploadFileRequest) error
 }
 
-func New(pm project.Manager, dum documentUpdater.Manager, fm filestore.Manager, editorEvents channel.Writer, pmm projectMetadata.Manager) Manager {
+func New(options *types.Options, pm project.Manager, dum documentUpdater.Manager, fm filestore.Manager, editorEvents channel.Writer, pmm projectMetadata.Manager, us uploadScanner.Scanner) Manager {
 	return &manager{
 		dum:             dum,
 		editorEvents:    editorEvents,
 		fm:              fm,
 		pm:              pm,
 		projectMetadata: pmm,
+		us:              us,
+
+		imageMaxDimension: options.UploadImageDownscaling.MaxDimension,
+		imageMinFileSize:  options.UploadImageDownscaling.MinFileSize,
 	}
 }
 
@@ -64,6 +70,10 @@ type manager struct {
 	fm              filestore.Manager
 	pm              project.Manager
 	projectMetadata projectMetadata.Manager
+	us              uploadScanner.Scanner
+
+	imageMaxDimension int
+	imageMinFileSize  int64
 }
 
 type deleteTreeElementUpdate struct {
@@ -0,0 +1,102 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fileTree
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/imageResize"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+type inMemoryFile struct {
+	*bytes.Reader
+}
+
+func (f inMemoryFile) Close() error {
+	return nil
+}
+
+func (m *manager) downscaleUploadedImage(ctx context.Context, request *types.UploadFileRequest) error {
+	if m.imageMaxDimension == 0 ||
+		request.LinkedFileData != nil ||
+		request.Size < m.imageMinFileSize {
+		return nil
+	}
+	switch sharedTypes.PathName(request.FileName).Type() {
+	case "jpeg", "jpg", "png":
+	default:
+		return nil
+	}
+	if err := request.SeekFileToStart(); err != nil {
+		return err
+	}
+	blob, err := downscaleImage(ctx, request.File, m.imageMaxDimension)
+	if err != nil {
+		return err
+	}
+	if blob != nil && int64(len(blob)) < request.Size {
+		request.File = inMemoryFile{Reader: bytes.NewReader(blob)}
+		request.Size = int64(len(blob))
+	}
+	return request.SeekFileToStart()
+}
+
+// downscaleImage re-encodes a png/jpeg image that exceeds maxDimension in
+// width or height, preserving the aspect ratio. The returned blob is nil
+// when the image does not need downscaling or cannot be decoded.
+func downscaleImage(ctx context.Context, r io.Reader, maxDimension int) ([]byte, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Tag(err, "read image")
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil
+	}
+	if cfg.Width <= maxDimension && cfg.Height <= maxDimension {
+		return nil, nil
+	}
+	dst, format, err := imageResize.Resize(ctx, raw, maxDimension)
+	if err == imageResize.ErrUnsupported {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Tag(err, "resize image")
+	}
+
+	buf := bytes.Buffer{}
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 90})
+	case "png":
+		err = png.Encode(&buf, dst)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Tag(err, "encode image")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,108 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fileTree
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodeTestImage(t *testing.T, format string, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, color.RGBA{
+				R: uint8(x), G: uint8(y), B: 128, A: 255,
+			})
+		}
+	}
+	buf := bytes.Buffer{}
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "png":
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		t.Fatalf("encode %s: %s", format, err)
+	}
+	return buf.Bytes()
+}
+
+func Test_downscaleImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		w      int
+		h      int
+		wantW  int
+		wantH  int
+	}{
+		{name: "small png", format: "png", w: 40, h: 20},
+		{name: "small jpeg", format: "jpeg", w: 40, h: 20},
+		{name: "at limit", format: "png", w: 64, h: 64},
+		{
+			name: "wide png", format: "png", w: 256, h: 128,
+			wantW: 64, wantH: 32,
+		},
+		{
+			name: "tall jpeg", format: "jpeg", w: 100, h: 400,
+			wantW: 16, wantH: 64,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blob := encodeTestImage(t, tt.format, tt.w, tt.h)
+			got, err := downscaleImage(context.Background(), bytes.NewReader(blob), 64)
+			if err != nil {
+				t.Fatalf("downscaleImage() error = %v", err)
+			}
+			if tt.wantW == 0 {
+				if got != nil {
+					t.Errorf("downscaleImage() changed small image")
+				}
+				return
+			}
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(got))
+			if err != nil {
+				t.Fatalf("decode downscaled image: %s", err)
+			}
+			if format != tt.format {
+				t.Errorf("downscaleImage() format = %s, want %s", format, tt.format)
+			}
+			if cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+				t.Errorf(
+					"downscaleImage() = %dx%d, want %dx%d",
+					cfg.Width, cfg.Height, tt.wantW, tt.wantH,
+				)
+			}
+		})
+	}
+}
+
+func Test_downscaleImage_NotAnImage(t *testing.T) {
+	got, err := downscaleImage(context.Background(), bytes.NewReader([]byte("foo")), 64)
+	if err != nil || got != nil {
+		t.Errorf("downscaleImage() = %v, %v, want nil, nil", got, err)
+	}
+}
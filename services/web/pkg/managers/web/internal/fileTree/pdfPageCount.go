@@ -0,0 +1,97 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fileTree
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func (m *manager) countUploadedPDFPages(request *types.UploadFileRequest) (int, error) {
+	if sharedTypes.PathName(request.FileName).Type() != "pdf" {
+		return 0, nil
+	}
+	if err := request.SeekFileToStart(); err != nil {
+		return 0, err
+	}
+	blob, err := io.ReadAll(request.File)
+	if err != nil {
+		return 0, errors.Tag(err, "read pdf")
+	}
+	return countPDFPages(blob), request.SeekFileToStart()
+}
+
+var (
+	pdfPagesCount = regexp.MustCompile(
+		`/Type\s*/Pages\b[^>]*?/Count\s+(\d+)|/Count\s+(\d+)[^>]*?/Type\s*/Pages\b`,
+	)
+	pdfStreamStart = regexp.MustCompile(`<<([^<>]*)>>\s*stream\r?\n`)
+	pdfObjStm      = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	pdfFlateDecode = regexp.MustCompile(`/Filter\s*/FlateDecode\b`)
+)
+
+// maxObjStmSize limits the memory usage for inflating object streams.
+const maxObjStmSize = 16 * 1024 * 1024
+
+// countPDFPages extracts the page count from the page tree of a PDF.
+// The root node of the page tree has the highest page count. It may be
+// stored inside a compressed object stream (PDF 1.5+).
+// A zero page count indicates that the blob is not a PDF or unsupported.
+func countPDFPages(blob []byte) int {
+	if !bytes.Contains(blob[:min(len(blob), 1024)], []byte("%PDF-")) {
+		return 0
+	}
+	n := maxPagesCount(blob)
+	if n > 0 {
+		return n
+	}
+	for _, idx := range pdfStreamStart.FindAllSubmatchIndex(blob, -1) {
+		dict := blob[idx[2]:idx[3]]
+		if !pdfObjStm.Match(dict) || !pdfFlateDecode.Match(dict) {
+			continue
+		}
+		r, err := zlib.NewReader(bytes.NewReader(blob[idx[1]:]))
+		if err != nil {
+			continue
+		}
+		s, _ := io.ReadAll(io.LimitReader(r, maxObjStmSize))
+		_ = r.Close()
+		n = max(n, maxPagesCount(s))
+	}
+	return n
+}
+
+func maxPagesCount(s []byte) int {
+	n := 0
+	for _, m := range pdfPagesCount.FindAllSubmatch(s, -1) {
+		raw := m[1]
+		if len(raw) == 0 {
+			raw = m[2]
+		}
+		if v, err := strconv.Atoi(string(raw)); err == nil && v > n {
+			n = v
+		}
+	}
+	return n
+}
@@ -0,0 +1,105 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fileTree
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+)
+
+const samplePDF = `%PDF-1.4
+1 0 obj
+<< /Type /Catalog /Pages 2 0 R >>
+endobj
+2 0 obj
+<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>
+endobj
+3 0 obj
+<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>
+endobj
+4 0 obj
+<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>
+endobj
+5 0 obj
+<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>
+endobj
+trailer
+<< /Root 1 0 R >>
+%%EOF
+`
+
+func objStmPDF(t *testing.T, content string) []byte {
+	b := bytes.Buffer{}
+	w := zlib.NewWriter(&b)
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatalf("compress: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("compress: %s", err)
+	}
+	blob := []byte("%PDF-1.5\n6 0 obj\n<< /Type /ObjStm /N 2 /First 8 /Filter /FlateDecode >>\nstream\n")
+	blob = append(blob, b.Bytes()...)
+	blob = append(blob, []byte("\nendstream\nendobj\n%%EOF\n")...)
+	return blob
+}
+
+func Test_countPDFPages(t *testing.T) {
+	tests := []struct {
+		name string
+		blob []byte
+		want int
+	}{
+		{
+			name: "plain",
+			blob: []byte(samplePDF),
+			want: 3,
+		},
+		{
+			name: "nested page tree",
+			blob: []byte(`%PDF-1.4
+2 0 obj
+<</Count 7/Kids[3 0 R 4 0 R]/Type/Pages>>
+endobj
+3 0 obj
+<</Type/Pages/Parent 2 0 R/Kids[]/Count 4>>
+endobj
+`),
+			want: 7,
+		},
+		{
+			name: "object stream",
+			blob: objStmPDF(
+				t, "1 0 2 40 << /Type /Catalog /Pages 2 0 R >> "+
+					"<< /Type /Pages /Kids [3 0 R] /Count 12 >>",
+			),
+			want: 12,
+		},
+		{
+			name: "not a pdf",
+			blob: []byte("/Type /Pages /Count 3"),
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countPDFPages(tt.blob); got != tt.want {
+				t.Errorf("countPDFPages() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -20,6 +20,8 @@ import (
 	"context"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
 )
 
@@ -33,6 +35,15 @@ func (m *manager) GetProjectEntities(ctx context.Context, request *types.GetProj
 	if err != nil {
 		return errors.Tag(err, "get project")
 	}
+	if request.IncludeSubType {
+		for i, e := range entities {
+			if e.Type == "file" {
+				entities[i].SubType = project.ClassifyFile(
+					sharedTypes.PathName(e.Path),
+				)
+			}
+		}
+	}
 	response.Entities = entities
 	return nil
 }
@@ -35,6 +35,12 @@ func (m *manager) UploadFile(ctx context.Context, request *types.UploadFileReque
 	if err := request.Validate(); err != nil {
 		return err
 	}
+	if err := m.us.Scan(ctx, request.File); err != nil {
+		return err
+	}
+	if err := request.SeekFileToStart(); err != nil {
+		return err
+	}
 	parentFolderId := request.ParentFolderId
 	projectId := request.ProjectId
 	userId := request.UserId
@@ -60,8 +66,15 @@ func (m *manager) UploadFile(ctx context.Context, request *types.UploadFileReque
 		}
 	}
 	var hash sharedTypes.Hash
+	var pageCount int
 	if !isDoc {
+		if err := m.downscaleUploadedImage(ctx, request); err != nil {
+			return errors.Tag(err, "downscale image")
+		}
 		var err error
+		if pageCount, err = m.countUploadedPDFPages(request); err != nil {
+			return errors.Tag(err, "count pdf pages")
+		}
 		if hash, err = HashFile(request.File, request.Size); err != nil {
 			return err
 		}
@@ -115,6 +128,7 @@ func (m *manager) UploadFile(ctx context.Context, request *types.UploadFileReque
 		file := project.NewFileRef(request.FileName, hash, request.Size)
 		file.CreatedAt = time.Now().Truncate(time.Microsecond)
 		file.LinkedFileData = request.LinkedFileData
+		file.PageCount = pageCount
 		if err = file.Id.Populate(); err != nil {
 			return err
 		}
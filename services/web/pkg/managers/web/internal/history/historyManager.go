@@ -18,9 +18,9 @@ package history
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/redis/go-redis/v9"
 
 	"github.com/das7pad/overleaf-go/services/document-updater/pkg/managers/documentUpdater"
 	"github.com/das7pad/overleaf-go/services/track-changes/pkg/managers/trackChanges"
@@ -31,8 +31,25 @@ type Manager interface {
 	GetProjectHistoryUpdates(ctx context.Context, request *types.GetProjectHistoryUpdatesRequest, response *types.GetProjectHistoryUpdatesResponse) error
 	GetDocDiff(ctx context.Context, request *types.GetDocDiffRequest, response *types.GetDocDiffResponse) error
 	RestoreDocVersion(ctx context.Context, request *types.RestoreDocVersionRequest) error
+	RestoreProjectToVersion(ctx context.Context, request *types.RestoreProjectToVersionRequest) error
+	PruneDocHistory(ctx context.Context, dryRun bool, start time.Time) error
 }
 
-func New(db *pgxpool.Pool, client redis.UniversalClient, dum documentUpdater.Manager) (Manager, error) {
-	return trackChanges.New(db, client, dum)
+func New(db *pgxpool.Pool, dum documentUpdater.Manager) (Manager, error) {
+	tcm, err := trackChanges.New(db, dum)
+	if err != nil {
+		return nil, err
+	}
+	return &manager{trackChangesManager: tcm, dum: dum}, nil
+}
+
+type trackChangesManager = trackChanges.Manager
+
+type manager struct {
+	trackChangesManager
+	dum documentUpdater.Manager
+}
+
+func (m *manager) PruneDocHistory(ctx context.Context, dryRun bool, start time.Time) error {
+	return m.dum.PruneDocHistory(ctx, dryRun, start)
 }
@@ -0,0 +1,113 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package imageResize
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+)
+
+const (
+	// MaxDecodePixels limits the memory usage for decoding an image.
+	MaxDecodePixels = 32_000_000
+
+	// MaxConcurrentDecodes limits the memory usage across all the decoding.
+	MaxConcurrentDecodes = 4
+)
+
+var ErrUnsupported = errors.New("unsupported image")
+
+var decodeSlots = make(chan struct{}, MaxConcurrentDecodes)
+
+// Resize decodes the image in raw, scales it down to fit into maxDimension
+// and applies the EXIF orientation of jpeg images. It waits for a free
+// decoding slot, which bounds the memory usage of concurrent calls.
+func Resize(ctx context.Context, raw []byte, maxDimension int) (*image.RGBA, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, "", ErrUnsupported
+	}
+	if cfg.Width*cfg.Height > MaxDecodePixels {
+		return nil, "", ErrUnsupported
+	}
+	select {
+	case decodeSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+	defer func() { <-decodeSlots }()
+
+	src, format, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, "", ErrUnsupported
+	}
+	w, h := Fit(cfg.Width, cfg.Height, maxDimension)
+	dst := Box(src, w, h)
+	if format == "jpeg" {
+		dst = orient(dst, readOrientation(raw))
+	}
+	return dst, format, nil
+}
+
+// Fit scales w and h down to fit into maxDimension, preserving the aspect
+// ratio.
+func Fit(w, h, maxDimension int) (int, int) {
+	if w <= maxDimension && h <= maxDimension {
+		return w, h
+	}
+	if w >= h {
+		return maxDimension, max(1, h*maxDimension/w)
+	}
+	return max(1, w*maxDimension/h), maxDimension
+}
+
+// Box downscales src by averaging the source pixels per target pixel.
+func Box(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*sh/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*sh/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*sw/w
+			x1 := max(x0+1, b.Min.X+(x+1)*sw/w)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(bl / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
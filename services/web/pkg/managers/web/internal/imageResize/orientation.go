@@ -0,0 +1,127 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package imageResize
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+const exifOrientationTag = 0x0112
+
+// readOrientation returns the EXIF orientation of a jpeg image, 1 if absent.
+func readOrientation(raw []byte) int {
+	if len(raw) < 4 || raw[0] != 0xFF || raw[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(raw); {
+		if raw[i] != 0xFF {
+			return 1
+		}
+		marker := raw[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// The image data starts, the metadata segments are done.
+			return 1
+		}
+		n := int(binary.BigEndian.Uint16(raw[i+2:]))
+		if n < 2 || i+2+n > len(raw) {
+			return 1
+		}
+		if marker == 0xE1 {
+			if o := parseExifOrientation(raw[i+4 : i+2+n]); o != 0 {
+				return o
+			}
+		}
+		i += 2 + n
+	}
+	return 1
+}
+
+// parseExifOrientation reads the orientation from the first IFD of an
+// APP1/Exif segment. It returns 0 when absent or invalid.
+func parseExifOrientation(b []byte) int {
+	if len(b) < 14 || string(b[:6]) != "Exif\x00\x00" {
+		return 0
+	}
+	t := b[6:]
+	var bo binary.ByteOrder
+	switch string(t[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(bo.Uint32(t[4:]))
+	if ifd < 8 || ifd+2 > len(t) {
+		return 0
+	}
+	n := int(bo.Uint16(t[ifd:]))
+	for j := 0; j < n; j++ {
+		e := ifd + 2 + j*12
+		if e+12 > len(t) {
+			return 0
+		}
+		if bo.Uint16(t[e:]) != exifOrientationTag {
+			continue
+		}
+		o := int(bo.Uint16(t[e+8:]))
+		if o < 1 || o > 8 {
+			return 0
+		}
+		return o
+	}
+	return 0
+}
+
+// orient applies the EXIF orientation o to img, which starts at 0,0.
+func orient(img *image.RGBA, o int) *image.RGBA {
+	if o <= 1 || o > 8 {
+		return img
+	}
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	dw, dh := w, h
+	if o >= 5 {
+		// Orientations 5-8 swap width and height.
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch o {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,103 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package imageResize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func withExifOrientation(t *testing.T, blob []byte, o uint16) []byte {
+	t.Helper()
+	tiff := make([]byte, 8+2+12+4)
+	copy(tiff, "MM")
+	binary.BigEndian.PutUint16(tiff[2:], 42)
+	binary.BigEndian.PutUint32(tiff[4:], 8)
+	binary.BigEndian.PutUint16(tiff[8:], 1)
+	binary.BigEndian.PutUint16(tiff[10:], exifOrientationTag)
+	binary.BigEndian.PutUint16(tiff[12:], 3)
+	binary.BigEndian.PutUint32(tiff[14:], 1)
+	binary.BigEndian.PutUint16(tiff[18:], o)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	out := append([]byte{}, blob[:2]...)
+	out = append(out, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(2+len(payload)))
+	out = append(out, payload...)
+	return append(out, blob[2:]...)
+}
+
+func Test_readOrientation(t *testing.T) {
+	buf := bytes.Buffer{}
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("encode jpeg: %s", err)
+	}
+	blob := buf.Bytes()
+	if got := readOrientation(blob); got != 1 {
+		t.Errorf("readOrientation() without exif = %d, want 1", got)
+	}
+	rotated := withExifOrientation(t, blob, 6)
+	if got := readOrientation(rotated); got != 6 {
+		t.Errorf("readOrientation() = %d, want 6", got)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(rotated)); err != nil {
+		t.Errorf("decode jpeg with exif: %s", err)
+	}
+	if got := readOrientation([]byte("foo")); got != 1 {
+		t.Errorf("readOrientation() of garbage = %d, want 1", got)
+	}
+}
+
+func Test_orient(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	marker := color.RGBA{R: 255, A: 255}
+	// Mark the bottom left corner.
+	img.SetRGBA(0, 1, marker)
+
+	tests := []struct {
+		o    int
+		w, h int
+		x, y int
+	}{
+		{o: 1, w: 3, h: 2, x: 0, y: 1},
+		{o: 2, w: 3, h: 2, x: 2, y: 1},
+		{o: 3, w: 3, h: 2, x: 2, y: 0},
+		{o: 4, w: 3, h: 2, x: 0, y: 0},
+		{o: 5, w: 2, h: 3, x: 1, y: 0},
+		{o: 6, w: 2, h: 3, x: 0, y: 0},
+		{o: 7, w: 2, h: 3, x: 0, y: 2},
+		{o: 8, w: 2, h: 3, x: 1, y: 2},
+	}
+	for _, tt := range tests {
+		got := orient(img, tt.o)
+		if got.Rect.Dx() != tt.w || got.Rect.Dy() != tt.h {
+			t.Errorf(
+				"orient(%d) = %dx%d, want %dx%d",
+				tt.o, got.Rect.Dx(), got.Rect.Dy(), tt.w, tt.h,
+			)
+			continue
+		}
+		if got.RGBAAt(tt.x, tt.y) != marker {
+			t.Errorf("orient(%d): marker not at %d,%d", tt.o, tt.x, tt.y)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package openInOverleaf
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+// gitArchiveURL maps a repository URL on GitHub or GitLab to the URL of a
+// zip archive of the given ref. The proxy does not follow redirects, hence
+// the direct codeload URL for GitHub.
+func gitArchiveURL(u *sharedTypes.URL, ref string) (*sharedTypes.URL, error) {
+	if u.Scheme != "https" {
+		return nil, &errors.ValidationError{Msg: "git url must use https"}
+	}
+	p := strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/")
+	segments := strings.Split(p, "/")
+	for _, s := range segments {
+		if s == "" || s == "." || s == ".." || s == "-" {
+			return nil, &errors.ValidationError{Msg: "invalid repository path"}
+		}
+	}
+	repo := segments[len(segments)-1]
+	escapedRef := (&url.URL{Path: ref}).EscapedPath()
+	var raw string
+	switch strings.ToLower(u.Hostname()) {
+	case "github.com", "www.github.com":
+		if len(segments) != 2 {
+			return nil, &errors.ValidationError{
+				Msg: "expected github url in the form /owner/repo",
+			}
+		}
+		raw = "https://codeload.github.com/" + p + "/zip/" + escapedRef
+	case "gitlab.com":
+		if len(segments) < 2 {
+			return nil, &errors.ValidationError{
+				Msg: "expected gitlab url in the form /group/repo",
+			}
+		}
+		archiveName := repo + "-" + strings.ReplaceAll(ref, "/", "-")
+		raw = "https://gitlab.com/" + p + "/-/archive/" + escapedRef + "/" +
+			(&url.URL{Path: archiveName}).EscapedPath() + ".zip"
+	default:
+		return nil, &errors.ValidationError{
+			Msg: "unsupported git host, expected github.com or gitlab.com",
+		}
+	}
+	return sharedTypes.ParseAndValidateURL(raw)
+}
+
+func (m *manager) CreateFromGitURL(ctx context.Context, request *types.CreateProjectFromGitURLRequest, response *types.CreateProjectResponse) error {
+	if !m.gitImportEnabled {
+		return &errors.UnprocessableEntityError{
+			Msg: "import from git is disabled",
+		}
+	}
+	if err := request.Session.CheckIsLoggedIn(); err != nil {
+		return err
+	}
+	request.Preprocess()
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	src, err := gitArchiveURL(request.URL, request.Ref)
+	if err != nil {
+		return err
+	}
+
+	f, err := m.proxy.DownloadFile(ctx, src)
+	if err != nil {
+		return errors.Tag(err, "download archive")
+	}
+	defer f.Cleanup()
+
+	d := f.ToUploadDetails()
+	// The name of the download depends on the ref, use a stable one.
+	d.FileName = "archive.zip"
+	return m.pum.CreateFromZip(ctx, &types.CreateProjectFromZipRequest{
+		Compiler:       request.Compiler,
+		WithSession:    types.WithSession{Session: request.Session},
+		HasDefaultName: request.HasDefaultName,
+		Name:           request.Name,
+		UploadDetails:  d,
+	}, response)
+}
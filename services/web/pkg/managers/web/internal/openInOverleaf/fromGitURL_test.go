@@ -0,0 +1,98 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package openInOverleaf
+
+import (
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+func Test_gitArchiveURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "github",
+			url:  "https://github.com/foo/bar",
+			ref:  "HEAD",
+			want: "https://codeload.github.com/foo/bar/zip/HEAD",
+		},
+		{
+			name: "github clone url",
+			url:  "https://github.com/foo/bar.git",
+			ref:  "v1.0",
+			want: "https://codeload.github.com/foo/bar/zip/v1.0",
+		},
+		{
+			name: "gitlab subgroup",
+			url:  "https://gitlab.com/foo/baz/bar/",
+			ref:  "feature/x",
+			want: "https://gitlab.com/foo/baz/bar/-/archive/feature/x/bar-feature-x.zip",
+		},
+		{
+			name:    "github nested",
+			url:     "https://github.com/foo/bar/tree/main",
+			ref:     "HEAD",
+			wantErr: true,
+		},
+		{
+			name:    "gitlab too short",
+			url:     "https://gitlab.com/foo",
+			ref:     "HEAD",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported host",
+			url:     "https://example.com/foo/bar",
+			ref:     "HEAD",
+			wantErr: true,
+		},
+		{
+			name:    "plain http",
+			url:     "http://github.com/foo/bar",
+			ref:     "HEAD",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := sharedTypes.ParseAndValidateURL(tt.url)
+			if err != nil {
+				t.Fatalf("parse url: %s", err)
+			}
+			got, err := gitArchiveURL(u, tt.ref)
+			if tt.wantErr {
+				if !errors.IsValidationError(err) {
+					t.Fatalf("gitArchiveURL() error = %v, want validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("gitArchiveURL() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("gitArchiveURL() = %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
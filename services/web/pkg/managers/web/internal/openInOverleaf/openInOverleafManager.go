@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -27,6 +27,7 @@ import (
 )
 
 type Manager interface {
+	CreateFromGitURL(ctx context.Context, request *types.CreateProjectFromGitURLRequest, response *types.CreateProjectResponse) error
 	OpenInOverleaf(ctx context.Context, request *types.OpenInOverleafRequest, response *types.CreateProjectResponse) error
 	OpenInOverleafGatewayPage(ctx context.Context, request *types.OpenInOverleafGatewayPageRequest, response *types.OpenInOverleafGatewayPageResponse) error
 	OpenInOverleafDocumentationPage(ctx context.Context, request *types.OpenInOverleafDocumentationPageRequest, response *types.OpenInOverleafDocumentationPageResponse) error
@@ -36,18 +37,20 @@ func New(options *types.Options, ps *templates.PublicSettings, proxy linkedURLPr
 	raw := options.SiteURL.WithPath("/learn")
 	learnURL := sharedTypes.Snapshot(raw.String())
 	return &manager{
-		learnURL: learnURL,
-		proxy:    proxy,
-		ps:       ps,
-		pum:      pum,
+		gitImportEnabled: options.GitImportEnabled,
+		learnURL:         learnURL,
+		proxy:            proxy,
+		ps:               ps,
+		pum:              pum,
 	}
 }
 
 type manager struct {
-	learnURL sharedTypes.Snapshot
-	proxy    linkedURLProxy.Manager
-	ps       *templates.PublicSettings
-	pum      projectUpload.Manager
+	gitImportEnabled bool
+	learnURL         sharedTypes.Snapshot
+	proxy            linkedURLProxy.Manager
+	ps               *templates.PublicSettings
+	pum              projectUpload.Manager
 }
 
 func (m *manager) OpenInOverleaf(ctx context.Context, request *types.OpenInOverleafRequest, response *types.CreateProjectResponse) error {
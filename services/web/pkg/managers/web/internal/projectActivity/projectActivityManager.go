@@ -0,0 +1,168 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectActivity
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/message"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/history"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+type Manager interface {
+	GetProjectActivity(ctx context.Context, request *types.GetProjectActivityRequest, response *types.GetProjectActivityResponse) error
+}
+
+func New(hm history.Manager, mm message.Manager, pm project.Manager) Manager {
+	return &manager{
+		hm: hm,
+		mm: mm,
+		pm: pm,
+	}
+}
+
+type manager struct {
+	hm history.Manager
+	mm message.Manager
+	pm project.Manager
+}
+
+const activityPageSize = 20
+
+// GetProjectActivity merges the sources newest first, ordered by timestamp
+// and then id. The (timestamp, id) cursor of the last activity resumes the
+// feed without skipping activities that share a timestamp.
+func (m *manager) GetProjectActivity(ctx context.Context, r *types.GetProjectActivityRequest, res *types.GetProjectActivityResponse) error {
+	before := r.Before
+	if before == 0 {
+		before = sharedTypes.Timestamp(time.Now().UnixMilli())
+	}
+	// Edits have no id and sort last, the history has an exclusive bound.
+	beforeEdits := before
+	if r.BeforeId != (sharedTypes.UUID{}) {
+		beforeEdits++
+	}
+
+	eg, pCtx := errgroup.WithContext(ctx)
+	updates := types.GetProjectHistoryUpdatesResponse{}
+	eg.Go(func() error {
+		err := m.hm.GetProjectHistoryUpdates(
+			pCtx, &types.GetProjectHistoryUpdatesRequest{
+				ProjectId: r.ProjectId,
+				UserId:    r.UserId,
+				Before:    beforeEdits,
+			}, &updates,
+		)
+		if err != nil {
+			return errors.Tag(err, "get history")
+		}
+		return nil
+	})
+	var messages []message.Message
+	eg.Go(func() error {
+		err := m.mm.GetGlobalMessagesBefore(
+			pCtx, r.ProjectId, activityPageSize, before.ToTime(), r.BeforeId,
+			&messages,
+		)
+		if err != nil {
+			return errors.Tag(err, "get messages")
+		}
+		return nil
+	})
+	var auditLog []project.AuditLogEntry
+	eg.Go(func() error {
+		var err error
+		auditLog, err = m.pm.GetAuditLog(
+			pCtx, r.ProjectId, before.ToTime(), r.BeforeId, activityPageSize,
+		)
+		if err != nil {
+			return errors.Tag(err, "get audit log")
+		}
+		return nil
+	})
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	a := make(
+		[]types.ProjectActivity, 0,
+		len(updates.Updates)+len(messages)+len(auditLog),
+	)
+	for i := range updates.Updates {
+		a = append(a, types.ProjectActivity{
+			Kind:      types.ProjectActivityEdit,
+			Timestamp: updates.Updates[i].Meta.EndTS,
+			Edit:      &updates.Updates[i],
+		})
+	}
+	for i := range messages {
+		a = append(a, types.ProjectActivity{
+			Kind:        types.ProjectActivityChatMessage,
+			Timestamp:   sharedTypes.Timestamp(messages[i].CreatedAt.UnixMilli()),
+			ChatMessage: &messages[i],
+		})
+	}
+	for i := range auditLog {
+		a = append(a, types.ProjectActivity{
+			Kind:      types.ProjectActivityAudit,
+			Timestamp: sharedTypes.Timestamp(auditLog[i].CreatedAt.UnixMilli()),
+			Audit:     &auditLog[i],
+		})
+	}
+	sort.SliceStable(a, func(i, j int) bool {
+		if a[i].Timestamp != a[j].Timestamp {
+			return a[i].Timestamp > a[j].Timestamp
+		}
+		x, y := a[i].Id(), a[j].Id()
+		return bytes.Compare(x[:], y[:]) > 0
+	})
+
+	// Any of the sources may have more entries.
+	hasMore := updates.NextBeforeTimestamp != 0 ||
+		len(messages) == activityPageSize ||
+		len(auditLog) == activityPageSize
+	if n := len(a); n > activityPageSize {
+		// The next page cannot tell apart edits with the same timestamp.
+		last := a[activityPageSize-1]
+		n = activityPageSize
+		for last.Kind == types.ProjectActivityEdit && n < len(a) &&
+			a[n].Kind == types.ProjectActivityEdit &&
+			a[n].Timestamp == last.Timestamp {
+			n++
+		}
+		a = a[:n]
+		hasMore = true
+	}
+	if hasMore && len(a) > 0 {
+		last := &a[len(a)-1]
+		res.NextBeforeTimestamp = last.Timestamp
+		if id := last.Id(); id != (sharedTypes.UUID{}) {
+			res.NextBeforeId = &id
+		}
+	}
+	res.Activities = a
+	return nil
+}
@@ -0,0 +1,153 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectDownload
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+type archiveWriter interface {
+	CreateFolder(path string) error
+	// CreateFile adds a file entry. A negative size signals that the size
+	// is not known upfront.
+	CreateFile(path string, size int64) (io.Writer, error)
+	Close() error
+}
+
+func newArchiveWriter(format types.ArchiveFormat, w io.Writer) archiveWriter {
+	if format == types.ArchiveFormatTarGz {
+		gz := gzip.NewWriter(w)
+		return &tarGzWriter{gz: gz, tw: tar.NewWriter(gz), now: time.Now()}
+	}
+	return &zipWriter{z: zip.NewWriter(w)}
+}
+
+type zipWriter struct {
+	z *zip.Writer
+}
+
+func (a *zipWriter) CreateFolder(path string) error {
+	_, err := a.z.Create(path + "/")
+	return err
+}
+
+func (a *zipWriter) CreateFile(path string, _ int64) (io.Writer, error) {
+	return a.z.Create(path)
+}
+
+func (a *zipWriter) Close() error {
+	return a.z.Close()
+}
+
+type tarGzWriter struct {
+	gz  *gzip.Writer
+	tw  *tar.Writer
+	now time.Time
+
+	// pending buffers a file of unknown size, as tar needs it upfront.
+	pending     *os.File
+	pendingPath string
+}
+
+func (a *tarGzWriter) CreateFolder(path string) error {
+	if err := a.flushPending(); err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	return a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     path + "/",
+		Mode:     0o755,
+		ModTime:  a.now,
+	})
+}
+
+func (a *tarGzWriter) CreateFile(path string, size int64) (io.Writer, error) {
+	if err := a.flushPending(); err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		f, err := os.CreateTemp("", "tar-entry")
+		if err != nil {
+			return nil, errors.Tag(err, "create buffer")
+		}
+		a.pending = f
+		a.pendingPath = path
+		return f, nil
+	}
+	err := a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  a.now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.tw, nil
+}
+
+func (a *tarGzWriter) flushPending() error {
+	f := a.pending
+	if f == nil {
+		return nil
+	}
+	a.pending = nil
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return errors.Tag(err, "get buffer size")
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return errors.Tag(err, "seek buffer")
+	}
+	w, err := a.CreateFile(a.pendingPath, size)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, f); err != nil {
+		return errors.Tag(err, "copy buffer")
+	}
+	return nil
+}
+
+func (a *tarGzWriter) Close() error {
+	errFlush := a.flushPending()
+	errTar := a.tw.Close()
+	errGz := a.gz.Close()
+	if errFlush != nil {
+		return errFlush
+	}
+	if errTar != nil {
+		return errTar
+	}
+	return errGz
+}
@@ -0,0 +1,90 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectDownload
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func Test_tarGzWriter(t *testing.T) {
+	buf := bytes.Buffer{}
+	a := newArchiveWriter(types.ArchiveFormatTarGz, &buf)
+	if err := a.CreateFolder(""); err != nil {
+		t.Fatal(err)
+	}
+	w, err := a.CreateFile("main.tex", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("main"))
+	if err = a.CreateFolder("sub"); err != nil {
+		t.Fatal(err)
+	}
+	if w, err = a.CreateFile("sub/unknown.bin", -1); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("unknown size"))
+	if err = a.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("open gzip: %s", err)
+	}
+	tr := tar.NewReader(gz)
+	got := make(map[string]string)
+	var names []string
+	for {
+		h, err2 := tr.Next()
+		if err2 == io.EOF {
+			break
+		}
+		if err2 != nil {
+			t.Fatalf("read tar: %s", err2)
+		}
+		names = append(names, h.Name)
+		blob, _ := io.ReadAll(tr)
+		got[h.Name] = string(blob)
+	}
+	wantNames := []string{"main.tex", "sub/", "sub/unknown.bin"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("entries = %v, want %v", names, wantNames)
+	}
+	if got["main.tex"] != "main" || got["sub/unknown.bin"] != "unknown size" {
+		t.Errorf("contents = %v", got)
+	}
+}
+
+func Test_tarGzWriter_SizeMismatch(t *testing.T) {
+	a := newArchiveWriter(types.ArchiveFormatTarGz, io.Discard)
+	w, err := a.CreateFile("main.tex", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("short"))
+	if err = a.Close(); err == nil {
+		t.Errorf("Close() expected error for short entry")
+	}
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,7 +17,6 @@
 package projectDownload
 
 import (
-	"archive/zip"
 	"context"
 	"fmt"
 	"io"
@@ -28,6 +27,18 @@ import (
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
 )
 
+type bufferedProjectArchive struct {
+	err      error
+	filename sharedTypes.Filename
+	fsPath   string
+}
+
+func (b *bufferedProjectArchive) Cleanup() {
+	if b.fsPath != "" {
+		_ = os.Remove(b.fsPath)
+	}
+}
+
 func (m *manager) CreateMultiProjectZIP(ctx context.Context, request *types.CreateMultiProjectZIPRequest, response *types.CreateProjectZIPResponse) error {
 	if err := request.Validate(); err != nil {
 		return err
@@ -38,33 +49,107 @@ func (m *manager) CreateMultiProjectZIP(ctx context.Context, request *types.Crea
 		return errors.Tag(err, "create buffer")
 	}
 	response.FSPath = buffer.Name()
-	f := zip.NewWriter(buffer)
+	f := newArchiveWriter(request.Format, buffer)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]chan bufferedProjectArchive, len(request.ProjectIds))
+	for i := range results {
+		results[i] = make(chan bufferedProjectArchive, 1)
+	}
+	go m.bufferProjectArchives(ctx, request, results)
 
-	for _, projectId := range request.ProjectIds {
-		err = m.createProjectZIP(ctx, &types.CreateProjectZIPRequest{
-			WithSession: types.WithSession{Session: request.Session},
-			ProjectId:   projectId,
-		}, func(filename sharedTypes.Filename) (io.Writer, error) {
-			return f.Create(string(filename))
-		})
-		if err != nil {
-			err = errors.Tag(err, "project: "+projectId.String())
-			break
+	// Consume all the results for cleaning up all the buffers.
+	for i, projectId := range request.ProjectIds {
+		b := <-results[i]
+		if err == nil {
+			if b.err != nil {
+				err = b.err
+			} else {
+				err = copyProjectArchive(f, &b)
+			}
+			if err != nil {
+				err = errors.Tag(err, "project: "+projectId.String())
+				cancel()
+			}
 		}
+		b.Cleanup()
 	}
-	errCloseZIP := f.Close()
+	errCloseArchive := f.Close()
 	errCloseBuffer := buffer.Close()
 	if err != nil {
 		return err
 	}
-	if errCloseZIP != nil {
-		return errors.Tag(errCloseZIP, "close zip")
+	if errCloseArchive != nil {
+		return errors.Tag(errCloseArchive, "close archive")
 	}
 	if errCloseBuffer != nil {
 		return errors.Tag(errCloseBuffer, "close buffer")
 	}
 	response.Filename = sharedTypes.Filename(fmt.Sprintf(
-		"Overleaf Projects (%d items).zip", len(request.ProjectIds),
+		"Overleaf Projects (%d items)%s",
+		len(request.ProjectIds), request.Format.Extension(),
 	))
 	return nil
 }
+
+// bufferProjectArchives fetches up to multiProjectConcurrency projects in
+// parallel. It sends exactly one result per project, also after cancelling.
+func (m *manager) bufferProjectArchives(ctx context.Context, request *types.CreateMultiProjectZIPRequest, results []chan bufferedProjectArchive) {
+	sem := make(chan struct{}, m.multiProjectConcurrency)
+	for i, projectId := range request.ProjectIds {
+		select {
+		case <-ctx.Done():
+			results[i] <- bufferedProjectArchive{err: ctx.Err()}
+			continue
+		case sem <- struct{}{}:
+		}
+		go func() {
+			defer func() { <-sem }()
+			results[i] <- m.bufferProjectArchive(ctx, &types.CreateProjectZIPRequest{
+				WithSession: types.WithSession{Session: request.Session},
+				Format:      request.Format,
+				ProjectId:   projectId,
+			})
+		}()
+	}
+}
+
+func (m *manager) bufferProjectArchive(ctx context.Context, request *types.CreateProjectZIPRequest) bufferedProjectArchive {
+	buffer, err := os.CreateTemp("", "zip-download-project")
+	if err != nil {
+		return bufferedProjectArchive{err: errors.Tag(err, "create buffer")}
+	}
+	b := bufferedProjectArchive{fsPath: buffer.Name()}
+	errCreate := m.createProjectArchive(ctx, request, func(filename sharedTypes.Filename) (io.Writer, error) {
+		b.filename = filename
+		return buffer, nil
+	})
+	errClose := buffer.Close()
+	if errCreate != nil {
+		b.err = errCreate
+	} else if errClose != nil {
+		b.err = errors.Tag(errClose, "close buffer")
+	}
+	return b
+}
+
+func copyProjectArchive(a archiveWriter, b *bufferedProjectArchive) error {
+	f, err := os.Open(b.fsPath)
+	if err != nil {
+		return errors.Tag(err, "open buffer")
+	}
+	defer func() { _ = f.Close() }()
+	s, err := f.Stat()
+	if err != nil {
+		return errors.Tag(err, "stat buffer")
+	}
+	w, err := a.CreateFile(string(b.filename), s.Size())
+	if err != nil {
+		return errors.Tag(err, "create file: "+string(b.filename))
+	}
+	if _, err = io.Copy(w, f); err != nil {
+		return errors.Tag(err, "write file: "+string(b.filename))
+	}
+	return nil
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -28,18 +28,24 @@ import (
 type Manager interface {
 	CreateMultiProjectZIP(ctx context.Context, request *types.CreateMultiProjectZIPRequest, response *types.CreateProjectZIPResponse) error
 	CreateProjectZIP(ctx context.Context, request *types.CreateProjectZIPRequest, response *types.CreateProjectZIPResponse) error
+	StreamProjectZIP(ctx context.Context, request *types.StreamProjectZIPRequest) error
 }
 
-func New(pm project.Manager, dum documentUpdater.Manager, fm filestore.Manager) Manager {
+func New(options types.ProjectDownloadOptions, pm project.Manager, dum documentUpdater.Manager, fm filestore.Manager) Manager {
+	if options.MultiProjectConcurrency == 0 {
+		options.MultiProjectConcurrency = 4
+	}
 	return &manager{
-		dum: dum,
-		fm:  fm,
-		pm:  pm,
+		dum:                     dum,
+		fm:                      fm,
+		multiProjectConcurrency: options.MultiProjectConcurrency,
+		pm:                      pm,
 	}
 }
 
 type manager struct {
-	dum documentUpdater.Manager
-	fm  filestore.Manager
-	pm  project.Manager
+	dum                     documentUpdater.Manager
+	fm                      filestore.Manager
+	multiProjectConcurrency int
+	pm                      project.Manager
 }
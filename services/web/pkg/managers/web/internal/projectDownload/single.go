@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,7 +17,6 @@
 package projectDownload
 
 import (
-	"archive/zip"
 	"context"
 	"io"
 	"os"
@@ -35,7 +34,7 @@ func (m *manager) CreateProjectZIP(ctx context.Context, request *types.CreatePro
 	}
 	response.FSPath = buffer.Name()
 
-	errCreate := m.createProjectZIP(ctx, request, func(filename sharedTypes.Filename) (io.Writer, error) {
+	errCreate := m.createProjectArchive(ctx, request, func(filename sharedTypes.Filename) (io.Writer, error) {
 		response.Filename = filename
 		return buffer, nil
 	})
@@ -50,9 +49,17 @@ func (m *manager) CreateProjectZIP(ctx context.Context, request *types.CreatePro
 	return nil
 }
 
+// StreamProjectZIP writes the archive directly into the given writer,
+// fetching files from the filestore on the fly.
+func (m *manager) StreamProjectZIP(ctx context.Context, request *types.StreamProjectZIPRequest) error {
+	return m.createProjectArchive(
+		ctx, &request.CreateProjectZIPRequest, request.GetWriter,
+	)
+}
+
 type bufferGetter func(filename sharedTypes.Filename) (io.Writer, error)
 
-func (m *manager) getProjectForZip(ctx context.Context, projectId, userId sharedTypes.UUID, token project.AccessToken) (*project.ForZip, error) {
+func (m *manager) getProjectForZip(ctx context.Context, projectId, userId sharedTypes.UUID, token project.AccessToken, prefix sharedTypes.DirName) (*project.ForZip, error) {
 	_, err := m.pm.GetAuthorizationDetails(ctx, projectId, userId, token)
 	if err != nil {
 		return nil, errors.Tag(err, "check auth")
@@ -62,37 +69,44 @@ func (m *manager) getProjectForZip(ctx context.Context, projectId, userId shared
 		return nil, errors.Tag(err, "flush project")
 	}
 
-	p, err := m.pm.GetForZip(ctx, projectId, userId, token)
+	p, err := m.pm.GetForZip(ctx, projectId, userId, token, prefix)
 	if err != nil {
 		return nil, errors.Tag(err, "get project")
 	}
 	return p, nil
 }
 
-func (m *manager) createProjectZIP(ctx context.Context, request *types.CreateProjectZIPRequest, getBuffer bufferGetter) error {
+func (m *manager) createProjectArchive(ctx context.Context, request *types.CreateProjectZIPRequest, getBuffer bufferGetter) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
 	userId := request.Session.User.Id
 	projectId := request.ProjectId
 	token := request.Session.GetAnonTokenAccess(projectId)
-	p, errGetProject := m.getProjectForZip(ctx, projectId, userId, token)
+	p, errGetProject := m.getProjectForZip(
+		ctx, projectId, userId, token, request.Path,
+	)
 	if errGetProject != nil {
 		return errGetProject
 	}
 
-	buffer, errBuff := getBuffer(sharedTypes.Filename(string(p.Name) + ".zip"))
+	buffer, errBuff := getBuffer(sharedTypes.Filename(
+		string(p.Name) + request.Format.Extension(),
+	))
 	if errBuff != nil {
 		return errors.Tag(errBuff, "get buffer")
 	}
-	z := zip.NewWriter(buffer)
+	a := newArchiveWriter(request.Format, buffer)
 
 	t := p.GetRootFolder()
 	err := t.WalkFolders(func(f *project.Folder) error {
-		if _, err := z.Create(f.Path.String() + "/"); err != nil {
+		if err := a.CreateFolder(f.Path.String()); err != nil {
 			return errors.Tag(err, "create folder: "+f.Path.String())
 		}
 
 		for _, d := range f.Docs {
 			path := f.Path.Join(d.Name).String()
-			w, err := z.Create(path)
+			w, err := a.CreateFile(path, int64(len(d.Snapshot)))
 			if err != nil {
 				return errors.Tag(err, "create doc: "+path)
 			}
@@ -103,16 +117,17 @@ func (m *manager) createProjectZIP(ctx context.Context, request *types.CreatePro
 
 		for _, fileRef := range f.FileRefs {
 			path := f.Path.Join(fileRef.Name).String()
-			w, err := z.Create(path)
-			if err != nil {
-				return errors.Tag(err, "create file: "+path)
-			}
-			_, reader, err := m.fm.GetReadStreamForProjectFile(
+			size, reader, err := m.fm.GetReadStreamForProjectFile(
 				ctx, projectId, fileRef.Id,
 			)
 			if err != nil {
 				return errors.Tag(err, "get file: "+fileRef.Id.String())
 			}
+			w, err := a.CreateFile(path, size)
+			if err != nil {
+				_ = reader.Close()
+				return errors.Tag(err, "create file: "+path)
+			}
 			_, errCopy := io.Copy(w, reader)
 			errClose := reader.Close()
 			if errCopy != nil {
@@ -124,12 +139,12 @@ func (m *manager) createProjectZIP(ctx context.Context, request *types.CreatePro
 		}
 		return nil
 	})
-	errClose := z.Close()
+	errClose := a.Close()
 	if err != nil {
 		return err
 	}
 	if errClose != nil {
-		return errors.Tag(errClose, "close zip")
+		return errors.Tag(errClose, "close archive")
 	}
 	return nil
 }
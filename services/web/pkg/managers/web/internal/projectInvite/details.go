@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -54,6 +54,7 @@ func (d *projectInviteDetails) ValidateForCreation() error {
 		return nil
 	}
 	if d.sender.Id == d.user.Id {
+		// Only the owner can send invites.
 		return &errors.ValidationError{Msg: "cannot_invite_self"}
 	}
 	authorizationDetails, err := d.project.GetPrivilegeLevelAuthenticated()
@@ -66,7 +67,10 @@ func (d *projectInviteDetails) ValidateForCreation() error {
 		return nil
 	}
 	if authorizationDetails.PrivilegeLevel.IsAtLeast(d.invite.PrivilegeLevel) {
-		return &errors.ValidationError{Msg: "user is already a project member"}
+		return &errors.ValidationError{
+			Msg: "user is already a project member with " +
+				string(authorizationDetails.PrivilegeLevel) + " access",
+		}
 	}
 	return nil
 }
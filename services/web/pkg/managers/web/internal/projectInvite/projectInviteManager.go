@@ -55,10 +55,11 @@ func New(options *types.Options, ps *templates.PublicSettings, db *pgxpool.Pool,
 		pm:           pm,
 		um:           um,
 
-		appName:      options.AppName,
-		emailOptions: options.EmailOptions(),
-		ps:           ps,
-		siteURL:      options.SiteURL,
+		appName:        options.AppName,
+		emailOptions:   options.EmailOptions(),
+		ps:             ps,
+		resendInterval: options.RateLimits.ProjectInviteResendInterval,
+		siteURL:        options.SiteURL,
 	}
 }
 
@@ -69,10 +70,11 @@ type manager struct {
 	pm           project.Manager
 	um           user.Manager
 
-	appName      string
-	emailOptions *types.EmailOptions
-	ps           *templates.PublicSettings
-	siteURL      sharedTypes.URL
+	appName        string
+	emailOptions   *types.EmailOptions
+	ps             *templates.PublicSettings
+	resendInterval time.Duration
+	siteURL        sharedTypes.URL
 }
 
 func getKey(inviteId sharedTypes.UUID) string {
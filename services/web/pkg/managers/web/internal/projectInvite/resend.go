@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,6 +18,7 @@ package projectInvite
 
 import (
 	"context"
+	"time"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
@@ -32,6 +33,16 @@ func (m *manager) ResendProjectInvite(ctx context.Context, request *types.Resend
 		return errors.Tag(err, "get invite")
 	}
 
+	if m.resendInterval > 0 {
+		resentAt, err2 := m.pim.GetResentAt(ctx, projectId, inviteId)
+		if err2 != nil {
+			return errors.Tag(err2, "get last resend")
+		}
+		if retryIn := time.Until(resentAt.Add(m.resendInterval)); retryIn > 0 {
+			return &errors.RateLimitedError{RetryIn: retryIn}
+		}
+	}
+
 	d, err := m.getDetails(ctx, pi, request.UserId)
 	if err != nil {
 		return err
@@ -44,5 +55,11 @@ func (m *manager) ResendProjectInvite(ctx context.Context, request *types.Resend
 	if err = m.sendEmail(ctx, d); err != nil {
 		return err
 	}
+
+	if m.resendInterval > 0 {
+		if err = m.pim.MarkResent(ctx, projectId, inviteId); err != nil {
+			return errors.Tag(err, "mark invite as resent")
+		}
+	}
 	return nil
 }
@@ -0,0 +1,206 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectMetadata
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+const (
+	maxBibFiles   = 50
+	maxBibEntries = 10_000
+)
+
+func (m *manager) GetBibliography(ctx context.Context, request *types.GetBibliographyRequest, response *types.GetBibliographyResponse) error {
+	files, err := m.getDocSnapshots(
+		ctx, request.ProjectId, func(t sharedTypes.FileType) bool {
+			return t == "bib"
+		},
+	)
+	if err != nil {
+		return err
+	}
+	*response = aggregateBibFiles(files)
+	return nil
+}
+
+func aggregateBibFiles(files []docSnapshot) types.GetBibliographyResponse {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+	r := types.GetBibliographyResponse{
+		Conflicts: make([]types.BibConflict, 0),
+		Entries:   make([]types.BibEntry, 0),
+	}
+	if len(files) > maxBibFiles {
+		files = files[:maxBibFiles]
+		r.Truncated = true
+	}
+	definedIn := make(map[string][]sharedTypes.PathName)
+	for _, f := range files {
+		for _, e := range parseBib(f.snapshot) {
+			paths, seen := definedIn[e.Key]
+			if seen {
+				if paths[len(paths)-1] != f.path {
+					definedIn[e.Key] = append(paths, f.path)
+				}
+				continue
+			}
+			if len(r.Entries) == maxBibEntries {
+				r.Truncated = true
+				break
+			}
+			definedIn[e.Key] = []sharedTypes.PathName{f.path}
+			r.Entries = append(r.Entries, e)
+		}
+	}
+	for _, e := range r.Entries {
+		if paths := definedIn[e.Key]; len(paths) > 1 {
+			r.Conflicts = append(r.Conflicts, types.BibConflict{
+				Key:   e.Key,
+				Paths: paths,
+			})
+		}
+	}
+	return r
+}
+
+func parseBib(s string) []types.BibEntry {
+	entries := make([]types.BibEntry, 0)
+	for {
+		idx := strings.IndexByte(s, '@')
+		if idx == -1 {
+			return entries
+		}
+		s = s[idx+1:]
+		idx = strings.IndexAny(s, "{(")
+		if idx == -1 {
+			return entries
+		}
+		kind := strings.ToLower(strings.TrimSpace(s[:idx]))
+		if !isBibEntryKind(kind) {
+			// Stray '@', e.g. in an email address of a comment.
+			continue
+		}
+		closing := byte('}')
+		if s[idx] == '(' {
+			closing = ')'
+		}
+		var body string
+		body, s = splitBibGroup(s[idx+1:], closing)
+		switch kind {
+		case "comment", "preamble", "string":
+			continue
+		}
+		if e, ok := parseBibEntry(body); ok {
+			entries = append(entries, e)
+		}
+	}
+}
+
+func isBibEntryKind(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func parseBibEntry(body string) (types.BibEntry, bool) {
+	idx := strings.IndexByte(body, ',')
+	if idx == -1 {
+		return types.BibEntry{}, false
+	}
+	e := types.BibEntry{Key: strings.TrimSpace(body[:idx])}
+	if e.Key == "" {
+		return types.BibEntry{}, false
+	}
+	s := body[idx+1:]
+	for {
+		idx = strings.IndexByte(s, '=')
+		if idx == -1 {
+			return e, true
+		}
+		name := strings.ToLower(strings.Trim(s[:idx], ", \t\r\n"))
+		var v string
+		v, s = readBibValue(strings.TrimSpace(s[idx+1:]))
+		switch name {
+		case "author":
+			e.Author = v
+		case "title":
+			e.Title = v
+		case "year":
+			e.Year = v
+		}
+	}
+}
+
+func readBibValue(s string) (string, string) {
+	b := strings.Builder{}
+	for len(s) > 0 {
+		var v string
+		switch s[0] {
+		case '{':
+			v, s = splitBibGroup(s[1:], '}')
+		case '"':
+			v, s = splitBibGroup(s[1:], '"')
+		default:
+			idx := strings.IndexAny(s, ",#")
+			if idx == -1 {
+				idx = len(s)
+			}
+			v, s = s[:idx], s[idx:]
+		}
+		b.WriteString(v)
+		s = strings.TrimSpace(s)
+		if len(s) == 0 || s[0] != '#' {
+			break
+		}
+		// String concatenation.
+		s = strings.TrimSpace(s[1:])
+	}
+	s = strings.TrimPrefix(s, ",")
+	v := strings.NewReplacer("{", "", "}", "").Replace(b.String())
+	return strings.Join(strings.Fields(v), " "), s
+}
+
+// splitBibGroup returns the content up to the closing delimiter, skipping
+// over nested braces, and the remainder after the delimiter.
+func splitBibGroup(s string, closing byte) (string, string) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == closing && depth == 0:
+			return s[:i], s[i+1:]
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		}
+	}
+	return s, ""
+}
@@ -0,0 +1,123 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectMetadata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func Test_parseBib(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []types.BibEntry
+	}{
+		{
+			name: "empty",
+			s:    "",
+			want: []types.BibEntry{},
+		},
+		{
+			name: "braces and quotes",
+			s: `
+% Contact: foo@bar.com
+@string{acm = "ACM"}
+@Article{knuth84,
+  author = {Donald E. Knuth},
+  title  = "Literate {P}rogramming",
+  year   = 1984,
+}
+@book(lamport94, title={{\LaTeX}: A Document
+                        Preparation System}, year="19" # "94")
+`,
+			want: []types.BibEntry{
+				{
+					Key:    "knuth84",
+					Title:  "Literate Programming",
+					Author: "Donald E. Knuth",
+					Year:   "1984",
+				},
+				{
+					Key:   "lamport94",
+					Title: `\LaTeX: A Document Preparation System`,
+					Year:  "1994",
+				},
+			},
+		},
+		{
+			name: "missing key",
+			s:    "@misc{title={foo}}",
+			want: []types.BibEntry{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseBib(tt.s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBib() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_aggregateBibFiles(t *testing.T) {
+	files := []docSnapshot{
+		{
+			path:     "refs/b.bib",
+			snapshot: "@misc{foo, title={Foo from b}}\n@misc{baz, title={Baz}}",
+		},
+		{
+			path:     "a.bib",
+			snapshot: "@misc{foo, title={Foo from a}}\n@misc{bar, title={Bar}}",
+		},
+	}
+	want := []types.BibEntry{
+		{Key: "foo", Title: "Foo from a"},
+		{Key: "bar", Title: "Bar"},
+		{Key: "baz", Title: "Baz"},
+	}
+	got := aggregateBibFiles(files)
+	if !reflect.DeepEqual(got.Entries, want) {
+		t.Errorf("aggregateBibFiles() entries = %#v, want %#v", got.Entries, want)
+	}
+	if got.Truncated {
+		t.Errorf("aggregateBibFiles() truncated = true, want false")
+	}
+}
+
+func Test_aggregateBibFilesConflicts(t *testing.T) {
+	files := []docSnapshot{
+		{
+			path:     "b.bib",
+			snapshot: "@misc{foo, title={Foo from b}}",
+		},
+		{
+			path:     "a.bib",
+			snapshot: "@misc{foo, title={Foo from a}}\n@misc{bar, title={Bar}}\n@misc{bar, title={Bar again}}",
+		},
+	}
+	want := []types.BibConflict{
+		{Key: "foo", Paths: []sharedTypes.PathName{"a.bib", "b.bib"}},
+	}
+	got := aggregateBibFiles(files)
+	if !reflect.DeepEqual(got.Conflicts, want) {
+		t.Errorf("aggregateBibFiles() conflicts = %#v, want %#v", got.Conflicts, want)
+	}
+}
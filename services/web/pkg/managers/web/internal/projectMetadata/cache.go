@@ -24,7 +24,9 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/das7pad/overleaf-go/pkg/cache"
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/httpUtils"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	documentUpdaterTypes "github.com/das7pad/overleaf-go/services/document-updater/pkg/types"
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
@@ -32,6 +34,43 @@ import (
 
 const cacheExpiry = time.Hour * 24
 
+type docCacheKey struct {
+	docId   sharedTypes.UUID
+	version sharedTypes.Version
+}
+
+type docCacheEntry struct {
+	projectId sharedTypes.UUID
+	meta      types.LightDocProjectMetadata
+}
+
+// docCache holds the parsed metadata of individual docs by version. A hit
+// skips fetching the doc content from the document-updater.
+type docCache struct {
+	c *cache.Limited[docCacheKey, docCacheEntry]
+}
+
+func newDocCache() *docCache {
+	return &docCache{
+		c: cache.NewLimited[docCacheKey, docCacheEntry](1000),
+	}
+}
+
+func (c *docCache) Get(projectId, docId sharedTypes.UUID, v sharedTypes.Version) (types.LightDocProjectMetadata, bool) {
+	e, ok := c.c.Get(docCacheKey{docId: docId, version: v})
+	if !ok || e.projectId != projectId {
+		return types.LightDocProjectMetadata{}, false
+	}
+	return e.meta, true
+}
+
+func (c *docCache) Add(projectId, docId sharedTypes.UUID, v sharedTypes.Version, meta types.LightDocProjectMetadata) {
+	c.c.Add(docCacheKey{docId: docId, version: v}, docCacheEntry{
+		projectId: projectId,
+		meta:      meta,
+	})
+}
+
 type cacheEntry struct {
 	ProjectVersion time.Time                  `json:"projectVersion"`
 	ProjectMeta    types.LightProjectMetadata `json:"projectMeta"`
@@ -99,7 +138,7 @@ func (m *manager) getForProjectWithCache(ctx context.Context, projectId sharedTy
 	if err := eg.Wait(); err != nil {
 		log.Printf(
 			"failed to fetch metadata for %s: %s",
-			projectId, err.Error(),
+			projectId, httpUtils.TagRequestId(ctx, err).Error(),
 		)
 		// fallback to flushed state.
 	}
@@ -0,0 +1,311 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectMetadata
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+//goland:noinspection SpellCheckingInspection
+var (
+	dependencyCommands = map[string]bool{
+		"addbibresource":  true,
+		"bibliography":    true,
+		"include":         true,
+		"includegraphics": true,
+		"input":           true,
+	}
+	graphicsExtensions = []string{".pdf", ".png", ".jpg", ".jpeg", ".eps"}
+
+	// implicitlyUsedFileTypes are loaded by LaTeX via the search path.
+	implicitlyUsedFileTypes = map[sharedTypes.FileType]bool{
+		"bst": true,
+		"cfg": true,
+		"cls": true,
+		"sty": true,
+	}
+)
+
+func (m *manager) GetDependencyGraph(ctx context.Context, request *types.GetDependencyGraphRequest, response *types.GetDependencyGraphResponse) error {
+	docs, files, err := m.getDocSnapshotsAndFiles(
+		ctx, request.ProjectId, func(sharedTypes.FileType) bool {
+			return true
+		},
+	)
+	if err != nil {
+		return err
+	}
+	root := request.RootDocPath
+	if root == "" {
+		if root, err = m.getRootDocPath(ctx, request.ProjectId, docs); err != nil {
+			return err
+		}
+	}
+	filePaths := make([]sharedTypes.PathName, len(files))
+	for i, f := range files {
+		filePaths[i] = f.Path
+	}
+	g, err := buildDependencyGraph(root, docs, filePaths)
+	if err != nil {
+		return err
+	}
+	*response = g
+	return nil
+}
+
+func (m *manager) ListUnusedFiles(ctx context.Context, request *types.ListUnusedFilesRequest, response *types.ListUnusedFilesResponse) error {
+	docs, files, err := m.getDocSnapshotsAndFiles(
+		ctx, request.ProjectId, func(sharedTypes.FileType) bool {
+			return true
+		},
+	)
+	if err != nil {
+		return err
+	}
+	root := request.RootDocPath
+	if root == "" {
+		if root, err = m.getRootDocPath(ctx, request.ProjectId, docs); err != nil {
+			return err
+		}
+	}
+	r, err := findUnusedFiles(root, docs, files)
+	if err != nil {
+		return err
+	}
+	*response = r
+	return nil
+}
+
+func (m *manager) getRootDocPath(ctx context.Context, projectId sharedTypes.UUID, docs []docSnapshot) (sharedTypes.PathName, error) {
+	rootDocId, err := m.pm.GetRootDocId(ctx, projectId)
+	if err != nil {
+		return "", errors.Tag(err, "get root doc id")
+	}
+	for _, d := range docs {
+		if d.id == rootDocId {
+			return d.path, nil
+		}
+	}
+	return "", &errors.ValidationError{Msg: "project has no root doc"}
+}
+
+func findUnusedFiles(root sharedTypes.PathName, docs []docSnapshot, files []project.FileRef) (types.ListUnusedFilesResponse, error) {
+	filePaths := make([]sharedTypes.PathName, len(files))
+	for i, f := range files {
+		filePaths[i] = f.Path
+	}
+	g, err := buildDependencyGraph(root, docs, filePaths)
+	if err != nil {
+		return types.ListUnusedFilesResponse{}, err
+	}
+
+	entities := make(map[sharedTypes.PathName]types.UnusedFile, len(docs)+len(files))
+	for _, d := range docs {
+		entities[d.path] = types.UnusedFile{
+			Id:   d.id,
+			Path: d.path,
+			Type: project.TreeNodeKindDoc,
+		}
+	}
+	for _, f := range files {
+		entities[f.Path] = types.UnusedFile{
+			Id:   f.Id,
+			Path: f.Path,
+			Type: project.TreeNodeKindFile,
+		}
+	}
+	r := types.ListUnusedFilesResponse{
+		Root:  g.Root,
+		Files: make([]types.UnusedFile, 0, len(g.Unreferenced)),
+	}
+	for _, p := range g.Unreferenced {
+		if implicitlyUsedFileTypes[p.Type()] {
+			continue
+		}
+		r.Files = append(r.Files, entities[p])
+	}
+	return r, nil
+}
+
+type dependency struct {
+	command string
+	path    string
+}
+
+func buildDependencyGraph(root sharedTypes.PathName, docs []docSnapshot, files []sharedTypes.PathName) (types.GetDependencyGraphResponse, error) {
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].path < docs[j].path
+	})
+	snapshots := make(map[sharedTypes.PathName]string, len(docs))
+	for _, d := range docs {
+		snapshots[d.path] = d.snapshot
+	}
+	exists := make(map[sharedTypes.PathName]bool, len(docs)+len(files))
+	for _, d := range docs {
+		exists[d.path] = true
+	}
+	for _, p := range files {
+		exists[p] = true
+	}
+
+	if _, ok := snapshots[root]; !ok {
+		return types.GetDependencyGraphResponse{}, &errors.NotFoundError{}
+	}
+
+	g := types.GetDependencyGraphResponse{
+		Root:         root,
+		Nodes:        []sharedTypes.PathName{root},
+		Edges:        make([]types.DependencyGraphEdge, 0),
+		Missing:      make([]sharedTypes.PathName, 0),
+		Unreferenced: make([]sharedTypes.PathName, 0),
+	}
+	seen := map[sharedTypes.PathName]bool{root: true}
+	for i := 0; i < len(g.Nodes); i++ {
+		from := g.Nodes[i]
+		s, isDoc := snapshots[from]
+		if !isDoc {
+			continue
+		}
+		for _, dep := range scanDependencies(s) {
+			to, found := resolveDependency(dep, exists)
+			g.Edges = append(g.Edges, types.DependencyGraphEdge{
+				From:    from,
+				To:      to,
+				Command: dep.command,
+			})
+			if seen[to] {
+				continue
+			}
+			seen[to] = true
+			if found {
+				g.Nodes = append(g.Nodes, to)
+			} else {
+				g.Missing = append(g.Missing, to)
+			}
+		}
+	}
+	for p := range exists {
+		if !seen[p] {
+			g.Unreferenced = append(g.Unreferenced, p)
+		}
+	}
+	sort.Slice(g.Unreferenced, func(i, j int) bool {
+		return g.Unreferenced[i] < g.Unreferenced[j]
+	})
+	return g, nil
+}
+
+func resolveDependency(dep dependency, exists map[sharedTypes.PathName]bool) (sharedTypes.PathName, bool) {
+	p := strings.TrimPrefix(dep.path, "./")
+	var candidates []string
+	switch dep.command {
+	case "includegraphics":
+		candidates = append(candidates, p)
+		for _, ext := range graphicsExtensions {
+			candidates = append(candidates, p+ext)
+		}
+	case "addbibresource":
+		candidates = append(candidates, p)
+	case "bibliography":
+		if !strings.HasSuffix(p, ".bib") {
+			p += ".bib"
+		}
+		candidates = append(candidates, p)
+	default:
+		// LaTeX tries the .tex extension first.
+		if !strings.HasSuffix(p, ".tex") {
+			candidates = append(candidates, p+".tex")
+		}
+		candidates = append(candidates, p)
+	}
+	for _, c := range candidates {
+		if exists[sharedTypes.PathName(c)] {
+			return sharedTypes.PathName(c), true
+		}
+	}
+	return sharedTypes.PathName(candidates[0]), false
+}
+
+func scanDependencies(s string) []dependency {
+	var deps []dependency
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '%':
+			idx := strings.IndexByte(s[i:], '\n')
+			if idx == -1 {
+				i = len(s)
+			} else {
+				i += idx
+			}
+		case '\\':
+			name := readCommandName(s[i+1:])
+			i += len(name)
+			if name == "" {
+				// Escaped character, e.g. \%.
+				i++
+				continue
+			}
+			switch {
+			case name == "verb":
+				i = skipVerb(s, i+1) - 1
+			case name == "begin":
+				arg, end, ok := readCommandArg(s, i+1)
+				if ok && lintVerbatimEnvironments[arg] {
+					i = skipVerbatim(s, end, arg) - 1
+				}
+			case dependencyCommands[name]:
+				arg, end, ok := readCommandArg(s, skipOptionalArg(s, i+1))
+				if !ok {
+					continue
+				}
+				i = end - 1
+				paths := []string{arg}
+				if name == "bibliography" {
+					paths = strings.Split(arg, ",")
+				}
+				for _, path := range paths {
+					if path = strings.TrimSpace(path); path != "" {
+						deps = append(deps, dependency{command: name, path: path})
+					}
+				}
+			}
+		}
+	}
+	return deps
+}
+
+// skipOptionalArg skips over an argument like [width=5cm] starting at idx.
+func skipOptionalArg(s string, idx int) int {
+	for idx < len(s) && (s[idx] == ' ' || s[idx] == '\t') {
+		idx++
+	}
+	if idx == len(s) || s[idx] != '[' {
+		return idx
+	}
+	end := strings.IndexAny(s[idx+1:], "]\n")
+	if end == -1 || s[idx+1+end] != ']' {
+		return idx
+	}
+	return idx + 1 + end + 1
+}
@@ -0,0 +1,135 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectMetadata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func Test_buildDependencyGraph(t *testing.T) {
+	docs := []docSnapshot{
+		{
+			path: "main.tex",
+			snapshot: `\documentclass{article}
+\begin{document}
+\input{sections/intro}
+\include{./sections/missing}
+% \input{sections/commented}
+\includegraphics[width=\linewidth]{figures/frog}
+\end{document}`,
+		},
+		{
+			path: "sections/intro.tex",
+			snapshot: `\section{Intro}
+\input{sections/details.tex}
+\begin{verbatim}
+\input{sections/verbatim}
+\end{verbatim}`,
+		},
+		{
+			path:     "sections/details.tex",
+			snapshot: `\input{sections/intro}`,
+		},
+		{
+			path:     "sections/unused.tex",
+			snapshot: `\section{Unused}`,
+		},
+	}
+	files := []sharedTypes.PathName{"figures/frog.png", "figures/old.png"}
+
+	got, err := buildDependencyGraph("main.tex", docs, files)
+	if err != nil {
+		t.Fatalf("buildDependencyGraph() error = %s", err)
+	}
+	want := types.GetDependencyGraphResponse{
+		Root: "main.tex",
+		Nodes: []sharedTypes.PathName{
+			"main.tex",
+			"sections/intro.tex",
+			"figures/frog.png",
+			"sections/details.tex",
+		},
+		Edges: []types.DependencyGraphEdge{
+			{From: "main.tex", To: "sections/intro.tex", Command: "input"},
+			{From: "main.tex", To: "sections/missing.tex", Command: "include"},
+			{From: "main.tex", To: "figures/frog.png", Command: "includegraphics"},
+			{From: "sections/intro.tex", To: "sections/details.tex", Command: "input"},
+			{From: "sections/details.tex", To: "sections/intro.tex", Command: "input"},
+		},
+		Missing: []sharedTypes.PathName{"sections/missing.tex"},
+		Unreferenced: []sharedTypes.PathName{
+			"figures/old.png",
+			"sections/unused.tex",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildDependencyGraph() = %+v, want %+v", got, want)
+	}
+
+	if _, err = buildDependencyGraph("other.tex", docs, files); err == nil {
+		t.Errorf("buildDependencyGraph() with unknown root: expected error")
+	}
+}
+
+func Test_findUnusedFiles(t *testing.T) {
+	docs := []docSnapshot{
+		{
+			id:   sharedTypes.UUID{1},
+			path: "main.tex",
+			snapshot: `\documentclass{custom}
+\includegraphics{frog}
+\bibliography{refs}`,
+		},
+		{id: sharedTypes.UUID{2}, path: "refs.bib"},
+		{id: sharedTypes.UUID{3}, path: "custom.cls"},
+		{id: sharedTypes.UUID{4}, path: "notes.tex"},
+	}
+	files := []project.FileRef{
+		{LeafFields: project.LeafFields{Path: "frog.jpg"}},
+		{LeafFields: project.LeafFields{Path: "unused.png"}},
+	}
+	files[0].Id = sharedTypes.UUID{5}
+	files[1].Id = sharedTypes.UUID{6}
+
+	got, err := findUnusedFiles("main.tex", docs, files)
+	if err != nil {
+		t.Fatalf("findUnusedFiles() error = %s", err)
+	}
+	want := types.ListUnusedFilesResponse{
+		Root: "main.tex",
+		Files: []types.UnusedFile{
+			{
+				Id:   sharedTypes.UUID{4},
+				Path: "notes.tex",
+				Type: project.TreeNodeKindDoc,
+			},
+			{
+				Id:   sharedTypes.UUID{6},
+				Path: "unused.png",
+				Type: project.TreeNodeKindFile,
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findUnusedFiles() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,248 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectMetadata
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+const maxLintWarnings = 500
+
+//goland:noinspection SpellCheckingInspection
+var (
+	lintRefCommands = map[string]bool{
+		"Cref":    true,
+		"autoref": true,
+		"cref":    true,
+		"eqref":   true,
+		"nameref": true,
+		"pageref": true,
+		"ref":     true,
+		"vref":    true,
+	}
+	lintVerbatimEnvironments = map[string]bool{
+		"Verbatim":   true,
+		"comment":    true,
+		"lstlisting": true,
+		"minted":     true,
+		"verbatim":   true,
+		"verbatim*":  true,
+	}
+)
+
+func (m *manager) LintProject(ctx context.Context, request *types.LintProjectRequest, response *types.LintProjectResponse) error {
+	docs, err := m.getDocSnapshots(
+		ctx, request.ProjectId, sharedTypes.FileType.ValidForRootDoc,
+	)
+	if err != nil {
+		return err
+	}
+	response.Warnings, response.Truncated = lintDocs(docs)
+	return nil
+}
+
+func lintDocs(docs []docSnapshot) ([]types.LintWarning, bool) {
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].path < docs[j].path
+	})
+	labels := make(map[string]bool)
+	for _, d := range docs {
+		for _, match := range labelRegex.FindAllStringSubmatch(d.snapshot, -1) {
+			labels[match[1]] = true
+		}
+	}
+	l := linter{
+		labels:   labels,
+		warnings: make([]types.LintWarning, 0),
+	}
+	for _, d := range docs {
+		l.lint(d)
+		if l.truncated {
+			break
+		}
+	}
+	return l.warnings, l.truncated
+}
+
+type linter struct {
+	labels    map[string]bool
+	warnings  []types.LintWarning
+	truncated bool
+
+	d docSnapshot
+}
+
+type lintEnvironment struct {
+	name string
+	idx  int
+}
+
+func (l *linter) warn(idx int, msg string) {
+	if len(l.warnings) == maxLintWarnings {
+		l.truncated = true
+		return
+	}
+	s := l.d.snapshot[:idx]
+	lineStart := strings.LastIndexByte(s, '\n') + 1
+	l.warnings = append(l.warnings, types.LintWarning{
+		Path:    l.d.path,
+		Line:    strings.Count(s, "\n") + 1,
+		Column:  len([]rune(s[lineStart:])) + 1,
+		Message: msg,
+	})
+}
+
+func (l *linter) lint(d docSnapshot) {
+	l.d = d
+	s := d.snapshot
+	var braces []int
+	var environments []lintEnvironment
+	for i := 0; i < len(s) && !l.truncated; i++ {
+		switch s[i] {
+		case '%':
+			idx := strings.IndexByte(s[i:], '\n')
+			if idx == -1 {
+				i = len(s)
+			} else {
+				i += idx
+			}
+		case '{':
+			braces = append(braces, i)
+		case '}':
+			if len(braces) == 0 {
+				l.warn(i, "unmatched '}'")
+			} else {
+				braces = braces[:len(braces)-1]
+			}
+		case '\\':
+			start := i
+			name := readCommandName(s[i+1:])
+			i += len(name)
+			if name == "" {
+				// Escaped character, e.g. \{ or \%.
+				i++
+				continue
+			}
+			switch {
+			case name == "verb":
+				i = skipVerb(s, i+1) - 1
+			case name == "begin" || name == "end":
+				arg, end, ok := readCommandArg(s, i+1)
+				if !ok {
+					continue
+				}
+				i = end - 1
+				if name == "begin" {
+					if lintVerbatimEnvironments[arg] {
+						i = skipVerbatim(s, end, arg) - 1
+						continue
+					}
+					environments = append(environments, lintEnvironment{
+						name: arg,
+						idx:  start,
+					})
+					continue
+				}
+				environments = l.closeEnvironment(environments, arg, start)
+			case lintRefCommands[name]:
+				arg, end, ok := readCommandArg(s, i+1)
+				if !ok {
+					continue
+				}
+				i = end - 1
+				for _, label := range strings.Split(arg, ",") {
+					label = strings.TrimSpace(label)
+					if label != "" && !l.labels[label] {
+						l.warn(start, "reference to undefined label '"+label+"'")
+					}
+				}
+			}
+		}
+	}
+	for _, idx := range braces {
+		l.warn(idx, "unclosed '{'")
+	}
+	for _, e := range environments {
+		l.warn(e.idx, "\\begin{"+e.name+"} without matching \\end")
+	}
+}
+
+func (l *linter) closeEnvironment(environments []lintEnvironment, name string, idx int) []lintEnvironment {
+	for j := len(environments) - 1; j >= 0; j-- {
+		if environments[j].name != name {
+			continue
+		}
+		for _, e := range environments[j+1:] {
+			l.warn(e.idx, "\\begin{"+e.name+"} closed by \\end{"+name+"}")
+		}
+		return environments[:j]
+	}
+	l.warn(idx, "\\end{"+name+"} without matching \\begin")
+	return environments
+}
+
+func readCommandName(s string) string {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// readCommandArg reads a simple argument like {foo} starting at idx.
+func readCommandArg(s string, idx int) (string, int, bool) {
+	for idx < len(s) && (s[idx] == ' ' || s[idx] == '\t') {
+		idx++
+	}
+	if idx == len(s) || s[idx] != '{' {
+		return "", idx, false
+	}
+	end := strings.IndexAny(s[idx+1:], "{}\n")
+	if end == -1 || s[idx+1+end] != '}' {
+		return "", idx, false
+	}
+	return s[idx+1 : idx+1+end], idx + 1 + end + 1, true
+}
+
+func skipVerb(s string, idx int) int {
+	if idx < len(s) && s[idx] == '*' {
+		idx++
+	}
+	if idx >= len(s) {
+		return idx
+	}
+	end := strings.IndexByte(s[idx+1:], s[idx])
+	if end == -1 {
+		return len(s)
+	}
+	return idx + 1 + end + 1
+}
+
+func skipVerbatim(s string, idx int, name string) int {
+	end := strings.Index(s[idx:], "\\end{"+name+"}")
+	if end == -1 {
+		return len(s)
+	}
+	return idx + end + len("\\end{"+name+"}")
+}
@@ -0,0 +1,133 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectMetadata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func Test_lintDocs(t *testing.T) {
+	tests := []struct {
+		name string
+		docs []docSnapshot
+		want []types.LintWarning
+	}{
+		{
+			name: "clean",
+			docs: []docSnapshot{
+				{
+					path: "main.tex",
+					snapshot: `\begin{document}
+\section{Intro}\label{sec:intro}
+See \cref{sec:intro,sec:other} and 100\%.
+% \begin{figure} \ref{commented}
+\verb|\begin{itemize}|
+\begin{verbatim}
+\end{document} {
+\end{verbatim}
+\end{document}`,
+				},
+				{
+					path:     "other.tex",
+					snapshot: `\section{Other}\label{sec:other}`,
+				},
+			},
+			want: []types.LintWarning{},
+		},
+		{
+			name: "unbalanced environment",
+			docs: []docSnapshot{
+				{
+					path: "main.tex",
+					snapshot: `\begin{document}
+  \begin{itemize}
+    \item foo
+\end{document}
+\end{figure}`,
+				},
+			},
+			want: []types.LintWarning{
+				{
+					Path:    "main.tex",
+					Line:    2,
+					Column:  3,
+					Message: `\begin{itemize} closed by \end{document}`,
+				},
+				{
+					Path:    "main.tex",
+					Line:    5,
+					Column:  1,
+					Message: `\end{figure} without matching \begin`,
+				},
+			},
+		},
+		{
+			name: "unbalanced braces",
+			docs: []docSnapshot{
+				{
+					path:     "main.tex",
+					snapshot: "\\textbf{foo}}\n\\emph{bar",
+				},
+			},
+			want: []types.LintWarning{
+				{
+					Path:    "main.tex",
+					Line:    1,
+					Column:  13,
+					Message: "unmatched '}'",
+				},
+				{
+					Path:    "main.tex",
+					Line:    2,
+					Column:  6,
+					Message: "unclosed '{'",
+				},
+			},
+		},
+		{
+			name: "undefined ref",
+			docs: []docSnapshot{
+				{
+					path:     "main.tex",
+					snapshot: "\\label{fig:a}\nSee \\ref{fig:a} and \\eqref{eq:missing}.",
+				},
+			},
+			want: []types.LintWarning{
+				{
+					Path:    "main.tex",
+					Line:    2,
+					Column:  21,
+					Message: "reference to undefined label 'eq:missing'",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := lintDocs(tt.docs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lintDocs() = %#v, want %#v", got, tt.want)
+			}
+			if truncated {
+				t.Errorf("lintDocs() truncated = true, want false")
+			}
+		})
+	}
+}
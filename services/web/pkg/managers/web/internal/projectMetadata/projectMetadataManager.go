@@ -34,24 +34,31 @@ import (
 
 type Manager interface {
 	BroadcastMetadataForDocFromSnapshot(projectId, docId sharedTypes.UUID, snapshot string) error
+	GetBibliography(ctx context.Context, request *types.GetBibliographyRequest, response *types.GetBibliographyResponse) error
+	GetDependencyGraph(ctx context.Context, request *types.GetDependencyGraphRequest, response *types.GetDependencyGraphResponse) error
 	GetMetadataForProject(ctx context.Context, request *types.GetMetadataForProjectRequest, response *types.GetMetadataForProjectResponse) error
 	GetMetadataForDoc(ctx context.Context, request *types.GetMetadataForDocRequest, response *types.GetMetadataForDocResponse) error
+	GetMetadataForDocs(ctx context.Context, request *types.GetMetadataForDocsRequest, response *types.GetMetadataForDocsResponse) error
+	LintProject(ctx context.Context, request *types.LintProjectRequest, response *types.LintProjectResponse) error
+	ListUnusedFiles(ctx context.Context, request *types.ListUnusedFilesRequest, response *types.ListUnusedFilesResponse) error
 }
 
 func New(client redis.UniversalClient, editorEvents channel.Writer, pm project.Manager, dum documentUpdater.Manager) Manager {
 	return &manager{
-		client: client,
-		c:      editorEvents,
-		pm:     pm,
-		dum:    dum,
+		client:   client,
+		c:        editorEvents,
+		pm:       pm,
+		dum:      dum,
+		docCache: newDocCache(),
 	}
 }
 
 type manager struct {
-	client redis.UniversalClient
-	c      channel.Writer
-	pm     project.Manager
-	dum    documentUpdater.Manager
+	client   redis.UniversalClient
+	c        channel.Writer
+	pm       project.Manager
+	dum      documentUpdater.Manager
+	docCache *docCache
 }
 
 func (m *manager) GetMetadataForProject(ctx context.Context, request *types.GetMetadataForProjectRequest, response *types.GetMetadataForProjectResponse) error {
@@ -73,12 +80,28 @@ func (m *manager) BroadcastMetadataForDocFromSnapshot(projectId, docId sharedTyp
 	return m.broadcast(ctx, projectId, docId, inflate(m.parseDoc(snapshot)))
 }
 
+func (m *manager) getMetadataForDoc(ctx context.Context, projectId, docId sharedTypes.UUID) (types.ProjectDocMetadata, error) {
+	v, err := m.dum.GetDocVersion(ctx, projectId, docId)
+	if err != nil {
+		return types.ProjectDocMetadata{}, errors.Tag(err, "get doc version")
+	}
+	if meta, ok := m.docCache.Get(projectId, docId, v); ok {
+		return inflate(meta), nil
+	}
+	d, err := m.dum.GetDoc(ctx, projectId, docId, -1)
+	if err != nil {
+		return types.ProjectDocMetadata{}, errors.Tag(err, "get doc")
+	}
+	meta := m.parseDoc(d.Snapshot)
+	m.docCache.Add(projectId, docId, d.Version, meta)
+	return inflate(meta), nil
+}
+
 func (m *manager) GetMetadataForDoc(ctx context.Context, request *types.GetMetadataForDocRequest, response *types.GetMetadataForDocResponse) error {
-	d, err := m.dum.GetDoc(ctx, request.ProjectId, request.DocId, -1)
+	meta, err := m.getMetadataForDoc(ctx, request.ProjectId, request.DocId)
 	if err != nil {
-		return errors.Tag(err, "get doc")
+		return err
 	}
-	meta := inflate(m.parseDoc(d.Snapshot))
 
 	if !request.Broadcast {
 		// Skip pub/sub for projects with a single active user.
@@ -89,6 +112,25 @@ func (m *manager) GetMetadataForDoc(ctx context.Context, request *types.GetMetad
 	return m.broadcast(ctx, request.ProjectId, request.DocId, meta)
 }
 
+func (m *manager) GetMetadataForDocs(ctx context.Context, request *types.GetMetadataForDocsRequest, response *types.GetMetadataForDocsResponse) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	p := make(types.ProjectMetadata, len(request.DocIds))
+	for _, docId := range request.DocIds {
+		meta, err := m.getMetadataForDoc(ctx, request.ProjectId, docId)
+		if err != nil {
+			if errors.IsNotFoundError(err) {
+				continue
+			}
+			return err
+		}
+		p[docId.String()] = meta
+	}
+	response.ProjectMetadata = p
+	return nil
+}
+
 func (m *manager) broadcast(ctx context.Context, projectId, docId sharedTypes.UUID, meta types.ProjectDocMetadata) error {
 	blob, err := json.Marshal(types.GetMetadataForDocResponse{
 		DocId:              docId,
@@ -108,6 +150,52 @@ func (m *manager) broadcast(ctx context.Context, projectId, docId sharedTypes.UU
 	return nil
 }
 
+type docSnapshot struct {
+	id       sharedTypes.UUID
+	path     sharedTypes.PathName
+	snapshot string
+}
+
+func (m *manager) getDocSnapshots(ctx context.Context, projectId sharedTypes.UUID, include func(t sharedTypes.FileType) bool) ([]docSnapshot, error) {
+	docs, _, err := m.getDocSnapshotsAndFiles(ctx, projectId, include)
+	return docs, err
+}
+
+func (m *manager) getDocSnapshotsAndFiles(ctx context.Context, projectId sharedTypes.UUID, include func(t sharedTypes.FileType) bool) ([]docSnapshot, []project.FileRef, error) {
+	recentlyEdited, err := m.dum.GetProjectDocsAndFlushIfOldSnapshot(
+		ctx, projectId,
+	)
+	if err != nil {
+		return nil, nil, errors.Tag(err, "get docs from redis")
+	}
+	docs, files, err := m.pm.GetProjectWithContent(ctx, projectId)
+	if err != nil {
+		return nil, nil, errors.Tag(err, "get docs from db")
+	}
+	out := make([]docSnapshot, 0)
+	seen := make(map[sharedTypes.UUID]bool, len(recentlyEdited))
+	for _, d := range recentlyEdited {
+		seen[d.Id] = true
+		if include(d.PathName.Type()) {
+			out = append(out, docSnapshot{
+				id:       d.Id,
+				path:     d.PathName,
+				snapshot: d.Snapshot,
+			})
+		}
+	}
+	for _, d := range docs {
+		if !seen[d.Id] && include(d.Path.Type()) {
+			out = append(out, docSnapshot{
+				id:       d.Id,
+				path:     d.Path,
+				snapshot: d.Snapshot,
+			})
+		}
+	}
+	return out, files, nil
+}
+
 func (m *manager) getForProjectWithoutCache(ctx context.Context, projectId sharedTypes.UUID, recentlyEdited documentUpdaterTypes.DocContentSnapshots) (types.LightProjectMetadata, error) {
 	docs, _, err := m.pm.GetProjectWithContent(ctx, projectId)
 	if err != nil {
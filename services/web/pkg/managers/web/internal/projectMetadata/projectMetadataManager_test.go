@@ -0,0 +1,155 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectMetadata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/document-updater/pkg/managers/documentUpdater"
+	documentUpdaterTypes "github.com/das7pad/overleaf-go/services/document-updater/pkg/types"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+type fakeDocumentUpdater struct {
+	documentUpdater.Manager
+	docs    map[sharedTypes.UUID]documentUpdaterTypes.GetDocResponse
+	fetches int
+}
+
+func (f *fakeDocumentUpdater) GetDocVersion(_ context.Context, _, docId sharedTypes.UUID) (sharedTypes.Version, error) {
+	d, ok := f.docs[docId]
+	if !ok {
+		return 0, &errors.NotFoundError{}
+	}
+	return d.Version, nil
+}
+
+func (f *fakeDocumentUpdater) GetDoc(_ context.Context, _, docId sharedTypes.UUID, _ sharedTypes.Version) (*documentUpdaterTypes.GetDocResponse, error) {
+	d, ok := f.docs[docId]
+	if !ok {
+		return nil, &errors.NotFoundError{}
+	}
+	f.fetches++
+	return &d, nil
+}
+
+func TestManager_GetMetadataForDocs(t *testing.T) {
+	a := sharedTypes.UUID{1}
+	b := sharedTypes.UUID{2}
+	missing := sharedTypes.UUID{3}
+	m := &manager{
+		dum: &fakeDocumentUpdater{
+			docs: map[sharedTypes.UUID]documentUpdaterTypes.GetDocResponse{
+				a: {Snapshot: "\\label{foo}"},
+				b: {Snapshot: "\\label{bar}\n\\label{baz}"},
+			},
+		},
+		docCache: newDocCache(),
+	}
+	response := types.GetMetadataForDocsResponse{}
+	err := m.GetMetadataForDocs(context.Background(), &types.GetMetadataForDocsRequest{
+		DocIds: []sharedTypes.UUID{a, missing, b},
+	}, &response)
+	if err != nil {
+		t.Fatalf("GetMetadataForDocs() error = %v", err)
+	}
+	want := map[string][]types.LatexLabel{
+		a.String(): {"foo"},
+		b.String(): {"bar", "baz"},
+	}
+	if len(response.ProjectMetadata) != len(want) {
+		t.Fatalf("GetMetadataForDocs() got %d docs, want %d", len(response.ProjectMetadata), len(want))
+	}
+	for id, labels := range want {
+		if got := response.ProjectMetadata[id].Labels; !reflect.DeepEqual(got, labels) {
+			t.Errorf("GetMetadataForDocs() labels for %s = %v, want %v", id, got, labels)
+		}
+	}
+}
+
+func TestManager_GetMetadataForDocsValidation(t *testing.T) {
+	m := &manager{dum: &fakeDocumentUpdater{}}
+	err := m.GetMetadataForDocs(context.Background(), &types.GetMetadataForDocsRequest{}, &types.GetMetadataForDocsResponse{})
+	if !errors.IsValidationError(err) {
+		t.Errorf("GetMetadataForDocs() error = %v, want validation error", err)
+	}
+}
+
+func TestManager_GetMetadataForDocCache(t *testing.T) {
+	docId := sharedTypes.UUID{1}
+	dum := &fakeDocumentUpdater{
+		docs: map[sharedTypes.UUID]documentUpdaterTypes.GetDocResponse{
+			docId: {Snapshot: "\\label{foo}", Version: 1},
+		},
+	}
+	m := &manager{dum: dum, docCache: newDocCache()}
+	get := func() []types.LatexLabel {
+		response := types.GetMetadataForDocResponse{}
+		err := m.GetMetadataForDoc(context.Background(), &types.GetMetadataForDocRequest{
+			DocId: docId,
+		}, &response)
+		if err != nil {
+			t.Fatalf("GetMetadataForDoc() error = %v", err)
+		}
+		return response.ProjectDocMetadata.Labels
+	}
+	if got := get(); !reflect.DeepEqual(got, []types.LatexLabel{"foo"}) {
+		t.Fatalf("GetMetadataForDoc() labels = %v, want [foo]", got)
+	}
+
+	t.Run("hit at same version", func(t *testing.T) {
+		// Changing the content without bumping the version is not possible
+		//  in practice. Use it for detecting a cache hit.
+		dum.docs[docId] = documentUpdaterTypes.GetDocResponse{
+			Snapshot: "\\label{bar}", Version: 1,
+		}
+		if got := get(); !reflect.DeepEqual(got, []types.LatexLabel{"foo"}) {
+			t.Errorf("GetMetadataForDoc() labels = %v, want [foo]", got)
+		}
+		if dum.fetches != 1 {
+			t.Errorf("GetMetadataForDoc() fetched doc %d times, want 1", dum.fetches)
+		}
+	})
+	t.Run("invalidate after edit", func(t *testing.T) {
+		dum.docs[docId] = documentUpdaterTypes.GetDocResponse{
+			Snapshot: "\\label{bar}", Version: 2,
+		}
+		if got := get(); !reflect.DeepEqual(got, []types.LatexLabel{"bar"}) {
+			t.Errorf("GetMetadataForDoc() labels = %v, want [bar]", got)
+		}
+		if dum.fetches != 2 {
+			t.Errorf("GetMetadataForDoc() fetched doc %d times, want 2", dum.fetches)
+		}
+	})
+	t.Run("scoped to project", func(t *testing.T) {
+		response := types.GetMetadataForDocResponse{}
+		err := m.GetMetadataForDoc(context.Background(), &types.GetMetadataForDocRequest{
+			ProjectId: sharedTypes.UUID{42},
+			DocId:     docId,
+		}, &response)
+		if err != nil {
+			t.Fatalf("GetMetadataForDoc() error = %v", err)
+		}
+		if dum.fetches != 3 {
+			t.Errorf("GetMetadataForDoc() fetched doc %d times, want 3", dum.fetches)
+		}
+	})
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -48,6 +48,7 @@ func (m *manager) CreateExampleProject(ctx context.Context, request *types.Creat
 		return errors.Tag(err, "render template")
 	}
 	return m.CreateProject(ctx, &types.CreateProjectRequest{
+		ExtraFolders:       m.defaultFolders,
 		Files:              files,
 		Name:               request.Name,
 		SpellCheckLanguage: "inherit",
@@ -82,6 +82,9 @@ func (m *manager) CreateProject(ctx context.Context, request *types.CreateProjec
 	p.CreatedAt = time.Now().Truncate(time.Microsecond)
 	if request.Compiler != "" {
 		p.Compiler = request.Compiler
+	} else {
+		p.Compiler = m.defaultCompiler
+		p.CompilerInherited = true
 	}
 	p.ImageName = m.defaultImage
 	if request.ImageName != "" {
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -20,12 +20,14 @@ import (
 	"context"
 	"time"
 
+	"github.com/das7pad/overleaf-go/pkg/constants"
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/models/project"
 	"github.com/das7pad/overleaf-go/pkg/models/user"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	"github.com/das7pad/overleaf-go/services/document-updater/pkg/managers/documentUpdater"
 	"github.com/das7pad/overleaf-go/services/filestore/pkg/managers/filestore"
+	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/uploadScanner"
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
 )
 
@@ -36,22 +38,36 @@ type Manager interface {
 	CreateFromZip(ctx context.Context, request *types.CreateProjectFromZipRequest, response *types.CreateProjectResponse) error
 }
 
-func New(options *types.Options, pm project.Manager, um user.Manager, dum documentUpdater.Manager, fm filestore.Manager) Manager {
+func New(options *types.Options, pm project.Manager, um user.Manager, dum documentUpdater.Manager, fm filestore.Manager, us uploadScanner.Scanner) Manager {
+	maxFilesPerUpload := options.MaxFilesPerUpload
+	if maxFilesPerUpload == 0 {
+		maxFilesPerUpload = constants.MaxFilesPerProject
+	}
 	return &manager{
-		dum:          dum,
-		fm:           fm,
-		pm:           pm,
-		um:           um,
-		defaultImage: options.DefaultImage,
+		dum:            dum,
+		fm:             fm,
+		pm:             pm,
+		um:             um,
+		us:             us,
+		defaultImage:   options.DefaultImage,
+		defaultFolders: options.DefaultProjectFolders,
+
+		defaultCompiler:   options.GetDefaultCompiler(),
+		maxFilesPerUpload: maxFilesPerUpload,
 	}
 }
 
 type manager struct {
-	dum          documentUpdater.Manager
-	fm           filestore.Manager
-	pm           project.Manager
-	um           user.Manager
-	defaultImage sharedTypes.ImageName
+	dum            documentUpdater.Manager
+	fm             filestore.Manager
+	pm             project.Manager
+	um             user.Manager
+	us             uploadScanner.Scanner
+	defaultImage   sharedTypes.ImageName
+	defaultFolders []sharedTypes.DirName
+
+	defaultCompiler   sharedTypes.Compiler
+	maxFilesPerUpload int
 }
 
 func (m *manager) purgeFilestoreData(projectId sharedTypes.UUID) error {
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -63,19 +63,58 @@ func (m *manager) CreateFromZip(ctx context.Context, request *types.CreateProjec
 	if err := request.Session.CheckIsLoggedIn(); err != nil {
 		return err
 	}
+	if err := m.us.Scan(ctx, request.File); err != nil {
+		return err
+	}
 
 	r, errNewReader := zip.NewReader(request.File, request.Size)
 	if errNewReader != nil {
-		return errors.Tag(errNewReader, "open zip")
+		return &errors.ValidationError{
+			Msg: "invalid zip file: " + errNewReader.Error(),
+		}
+	}
+	summary, err := scanZip(r, m.maxFilesPerUpload)
+	if err != nil {
+		return err
 	}
+	files := make([]types.CreateProjectFile, len(summary.files))
+	for i, f := range summary.files {
+		if prefix := len(summary.topDir); prefix != 0 {
+			f.Name = f.Name[prefix:]
+		}
+		files[i] = f
+	}
+
+	return m.CreateProject(ctx, &types.CreateProjectRequest{
+		AddHeader:          request.AddHeader,
+		Compiler:           request.Compiler,
+		Files:              files,
+		HasDefaultName:     request.HasDefaultName,
+		Name:               request.Name,
+		SpellCheckLanguage: "inherit",
+		UserId:             request.Session.User.Id,
+	}, response)
+}
+
+// zipSummary describes the contents of a zip file as per its central
+// directory.
+type zipSummary struct {
+	files            []*zipFile
+	topDir           string
+	uncompressedSize uint64
+}
+
+// scanZip validates the structure and size of a zip file before
+// processing any of its contents. The sizes of the central directory are
+// reliable, as the zip reader rejects entries that decompress to more.
+func scanZip(r *zip.Reader, maxFiles int) (*zipSummary, error) {
 	if len(r.File) > 10_000 {
-		return &errors.ValidationError{
+		return nil, &errors.ValidationError{
 			Msg: "too many entries in zip file (>10000)",
 		}
 	}
 
-	files := make([]types.CreateProjectFile, 0, constants.MaxFilesPerProject)
-	topDir := ""
+	s := zipSummary{files: make([]*zipFile, 0, len(r.File))}
 	topDirSet := false
 	for _, file := range r.File {
 		mode := file.Mode()
@@ -83,40 +122,37 @@ func (m *manager) CreateFromZip(ctx context.Context, request *types.CreateProjec
 			continue
 		}
 		if !mode.IsRegular() {
-			return &errors.ValidationError{
+			return nil, &errors.ValidationError{
 				Msg: fmt.Sprintf("%q is not a dir/file", file.Name),
 			}
 		}
-		if len(files) >= constants.MaxFilesPerProject {
-			return &errors.ValidationError{
-				Msg: "too many files for new project",
+		if len(s.files) >= maxFiles {
+			return nil, &errors.ValidationError{
+				Msg: fmt.Sprintf("too many files in zip file (>%d)", maxFiles),
 			}
 		}
-		files = append(files, &zipFile{File: file})
+		s.uncompressedSize += file.UncompressedSize64
+		if s.uncompressedSize > constants.MaxUploadSize {
+			return nil, &errors.ValidationError{
+				Msg: fmt.Sprintf(
+					"zip file is too large when extracted (>%d bytes)",
+					constants.MaxUploadSize,
+				),
+			}
+		}
+		s.files = append(s.files, &zipFile{File: file})
 		if !topDirSet {
 			if idx := strings.IndexByte(file.Name, '/'); idx != -1 {
-				topDir = file.Name[:idx+1]
+				s.topDir = file.Name[:idx+1]
 			}
 			topDirSet = true
 		}
-		if topDir != "" && !strings.HasPrefix(file.Name, topDir) {
-			topDir = ""
+		if s.topDir != "" && !strings.HasPrefix(file.Name, s.topDir) {
+			s.topDir = ""
 		}
 	}
-	if prefix := len(topDir); prefix != 0 {
-		for _, file := range files {
-			f := file.(*zipFile)
-			f.Name = f.Name[prefix:]
-		}
+	if len(s.files) == 0 {
+		return nil, &errors.ValidationError{Msg: "zip file has no files"}
 	}
-
-	return m.CreateProject(ctx, &types.CreateProjectRequest{
-		AddHeader:          request.AddHeader,
-		Compiler:           request.Compiler,
-		Files:              files,
-		HasDefaultName:     request.HasDefaultName,
-		Name:               request.Name,
-		SpellCheckLanguage: "inherit",
-		UserId:             request.Session.User.Id,
-	}, response)
+	return &s, nil
 }
@@ -0,0 +1,153 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package projectUpload
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/constants"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+)
+
+type testZipEntry struct {
+	name             string
+	uncompressedSize uint64
+}
+
+func buildTestZip(t *testing.T, entries []testZipEntry) *zip.Reader {
+	buf := bytes.Buffer{}
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		if e.uncompressedSize == 0 {
+			if _, err := w.Create(e.name); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		// Claim a size in the central directory without storing the data.
+		_, err := w.CreateRaw(&zip.FileHeader{
+			Name:               e.name,
+			Method:             zip.Deflate,
+			UncompressedSize64: e.uncompressedSize,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func Test_scanZip(t *testing.T) {
+	tests := []struct {
+		name       string
+		entries    []testZipEntry
+		maxFiles   int
+		wantTopDir string
+		wantFiles  int
+		wantErr    bool
+	}{
+		{
+			name: "flat",
+			entries: []testZipEntry{
+				{name: "main.tex"}, {name: "figures/a.png"},
+			},
+			maxFiles:  10,
+			wantFiles: 2,
+		},
+		{
+			name: "single top-level folder",
+			entries: []testZipEntry{
+				{name: "paper/"}, {name: "paper/main.tex"},
+				{name: "paper/figures/a.png"},
+			},
+			maxFiles:   10,
+			wantTopDir: "paper/",
+			wantFiles:  2,
+		},
+		{
+			name: "too many files",
+			entries: []testZipEntry{
+				{name: "a.tex"}, {name: "b.tex"}, {name: "c.tex"},
+			},
+			maxFiles: 2,
+			wantErr:  true,
+		},
+		{
+			name: "large file",
+			entries: []testZipEntry{
+				{name: "a.bin", uncompressedSize: constants.MaxUploadSize + 1},
+			},
+			maxFiles: 10,
+			wantErr:  true,
+		},
+		{
+			name: "total at limit",
+			entries: []testZipEntry{
+				{name: "a.bin", uncompressedSize: constants.MaxUploadSize / 2},
+				{name: "b.bin", uncompressedSize: constants.MaxUploadSize / 2},
+			},
+			maxFiles:  10,
+			wantFiles: 2,
+		},
+		{
+			name: "large total",
+			entries: []testZipEntry{
+				{name: "a.bin", uncompressedSize: constants.MaxUploadSize / 2},
+				{name: "b.bin", uncompressedSize: constants.MaxUploadSize / 2},
+				{name: "c.bin", uncompressedSize: 1},
+			},
+			maxFiles: 10,
+			wantErr:  true,
+		},
+		{
+			name:     "empty",
+			entries:  []testZipEntry{{name: "empty/"}},
+			maxFiles: 10,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := buildTestZip(t, tt.entries)
+			got, err := scanZip(r, tt.maxFiles)
+			if tt.wantErr {
+				if !errors.IsValidationError(err) {
+					t.Errorf("scanZip() error = %v, want validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scanZip() error = %v", err)
+			}
+			if got.topDir != tt.wantTopDir {
+				t.Errorf("scanZip() topDir = %q, want %q", got.topDir, tt.wantTopDir)
+			}
+			if len(got.files) != tt.wantFiles {
+				t.Errorf("scanZip() files = %d, want %d", len(got.files), tt.wantFiles)
+			}
+		})
+	}
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2022-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -27,6 +27,8 @@ type Manager interface {
 	GetDictionary(ctx context.Context, request *types.GetDictionaryRequest, response *types.GetDictionaryResponse) error
 	LearnWord(ctx context.Context, request *types.LearnWordRequest) error
 	UnlearnWord(ctx context.Context, request *types.UnlearnWordRequest) error
+	ExportLearnedWords(ctx context.Context, request *types.ExportLearnedWordsRequest, response *types.ExportLearnedWordsResponse) error
+	ImportLearnedWords(ctx context.Context, request *types.ImportLearnedWordsRequest) error
 }
 
 func New(um user.Manager) Manager {
@@ -68,3 +70,26 @@ func (m *manager) UnlearnWord(ctx context.Context, request *types.UnlearnWordReq
 	}
 	return m.um.UnlearnWord(ctx, request.Session.User.Id, request.Word)
 }
+
+func (m *manager) ExportLearnedWords(ctx context.Context, request *types.ExportLearnedWordsRequest, response *types.ExportLearnedWordsResponse) error {
+	if err := request.Session.CheckIsLoggedIn(); err != nil {
+		return err
+	}
+	u := user.LearnedWordsField{}
+	if err := m.um.GetUser(ctx, request.Session.User.Id, &u); err != nil {
+		return err
+	}
+	response.Words = u.LearnedWords
+	return nil
+}
+
+func (m *manager) ImportLearnedWords(ctx context.Context, request *types.ImportLearnedWordsRequest) error {
+	if err := request.Session.CheckIsLoggedIn(); err != nil {
+		return err
+	}
+	request.Preprocess()
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	return m.um.LearnWords(ctx, request.Session.User.Id, request.Words)
+}
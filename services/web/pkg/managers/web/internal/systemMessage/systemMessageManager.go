@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -32,6 +32,8 @@ import (
 type Manager interface {
 	GetAllCached(ctx context.Context, userId sharedTypes.UUID) ([]systemMessage.Full, error)
 	GetAllCachedOnly(userId sharedTypes.UUID) ([]systemMessage.Full, bool)
+	GetForProject(ctx context.Context, userId, projectId sharedTypes.UUID) ([]systemMessage.Full, error)
+	GetForProjectCachedOnly(userId, projectId sharedTypes.UUID) ([]systemMessage.Full, bool)
 }
 
 type manager struct {
@@ -41,6 +43,7 @@ type manager struct {
 	pending pendingOperation.PendingOperation
 	expires time.Time
 	cached  []systemMessage.Full
+	all     []systemMessage.Full
 }
 
 var noMessages = make([]systemMessage.Full, 0)
@@ -49,6 +52,7 @@ func New(db *pgxpool.Pool) Manager {
 	return &manager{
 		sm:     systemMessage.New(db),
 		cached: noMessages,
+		all:    noMessages,
 	}
 }
 
@@ -57,10 +61,11 @@ func (m *manager) GetAllCached(ctx context.Context, userId sharedTypes.UUID) ([]
 		// Hide messages for logged out users.
 		return noMessages, nil
 	}
-	if messages, ok := m.fast(); ok {
+	if messages, _, ok := m.fast(); ok {
 		return messages, nil
 	}
-	return m.slow(ctx)
+	messages, _, err := m.slow(ctx)
+	return messages, err
 }
 
 func (m *manager) GetAllCachedOnly(userId sharedTypes.UUID) ([]systemMessage.Full, bool) {
@@ -68,27 +73,65 @@ func (m *manager) GetAllCachedOnly(userId sharedTypes.UUID) ([]systemMessage.Ful
 		// Hide messages for logged out users.
 		return noMessages, true
 	}
-	if messages, ok := m.fast(); ok {
+	if messages, _, ok := m.fast(); ok {
 		return messages, true
 	}
 	return nil, false
 }
 
-func (m *manager) fast() ([]systemMessage.Full, bool) {
+// GetForProject returns the global messages and the ones for the given
+// project. The caller must check access to the project.
+func (m *manager) GetForProject(ctx context.Context, userId, projectId sharedTypes.UUID) ([]systemMessage.Full, error) {
+	if userId.IsZero() {
+		// Hide messages for logged out users.
+		return noMessages, nil
+	}
+	if _, all, ok := m.fast(); ok {
+		return filterForProject(all, projectId), nil
+	}
+	_, all, err := m.slow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filterForProject(all, projectId), nil
+}
+
+func (m *manager) GetForProjectCachedOnly(userId, projectId sharedTypes.UUID) ([]systemMessage.Full, bool) {
+	if userId.IsZero() {
+		// Hide messages for logged out users.
+		return noMessages, true
+	}
+	if _, all, ok := m.fast(); ok {
+		return filterForProject(all, projectId), true
+	}
+	return nil, false
+}
+
+func filterForProject(all []systemMessage.Full, projectId sharedTypes.UUID) []systemMessage.Full {
+	messages := make([]systemMessage.Full, 0, len(all))
+	for _, message := range all {
+		if message.IsGlobal() || *message.ProjectId == projectId {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+func (m *manager) fast() ([]systemMessage.Full, []systemMessage.Full, bool) {
 	m.l.RLock()
 	defer m.l.RUnlock()
 	if m.expires.After(time.Now()) {
-		return m.cached, true
+		return m.cached, m.all, true
 	}
-	return nil, false
+	return nil, nil, false
 }
 
-func (m *manager) slow(ctx context.Context) ([]systemMessage.Full, error) {
+func (m *manager) slow(ctx context.Context) ([]systemMessage.Full, []systemMessage.Full, error) {
 	m.l.Lock()
 	if m.expires.After(time.Now()) {
 		defer m.l.Unlock()
 		// Another goroutine refreshed the cache already.
-		return m.cached, nil
+		return m.cached, m.all, nil
 	}
 	pending := m.pending
 	if pending == nil {
@@ -105,22 +148,36 @@ func (m *manager) slow(ctx context.Context) ([]systemMessage.Full, error) {
 		m.pending = nil
 	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return m.cached, nil
+	return m.cached, m.all, nil
 }
 
 func (m *manager) refresh() error {
 	ctx, done := context.WithTimeout(context.Background(), 10*time.Second)
 	defer done()
-	messages, err := m.sm.GetAll(ctx)
+	all, err := m.sm.GetAll(ctx)
 	if err != nil {
 		return err
 	}
+	messages := make([]systemMessage.Full, 0, len(all))
+	for _, message := range all {
+		if message.IsGlobal() {
+			messages = append(messages, message)
+		}
+	}
 	jitter := time.Duration(rand.Int63n(int64(2 * time.Second)))
+	expires := time.Now().Add(10*time.Second + jitter)
+	for _, message := range all {
+		if message.ExpiresAt != nil && message.ExpiresAt.Before(expires) {
+			// Drop the message from the cache once it expires.
+			expires = *message.ExpiresAt
+		}
+	}
 	m.l.Lock()
 	defer m.l.Unlock()
 	m.cached = messages
-	m.expires = time.Now().Add(10*time.Second + jitter)
+	m.all = all
+	m.expires = expires
 	return nil
 }
@@ -0,0 +1,67 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package thumbnail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/filestore/pkg/managers/filestore"
+)
+
+// CleanupStaleThumbnails deletes cached thumbnails that are older than
+// maxAge. They get generated again on the next request.
+func (m *manager) CleanupStaleThumbnails(ctx context.Context, dryRun bool, start time.Time) error {
+	cutOff := start.Add(-m.maxAge)
+	nFailed := 0
+	err := m.fm.IterateThumbnails(ctx, func(t filestore.ThumbnailObject) error {
+		if !t.LastModified.Before(cutOff) {
+			return nil
+		}
+		if dryRun {
+			log.Printf(
+				"dry-run thumbnail cleanup: %s/%d",
+				t.Hash, t.MaxDimension,
+			)
+			return nil
+		}
+		if err := m.fm.DeleteThumbnail(ctx, t.Hash, t.MaxDimension); err != nil {
+			err = errors.Tag(
+				err,
+				fmt.Sprintf(
+					"thumbnail cleanup failed: %s/%d",
+					t.Hash, t.MaxDimension,
+				),
+			)
+			nFailed++
+			log.Println(err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		err = errors.Tag(err, "iterate thumbnails")
+	}
+	if nFailed != 0 {
+		err = errors.Merge(err, errors.New(fmt.Sprintf(
+			"cleanup failed for %d thumbnails", nFailed,
+		)))
+	}
+	return err
+}
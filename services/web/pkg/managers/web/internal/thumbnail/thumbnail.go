@@ -0,0 +1,233 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os/exec"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/filestore/pkg/managers/filestore"
+	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/imageResize"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+type Manager interface {
+	CleanupStaleThumbnails(ctx context.Context, dryRun bool, start time.Time) error
+	GetProjectFileThumbnail(ctx context.Context, request *types.GetProjectFileThumbnailRequest, response *types.GetProjectFileThumbnailResponse) error
+}
+
+const (
+	defaultConcurrency = 2
+	defaultMaxAge      = 30 * 24 * time.Hour
+	defaultTimeout     = 30 * time.Second
+)
+
+func New(o types.ThumbnailOptions, pm project.Manager, fm filestore.Manager) Manager {
+	n := o.Concurrency
+	if n == 0 {
+		n = defaultConcurrency
+	}
+	generateSlots := make(chan struct{}, n)
+	for i := 0; i < n; i++ {
+		generateSlots <- struct{}{}
+	}
+	maxAge := o.MaxAge
+	if maxAge == 0 {
+		maxAge = defaultMaxAge
+	}
+	timeout := o.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return &manager{
+		fm:            fm,
+		pm:            pm,
+		generateSlots: generateSlots,
+		maxAge:        maxAge,
+		maxDimension:  o.MaxDimension,
+		pdfCommand:    o.PDFCommand,
+		timeout:       timeout,
+	}
+}
+
+type manager struct {
+	fm            filestore.Manager
+	pm            project.Manager
+	generateSlots chan struct{}
+	maxAge        time.Duration
+	maxDimension  int
+	pdfCommand    []string
+	timeout       time.Duration
+}
+
+var (
+	errDisabled = &errors.UnprocessableEntityError{
+		Msg: "thumbnails are disabled",
+	}
+	errUnsupported = &errors.UnprocessableEntityError{
+		Msg: "file type does not support thumbnails",
+	}
+)
+
+type kind int
+
+const (
+	kindUnsupported kind = iota
+	kindImage
+	kindPDF
+)
+
+func (m *manager) getKind(name sharedTypes.Filename) kind {
+	switch sharedTypes.PathName(name).Type() {
+	case "gif", "jpeg", "jpg", "png":
+		return kindImage
+	case "pdf":
+		if len(m.pdfCommand) > 0 {
+			return kindPDF
+		}
+	}
+	return kindUnsupported
+}
+
+type inMemoryFile struct {
+	*bytes.Reader
+}
+
+func (f inMemoryFile) Close() error {
+	return nil
+}
+
+func (m *manager) GetProjectFileThumbnail(ctx context.Context, request *types.GetProjectFileThumbnailRequest, response *types.GetProjectFileThumbnailResponse) error {
+	if m.maxDimension == 0 {
+		return errDisabled
+	}
+	projectId := request.ProjectId
+	fileId := request.FileId
+	userId := request.Session.User.Id
+	token := request.Session.GetAnonTokenAccess(projectId)
+	f, err := m.pm.GetFile(ctx, projectId, userId, token, fileId)
+	if err != nil {
+		return errors.Tag(err, "get file")
+	}
+	k := m.getKind(f.Name)
+	if k == kindUnsupported {
+		return errUnsupported
+	}
+
+	if f.Hash != "" {
+		s, r, err2 := m.fm.GetReadStreamForThumbnail(
+			ctx, f.Hash, m.maxDimension,
+		)
+		if err2 == nil {
+			response.Reader = r
+			response.Size = s
+			return nil
+		}
+		if !errors.IsNotFoundError(err2) {
+			return errors.Tag(err2, "get cached thumbnail")
+		}
+	}
+
+	waitCtx, done := context.WithTimeout(ctx, m.timeout)
+	defer done()
+	select {
+	case <-waitCtx.Done():
+		if err = ctx.Err(); err != nil {
+			// Parent context cancelled.
+			return err
+		}
+		return &errors.RateLimitedError{RetryIn: m.timeout}
+	case <-m.generateSlots:
+	}
+	defer func() { m.generateSlots <- struct{}{} }()
+
+	_, r, err := m.fm.GetReadStreamForProjectFile(ctx, projectId, fileId)
+	if err != nil {
+		return errors.Tag(err, "get filestream")
+	}
+	defer func() { _ = r.Close() }()
+	blob, err := m.generate(ctx, k, r)
+	if err != nil {
+		return err
+	}
+
+	if f.Hash != "" {
+		err = m.fm.SendStreamForThumbnail(
+			ctx, f.Hash, m.maxDimension,
+			bytes.NewReader(blob), int64(len(blob)),
+		)
+		if err != nil {
+			return errors.Tag(err, "cache thumbnail")
+		}
+	}
+	response.Reader = inMemoryFile{Reader: bytes.NewReader(blob)}
+	response.Size = int64(len(blob))
+	return nil
+}
+
+// generate produces a png thumbnail that fits into maxDimension.
+func (m *manager) generate(ctx context.Context, k kind, r io.Reader) ([]byte, error) {
+	if k == kindPDF {
+		blob, err := m.renderPDF(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(blob)
+	}
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Tag(err, "read image")
+	}
+	dst, _, err := imageResize.Resize(ctx, raw, m.maxDimension)
+	if err == imageResize.ErrUnsupported {
+		return nil, errUnsupported
+	}
+	if err != nil {
+		return nil, errors.Tag(err, "resize image")
+	}
+	buf := bytes.Buffer{}
+	if err = png.Encode(&buf, dst); err != nil {
+		return nil, errors.Tag(err, "encode thumbnail")
+	}
+	return buf.Bytes(), nil
+}
+
+// renderPDF renders the first page of a PDF file into an image.
+func (m *manager) renderPDF(ctx context.Context, r io.Reader) ([]byte, error) {
+	ctx, done := context.WithTimeout(ctx, m.timeout)
+	defer done()
+	buf := bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, m.pdfCommand[0], m.pdfCommand[1:]...)
+	cmd.Stdin = r
+	cmd.Stdout = &buf
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, errors.Tag(ctxErr, "render pdf")
+		}
+		return nil, errors.Tag(err, "render pdf")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,208 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+)
+
+func encodeTestImage(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, color.RGBA{
+				R: uint8(x), G: uint8(y), B: 128, A: 255,
+			})
+		}
+	}
+	buf := bytes.Buffer{}
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("encode jpeg: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func checkThumbnail(t *testing.T, blob []byte, wantW, wantH int) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(blob))
+	if err != nil {
+		t.Fatalf("decode thumbnail: %s", err)
+	}
+	if format != "png" {
+		t.Errorf("generate() format = %s, want png", format)
+	}
+	if cfg.Width != wantW || cfg.Height != wantH {
+		t.Errorf(
+			"generate() = %dx%d, want %dx%d",
+			cfg.Width, cfg.Height, wantW, wantH,
+		)
+	}
+}
+
+func Test_manager_generate_Image(t *testing.T) {
+	m := &manager{maxDimension: 64}
+	blob := encodeTestImage(t, 256, 128)
+	got, err := m.generate(context.Background(), kindImage, bytes.NewReader(blob))
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	checkThumbnail(t, got, 64, 32)
+}
+
+func Test_manager_generate_NotAnImage(t *testing.T) {
+	m := &manager{maxDimension: 64}
+	_, err := m.generate(context.Background(), kindImage, strings.NewReader("foo"))
+	if err != errUnsupported {
+		t.Errorf("generate() error = %v, want %v", err, errUnsupported)
+	}
+}
+
+// buildTestPDF produces a minimal PDF with one blank page per MediaBox.
+func buildTestPDF(pages ...[2]int) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+	}
+	kids := make([]string, len(pages))
+	for i, p := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", len(objects)+1)
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] >>",
+			p[0], p[1],
+		))
+	}
+	objects[1] = fmt.Sprintf(
+		"<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "), len(pages),
+	)
+
+	buf := bytes.Buffer{}
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = buf.Len()
+		_, _ = fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := buf.Len()
+	_, _ = fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, o := range offsets {
+		_, _ = fmt.Fprintf(&buf, "%010d 00000 n \n", o)
+	}
+	_, _ = fmt.Fprintf(
+		&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects)+1, xref,
+	)
+	return buf.Bytes()
+}
+
+const pdfRendererEnv = "THUMBNAIL_TEST_PDF_RENDERER"
+
+// TestPDFRendererProcess is not a test, but a stand-in for a PDF renderer
+// when running as a sub-process of Test_manager_generate_PDF.
+// It renders the MediaBox of the first page from stdin into a jpeg image.
+func TestPDFRendererProcess(t *testing.T) {
+	mode := os.Getenv(pdfRendererEnv)
+	if mode == "" {
+		return
+	}
+	defer os.Exit(0)
+	blob, err := io.ReadAll(os.Stdin)
+	if err != nil || !bytes.HasPrefix(blob, []byte("%PDF-")) {
+		os.Exit(1)
+	}
+	if mode == "hang" {
+		time.Sleep(time.Minute)
+	}
+	var w, h int
+	_, rest, _ := bytes.Cut(blob, []byte("/Type /Page /Parent"))
+	_, rest, _ = bytes.Cut(rest, []byte("/MediaBox"))
+	_, err = fmt.Sscanf(string(rest), " [0 0 %d %d]", &w, &h)
+	if err != nil {
+		os.Exit(1)
+	}
+	_, _ = os.Stdout.Write(encodeTestImage(t, w, h))
+}
+
+func Test_manager_generate_PDF(t *testing.T) {
+	pdf := buildTestPDF([2]int{100, 400}, [2]int{400, 100})
+	newManager := func(t *testing.T, mode string) *manager {
+		t.Setenv(pdfRendererEnv, mode)
+		return &manager{
+			maxDimension: 64,
+			pdfCommand: []string{
+				os.Args[0], "-test.run=^TestPDFRendererProcess$",
+			},
+			timeout: 5 * time.Second,
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("renders first page", func(t *testing.T) {
+		m := newManager(t, "render")
+		got, err := m.generate(ctx, kindPDF, bytes.NewReader(pdf))
+		if err != nil {
+			t.Fatalf("generate() error = %v", err)
+		}
+		checkThumbnail(t, got, 16, 64)
+	})
+	t.Run("renderer fails", func(t *testing.T) {
+		m := newManager(t, "render")
+		_, err := m.generate(ctx, kindPDF, strings.NewReader("foo"))
+		if err == nil || errors.GetCause(err) == errUnsupported {
+			t.Errorf("generate() error = %v, want render error", err)
+		}
+	})
+	t.Run("renderer times out", func(t *testing.T) {
+		m := newManager(t, "hang")
+		m.timeout = 100 * time.Millisecond
+		_, err := m.generate(ctx, kindPDF, bytes.NewReader(pdf))
+		if errors.GetCause(err) != context.DeadlineExceeded {
+			t.Errorf("generate() error = %v, want timeout", err)
+		}
+	})
+	t.Run("ghostscript", func(t *testing.T) {
+		if _, err := exec.LookPath("gs"); err != nil {
+			t.Skip("ghostscript is not installed")
+		}
+		m := &manager{
+			maxDimension: 64,
+			pdfCommand: []string{
+				"gs", "-q", "-dSAFER", "-dBATCH", "-dNOPAUSE",
+				"-sDEVICE=png16m", "-dFirstPage=1", "-dLastPage=1",
+				"-r72", "-sOutputFile=-", "-",
+			},
+			timeout: defaultTimeout,
+		}
+		got, err := m.generate(ctx, kindPDF, bytes.NewReader(pdf))
+		if err != nil {
+			t.Fatalf("generate() error = %v", err)
+		}
+		checkThumbnail(t, got, 16, 64)
+	})
+}
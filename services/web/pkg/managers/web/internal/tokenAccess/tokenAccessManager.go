@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,10 +18,16 @@ package tokenAccess
 
 import (
 	"context"
+	"encoding/json"
+	"log"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/notification"
 	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/models/user"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	"github.com/das7pad/overleaf-go/pkg/templates"
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
@@ -33,23 +39,40 @@ type Manager interface {
 	TokenAccessPage(ctx context.Context, request *types.TokenAccessPageRequest, response *types.TokenAccessPageResponse) error
 }
 
-func New(options *types.Options, ps *templates.PublicSettings, pm project.Manager) Manager {
+func New(options *types.Options, ps *templates.PublicSettings, db *pgxpool.Pool, pm project.Manager) Manager {
 	n := options.RateLimits.LinkSharingTokenLookupConcurrency
 	lookupSlots := make(chan struct{}, n)
 	for i := int64(0); i < n; i++ {
 		lookupSlots <- struct{}{}
 	}
 	return &manager{
+		nm:          notification.New(db),
 		pm:          pm,
 		ps:          ps,
 		lookupSlots: lookupSlots,
+
+		anonymousAccessDisabled: options.AnonymousTokenAccessDisabled,
 	}
 }
 
 type manager struct {
+	nm          notification.Manager
 	pm          project.Manager
 	ps          *templates.PublicSettings
 	lookupSlots chan struct{}
+
+	anonymousAccessDisabled bool
+}
+
+func (m *manager) checkAnonymousAccess(request *types.WithSession) error {
+	if m.anonymousAccessDisabled {
+		return request.Session.CheckIsLoggedIn()
+	}
+	return nil
+}
+
+func getNotificationKey(projectId, userId sharedTypes.UUID) string {
+	return "token-access-" + projectId.String() + "-" + userId.String()
 }
 
 func (m *manager) GrantTokenAccessReadAndWrite(ctx context.Context, request *types.GrantTokenAccessRequest, response *types.GrantTokenAccessResponse) error {
@@ -63,6 +86,9 @@ func (m *manager) GrantTokenAccessReadAndWrite(ctx context.Context, request *typ
 }
 
 func (m *manager) GrantTokenAccessReadOnly(ctx context.Context, request *types.GrantTokenAccessRequest, response *types.GrantTokenAccessResponse) error {
+	if err := m.checkAnonymousAccess(&request.WithSession); err != nil {
+		return err
+	}
 	return m.grantTokenAccess(
 		ctx, request, response,
 		sharedTypes.PrivilegeLevelReadOnly,
@@ -86,12 +112,15 @@ func (m *manager) grantTokenAccess(ctx context.Context, request *types.GrantToke
 	if request.Session.IsLoggedIn() {
 		existing, _ := p.GetPrivilegeLevelAuthenticated()
 		if fromToken.PrivilegeLevel.IsHigherThan(existing.PrivilegeLevel) {
+			// NOTE: The token type determines the lookup, the db query
+			//       applies the per-project cap again.
 			err = m.pm.GrantTokenAccess(
-				ctx, projectId, userId, token, fromToken.PrivilegeLevel,
+				ctx, projectId, userId, token, privilegeLevel,
 			)
 			if err != nil {
 				return errors.Tag(err, "grant access")
 			}
+			go m.notifyOwner(p, request.Session.User.ToPublicUserInfo())
 		}
 	} else {
 		request.Session.AddAnonTokenAccess(projectId, token)
@@ -100,12 +129,41 @@ func (m *manager) grantTokenAccess(ctx context.Context, request *types.GrantToke
 	return nil
 }
 
+func (m *manager) notifyOwner(p *project.ForTokenAccessDetails, u user.WithPublicInfo) {
+	ctx, done := context.WithTimeout(context.Background(), 10*time.Second)
+	defer done()
+
+	n := notification.Notification{}
+	n.Expires = time.Now().Add(30 * 24 * time.Hour)
+	n.Key = getNotificationKey(p.Id, u.Id)
+	n.TemplateKey = "notification_token_access_granted"
+	n.UserId = p.OwnerId
+	blob, err := json.Marshal(map[string]interface{}{
+		"userName":    u.DisplayName(),
+		"projectName": p.Name,
+		"projectId":   p.Id.String(),
+	})
+	if err == nil {
+		n.MessageOptions = blob
+		err = m.nm.Create(ctx, n)
+	}
+	if err != nil {
+		log.Printf(
+			"%s/%s: notify owner about token access: %s",
+			p.Id, u.Id, err.Error(),
+		)
+	}
+}
+
 func (m *manager) TokenAccessPage(ctx context.Context, request *types.TokenAccessPageRequest, response *types.TokenAccessPageResponse) error {
 	userId := request.Session.User.Id
 	var postULR *sharedTypes.URL
 	var privilegeLevel sharedTypes.PrivilegeLevel
 	switch {
 	case request.Token.ValidateReadOnly() == nil:
+		if err := m.checkAnonymousAccess(&request.WithSession); err != nil {
+			return err
+		}
 		privilegeLevel = sharedTypes.PrivilegeLevelReadOnly
 		postULR = m.ps.SiteURL.
 			WithPath("/api/grant/ro/" + string(request.Token))
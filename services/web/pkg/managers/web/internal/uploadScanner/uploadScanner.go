@@ -0,0 +1,79 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package uploadScanner
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+// Scanner inspects uploaded files before they get added to a project.
+// A nil error accepts the file.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) error
+}
+
+var ErrRejected = &errors.ValidationError{
+	Msg: "file rejected by upload scanner",
+}
+
+const defaultTimeout = 30 * time.Second
+
+func New(o types.UploadScannerOptions) Scanner {
+	if len(o.Command) == 0 {
+		return noopScanner{}
+	}
+	timeout := o.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return &commandScanner{command: o.Command, timeout: timeout}
+}
+
+type noopScanner struct{}
+
+func (noopScanner) Scan(context.Context, io.Reader) error {
+	return nil
+}
+
+type commandScanner struct {
+	command []string
+	timeout time.Duration
+}
+
+func (s *commandScanner) Scan(ctx context.Context, r io.Reader) error {
+	ctx, done := context.WithTimeout(ctx, s.timeout)
+	defer done()
+	cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...)
+	cmd.Stdin = r
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return errors.Tag(ctxErr, "scan upload")
+	}
+	if _, ok := err.(*exec.ExitError); ok {
+		return ErrRejected
+	}
+	return errors.Tag(err, "scan upload")
+}
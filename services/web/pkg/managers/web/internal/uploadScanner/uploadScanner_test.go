@@ -0,0 +1,71 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package uploadScanner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestScanner_Scan(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		command []string
+		content string
+		wantErr func(err error) bool
+	}{
+		{
+			name:    "disabled",
+			content: "bad",
+			wantErr: func(err error) bool { return err == nil },
+		},
+		{
+			name:    "good",
+			command: []string{"sh", "-c", "! grep -q bad"},
+			content: "good",
+			wantErr: func(err error) bool { return err == nil },
+		},
+		{
+			name:    "bad",
+			command: []string{"sh", "-c", "! grep -q bad"},
+			content: "bad",
+			wantErr: errors.IsValidationError,
+		},
+		{
+			name:    "missing command",
+			command: []string{"/non-existing/scanner"},
+			content: "good",
+			wantErr: func(err error) bool {
+				return err != nil && !errors.IsValidationError(err)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(types.UploadScannerOptions{Command: tt.command})
+			err := s.Scan(ctx, strings.NewReader(tt.content))
+			if !tt.wantErr(err) {
+				t.Errorf("Scan() error = %v", err)
+			}
+		})
+	}
+}
@@ -48,6 +48,9 @@ func (m *manager) CMDCreateUser(ctx context.Context, r *types.CMDCreateUserReque
 	}
 
 	u := user.NewUser(r.Email)
+	u.FirstName = r.FirstName
+	u.LastName = r.LastName
+	u.OneTimeTokenExpiresIn = r.TokenExpiry
 	if err := u.Id.Populate(); err != nil {
 		return err
 	}
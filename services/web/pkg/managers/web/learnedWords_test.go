@@ -0,0 +1,82 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_ExportImportLearnedWords(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	sess := registerUser(t, ctx, wm)
+
+	export := func() []string {
+		t.Helper()
+		res := types.ExportLearnedWordsResponse{}
+		err := wm.ExportLearnedWords(ctx, &types.ExportLearnedWordsRequest{
+			WithSession: types.WithSession{Session: sess},
+		}, &res)
+		if err != nil {
+			t.Fatalf("ExportLearnedWords(): %s", err)
+		}
+		return res.Words
+	}
+	importWords := func(words ...string) error {
+		return wm.ImportLearnedWords(ctx, &types.ImportLearnedWordsRequest{
+			WithSession: types.WithSession{Session: sess},
+			Words:       words,
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		if words := export(); len(words) != 0 {
+			t.Errorf("words = %q, want none", words)
+		}
+	})
+	t.Run("export learned", func(t *testing.T) {
+		err := wm.LearnWord(ctx, &types.LearnWordRequest{
+			WithSession: types.WithSession{Session: sess},
+			Word:        "foo",
+		})
+		if err != nil {
+			t.Fatalf("LearnWord(): %s", err)
+		}
+		if words := export(); !reflect.DeepEqual(words, []string{"foo"}) {
+			t.Errorf("words = %q, want [foo]", words)
+		}
+	})
+	t.Run("import with dedupe", func(t *testing.T) {
+		if err := importWords("bar", "foo", " ", "bar", "baz\n"); err != nil {
+			t.Fatalf("ImportLearnedWords(): %s", err)
+		}
+		want := []string{"foo", "bar", "baz"}
+		if words := export(); !reflect.DeepEqual(words, want) {
+			t.Errorf("words = %q, want %q", words, want)
+		}
+	})
+	t.Run("import nothing", func(t *testing.T) {
+		if err := importWords("", " "); !errors.IsValidationError(err) {
+			t.Errorf("expected validation error, got %v", err)
+		}
+	})
+}
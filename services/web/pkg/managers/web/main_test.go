@@ -0,0 +1,133 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/httpUtils"
+	"github.com/das7pad/overleaf-go/pkg/integrationTests"
+	"github.com/das7pad/overleaf-go/pkg/models/oneTimeToken"
+	"github.com/das7pad/overleaf-go/pkg/session"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/document-updater/pkg/managers/documentUpdater"
+	documentUpdaterTypes "github.com/das7pad/overleaf-go/services/document-updater/pkg/types"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestMain(m *testing.M) {
+	integrationTests.Setup(m)
+}
+
+func newTestManager(t testing.TB, ctx context.Context) Manager {
+	o := types.Options{}
+	o.FillFromEnv()
+	return newTestManagerWithOptions(t, ctx, &o)
+}
+
+func newTestManagerWithOptions(t testing.TB, ctx context.Context, o *types.Options) Manager {
+	rClient := utils.MustConnectRedis(ctx)
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(func() {
+		_ = rClient.Close()
+		db.Close()
+	})
+
+	wm, err := New(o, db, rClient, "", nil, nil)
+	if err != nil {
+		t.Fatalf("create web manager: %s", err)
+	}
+	return wm
+}
+
+func newTestManagerWithDocumentUpdater(t testing.TB, ctx context.Context, o *types.Options) (Manager, documentUpdater.Manager) {
+	rClient := utils.MustConnectRedis(ctx)
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(func() {
+		_ = rClient.Close()
+		db.Close()
+	})
+
+	dumOptions := documentUpdaterTypes.Options{}
+	dumOptions.FillFromEnv()
+	dumOptions.SnapshotEncryption = o.SnapshotEncryption
+	dum, err := documentUpdater.New(&dumOptions, db, rClient)
+	if err != nil {
+		t.Fatalf("create document updater: %s", err)
+	}
+	wm, err := New(o, db, rClient, "", dum, nil)
+	if err != nil {
+		t.Fatalf("create web manager: %s", err)
+	}
+	return wm, dum
+}
+
+func newSession(t testing.TB, ctx context.Context, wm Manager) (*httpUtils.Context, *session.Session) {
+	r := httptest.NewRequest(http.MethodTrace, "/", nil)
+	r = r.WithContext(ctx)
+	w := httptest.NewRecorder()
+	var c *httpUtils.Context
+	httpUtils.HandlerFunc(func(c2 *httpUtils.Context) {
+		c = c2
+	}).ServeHTTP(w, r)
+
+	sess, err := wm.GetOrCreateSession(c)
+	if err != nil {
+		t.Fatalf("create session: %s", err)
+	}
+	return c, sess
+}
+
+func registerUser(t testing.TB, ctx context.Context, wm Manager) *session.Session {
+	c, sess := newSession(t, ctx, wm)
+	username, err := oneTimeToken.GenerateNewToken()
+	if err != nil {
+		t.Fatalf("generate username: %s", err)
+	}
+	password, err := oneTimeToken.GenerateNewToken()
+	if err != nil {
+		t.Fatalf("generate password: %s", err)
+	}
+	err = wm.RegisterUser(c, &types.RegisterUserRequest{
+		WithSession: types.WithSession{Session: sess},
+		IPAddress:   "127.0.0.1",
+		Email:       sharedTypes.Email(fmt.Sprintf("%s@foo.bar", username)),
+		Password:    types.UserPassword(password),
+	}, &types.RegisterUserResponse{})
+	if err != nil {
+		t.Fatalf("register user: %s", err)
+	}
+	return sess
+}
+
+func createProject(t testing.TB, ctx context.Context, wm Manager, owner *session.Session) sharedTypes.UUID {
+	res := types.CreateExampleProjectResponse{}
+	err := wm.CreateExampleProject(ctx, &types.CreateExampleProjectRequest{
+		WithSession: types.WithSession{Session: owner},
+		Name:        "foo",
+		Template:    "none",
+	}, &res)
+	if err != nil {
+		t.Fatalf("create project: %s", err)
+	}
+	return *res.ProjectId
+}
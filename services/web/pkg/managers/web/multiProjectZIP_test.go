@@ -0,0 +1,107 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_CreateMultiProjectZIP(t *testing.T) {
+	ctx := context.Background()
+	o := types.Options{}
+	o.FillFromEnv()
+	o.ProjectDownload.MultiProjectConcurrency = 2
+	wm, _ := newTestManagerWithDocumentUpdater(t, ctx, &o)
+	owner := registerUser(t, ctx, wm)
+	other := registerUser(t, ctx, wm)
+	owned := []sharedTypes.UUID{
+		createProject(t, ctx, wm, owner),
+		createProject(t, ctx, wm, owner),
+		createProject(t, ctx, wm, owner),
+	}
+	foreign := createProject(t, ctx, wm, other)
+
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	assertNoBuffers := func(t *testing.T, keep string) {
+		t.Helper()
+		entries, err2 := os.ReadDir(tmp)
+		if err2 != nil {
+			t.Fatalf("read tmp dir: %s", err2)
+		}
+		for _, e := range entries {
+			if e.Name() != keep {
+				t.Errorf("leaked buffer: %s", e.Name())
+			}
+		}
+	}
+
+	t.Run("all owned", func(t *testing.T) {
+		response := types.CreateProjectZIPResponse{}
+		err := wm.CreateMultiProjectZIP(ctx, &types.CreateMultiProjectZIPRequest{
+			WithSession: types.WithSession{Session: owner},
+			Format:      types.ArchiveFormatZIP,
+			ProjectIds:  owned,
+		}, &response)
+		defer func() { _ = os.Remove(response.FSPath) }()
+		if err != nil {
+			t.Fatalf("CreateMultiProjectZIP(): %s", err)
+		}
+		assertNoBuffers(t, strings.TrimPrefix(response.FSPath, tmp+"/"))
+
+		r, err2 := zip.OpenReader(response.FSPath)
+		if err2 != nil {
+			t.Fatalf("open archive: %s", err2)
+		}
+		defer func() { _ = r.Close() }()
+		if len(r.File) != len(owned) {
+			t.Fatalf("archive has %d entries, want %d", len(r.File), len(owned))
+		}
+		for _, f := range r.File {
+			if !strings.HasSuffix(f.Name, ".zip") {
+				t.Errorf("entry %q is not a zip", f.Name)
+			}
+			if f.UncompressedSize64 == 0 {
+				t.Errorf("entry %q is empty", f.Name)
+			}
+		}
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		response := types.CreateProjectZIPResponse{}
+		err := wm.CreateMultiProjectZIP(ctx, &types.CreateMultiProjectZIPRequest{
+			WithSession: types.WithSession{Session: owner},
+			Format:      types.ArchiveFormatZIP,
+			ProjectIds:  append([]sharedTypes.UUID{foreign}, owned...),
+		}, &response)
+		defer func() { _ = os.Remove(response.FSPath) }()
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if !strings.Contains(err.Error(), foreign.String()) {
+			t.Errorf("error %q does not name project %s", err, foreign)
+		}
+		assertNoBuffers(t, strings.TrimPrefix(response.FSPath, tmp+"/"))
+	})
+}
@@ -0,0 +1,86 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	spellingTypes "github.com/das7pad/overleaf-go/services/spelling/pkg/types"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_SetSpellCheckLanguageForOwnedProjects(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	other := registerUser(t, ctx, wm)
+	owned := []sharedTypes.UUID{
+		createProject(t, ctx, wm, owner),
+		createProject(t, ctx, wm, owner),
+		createProject(t, ctx, wm, owner),
+	}
+	foreign := createProject(t, ctx, wm, other)
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	get := func(projectId sharedTypes.UUID) spellingTypes.SpellCheckLanguage {
+		t.Helper()
+		var l spellingTypes.SpellCheckLanguage
+		err := db.QueryRow(ctx, `
+SELECT spell_check_language
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&l)
+		if err != nil {
+			t.Fatalf("get spell check language: %s", err)
+		}
+		return l
+	}
+	before := get(foreign)
+
+	err := wm.SetSpellCheckLanguageForOwnedProjects(
+		ctx, &types.SetSpellCheckLanguageForOwnedProjectsRequest{
+			WithSession:        types.WithSession{Session: owner},
+			SpellCheckLanguage: "foo",
+		},
+	)
+	if !errors.IsValidationError(err) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	err = wm.SetSpellCheckLanguageForOwnedProjects(
+		ctx, &types.SetSpellCheckLanguageForOwnedProjectsRequest{
+			WithSession:        types.WithSession{Session: owner},
+			SpellCheckLanguage: "de",
+		},
+	)
+	if err != nil {
+		t.Fatalf("SetSpellCheckLanguageForOwnedProjects(): %s", err)
+	}
+	for _, projectId := range owned {
+		if l := get(projectId); l != "de" {
+			t.Errorf("owned project %s: language = %q, want de", projectId, l)
+		}
+	}
+	if l := get(foreign); l != before {
+		t.Errorf("foreign project: language = %q, want %q", l, before)
+	}
+}
@@ -0,0 +1,93 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_UploadFile_PDFPageCount(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	var rootFolderId sharedTypes.UUID
+	err := db.QueryRow(ctx, `
+SELECT root_folder_id
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&rootFolderId)
+	if err != nil {
+		t.Fatalf("get root folder: %s", err)
+	}
+
+	blob := []byte(`%PDF-1.4
+1 0 obj
+<< /Type /Catalog /Pages 2 0 R >>
+endobj
+2 0 obj
+<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>
+endobj
+3 0 obj
+<< /Type /Page /Parent 2 0 R >>
+endobj
+4 0 obj
+<< /Type /Page /Parent 2 0 R >>
+endobj
+trailer
+<< /Root 1 0 R >>
+%%EOF
+`)
+	err = wm.UploadFile(ctx, &types.UploadFileRequest{
+		ProjectId:      projectId,
+		UserId:         owner.User.Id,
+		ParentFolderId: rootFolderId,
+		UploadDetails: types.UploadDetails{
+			File:     zipUpload{Reader: bytes.NewReader(blob)},
+			FileName: "figure.pdf",
+			Size:     int64(len(blob)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("upload pdf: %s", err)
+	}
+
+	var pageCount int
+	err = db.QueryRow(ctx, `
+SELECT f.page_count
+FROM files f
+         INNER JOIN tree_nodes t ON f.id = t.id
+WHERE t.project_id = $1
+  AND t.path = 'figure.pdf'
+  AND t.deleted_at = '1970-01-01'
+`, projectId).Scan(&pageCount)
+	if err != nil {
+		t.Fatalf("get page count: %s", err)
+	}
+	if pageCount != 2 {
+		t.Errorf("page_count = %d, want 2", pageCount)
+	}
+}
@@ -0,0 +1,192 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/models/docHistory"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_GetProjectActivity(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	member := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	page := types.ProjectEditorPageResponse{}
+	err := wm.ProjectEditorPage(ctx, &types.ProjectEditorPageRequest{
+		WithSession: types.WithSession{Session: owner},
+		ProjectId:   projectId,
+	}, &page)
+	if err != nil {
+		t.Fatalf("load editor: %s", err)
+	}
+	docId := page.Data.EditorBootstrap.Project.RootDocId
+
+	editAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Microsecond)
+	err = docHistory.New(db, docHistory.Options{}).InsertBulk(
+		ctx, docId, []docHistory.ForInsert{{
+			UserId:  owner.User.Id,
+			Version: 1,
+			StartAt: editAt,
+			EndAt:   editAt,
+			Op: sharedTypes.Op{
+				{Insertion: sharedTypes.Snippet("x"), Position: 0},
+			},
+		}},
+	)
+	if err != nil {
+		t.Fatalf("insert history: %s", err)
+	}
+
+	err = wm.SendProjectMessage(ctx, &types.SendProjectChatMessageRequest{
+		ProjectId: projectId,
+		UserId:    owner.User.Id,
+		Content:   "hello",
+	})
+	if err != nil {
+		t.Fatalf("send message: %s", err)
+	}
+
+	tokens := enableTokenAccess(t, ctx, wm, owner, projectId)
+	err = wm.GrantTokenAccessReadAndWrite(ctx, &types.GrantTokenAccessRequest{
+		WithSession: types.WithSession{Session: member},
+		Token:       tokens.ReadAndWrite,
+	}, &types.GrantTokenAccessResponse{})
+	if err != nil {
+		t.Fatalf("grant token access: %s", err)
+	}
+	err = wm.TransferProjectOwnership(ctx, &types.TransferProjectOwnershipRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+		NewOwnerId: member.User.Id,
+	})
+	if err != nil {
+		t.Fatalf("transfer ownership: %s", err)
+	}
+
+	res := types.GetProjectActivityResponse{}
+	err = wm.GetProjectActivity(ctx, &types.GetProjectActivityRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    member.User.Id,
+		},
+	}, &res)
+	if err != nil {
+		t.Fatalf("GetProjectActivity(): %s", err)
+	}
+	want := []types.ProjectActivityKind{
+		types.ProjectActivityAudit,
+		types.ProjectActivityAudit,
+		types.ProjectActivityChatMessage,
+		types.ProjectActivityEdit,
+	}
+	if len(res.Activities) != len(want) {
+		t.Fatalf("GetProjectActivity() = %+v, want %v", res.Activities, want)
+	}
+	for i, a := range res.Activities {
+		if a.Kind != want[i] {
+			t.Errorf("Activities[%d].Kind = %q, want %q", i, a.Kind, want[i])
+		}
+		if i > 0 && a.Timestamp > res.Activities[i-1].Timestamp {
+			t.Errorf("Activities[%d] out of order", i)
+		}
+	}
+	if a := res.Activities[0].Audit; a.Operation != "transfer-ownership" {
+		t.Errorf("audit operation = %q", a.Operation)
+	}
+	if a := res.Activities[1].Audit; a.Operation != "join-via-token" ||
+		a.UserId == nil || *a.UserId != member.User.Id {
+		t.Errorf("audit = %+v, want join-via-token of member", a)
+	}
+	if m := res.Activities[2].ChatMessage; m.Content != "hello" {
+		t.Errorf("chat message content = %q", m.Content)
+	}
+	if res.NextBeforeTimestamp != 0 || res.NextBeforeId != nil {
+		t.Errorf("next cursor = %d/%v, want none", res.NextBeforeTimestamp, res.NextBeforeId)
+	}
+}
+
+func TestManager_GetProjectActivity_SameTimestamp(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	const n = 25
+	_, err := db.Exec(ctx, `
+INSERT INTO project_audit_log
+    (created_at, id, info, initiator_id, operation, project_id)
+SELECT transaction_timestamp() - INTERVAL '1 minute',
+       gen_random_uuid(),
+       NULL,
+       $2,
+       'test',
+       $1
+FROM generate_series(1, $3)
+`, projectId, owner.User.Id, n)
+	if err != nil {
+		t.Fatalf("insert audit log: %s", err)
+	}
+
+	seen := make(map[sharedTypes.UUID]bool)
+	r := types.GetProjectActivityRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+	}
+	for pages := 0; pages < 3; pages++ {
+		res := types.GetProjectActivityResponse{}
+		if err = wm.GetProjectActivity(ctx, &r, &res); err != nil {
+			t.Fatalf("GetProjectActivity(): %s", err)
+		}
+		for _, a := range res.Activities {
+			if a.Kind != types.ProjectActivityAudit {
+				continue
+			}
+			if seen[a.Audit.Id] {
+				t.Errorf("duplicate audit entry %s", a.Audit.Id)
+			}
+			seen[a.Audit.Id] = true
+		}
+		if res.NextBeforeTimestamp == 0 {
+			break
+		}
+		r.Before = res.NextBeforeTimestamp
+		r.BeforeId = sharedTypes.UUID{}
+		if res.NextBeforeId != nil {
+			r.BeforeId = *res.NextBeforeId
+		}
+	}
+	if len(seen) != n {
+		t.Errorf("got %d audit entries, want %d", len(seen), n)
+	}
+}
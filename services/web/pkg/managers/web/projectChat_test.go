@@ -0,0 +1,352 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/message"
+	"github.com/das7pad/overleaf-go/pkg/session"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_EditDeleteProjectMessage(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	author := registerUser(t, ctx, wm)
+	other := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, author)
+
+	err := wm.SendProjectMessage(ctx, &types.SendProjectChatMessageRequest{
+		ProjectId: projectId,
+		UserId:    author.User.Id,
+		Content:   "hello",
+	})
+	if err != nil {
+		t.Fatalf("send message: %s", err)
+	}
+	getMessages := func() []message.Message {
+		res := types.GetProjectChatMessagesResponse{}
+		err2 := wm.GetProjectMessages(ctx, &types.GetProjectChatMessagesRequest{
+			ProjectId: projectId,
+		}, &res)
+		if err2 != nil {
+			t.Fatalf("get messages: %s", err2)
+		}
+		return res.Messages
+	}
+	messageId := getMessages()[0].Id
+
+	edit := func(u *session.Session, id sharedTypes.UUID) error {
+		return wm.EditProjectMessage(ctx, &types.EditProjectChatMessageRequest{
+			ProjectId: projectId,
+			UserId:    u.User.Id,
+			MessageId: id,
+			Content:   "edited",
+		})
+	}
+	remove := func(u *session.Session, id sharedTypes.UUID) error {
+		return wm.DeleteProjectMessage(ctx, &types.DeleteProjectChatMessageRequest{
+			ProjectId: projectId,
+			UserId:    u.User.Id,
+			MessageId: id,
+		})
+	}
+
+	if err = edit(other, messageId); !errors.IsNotAuthorizedError(err) {
+		t.Errorf("edit by other user: expected not authorized, got %v", err)
+	}
+	if err = remove(other, messageId); !errors.IsNotAuthorizedError(err) {
+		t.Errorf("delete by other user: expected not authorized, got %v", err)
+	}
+
+	if err = edit(author, messageId); err != nil {
+		t.Fatalf("edit by author: %s", err)
+	}
+	msg := getMessages()[0]
+	if msg.Content != "edited" || msg.EditedAt == nil {
+		t.Errorf("message not edited: %+v", msg)
+	}
+
+	if err = remove(author, messageId); err != nil {
+		t.Fatalf("delete by author: %s", err)
+	}
+	if n := len(getMessages()); n != 0 {
+		t.Errorf("expected no messages after deletion, got %d", n)
+	}
+	if err = edit(author, messageId); !errors.IsNotFoundError(err) {
+		t.Errorf("edit after deletion: expected not found, got %v", err)
+	}
+}
+
+func TestManager_ProjectMessageReactions(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	author := registerUser(t, ctx, wm)
+	other := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, author)
+
+	err := wm.SendProjectMessage(ctx, &types.SendProjectChatMessageRequest{
+		ProjectId: projectId,
+		UserId:    author.User.Id,
+		Content:   "hello",
+	})
+	if err != nil {
+		t.Fatalf("send message: %s", err)
+	}
+	getReactions := func() message.Reactions {
+		res := types.GetProjectChatMessagesResponse{}
+		err2 := wm.GetProjectMessages(ctx, &types.GetProjectChatMessagesRequest{
+			ProjectId: projectId,
+		}, &res)
+		if err2 != nil {
+			t.Fatalf("get messages: %s", err2)
+		}
+		return res.Messages[0].Reactions
+	}
+	res := types.GetProjectChatMessagesResponse{}
+	err = wm.GetProjectMessages(ctx, &types.GetProjectChatMessagesRequest{
+		ProjectId: projectId,
+	}, &res)
+	if err != nil {
+		t.Fatalf("get messages: %s", err)
+	}
+	messageId := res.Messages[0].Id
+
+	react := func(u *session.Session, emoji message.Emoji) error {
+		return wm.AddProjectMessageReaction(ctx, &types.ReactToProjectChatMessageRequest{
+			ProjectId: projectId,
+			UserId:    u.User.Id,
+			MessageId: messageId,
+			Emoji:     emoji,
+		})
+	}
+
+	if err = react(author, "x"); !errors.IsValidationError(err) {
+		t.Errorf("unknown emoji: expected validation error, got %v", err)
+	}
+	for _, u := range []*session.Session{author, author, other} {
+		if err = react(u, "👍"); err != nil {
+			t.Fatalf("add reaction: %s", err)
+		}
+	}
+	if n := len(getReactions()["👍"]); n != 2 {
+		t.Errorf("expected 2 reactions after dedupe, got %d", n)
+	}
+
+	err = wm.RemoveProjectMessageReaction(ctx, &types.ReactToProjectChatMessageRequest{
+		ProjectId: projectId,
+		UserId:    author.User.Id,
+		MessageId: messageId,
+		Emoji:     "👍",
+	})
+	if err != nil {
+		t.Fatalf("remove reaction: %s", err)
+	}
+	got := getReactions()["👍"]
+	if len(got) != 1 || got[0] != other.User.Id {
+		t.Errorf("unexpected reactions after removal: %v", got)
+	}
+
+	err = wm.AddProjectMessageReaction(ctx, &types.ReactToProjectChatMessageRequest{
+		ProjectId: projectId,
+		UserId:    author.User.Id,
+		MessageId: author.User.Id,
+		Emoji:     "👍",
+	})
+	if !errors.IsNotFoundError(err) {
+		t.Errorf("unknown message: expected not found, got %v", err)
+	}
+}
+
+func TestManager_GetProjectMessages_Paging(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	u := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, u)
+
+	const n = 60
+	for i := 0; i < n; i++ {
+		err := wm.SendProjectMessage(ctx, &types.SendProjectChatMessageRequest{
+			ProjectId: projectId,
+			UserId:    u.User.Id,
+			Content:   strconv.Itoa(i),
+		})
+		if err != nil {
+			t.Fatalf("send message %d: %s", i, err)
+		}
+	}
+
+	var got []string
+	var pages int
+	r := types.GetProjectChatMessagesRequest{ProjectId: projectId}
+	for {
+		res := types.GetProjectChatMessagesResponse{}
+		if err := wm.GetProjectMessages(ctx, &r, &res); err != nil {
+			t.Fatalf("get messages: %s", err)
+		}
+		pages++
+		for _, msg := range res.Messages {
+			got = append(got, msg.Content)
+		}
+		if res.NextBeforeId == nil {
+			break
+		}
+		r.BeforeId = *res.NextBeforeId
+	}
+	if pages != 2 {
+		t.Errorf("expected 2 pages, got %d", pages)
+	}
+	if len(got) != n {
+		t.Fatalf("expected %d messages, got %d", n, len(got))
+	}
+	for i, s := range got {
+		if want := strconv.Itoa(n - 1 - i); s != want {
+			t.Errorf("message %d: got %q, want %q", i, s, want)
+		}
+	}
+}
+
+func TestManager_GetProjectMessages_DeletedCursor(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	u := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, u)
+
+	err := wm.SendProjectMessage(ctx, &types.SendProjectChatMessageRequest{
+		ProjectId: projectId,
+		UserId:    u.User.Id,
+		Content:   "hello",
+	})
+	if err != nil {
+		t.Fatalf("send message: %s", err)
+	}
+	res := types.GetProjectChatMessagesResponse{}
+	err = wm.GetProjectMessages(ctx, &types.GetProjectChatMessagesRequest{
+		ProjectId: projectId,
+	}, &res)
+	if err != nil {
+		t.Fatalf("get messages: %s", err)
+	}
+	if len(res.Messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(res.Messages))
+	}
+	messageId := res.Messages[0].Id
+	err = wm.DeleteProjectMessage(ctx, &types.DeleteProjectChatMessageRequest{
+		ProjectId: projectId,
+		MessageId: messageId,
+		UserId:    u.User.Id,
+	})
+	if err != nil {
+		t.Fatalf("delete message: %s", err)
+	}
+
+	err = wm.GetProjectMessages(ctx, &types.GetProjectChatMessagesRequest{
+		ProjectId: projectId,
+		BeforeId:  messageId,
+	}, &res)
+	if !errors.IsValidationError(err) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
+
+func TestManager_SearchProjectMessages(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	u := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, u)
+
+	for _, content := range []string{"Fix the Figure", "unrelated", "figure 2"} {
+		err := wm.SendProjectMessage(ctx, &types.SendProjectChatMessageRequest{
+			ProjectId: projectId,
+			UserId:    u.User.Id,
+			Content:   content,
+		})
+		if err != nil {
+			t.Fatalf("send message: %s", err)
+		}
+	}
+
+	search := func(q string) ([]message.Message, error) {
+		res := types.SearchProjectChatMessagesResponse{}
+		err := wm.SearchProjectMessages(ctx, &types.SearchProjectChatMessagesRequest{
+			ProjectId: projectId,
+			Query:     q,
+		}, &res)
+		return res.Messages, err
+	}
+
+	got, err := search("FIGURE")
+	if err != nil {
+		t.Fatalf("search: %s", err)
+	}
+	if len(got) != 2 || got[0].Content != "figure 2" ||
+		got[1].Content != "Fix the Figure" {
+		t.Errorf("unexpected search result: %+v", got)
+	}
+
+	if _, err = search(" "); !errors.IsValidationError(err) {
+		t.Errorf("blank query: expected validation error, got %v", err)
+	}
+	if _, err = search(strings.Repeat("x", 201)); !errors.IsValidationError(err) {
+		t.Errorf("long query: expected validation error, got %v", err)
+	}
+}
+
+func TestManager_ExportProjectMessages(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	u := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, u)
+
+	for _, content := range []string{"first", "second\nline"} {
+		err := wm.SendProjectMessage(ctx, &types.SendProjectChatMessageRequest{
+			ProjectId: projectId,
+			UserId:    u.User.Id,
+			Content:   content,
+		})
+		if err != nil {
+			t.Fatalf("send message: %s", err)
+		}
+	}
+
+	res := types.ExportProjectChatResponse{}
+	err := wm.ExportProjectMessages(ctx, &types.ExportProjectChatRequest{
+		ProjectId: projectId,
+	}, &res)
+	if err != nil {
+		t.Fatalf("export messages: %s", err)
+	}
+	if res.Filename != "chat.md" {
+		t.Errorf("unexpected filename: %q", res.Filename)
+	}
+	s := string(res.Transcript)
+	first := strings.Index(s, "> first\n")
+	second := strings.Index(s, "> second\n> line\n")
+	if first == -1 || second == -1 || first > second {
+		t.Errorf("unexpected transcript:\n%s", s)
+	}
+	author := u.User.ToPublicUserInfo()
+	if !strings.Contains(s, author.DisplayName()) {
+		t.Errorf("transcript is missing author:\n%s", s)
+	}
+}
@@ -0,0 +1,90 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_PropagateDefaultCompiler(t *testing.T) {
+	ctx := context.Background()
+	o := types.Options{}
+	o.FillFromEnv()
+	o.DefaultCompiler = sharedTypes.XeLaTeX
+	wm := newTestManagerWithOptions(t, ctx, &o)
+	owner := registerUser(t, ctx, wm)
+	inherited := createProject(t, ctx, wm, owner)
+	explicit := createProject(t, ctx, wm, owner)
+	err := wm.SetCompiler(ctx, &types.SetCompilerRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: explicit,
+			UserId:    owner.User.Id,
+		},
+		Compiler: sharedTypes.XeLaTeX,
+	})
+	if err != nil {
+		t.Fatalf("set compiler: %s", err)
+	}
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	check := func(projectId sharedTypes.UUID, want sharedTypes.Compiler) {
+		t.Helper()
+		var got sharedTypes.Compiler
+		err2 := db.QueryRow(ctx, `
+SELECT compiler
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&got)
+		if err2 != nil {
+			t.Fatalf("get compiler: %s", err2)
+		}
+		if got != want {
+			t.Errorf("compiler = %s, want %s", got, want)
+		}
+	}
+	check(inherited, sharedTypes.XeLaTeX)
+	check(explicit, sharedTypes.XeLaTeX)
+
+	// Changing the default does not touch existing projects by default.
+	o.DefaultCompiler = sharedTypes.LuaLaTeX
+	wm = newTestManagerWithOptions(t, ctx, &o)
+	if err = wm.PropagateDefaultCompiler(ctx, false, time.Now()); err != nil {
+		t.Fatalf("PropagateDefaultCompiler(): %s", err)
+	}
+	check(inherited, sharedTypes.XeLaTeX)
+	check(explicit, sharedTypes.XeLaTeX)
+
+	o.PropagateDefaultCompiler = true
+	wm = newTestManagerWithOptions(t, ctx, &o)
+	if err = wm.PropagateDefaultCompiler(ctx, true, time.Now()); err != nil {
+		t.Fatalf("PropagateDefaultCompiler() dry-run: %s", err)
+	}
+	check(inherited, sharedTypes.XeLaTeX)
+
+	if err = wm.PropagateDefaultCompiler(ctx, false, time.Now()); err != nil {
+		t.Fatalf("PropagateDefaultCompiler(): %s", err)
+	}
+	check(inherited, sharedTypes.LuaLaTeX)
+	check(explicit, sharedTypes.XeLaTeX)
+}
@@ -0,0 +1,122 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_CreateExampleProject_DefaultFolders(t *testing.T) {
+	ctx := context.Background()
+	o := types.Options{}
+	o.FillFromEnv()
+	o.DefaultProjectFolders = []sharedTypes.DirName{
+		"figures/", "sections/", "sections/appendix/",
+	}
+	wm := newTestManagerWithOptions(t, ctx, &o)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	r, err := db.Query(ctx, `
+SELECT path
+FROM tree_nodes
+WHERE project_id = $1
+  AND kind = 'folder'
+  AND deleted_at = '1970-01-01'
+`, projectId)
+	if err != nil {
+		t.Fatalf("get folders: %s", err)
+	}
+	defer r.Close()
+	found := make(map[string]bool)
+	for r.Next() {
+		var path string
+		if err = r.Scan(&path); err != nil {
+			t.Fatalf("scan folder: %s", err)
+		}
+		found[path] = true
+	}
+	if err = r.Err(); err != nil {
+		t.Fatalf("iterate folders: %s", err)
+	}
+	for _, d := range o.DefaultProjectFolders {
+		if !found[string(d)] {
+			t.Errorf("missing folder %q in %v", d, found)
+		}
+	}
+}
+
+type zipUpload struct {
+	*bytes.Reader
+}
+
+func (z zipUpload) Close() error {
+	return nil
+}
+
+func TestManager_CreateFromZip_MaxFilesPerUpload(t *testing.T) {
+	ctx := context.Background()
+	o := types.Options{}
+	o.FillFromEnv()
+	o.MaxFilesPerUpload = 3
+	wm := newTestManagerWithOptions(t, ctx, &o)
+	owner := registerUser(t, ctx, wm)
+
+	upload := func(n int) error {
+		buf := bytes.Buffer{}
+		w := zip.NewWriter(&buf)
+		for i := 0; i < n; i++ {
+			f, err := w.Create(fmt.Sprintf("%d.tex", i))
+			if err != nil {
+				t.Fatalf("create zip entry: %s", err)
+			}
+			if _, err = f.Write([]byte("foo")); err != nil {
+				t.Fatalf("write zip entry: %s", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("close zip: %s", err)
+		}
+		return wm.CreateFromZip(ctx, &types.CreateProjectFromZipRequest{
+			WithSession: types.WithSession{Session: owner},
+			Name:        "foo",
+			UploadDetails: types.UploadDetails{
+				File:     zipUpload{Reader: bytes.NewReader(buf.Bytes())},
+				FileName: "foo.zip",
+				Size:     int64(buf.Len()),
+			},
+		}, &types.CreateProjectResponse{})
+	}
+
+	if err := upload(3); err != nil {
+		t.Errorf("upload at limit: %s", err)
+	}
+	if err := upload(4); !errors.IsValidationError(err) {
+		t.Errorf("upload above limit: expected validation error, got %v", err)
+	}
+}
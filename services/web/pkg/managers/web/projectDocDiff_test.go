@@ -0,0 +1,147 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_GetDocDiff_VersionRange(t *testing.T) {
+	ctx := context.Background()
+	o := types.Options{}
+	o.FillFromEnv()
+	wm, dum := newTestManagerWithDocumentUpdater(t, ctx, &o)
+
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	page := types.ProjectEditorPageResponse{}
+	err := wm.ProjectEditorPage(ctx, &types.ProjectEditorPageRequest{
+		WithSession: types.WithSession{Session: owner},
+		ProjectId:   projectId,
+	}, &page)
+	if err != nil {
+		t.Fatalf("load editor: %s", err)
+	}
+	docId := page.Data.EditorBootstrap.Project.RootDocId
+	// History entries of different users do not get merged.
+	other := registerUser(t, ctx, wm)
+	userIds := []sharedTypes.UUID{owner.User.Id, other.User.Id, owner.User.Id}
+
+	d, err := dum.GetDoc(ctx, projectId, docId, -1)
+	if err != nil {
+		t.Fatalf("get doc: %s", err)
+	}
+	for i, s := range []string{"% one\n", "% two\n", "% three\n"} {
+		err = dum.QueueUpdate(ctx, projectId, docId, sharedTypes.DocumentUpdate{
+			DocId: docId,
+			Meta: sharedTypes.DocumentUpdateMeta{
+				Source: "test",
+				UserId: userIds[i],
+			},
+			Op: sharedTypes.Op{
+				{Insertion: sharedTypes.Snippet(s), Position: 0},
+			},
+			Version: d.Version + sharedTypes.Version(i),
+		})
+		if err != nil {
+			t.Fatalf("queue update %d: %s", i, err)
+		}
+	}
+	if _, err = dum.FlushDoc(ctx, projectId, docId); err != nil {
+		t.Fatalf("flush doc: %s", err)
+	}
+
+	getDiff := func(from, to sharedTypes.Version) (types.GetDocDiffResponse, error) {
+		res := types.GetDocDiffResponse{}
+		err2 := wm.GetDocDiff(ctx, &types.GetDocDiffRequest{
+			ProjectId: projectId,
+			DocId:     docId,
+			UserId:    owner.User.Id,
+			From:      from,
+			To:        to,
+		}, &res)
+		return res, err2
+	}
+	inserted := func(res types.GetDocDiffResponse) []string {
+		s := make([]string, 0)
+		for _, e := range res.Diff {
+			if len(e.Insertion) > 0 {
+				s = append(s, string(e.Insertion))
+			}
+		}
+		return s
+	}
+
+	v1 := d.Version + 1
+	t.Run("single version", func(t *testing.T) {
+		diff, err2 := getDiff(v1+1, 0)
+		if err2 != nil {
+			t.Fatalf("GetDocDiff(): %s", err2)
+		}
+		got := inserted(diff)
+		want := []string{"% three\n", "% two\n"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GetDocDiff() insertions = %q, want %q", got, want)
+		}
+	})
+	t.Run("two versions", func(t *testing.T) {
+		diff, err2 := getDiff(v1+1, v1+1)
+		if err2 != nil {
+			t.Fatalf("GetDocDiff(): %s", err2)
+		}
+		got := inserted(diff)
+		if len(got) != 1 || got[0] != "% two\n" {
+			t.Errorf("GetDocDiff() insertions = %q", got)
+		}
+		if unchanged := string(diff.Diff[len(diff.Diff)-1].Unchanged); unchanged != "% one\n"+d.Snapshot {
+			t.Errorf("GetDocDiff() unchanged = %q", unchanged)
+		}
+	})
+	t.Run("out of range", func(t *testing.T) {
+		if _, err2 := getDiff(v1, v1+3); !errors.IsValidationError(err2) {
+			t.Errorf("to: expected validation error, got %v", err2)
+		}
+		if _, err2 := getDiff(v1+3, 0); !errors.IsValidationError(err2) {
+			t.Errorf("from: expected validation error, got %v", err2)
+		}
+		if _, err2 := getDiff(v1+1, v1); !errors.IsValidationError(err2) {
+			t.Errorf("flipped: expected validation error, got %v", err2)
+		}
+	})
+	t.Run("history not available", func(t *testing.T) {
+		db := utils.MustConnectPostgres(ctx)
+		defer db.Close()
+		_, err2 := db.Exec(ctx, `
+DELETE
+FROM doc_history
+WHERE doc_id = $1
+`, docId)
+		if err2 != nil {
+			t.Fatalf("delete history: %s", err2)
+		}
+		if _, err2 = getDiff(v1, 0); !errors.IsValidationError(err2) {
+			t.Errorf("expected validation error, got %v", err2)
+		}
+	})
+}
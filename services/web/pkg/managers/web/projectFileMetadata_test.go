@@ -0,0 +1,119 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/fileTree"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_GetProjectFileMetadata(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	var rootFolderId sharedTypes.UUID
+	err := db.QueryRow(ctx, `
+SELECT root_folder_id
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&rootFolderId)
+	if err != nil {
+		t.Fatalf("get root folder: %s", err)
+	}
+
+	blob := []byte(`%PDF-1.4
+1 0 obj
+<< /Type /Catalog /Pages 2 0 R >>
+endobj
+2 0 obj
+<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>
+endobj
+trailer
+<< /Root 1 0 R >>
+%%EOF
+`)
+	hash, err := fileTree.HashFile(bytes.NewReader(blob), int64(len(blob)))
+	if err != nil {
+		t.Fatalf("hash pdf: %s", err)
+	}
+	err = wm.UploadFile(ctx, &types.UploadFileRequest{
+		ProjectId:      projectId,
+		UserId:         owner.User.Id,
+		ParentFolderId: rootFolderId,
+		UploadDetails: types.UploadDetails{
+			File:     zipUpload{Reader: bytes.NewReader(blob)},
+			FileName: "figure.pdf",
+			Size:     int64(len(blob)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("upload pdf: %s", err)
+	}
+
+	var fileId sharedTypes.UUID
+	err = db.QueryRow(ctx, `
+SELECT id
+FROM tree_nodes
+WHERE project_id = $1
+  AND path = 'figure.pdf'
+  AND deleted_at = '1970-01-01'
+`, projectId).Scan(&fileId)
+	if err != nil {
+		t.Fatalf("get file id: %s", err)
+	}
+
+	res := types.GetProjectFileMetadataResponse{}
+	err = wm.GetProjectFileMetadata(ctx, &types.GetProjectFileMetadataRequest{
+		WithSession: types.WithSession{Session: owner},
+		ProjectId:   projectId,
+		FileId:      fileId,
+	}, &res)
+	if err != nil {
+		t.Fatalf("GetProjectFileMetadata(): %s", err)
+	}
+	if res.Id != fileId {
+		t.Errorf("id = %s, want %s", res.Id, fileId)
+	}
+	if res.Name != "figure.pdf" {
+		t.Errorf("name = %q, want figure.pdf", res.Name)
+	}
+	if res.Hash != hash {
+		t.Errorf("hash = %s, want %s", res.Hash, hash)
+	}
+	if res.Size != int64(len(blob)) {
+		t.Errorf("size = %d, want %d", res.Size, len(blob))
+	}
+	if res.PageCount != 3 {
+		t.Errorf("pageCount = %d, want 3", res.PageCount)
+	}
+	if res.CreatedAt.IsZero() {
+		t.Errorf("created is not set")
+	}
+	if res.LinkedFileData != nil {
+		t.Errorf("linkedFileData = %v, want nil", res.LinkedFileData)
+	}
+}
@@ -0,0 +1,83 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_SetHistoryRetainFor(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	get := func() *int64 {
+		t.Helper()
+		var days *int64
+		err := db.QueryRow(ctx, `
+SELECT history_retain_for
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&days)
+		if err != nil {
+			t.Fatalf("get history retention: %s", err)
+		}
+		return days
+	}
+	set := func(days int64) error {
+		return wm.SetHistoryRetainFor(ctx, &types.SetHistoryRetainForRequest{
+			WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+				ProjectId: projectId,
+				UserId:    owner.User.Id,
+			},
+			HistoryRetainForDays: days,
+		})
+	}
+
+	t.Run("set", func(t *testing.T) {
+		if err := set(30); err != nil {
+			t.Fatalf("SetHistoryRetainFor(): %s", err)
+		}
+		if d := get(); d == nil || *d != 30 {
+			t.Errorf("history retention = %v, want 30", d)
+		}
+	})
+	t.Run("negative", func(t *testing.T) {
+		if err := set(-1); !errors.IsValidationError(err) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+		if d := get(); d == nil || *d != 30 {
+			t.Errorf("history retention = %v, want 30", d)
+		}
+	})
+	t.Run("reset", func(t *testing.T) {
+		if err := set(0); err != nil {
+			t.Fatalf("SetHistoryRetainFor(): %s", err)
+		}
+		if d := get(); d != nil {
+			t.Errorf("history retention = %d, want NULL", *d)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/docHistory"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_GetProjectHistoryUpdates_DateRange(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	wm := newTestManager(t, ctx)
+	dhm := docHistory.New(db, docHistory.Options{})
+
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	page := types.ProjectEditorPageResponse{}
+	err := wm.ProjectEditorPage(ctx, &types.ProjectEditorPageRequest{
+		WithSession: types.WithSession{Session: owner},
+		ProjectId:   projectId,
+	}, &page)
+	if err != nil {
+		t.Fatalf("load editor: %s", err)
+	}
+	docId := page.Data.EditorBootstrap.Project.RootDocId
+
+	day := 24 * time.Hour
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	dh := make([]docHistory.ForInsert, 0, 3)
+	for i, at := range []time.Time{
+		now.Add(-3 * day), now.Add(-2 * day), now.Add(-day),
+	} {
+		dh = append(dh, docHistory.ForInsert{
+			UserId:  owner.User.Id,
+			Version: sharedTypes.Version(i + 1),
+			StartAt: at,
+			EndAt:   at,
+			Op: sharedTypes.Op{
+				{Insertion: sharedTypes.Snippet("x"), Position: 0},
+			},
+		})
+	}
+	if err = dhm.InsertBulk(ctx, docId, dh); err != nil {
+		t.Fatalf("insert history: %s", err)
+	}
+
+	ts := func(d time.Time) sharedTypes.Timestamp {
+		return sharedTypes.Timestamp(d.UnixMilli())
+	}
+	get := func(from, to time.Time) ([]sharedTypes.Version, error) {
+		r := &types.GetProjectHistoryUpdatesRequest{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+			From:      ts(from),
+			To:        ts(to),
+		}
+		res := types.GetProjectHistoryUpdatesResponse{}
+		if err2 := wm.GetProjectHistoryUpdates(ctx, r, &res); err2 != nil {
+			return nil, err2
+		}
+		versions := make([]sharedTypes.Version, 0, len(res.Updates))
+		for _, u := range res.Updates {
+			versions = append(versions, u.Docs[docId.String()].FromV)
+		}
+		return versions, nil
+	}
+
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want []sharedTypes.Version
+	}{
+		{
+			name: "last two days",
+			from: now.Add(-2 * day),
+			to:   now,
+			want: []sharedTypes.Version{3, 2},
+		},
+		{
+			name: "older days",
+			from: now.Add(-4 * day),
+			to:   now.Add(-2 * day),
+			want: []sharedTypes.Version{2, 1},
+		},
+		{
+			name: "single day",
+			from: now.Add(-3 * day).Add(-time.Hour),
+			to:   now.Add(-3 * day).Add(time.Hour),
+			want: []sharedTypes.Version{1},
+		},
+		{
+			name: "empty",
+			from: now.Add(-10 * day),
+			to:   now.Add(-5 * day),
+			want: []sharedTypes.Version{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err2 := get(tt.from, tt.to)
+			if err2 != nil {
+				t.Fatalf("GetProjectHistoryUpdates(): %s", err2)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetProjectHistoryUpdates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err = get(now, now.Add(-day)); !errors.IsValidationError(err) {
+		t.Errorf("flipped range: expected validation error, got %v", err)
+	}
+	if _, err = get(now.Add(-100*day), now); !errors.IsValidationError(err) {
+		t.Errorf("range too large: expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_CreateProjectInvite(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	member := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	err := project.New(db, nil).GrantMemberAccess(
+		ctx, projectId, owner.User.Id, member.User.Id,
+		sharedTypes.PrivilegeLevelReadAndWrite,
+	)
+	if err != nil {
+		t.Fatalf("grant member access: %s", err)
+	}
+
+	invite := func(email sharedTypes.Email, level sharedTypes.PrivilegeLevel) error {
+		return wm.CreateProjectInvite(ctx, &types.CreateProjectInviteRequest{
+			WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+				ProjectId: projectId,
+				UserId:    owner.User.Id,
+			},
+			Email:          email,
+			PrivilegeLevel: level,
+		})
+	}
+	newUser := sharedTypes.Email(fmt.Sprintf("new-%s@foo.bar", projectId))
+	tests := []struct {
+		name    string
+		email   sharedTypes.Email
+		level   sharedTypes.PrivilegeLevel
+		wantErr bool
+	}{
+		{"owner", owner.User.Email, sharedTypes.PrivilegeLevelReadOnly, true},
+		{"member same privilege", member.User.Email, sharedTypes.PrivilegeLevelReadAndWrite, true},
+		{"member lower privilege", member.User.Email, sharedTypes.PrivilegeLevelReadOnly, true},
+		{"new user", newUser, sharedTypes.PrivilegeLevelReadAndWrite, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err = invite(tt.email, tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateProjectInvite() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.IsValidationError(err) {
+				t.Errorf("CreateProjectInvite() error = %v, want validation error", err)
+			}
+		})
+	}
+
+	res := types.ListProjectInvitesResponse{}
+	err = wm.ListProjectInvites(ctx, &types.ListProjectInvitesRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+	}, &res)
+	if err != nil {
+		t.Fatalf("list invites: %s", err)
+	}
+	if len(res.Invites) != 1 || res.Invites[0].Email != newUser {
+		t.Errorf("invites = %v, want only %s", res.Invites, newUser)
+	}
+}
+
+func TestManager_ResendProjectInvite(t *testing.T) {
+	ctx := context.Background()
+	o := types.Options{}
+	o.FillFromEnv()
+	o.RateLimits.ProjectInviteResendInterval = time.Second
+	wm := newTestManagerWithOptions(t, ctx, &o)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	withIds := types.WithProjectIdAndUserId{
+		ProjectId: projectId,
+		UserId:    owner.User.Id,
+	}
+	err := wm.CreateProjectInvite(ctx, &types.CreateProjectInviteRequest{
+		WithProjectIdAndUserId: withIds,
+		Email:                  sharedTypes.Email(fmt.Sprintf("new-%s@foo.bar", projectId)),
+		PrivilegeLevel:         sharedTypes.PrivilegeLevelReadOnly,
+	})
+	if err != nil {
+		t.Fatalf("create invite: %s", err)
+	}
+	res := types.ListProjectInvitesResponse{}
+	err = wm.ListProjectInvites(ctx, &types.ListProjectInvitesRequest{
+		WithProjectIdAndUserId: withIds,
+	}, &res)
+	if err != nil || len(res.Invites) != 1 {
+		t.Fatalf("list invites: %v %s", res.Invites, err)
+	}
+
+	resend := func() error {
+		return wm.ResendProjectInvite(ctx, &types.ResendProjectInviteRequest{
+			WithProjectIdAndUserId: withIds,
+			InviteId:               res.Invites[0].Id,
+		})
+	}
+	if err = resend(); err != nil {
+		t.Fatalf("first resend: %s", err)
+	}
+	err = resend()
+	rateLimited, ok := errors.GetCause(err).(*errors.RateLimitedError)
+	if !ok {
+		t.Fatalf("resend within interval: error = %v, want rate limited", err)
+	}
+	if rateLimited.RetryIn <= 0 || rateLimited.RetryIn > time.Second {
+		t.Errorf("RetryIn = %s, want within interval", rateLimited.RetryIn)
+	}
+	time.Sleep(time.Second)
+	if err = resend(); err != nil {
+		t.Errorf("resend after interval: %s", err)
+	}
+}
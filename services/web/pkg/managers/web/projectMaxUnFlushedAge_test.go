@@ -0,0 +1,83 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_SetMaxUnFlushedAge(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	get := func() *int64 {
+		t.Helper()
+		var seconds *int64
+		err := db.QueryRow(ctx, `
+SELECT max_unflushed_age
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&seconds)
+		if err != nil {
+			t.Fatalf("get max unflushed age: %s", err)
+		}
+		return seconds
+	}
+	set := func(seconds int64) error {
+		return wm.SetMaxUnFlushedAge(ctx, &types.SetMaxUnFlushedAgeRequest{
+			WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+				ProjectId: projectId,
+				UserId:    owner.User.Id,
+			},
+			MaxUnFlushedAgeSeconds: seconds,
+		})
+	}
+
+	t.Run("tighter", func(t *testing.T) {
+		if err := set(30); err != nil {
+			t.Fatalf("SetMaxUnFlushedAge(): %s", err)
+		}
+		if s := get(); s == nil || *s != 30 {
+			t.Errorf("max unflushed age = %v, want 30", s)
+		}
+	})
+	t.Run("too small", func(t *testing.T) {
+		if err := set(1); !errors.IsValidationError(err) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+		if s := get(); s == nil || *s != 30 {
+			t.Errorf("max unflushed age = %v, want 30", s)
+		}
+	})
+	t.Run("reset", func(t *testing.T) {
+		if err := set(0); err != nil {
+			t.Fatalf("SetMaxUnFlushedAge(): %s", err)
+		}
+		if s := get(); s != nil {
+			t.Errorf("max unflushed age = %d, want NULL", *s)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_RestoreProjectToVersion(t *testing.T) {
+	ctx := context.Background()
+	o := types.Options{}
+	o.FillFromEnv()
+	wm, dum := newTestManagerWithDocumentUpdater(t, ctx, &o)
+
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	page := types.ProjectEditorPageResponse{}
+	err := wm.ProjectEditorPage(ctx, &types.ProjectEditorPageRequest{
+		WithSession: types.WithSession{Session: owner},
+		ProjectId:   projectId,
+	}, &page)
+	if err != nil {
+		t.Fatalf("load editor: %s", err)
+	}
+	docId := page.Data.EditorBootstrap.Project.RootDocId
+	other := registerUser(t, ctx, wm)
+
+	getSnapshot := func() string {
+		d, err2 := dum.GetDoc(ctx, projectId, docId, -1)
+		if err2 != nil {
+			t.Fatalf("get doc: %s", err2)
+		}
+		return d.Snapshot
+	}
+	edit := func(userId sharedTypes.UUID, s string) {
+		d, err2 := dum.GetDoc(ctx, projectId, docId, -1)
+		if err2 != nil {
+			t.Fatalf("get doc: %s", err2)
+		}
+		err2 = dum.QueueUpdate(ctx, projectId, docId, sharedTypes.DocumentUpdate{
+			DocId: docId,
+			Meta: sharedTypes.DocumentUpdateMeta{
+				Source: "test",
+				UserId: userId,
+			},
+			Op: sharedTypes.Op{
+				{Insertion: sharedTypes.Snippet(s), Position: 0},
+			},
+			Version: d.Version,
+		})
+		if err2 != nil {
+			t.Fatalf("queue update: %s", err2)
+		}
+		if _, err2 = dum.FlushDoc(ctx, projectId, docId); err2 != nil {
+			t.Fatalf("flush doc: %s", err2)
+		}
+	}
+	restore := func(at time.Time) error {
+		return wm.RestoreProjectToVersion(ctx, &types.RestoreProjectToVersionRequest{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+			At:        sharedTypes.Timestamp(at.UnixMilli()),
+		})
+	}
+	tick := func() time.Time {
+		time.Sleep(5 * time.Millisecond)
+		at := time.Now()
+		time.Sleep(5 * time.Millisecond)
+		return at
+	}
+
+	edit(owner.User.Id, "% one\n")
+	good := getSnapshot()
+	atGood := tick()
+	edit(other.User.Id, "% two\n")
+	edit(owner.User.Id, "% three\n")
+	bad := getSnapshot()
+	atBad := tick()
+
+	if err = restore(atGood); err != nil {
+		t.Fatalf("RestoreProjectToVersion(): %s", err)
+	}
+	if s := getSnapshot(); s != good {
+		t.Errorf("restored snapshot = %q, want %q", s, good)
+	}
+
+	// The restore is undoable.
+	if err = restore(atBad); err != nil {
+		t.Fatalf("RestoreProjectToVersion() undo: %s", err)
+	}
+	if s := getSnapshot(); s != bad {
+		t.Errorf("undo snapshot = %q, want %q", s, bad)
+	}
+
+	err = restore(time.Now().Add(time.Hour))
+	if !errors.IsValidationError(err) {
+		t.Errorf("future: expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func listSnippets(t *testing.T, ctx context.Context, wm Manager, projectId, userId sharedTypes.UUID) []project.Snippet {
+	res := types.ListProjectSnippetsResponse{}
+	err := wm.ListProjectSnippets(ctx, &types.ListProjectSnippetsRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    userId,
+		},
+	}, &res)
+	if err != nil {
+		t.Fatalf("list snippets: %s", err)
+	}
+	return res.Snippets
+}
+
+func TestManager_ProjectSnippets(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	ids := types.WithProjectIdAndUserId{
+		ProjectId: projectId,
+		UserId:    owner.User.Id,
+	}
+
+	for _, s := range []project.Snippet{
+		{Name: "table", Body: "\\begin{table}\n\\end{table}"},
+		{Name: "figure", Body: "\\begin{figure}\n\\end{figure}"},
+		{Name: "table", Body: "\\begin{tabular}{c}\n\\end{tabular}"},
+	} {
+		err := wm.SetProjectSnippet(ctx, &types.SetProjectSnippetRequest{
+			WithProjectIdAndUserId: ids,
+			Snippet:                s,
+		})
+		if err != nil {
+			t.Fatalf("set snippet: %s", err)
+		}
+	}
+	want := []project.Snippet{
+		{Name: "figure", Body: "\\begin{figure}\n\\end{figure}"},
+		{Name: "table", Body: "\\begin{tabular}{c}\n\\end{tabular}"},
+	}
+	if got := listSnippets(t, ctx, wm, projectId, owner.User.Id); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListProjectSnippets() = %#v, want %#v", got, want)
+	}
+
+	res := types.ProjectEditorPageResponse{}
+	err := wm.ProjectEditorPage(ctx, &types.ProjectEditorPageRequest{
+		WithSession: types.WithSession{Session: owner},
+		ProjectId:   projectId,
+	}, &res)
+	if err != nil {
+		t.Fatalf("load editor: %s", err)
+	}
+	if got := res.Data.EditorBootstrap.Project.Snippets; !reflect.DeepEqual(got, want) {
+		t.Errorf("bootstrap snippets = %#v, want %#v", got, want)
+	}
+
+	err = wm.DeleteProjectSnippet(ctx, &types.DeleteProjectSnippetRequest{
+		WithProjectIdAndUserId: ids,
+		Name:                   "figure",
+	})
+	if err != nil {
+		t.Fatalf("delete snippet: %s", err)
+	}
+	want = want[1:]
+	if got := listSnippets(t, ctx, wm, projectId, owner.User.Id); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListProjectSnippets() after delete = %#v, want %#v", got, want)
+	}
+
+	for i := len(want); i < project.MaxSnippetsPerProject; i++ {
+		err = wm.SetProjectSnippet(ctx, &types.SetProjectSnippetRequest{
+			WithProjectIdAndUserId: ids,
+			Snippet: project.Snippet{
+				Name: project.SnippetName("s" + strconv.Itoa(i)),
+				Body: "foo",
+			},
+		})
+		if err != nil {
+			t.Fatalf("set snippet %d: %s", i, err)
+		}
+	}
+	err = wm.SetProjectSnippet(ctx, &types.SetProjectSnippetRequest{
+		WithProjectIdAndUserId: ids,
+		Snippet:                project.Snippet{Name: "one-more", Body: "foo"},
+	})
+	if errors.GetCause(err) != project.ErrTooManySnippets {
+		t.Errorf("set snippet over limit: err = %v, want %v", err, project.ErrTooManySnippets)
+	}
+	err = wm.SetProjectSnippet(ctx, &types.SetProjectSnippetRequest{
+		WithProjectIdAndUserId: ids,
+		Snippet:                project.Snippet{Name: "table", Body: "bar"},
+	})
+	if err != nil {
+		t.Errorf("update snippet at limit: %s", err)
+	}
+}
+
+func TestManager_SetProjectSnippet_ReadOnly(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	member := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	tokens := enableTokenAccess(t, ctx, wm, owner, projectId)
+	err := wm.GrantTokenAccessReadOnly(ctx, &types.GrantTokenAccessRequest{
+		WithSession: types.WithSession{Session: member},
+		Token:       tokens.ReadOnly,
+	}, &types.GrantTokenAccessResponse{})
+	if err != nil {
+		t.Fatalf("grant token access: %s", err)
+	}
+
+	err = wm.SetProjectSnippet(ctx, &types.SetProjectSnippetRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    member.User.Id,
+		},
+		Snippet: project.Snippet{Name: "table", Body: "foo"},
+	})
+	if !errors.IsNotAuthorizedError(err) {
+		t.Errorf("set snippet as read-only member: err = %v, want not authorized", err)
+	}
+	if got := listSnippets(t, ctx, wm, projectId, owner.User.Id); len(got) != 0 {
+		t.Errorf("ListProjectSnippets() = %#v, want none", got)
+	}
+}
@@ -0,0 +1,112 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	spellingTypes "github.com/das7pad/overleaf-go/services/spelling/pkg/types"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_SetSpellCheckLanguage(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	get := func() spellingTypes.SpellCheckLanguage {
+		t.Helper()
+		var l spellingTypes.SpellCheckLanguage
+		err := db.QueryRow(ctx, `
+SELECT spell_check_language
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&l)
+		if err != nil {
+			t.Fatalf("get spell check language: %s", err)
+		}
+		return l
+	}
+	var ownerLanguage spellingTypes.SpellCheckLanguage
+	err := db.QueryRow(ctx, `
+SELECT editor_config ->> 'spellCheckLanguage'
+FROM users
+WHERE id = $1
+`, owner.User.Id).Scan(&ownerLanguage)
+	if err != nil {
+		t.Fatalf("get owner spell check language: %s", err)
+	}
+	set := func(l spellingTypes.SpellCheckLanguage) error {
+		return wm.SetSpellCheckLanguage(
+			ctx, &types.SetSpellCheckLanguageRequest{
+				WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+					ProjectId: projectId,
+					UserId:    owner.User.Id,
+				},
+				SpellCheckLanguage: l,
+			},
+		)
+	}
+
+	t.Run("supported language", func(t *testing.T) {
+		if err = set("de"); err != nil {
+			t.Fatalf("SetSpellCheckLanguage(): %s", err)
+		}
+		if l := get(); l != "de" {
+			t.Errorf("language = %q, want de", l)
+		}
+	})
+	t.Run("inherit", func(t *testing.T) {
+		if err = set(spellingTypes.InheritLanguage); err != nil {
+			t.Fatalf("SetSpellCheckLanguage(): %s", err)
+		}
+		if l := get(); l != ownerLanguage {
+			t.Errorf("language = %q, want %q", l, ownerLanguage)
+		}
+	})
+	t.Run("unknown language", func(t *testing.T) {
+		before := get()
+		err = set("xx")
+		if !errors.IsValidationError(err) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+		if l := get(); l != before {
+			t.Errorf("language = %q, want %q", l, before)
+		}
+	})
+	t.Run("non member", func(t *testing.T) {
+		other := registerUser(t, ctx, wm)
+		err = wm.SetSpellCheckLanguage(
+			ctx, &types.SetSpellCheckLanguageRequest{
+				WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+					ProjectId: projectId,
+					UserId:    other.User.Id,
+				},
+				SpellCheckLanguage: "fr",
+			},
+		)
+		if !errors.IsNotAuthorizedError(err) {
+			t.Fatalf("expected not authorized error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_GetProjectStatistics(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	member := registerUser(t, ctx, wm)
+	collaborator := registerUser(t, ctx, wm)
+	expired := registerUser(t, ctx, wm)
+	outsider := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	tokens := enableTokenAccess(t, ctx, wm, owner, projectId)
+
+	err := wm.GrantTokenAccessReadAndWrite(ctx, &types.GrantTokenAccessRequest{
+		WithSession: types.WithSession{Session: member},
+		Token:       tokens.ReadAndWrite,
+	}, &types.GrantTokenAccessResponse{})
+	if err != nil {
+		t.Fatalf("grant token access: %s", err)
+	}
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	expiredAt := time.Now().Add(-time.Hour)
+	for userId, expiresAt := range map[sharedTypes.UUID]*time.Time{
+		collaborator.User.Id: nil,
+		expired.User.Id:      &expiredAt,
+	} {
+		_, err = db.Exec(ctx, `
+INSERT INTO project_members
+(project_id, user_id, access_source, privilege_level, archived, trashed,
+ access_expires_at)
+VALUES ($1, $2, 'invite', 'readAndWrite', FALSE, FALSE, $3)
+`, projectId, userId, expiresAt)
+		if err != nil {
+			t.Fatalf("seed member: %s", err)
+		}
+	}
+	pm := project.New(db, nil)
+	for i := 0; i < 2; i++ {
+		if err = pm.BumpCompileCount(ctx, projectId); err != nil {
+			t.Fatalf("bump compile count: %s", err)
+		}
+	}
+	var wantSize int64
+	err = db.QueryRow(ctx, `
+SELECT sum(octet_length(d.snapshot))
+FROM docs d
+         INNER JOIN tree_nodes t ON d.id = t.id
+WHERE t.project_id = $1
+`, projectId).Scan(&wantSize)
+	if err != nil {
+		t.Fatalf("get doc sizes: %s", err)
+	}
+
+	res := types.GetProjectStatisticsResponse{}
+	err = wm.GetProjectStatistics(ctx, &types.GetProjectStatisticsRequest{
+		WithSession: types.WithSession{Session: member},
+		ProjectId:   projectId,
+	}, &res)
+	if err != nil {
+		t.Fatalf("get statistics: %s", err)
+	}
+	if res.Collaborators != 1 {
+		t.Errorf("Collaborators = %d, want 1", res.Collaborators)
+	}
+	if res.Compiles != 2 {
+		t.Errorf("Compiles = %d, want 2", res.Compiles)
+	}
+	if res.Docs != 1 {
+		t.Errorf("Docs = %d, want 1", res.Docs)
+	}
+	if res.Files != 0 {
+		t.Errorf("Files = %d, want 0", res.Files)
+	}
+	if res.TotalSize != wantSize || wantSize == 0 {
+		t.Errorf("TotalSize = %d, want %d", res.TotalSize, wantSize)
+	}
+	if res.LastUpdatedAt.IsZero() {
+		t.Errorf("LastUpdatedAt is zero")
+	}
+
+	err = wm.GetProjectStatistics(ctx, &types.GetProjectStatisticsRequest{
+		WithSession: types.WithSession{Session: outsider},
+		ProjectId:   projectId,
+	}, &types.GetProjectStatisticsResponse{})
+	if !errors.IsNotFoundError(err) {
+		t.Errorf("get statistics as outsider: err = %v, want not found", err)
+	}
+}
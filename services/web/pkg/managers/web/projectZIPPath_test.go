@@ -0,0 +1,130 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_StreamProjectZIP_Path(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	o := types.Options{}
+	o.FillFromEnv()
+	wm, _ := newTestManagerWithDocumentUpdater(t, ctx, &o)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	var rootFolderId sharedTypes.UUID
+	err := db.QueryRow(ctx, `
+SELECT root_folder_id
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&rootFolderId)
+	if err != nil {
+		t.Fatalf("get root folder: %s", err)
+	}
+	folder := types.AddFolderResponse{}
+	err = wm.AddFolderToProject(ctx, &types.AddFolderRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+		Name:           "chapter1",
+		ParentFolderId: rootFolderId,
+	}, &folder)
+	if err != nil {
+		t.Fatalf("add folder: %s", err)
+	}
+	blob := []byte("data")
+	err = wm.UploadFile(ctx, &types.UploadFileRequest{
+		ProjectId:      projectId,
+		UserId:         owner.User.Id,
+		ParentFolderId: folder.Id,
+		UploadDetails: types.UploadDetails{
+			File:     zipUpload{Reader: bytes.NewReader(blob)},
+			FileName: "data.bin",
+			Size:     int64(len(blob)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("upload file: %s", err)
+	}
+
+	download := func(path sharedTypes.DirName) ([]string, error) {
+		buf := bytes.Buffer{}
+		request := types.StreamProjectZIPRequest{}
+		request.Session = owner
+		request.ProjectId = projectId
+		request.Path = path
+		request.GetWriter = func(sharedTypes.Filename) (io.Writer, error) {
+			return &buf, nil
+		}
+		if err2 := wm.StreamProjectZIP(ctx, &request); err2 != nil {
+			return nil, err2
+		}
+		z, err2 := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err2 != nil {
+			t.Fatalf("open zip: %s", err2)
+		}
+		var files []string
+		for _, f := range z.File {
+			if !f.FileInfo().IsDir() {
+				files = append(files, f.Name)
+			}
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+
+	files, err := download("")
+	if err != nil {
+		t.Fatalf("StreamProjectZIP(): %s", err)
+	}
+	if want := []string{"chapter1/data.bin", "main.tex"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("full: files = %v, want %v", files, want)
+	}
+
+	files, err = download("chapter1/")
+	if err != nil {
+		t.Fatalf("StreamProjectZIP() chapter1: %s", err)
+	}
+	if want := []string{"chapter1/data.bin"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("chapter1: files = %v, want %v", files, want)
+	}
+
+	_, err = download("missing/")
+	if !errors.IsNotFoundError(err) {
+		t.Errorf("missing: expected not found error, got %v", err)
+	}
+	_, err = download("../")
+	if !errors.IsValidationError(err) {
+		t.Errorf("jumping: expected validation error, got %v", err)
+	}
+}
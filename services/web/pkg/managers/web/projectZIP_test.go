@@ -0,0 +1,117 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/session"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func setupZIPBenchmark(b *testing.B) (context.Context, Manager, *session.Session, sharedTypes.UUID) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	b.Cleanup(db.Close)
+	o := types.Options{}
+	o.FillFromEnv()
+	wm, _ := newTestManagerWithDocumentUpdater(b, ctx, &o)
+	owner := registerUser(b, ctx, wm)
+	projectId := createProject(b, ctx, wm, owner)
+
+	var rootFolderId sharedTypes.UUID
+	err := db.QueryRow(ctx, `
+SELECT root_folder_id
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&rootFolderId)
+	if err != nil {
+		b.Fatalf("get root folder: %s", err)
+	}
+	blob := make([]byte, 8<<20)
+	if _, err = rand.Read(blob); err != nil {
+		b.Fatalf("generate file: %s", err)
+	}
+	err = wm.UploadFile(ctx, &types.UploadFileRequest{
+		ProjectId:      projectId,
+		UserId:         owner.User.Id,
+		ParentFolderId: rootFolderId,
+		UploadDetails: types.UploadDetails{
+			File:     zipUpload{Reader: bytes.NewReader(blob)},
+			FileName: "data.bin",
+			Size:     int64(len(blob)),
+		},
+	})
+	if err != nil {
+		b.Fatalf("upload file: %s", err)
+	}
+	return ctx, wm, owner, projectId
+}
+
+func BenchmarkManager_CreateProjectZIP(b *testing.B) {
+	ctx, wm, owner, projectId := setupZIPBenchmark(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	var onDisk int64
+	for i := 0; i < b.N; i++ {
+		res := types.CreateProjectZIPResponse{}
+		err := wm.CreateProjectZIP(ctx, &types.CreateProjectZIPRequest{
+			WithSession: types.WithSession{Session: owner},
+			ProjectId:   projectId,
+		}, &res)
+		if err != nil {
+			b.Fatalf("CreateProjectZIP(): %s", err)
+		}
+		f, err := os.Open(res.FSPath)
+		if err != nil {
+			b.Fatalf("open zip: %s", err)
+		}
+		n, err := io.Copy(io.Discard, f)
+		if err != nil {
+			b.Fatalf("read zip: %s", err)
+		}
+		onDisk = n
+		_ = f.Close()
+		res.Cleanup()
+	}
+	b.ReportMetric(float64(onDisk), "disk-B/op")
+}
+
+func BenchmarkManager_StreamProjectZIP(b *testing.B) {
+	ctx, wm, owner, projectId := setupZIPBenchmark(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		request := types.StreamProjectZIPRequest{}
+		request.Session = owner
+		request.ProjectId = projectId
+		request.GetWriter = func(sharedTypes.Filename) (io.Writer, error) {
+			return io.Discard, nil
+		}
+		if err := wm.StreamProjectZIP(ctx, &request); err != nil {
+			b.Fatalf("StreamProjectZIP(): %s", err)
+		}
+	}
+	b.ReportMetric(0, "disk-B/op")
+}
@@ -0,0 +1,70 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/models/doc"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_SnapshotEncryption(t *testing.T) {
+	ctx := context.Background()
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	o := types.Options{}
+	o.FillFromEnv()
+	o.SnapshotEncryption = doc.EncryptionOptions{Key: strings.Repeat("42", 32)}
+	wm, dum := newTestManagerWithDocumentUpdater(t, ctx, &o)
+
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	page := types.ProjectEditorPageResponse{}
+	err := wm.ProjectEditorPage(ctx, &types.ProjectEditorPageRequest{
+		WithSession: types.WithSession{Session: owner},
+		ProjectId:   projectId,
+	}, &page)
+	if err != nil {
+		t.Fatalf("load editor: %s", err)
+	}
+	docId := page.Data.EditorBootstrap.Project.RootDocId
+
+	var stored string
+	err = db.QueryRow(ctx, `
+SELECT snapshot
+FROM docs
+WHERE id = $1
+`, docId).Scan(&stored)
+	if err != nil {
+		t.Fatalf("get persisted doc: %s", err)
+	}
+	if strings.Contains(stored, `\documentclass`) {
+		t.Errorf("persisted snapshot is plaintext: %q", stored)
+	}
+
+	d, err := dum.GetDoc(ctx, projectId, docId, -1)
+	if err != nil {
+		t.Fatalf("get doc: %s", err)
+	}
+	if !strings.Contains(string(d.Snapshot), `\documentclass`) {
+		t.Errorf("GetDoc() snapshot = %q, want decrypted snapshot", d.Snapshot)
+	}
+}
@@ -0,0 +1,434 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/session"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func enableTokenAccess(t *testing.T, ctx context.Context, wm Manager, owner *session.Session, projectId sharedTypes.UUID) *project.Tokens {
+	res := types.SetPublicAccessLevelResponse{}
+	err := wm.SetPublicAccessLevel(ctx, &types.SetPublicAccessLevelRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+		PublicAccessLevel: project.TokenBasedAccess,
+	}, &res)
+	if err != nil {
+		t.Fatalf("enable token access: %s", err)
+	}
+	return res.Tokens
+}
+
+func TestManager_GrantTokenAccessReadAndWrite_NotifiesOwner(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	member := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	tokens := enableTokenAccess(t, ctx, wm, owner, projectId)
+
+	err := wm.GrantTokenAccessReadAndWrite(ctx, &types.GrantTokenAccessRequest{
+		WithSession: types.WithSession{Session: member},
+		Token:       tokens.ReadAndWrite,
+	}, &types.GrantTokenAccessResponse{})
+	if err != nil {
+		t.Fatalf("grant token access: %s", err)
+	}
+
+	// The notification is created in the background.
+	for i := 0; i < 50; i++ {
+		notifications := types.GetNotificationsResponse{}
+		err = wm.GetUserNotifications(ctx, &types.GetNotificationsRequest{
+			WithSession: types.WithSession{Session: owner},
+		}, &notifications)
+		if err != nil {
+			t.Fatalf("get notifications: %s", err)
+		}
+		for _, n := range notifications {
+			if n.TemplateKey == "notification_token_access_granted" {
+				return
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Errorf("owner was not notified about token access")
+}
+
+func TestManager_GrantTokenAccessReadOnly_Anonymous(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled bool
+		wantErr  error
+	}{
+		{
+			name:     "enabled",
+			disabled: false,
+			wantErr:  nil,
+		},
+		{
+			name:     "disabled",
+			disabled: true,
+			wantErr:  session.ErrNotLoggedIn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			o := types.Options{}
+			o.FillFromEnv()
+			o.AnonymousTokenAccessDisabled = tt.disabled
+			wm := newTestManagerWithOptions(t, ctx, &o)
+			owner := registerUser(t, ctx, wm)
+			projectId := createProject(t, ctx, wm, owner)
+			tokens := enableTokenAccess(t, ctx, wm, owner, projectId)
+			_, anonymous := newSession(t, ctx, wm)
+
+			err := wm.GrantTokenAccessReadOnly(ctx, &types.GrantTokenAccessRequest{
+				WithSession: types.WithSession{Session: anonymous},
+				Token:       tokens.ReadOnly,
+			}, &types.GrantTokenAccessResponse{})
+			if err != tt.wantErr {
+				t.Fatalf("GrantTokenAccessReadOnly() error = %v, want %v", err, tt.wantErr)
+			}
+			got := anonymous.GetAnonTokenAccess(projectId)
+			if tt.disabled && got != "" {
+				t.Errorf("GetAnonTokenAccess() = %q, want empty", got)
+			}
+			if !tt.disabled && got != tokens.ReadOnly {
+				t.Errorf("GetAnonTokenAccess() = %q, want %q", got, tokens.ReadOnly)
+			}
+		})
+	}
+}
+
+func TestManager_GrantTokenAccessReadAndWrite_RecordsTokenType(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	member := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	tokens := enableTokenAccess(t, ctx, wm, owner, projectId)
+
+	err := wm.GrantTokenAccessReadAndWrite(ctx, &types.GrantTokenAccessRequest{
+		WithSession: types.WithSession{Session: member},
+		Token:       tokens.ReadAndWrite,
+	}, &types.GrantTokenAccessResponse{})
+	if err != nil {
+		t.Fatalf("grant token access: %s", err)
+	}
+
+	members := types.ListProjectMembersResponse{}
+	err = wm.ListProjectMembers(ctx, &types.ListProjectMembersRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+	}, &members)
+	if err != nil {
+		t.Fatalf("list members: %s", err)
+	}
+	for _, m := range members.Members {
+		if m.Id == member.User.Id {
+			t.Errorf("token member is listed as collaborator")
+		}
+	}
+
+	res := types.ListProjectTokenMembersResponse{}
+	err = wm.ListProjectTokenMembers(ctx, &types.ListProjectTokenMembersRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+	}, &res)
+	if err != nil {
+		t.Fatalf("list token members: %s", err)
+	}
+	for _, m := range res.Members {
+		if m.Id != member.User.Id {
+			continue
+		}
+		want := sharedTypes.PrivilegeLevelReadAndWrite
+		if m.TokenPrivilegeLevel != want {
+			t.Errorf("TokenPrivilegeLevel = %q, want %q", m.TokenPrivilegeLevel, want)
+		}
+		return
+	}
+	t.Errorf("token member is missing from token members list")
+}
+
+func TestManager_GrantTokenAccessReadOnly_ClearsExpiry(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	member := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	tokens := enableTokenAccess(t, ctx, wm, owner, projectId)
+	grant := func() {
+		err := wm.GrantTokenAccessReadOnly(ctx, &types.GrantTokenAccessRequest{
+			WithSession: types.WithSession{Session: member},
+			Token:       tokens.ReadOnly,
+		}, &types.GrantTokenAccessResponse{})
+		if err != nil {
+			t.Fatalf("grant token access: %s", err)
+		}
+	}
+	grant()
+
+	expiresAt := time.Now().Add(time.Hour)
+	err := wm.SetMemberAccessExpiresAtInProject(ctx, &types.SetMemberAccessExpiresAtInProjectRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+		MemberId:        member.User.Id,
+		AccessExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("set expiry: %s", err)
+	}
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	_, err = db.Exec(ctx, `
+UPDATE project_members
+SET access_expires_at = transaction_timestamp() - INTERVAL '1 minute'
+WHERE project_id = $1
+  AND user_id = $2
+`, projectId, member.User.Id)
+	if err != nil {
+		t.Fatalf("expire access: %s", err)
+	}
+
+	grant()
+	var got *time.Time
+	err = db.QueryRow(ctx, `
+SELECT access_expires_at
+FROM project_members
+WHERE project_id = $1
+  AND user_id = $2
+`, projectId, member.User.Id).Scan(&got)
+	if err != nil {
+		t.Fatalf("get expiry: %s", err)
+	}
+	if got != nil {
+		t.Errorf("access_expires_at = %s, want NULL", got)
+	}
+}
+
+func TestManager_GrantTokenAccessReadOnly_KeepsHigherAccess(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	member := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	tokens := enableTokenAccess(t, ctx, wm, owner, projectId)
+	err := wm.GrantTokenAccessReadAndWrite(ctx, &types.GrantTokenAccessRequest{
+		WithSession: types.WithSession{Session: member},
+		Token:       tokens.ReadAndWrite,
+	}, &types.GrantTokenAccessResponse{})
+	if err != nil {
+		t.Fatalf("grant read-and-write token access: %s", err)
+	}
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+	err = wm.SetMemberAccessExpiresAtInProject(ctx, &types.SetMemberAccessExpiresAtInProjectRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+		MemberId:        member.User.Id,
+		AccessExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("set expiry: %s", err)
+	}
+
+	err = wm.GrantTokenAccessReadOnly(ctx, &types.GrantTokenAccessRequest{
+		WithSession: types.WithSession{Session: member},
+		Token:       tokens.ReadOnly,
+	}, &types.GrantTokenAccessResponse{})
+	if err != nil {
+		t.Fatalf("grant read-only token access: %s", err)
+	}
+
+	db := utils.MustConnectPostgres(ctx)
+	t.Cleanup(db.Close)
+	var l sharedTypes.PrivilegeLevel
+	var got *time.Time
+	err = db.QueryRow(ctx, `
+SELECT privilege_level, access_expires_at
+FROM project_members
+WHERE project_id = $1
+  AND user_id = $2
+`, projectId, member.User.Id).Scan(&l, &got)
+	if err != nil {
+		t.Fatalf("get member: %s", err)
+	}
+	if l != sharedTypes.PrivilegeLevelReadAndWrite {
+		t.Errorf("privilege_level = %s, want %s", l, sharedTypes.PrivilegeLevelReadAndWrite)
+	}
+	if got == nil || !got.Equal(expiresAt) {
+		t.Errorf("access_expires_at = %v, want %s", got, expiresAt)
+	}
+}
+
+func TestManager_PreviewTokenAccess(t *testing.T) {
+	ctx := context.Background()
+	wm := newTestManager(t, ctx)
+	owner := registerUser(t, ctx, wm)
+	member := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	preview := func(u *session.Session, l sharedTypes.PrivilegeLevel) (*types.PreviewTokenAccessResponse, error) {
+		res := types.PreviewTokenAccessResponse{}
+		err := wm.PreviewTokenAccess(ctx, &types.PreviewTokenAccessRequest{
+			WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+				ProjectId: projectId,
+				UserId:    u.User.Id,
+			},
+			PrivilegeLevel: l,
+		}, &res)
+		return &res, err
+	}
+
+	t.Run("link sharing disabled", func(t *testing.T) {
+		_, err := preview(owner, "")
+		if !errors.IsUnprocessableEntityError(err) {
+			t.Fatalf("expected unprocessable entity error, got %v", err)
+		}
+	})
+
+	enableTokenAccess(t, ctx, wm, owner, projectId)
+
+	t.Run("not owner", func(t *testing.T) {
+		_, err := preview(member, "")
+		if !errors.IsNotAuthorizedError(err) {
+			t.Fatalf("expected not authorized error, got %v", err)
+		}
+	})
+
+	page := types.ProjectEditorPageResponse{}
+	err := wm.ProjectEditorPage(ctx, &types.ProjectEditorPageRequest{
+		WithSession: types.WithSession{Session: owner},
+		ProjectId:   projectId,
+	}, &page)
+	if err != nil {
+		t.Fatalf("load editor: %s", err)
+	}
+	ownerView := page.Data.EditorBootstrap
+
+	for _, l := range []sharedTypes.PrivilegeLevel{
+		sharedTypes.PrivilegeLevelReadOnly,
+		sharedTypes.PrivilegeLevelReadAndWrite,
+	} {
+		t.Run(string(l), func(t *testing.T) {
+			res, err2 := preview(owner, l)
+			if err2 != nil {
+				t.Fatalf("preview: %s", err2)
+			}
+			if res.AuthorizationDetails.PrivilegeLevel != l {
+				t.Errorf("privilege level: %q", res.AuthorizationDetails.PrivilegeLevel)
+			}
+			if res.AuthorizationDetails.AccessSource != project.AccessSourceToken {
+				t.Errorf("access source: %q", res.AuthorizationDetails.AccessSource)
+			}
+			wantRestricted := l == sharedTypes.PrivilegeLevelReadOnly
+			if bool(res.IsRestrictedUser) != wantRestricted || ownerView.IsRestrictedUser {
+				t.Errorf("restricted: preview %v, owner %v", res.IsRestrictedUser, ownerView.IsRestrictedUser)
+			}
+			wantLogin := l == sharedTypes.PrivilegeLevelReadAndWrite
+			if res.RequiresLogin != wantLogin {
+				t.Errorf("requires login: %v, want %v", res.RequiresLogin, wantLogin)
+			}
+			if res.Project.Id != ownerView.Project.Id || res.Project.Name != ownerView.Project.Name {
+				t.Errorf("project mismatch: %v vs %v", res.Project.Id, ownerView.Project.Id)
+			}
+			if res.RootDocPath != ownerView.RootDocPath {
+				t.Errorf("root doc path: %q vs %q", res.RootDocPath, ownerView.RootDocPath)
+			}
+		})
+	}
+}
+
+func TestManager_PreviewTokenAccess_AnonymousDisabled(t *testing.T) {
+	ctx := context.Background()
+	o := types.Options{}
+	o.FillFromEnv()
+	o.AnonymousTokenAccessDisabled = true
+	wm := newTestManagerWithOptions(t, ctx, &o)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+	enableTokenAccess(t, ctx, wm, owner, projectId)
+
+	res := types.PreviewTokenAccessResponse{}
+	err := wm.PreviewTokenAccess(ctx, &types.PreviewTokenAccessRequest{
+		WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+			ProjectId: projectId,
+			UserId:    owner.User.Id,
+		},
+		PrivilegeLevel: sharedTypes.PrivilegeLevelReadOnly,
+	}, &res)
+	if err != nil {
+		t.Fatalf("preview: %s", err)
+	}
+	if !res.RequiresLogin {
+		t.Errorf("requires login: false, want true")
+	}
+}
+
+func TestManager_SetPublicAccessLevel_Restricted(t *testing.T) {
+	ctx := context.Background()
+	o := types.Options{}
+	o.FillFromEnv()
+	o.AllowedPublicAccessLevels = types.PublicAccessLevels{
+		project.PrivateAccess,
+	}
+	wm := newTestManagerWithOptions(t, ctx, &o)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	set := func(l project.PublicAccessLevel) error {
+		return wm.SetPublicAccessLevel(ctx, &types.SetPublicAccessLevelRequest{
+			WithProjectIdAndUserId: types.WithProjectIdAndUserId{
+				ProjectId: projectId,
+				UserId:    owner.User.Id,
+			},
+			PublicAccessLevel: l,
+		}, &types.SetPublicAccessLevelResponse{})
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		if err := set(project.PrivateAccess); err != nil {
+			t.Fatalf("set private: %s", err)
+		}
+	})
+	t.Run("disallowed", func(t *testing.T) {
+		err := set(project.TokenBasedAccess)
+		if !errors.IsValidationError(err) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+	})
+}
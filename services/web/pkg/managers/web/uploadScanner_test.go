@@ -0,0 +1,84 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/das7pad/overleaf-go/cmd/pkg/utils"
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+	"github.com/das7pad/overleaf-go/services/web/pkg/types"
+)
+
+func TestManager_UploadFile_UploadScanner(t *testing.T) {
+	ctx := context.Background()
+	o := types.Options{}
+	o.FillFromEnv()
+	o.UploadScanner.Command = []string{"sh", "-c", "! grep -q EICAR"}
+	wm := newTestManagerWithOptions(t, ctx, &o)
+	owner := registerUser(t, ctx, wm)
+	projectId := createProject(t, ctx, wm, owner)
+
+	db := utils.MustConnectPostgres(ctx)
+	defer db.Close()
+	var rootFolderId sharedTypes.UUID
+	err := db.QueryRow(ctx, `
+SELECT root_folder_id
+FROM projects
+WHERE id = $1
+`, projectId).Scan(&rootFolderId)
+	if err != nil {
+		t.Fatalf("get root folder: %s", err)
+	}
+
+	upload := func(name sharedTypes.Filename, blob []byte) error {
+		return wm.UploadFile(ctx, &types.UploadFileRequest{
+			ProjectId:      projectId,
+			UserId:         owner.User.Id,
+			ParentFolderId: rootFolderId,
+			UploadDetails: types.UploadDetails{
+				File:     zipUpload{Reader: bytes.NewReader(blob)},
+				FileName: name,
+				Size:     int64(len(blob)),
+			},
+		})
+	}
+	if err = upload("good.bin", []byte{0, 1, 2, 3}); err != nil {
+		t.Errorf("upload good file: %s", err)
+	}
+	if err = upload("bad.bin", []byte("\x00EICAR\x00")); !errors.IsValidationError(err) {
+		t.Errorf("upload bad file: expected validation error, got %v", err)
+	}
+
+	var names []string
+	err = db.QueryRow(ctx, `
+SELECT array_agg(path ORDER BY path)
+FROM tree_nodes
+WHERE project_id = $1
+  AND kind = 'file'
+  AND deleted_at = '1970-01-01'
+`, projectId).Scan(&names)
+	if err != nil {
+		t.Fatalf("get files: %s", err)
+	}
+	if len(names) != 1 || names[0] != "good.bin" {
+		t.Errorf("files = %v, want [good.bin]", names)
+	}
+}
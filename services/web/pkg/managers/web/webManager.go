@@ -18,7 +18,6 @@ package web
 
 import (
 	"context"
-	"log"
 	"math/rand"
 	"time"
 
@@ -29,6 +28,8 @@ import (
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/jwt/loggedInUserJWT"
 	"github.com/das7pad/overleaf-go/pkg/jwt/projectJWT"
+	"github.com/das7pad/overleaf-go/pkg/logger"
+	"github.com/das7pad/overleaf-go/pkg/models/doc"
 	"github.com/das7pad/overleaf-go/pkg/models/message"
 	"github.com/das7pad/overleaf-go/pkg/models/project"
 	tagModel "github.com/das7pad/overleaf-go/pkg/models/tag"
@@ -50,6 +51,7 @@ import (
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/login"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/notifications"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/openInOverleaf"
+	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/projectActivity"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/projectDeletion"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/projectDownload"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/projectInvite"
@@ -60,7 +62,9 @@ import (
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/spelling"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/systemMessage"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/tag"
+	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/thumbnail"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/tokenAccess"
+	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/uploadScanner"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/userCreation"
 	"github.com/das7pad/overleaf-go/services/web/pkg/managers/web/internal/userDeletion"
 	"github.com/das7pad/overleaf-go/services/web/pkg/types"
@@ -83,6 +87,7 @@ type Manager interface {
 	loginManager
 	notificationsManager
 	openInOverleafManager
+	projectActivityManager
 	projectDeletionManager
 	projectDownloadManager
 	projectInviteManager
@@ -94,6 +99,7 @@ type Manager interface {
 	spellingManager
 	systemMessageManager
 	tagManager
+	thumbnailManager
 	tokenAccessManager
 	userCreationManager
 	userDeletionManager
@@ -121,6 +127,7 @@ func New(options *types.Options, db *pgxpool.Pool, client redis.UniversalClient,
 		return nil, err
 	}
 	options.SessionCookie.Secure = options.SiteURL.Scheme == "https"
+	options.UsePooledEmailSender()
 	sm := session.New(options.SessionCookie, client)
 	editorEvents := channel.NewWriter(client, "editor-events")
 	mm := message.New(db)
@@ -129,7 +136,11 @@ func New(options *types.Options, db *pgxpool.Pool, client redis.UniversalClient,
 		return nil, err
 	}
 	nm := notifications.New(db)
-	pm := project.New(db)
+	sc, err := doc.NewSnapshotCipher(options.SnapshotEncryption)
+	if err != nil {
+		return nil, err
+	}
+	pm := project.New(db, sc)
 	smm := systemMessage.New(db)
 	tm := tagModel.New(db)
 	um := user.New(db)
@@ -158,23 +169,26 @@ func New(options *types.Options, db *pgxpool.Pool, client redis.UniversalClient,
 	)
 	pmm := projectMetadata.New(client, editorEvents, pm, dum)
 	tagM := tag.New(tm)
-	tam := tokenAccess.New(options, ps, pm)
+	tam := tokenAccess.New(options, ps, db, pm)
 	pim := projectInvite.New(
 		options, ps, db, editorEvents, pm, um,
 	)
-	ftm := fileTree.New(pm, dum, fm, editorEvents, pmm)
-	pum := projectUpload.New(options, pm, um, dum, fm)
-	hm, err := history.New(db, client, dum)
+	us := uploadScanner.New(options.UploadScanner)
+	ftm := fileTree.New(options, pm, dum, fm, editorEvents, pmm, us)
+	pum := projectUpload.New(options, pm, um, dum, fm, us)
+	hm, err := history.New(db, dum)
 	if err != nil {
 		return nil, err
 	}
 	OIOm := openInOverleaf.New(options, ps, proxy, pum)
+	pam := projectActivity.New(hm, mm, pm)
 	lfm, err := linkedFile.New(options, pm, dum, fm, cm, ftm, proxy)
 	if err != nil {
 		return nil, err
 	}
-	pdm := projectDownload.New(pm, dum, fm)
+	pdm := projectDownload.New(options.ProjectDownload, pm, dum, fm)
 	pDelM := projectDeletion.New(pm, dum, fm)
+	thm := thumbnail.New(options.Thumbnails, pm, fm)
 	uDelM := userDeletion.New(um, pDelM)
 	ucm := userCreation.New(options, ps, db, um, lm)
 	learnM, err := learn.New(options, ps, proxy)
@@ -200,6 +214,7 @@ func New(options *types.Options, db *pgxpool.Pool, client redis.UniversalClient,
 		loginManager:           lm,
 		notificationsManager:   nm,
 		openInOverleafManager:  OIOm,
+		projectActivityManager: pam,
 		projectDeletionManager: pDelM,
 		projectDownloadManager: pdm,
 		projectInviteManager:   pim,
@@ -213,6 +228,7 @@ func New(options *types.Options, db *pgxpool.Pool, client redis.UniversalClient,
 		spellingManager:        spm,
 		systemMessageManager:   smm,
 		tagManager:             tagM,
+		thumbnailManager:       thm,
 		tokenAccessManager:     tam,
 		userCreationManager:    ucm,
 		userDeletionManager:    uDelM,
@@ -241,6 +257,8 @@ type notificationsManager = notifications.Manager
 
 type openInOverleafManager = openInOverleaf.Manager
 
+type projectActivityManager = projectActivity.Manager
+
 type projectDeletionManager = projectDeletion.Manager
 
 type projectDownloadManager = projectDownload.Manager
@@ -261,6 +279,8 @@ type systemMessageManager = systemMessage.Manager
 
 type tagManager = tag.Manager
 
+type thumbnailManager = thumbnail.Manager
+
 type tokenAccessManager = tokenAccess.Manager
 
 type siteLanguageManager = siteLanguage.Manager
@@ -281,6 +301,7 @@ type manager struct {
 	loginManager
 	notificationsManager
 	openInOverleafManager
+	projectActivityManager
 	projectDeletionManager
 	projectDownloadManager
 	projectInviteManager
@@ -292,6 +313,7 @@ type manager struct {
 	spellingManager
 	systemMessageManager
 	tagManager
+	thumbnailManager
 	tokenAccessManager
 	userCreationManager
 	userDeletionManager
@@ -335,15 +357,31 @@ func (m *manager) CronOnce(ctx context.Context, dryRun bool) bool {
 	start := time.Now()
 	ok := true
 	if err := m.HardDeleteExpiredProjects(ctx, dryRun, start); err != nil {
-		log.Println("hard deletion of projects failed: " + err.Error())
+		logger.Error("hard deletion of projects failed", err)
 		ok = false
 	}
 	if err := m.HardDeleteExpiredUsers(ctx, dryRun, start); err != nil {
-		log.Println("hard deletion of users failed: " + err.Error())
+		logger.Error("hard deletion of users failed", err)
 		ok = false
 	}
 	if err := m.CleanupStaleFileUploads(ctx, dryRun, start); err != nil {
-		log.Println("purging of file uploads failed: " + err.Error())
+		logger.Error("purging of file uploads failed", err)
+		ok = false
+	}
+	if err := m.RemoveExpiredMembers(ctx, dryRun, start); err != nil {
+		logger.Error("removing expired project members failed", err)
+		ok = false
+	}
+	if err := m.PropagateDefaultCompiler(ctx, dryRun, start); err != nil {
+		logger.Error("propagation of default compiler failed", err)
+		ok = false
+	}
+	if err := m.PruneDocHistory(ctx, dryRun, start); err != nil {
+		logger.Error("pruning of doc history failed", err)
+		ok = false
+	}
+	if err := m.CleanupStaleThumbnails(ctx, dryRun, start); err != nil {
+		logger.Error("cleanup of thumbnails failed", err)
 		ok = false
 	}
 	return ok
@@ -30,6 +30,7 @@ import (
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/httpUtils"
 	"github.com/das7pad/overleaf-go/pkg/jwt/projectJWT"
+	"github.com/das7pad/overleaf-go/pkg/models/message"
 	"github.com/das7pad/overleaf-go/pkg/models/project"
 	"github.com/das7pad/overleaf-go/pkg/models/projectInvite"
 	"github.com/das7pad/overleaf-go/pkg/session"
@@ -123,9 +124,12 @@ func (h *httpController) addRoutes(router *httpUtils.Router, corsOptions httpUti
 	apiRouter.POST("/grant/rw/{token}", h.grantTokenAccessReadAndWrite)
 	apiRouter.POST("/project/new", h.createExampleProject)
 	apiRouter.POST("/project/new/upload", h.createFromZip)
+	apiRouter.POST("/project/new/git", h.createFromGitURL)
 	apiRouter.GET("/project/download/zip", h.createMultiProjectZIP)
 	apiRouter.POST("/register", h.registerUser)
 	apiRouter.GET("/spelling/dict", h.getDictionary)
+	apiRouter.GET("/spelling/dict/export", h.exportLearnedWords)
+	apiRouter.POST("/spelling/dict/import", h.importLearnedWords)
 	apiRouter.POST("/spelling/learn", h.learnWord)
 	apiRouter.POST("/spelling/unlearn", h.unlearnWord)
 	apiRouter.GET("/user/contacts", h.getUserContacts)
@@ -142,6 +146,7 @@ func (h *httpController) addRoutes(router *httpUtils.Router, corsOptions httpUti
 	apiRouter.PUT("/user/settings/name", h.setUserName)
 	apiRouter.GET("/user/jwt", h.getLoggedInUserJWT)
 	apiRouter.GET("/user/projects", h.getUserProjects)
+	apiRouter.PUT("/user/projects/spellCheckLanguage", h.setSpellCheckLanguageForOwnedProjects)
 	apiRouter.POST("/login", h.login)
 	apiRouter.POST("/logout", h.logout)
 
@@ -185,6 +190,7 @@ func (h *httpController) addRoutes(router *httpUtils.Router, corsOptions httpUti
 		r.GET("/jwt", h.getProjectJWT)
 		r.POST("/leave", h.leaveProject)
 		r.POST("/rename", h.renameProject)
+		r.GET("/statistics", h.getProjectStatistics)
 		r.DELETE("/trash", h.unTrashProject)
 		r.POST("/trash", h.trashProject)
 		r.POST("/undelete", h.deleteProject)
@@ -194,6 +200,8 @@ func (h *httpController) addRoutes(router *httpUtils.Router, corsOptions httpUti
 		rFile.Use(httpUtils.ValidateAndSetId("fileId"))
 		rFile.GET("", h.getProjectFile)
 		rFile.HEAD("", h.getProjectFileSize)
+		rFile.GET("/metadata", h.getProjectFileMetadata)
+		rFile.GET("/thumbnail", h.getProjectFileThumbnail)
 
 		rInvite := r.Group("/invite")
 		rTokenInvite := rInvite.Group("/token/{token}")
@@ -224,7 +232,14 @@ func (h *httpController) addRoutes(router *httpUtils.Router, corsOptions httpUti
 	projectJWTRouter.POST("/wordcount", h.wordCount)
 
 	projectJWTRouter.GET("/accessTokens", h.getAccessTokens)
+	projectJWTRouter.GET("/bibliography", h.getBibliography)
+	projectJWTRouter.GET("/dependencyGraph", h.getDependencyGraph)
+	projectJWTRouter.GET("/lint", h.lintProject)
 	projectJWTRouter.GET("/metadata", h.getMetadataForProject)
+	projectJWTRouter.GET("/snippets", h.listProjectSnippets)
+	projectJWTRouter.GET("/system/messages", h.getProjectSystemMessages)
+	projectJWTRouter.GET("/unusedFiles", h.listUnusedFiles)
+	projectJWTRouter.POST("/docs/metadata", h.getMetadataForDocs)
 
 	{
 		// Write endpoints
@@ -239,10 +254,13 @@ func (h *httpController) addRoutes(router *httpUtils.Router, corsOptions httpUti
 		r.POST("/doc", h.addDocToProject)
 		r.POST("/folder", h.addFolderToProject)
 		r.POST("/linked_file", h.createLinkedFile)
+		r.POST("/snippets", h.setProjectSnippet)
+		r.DELETE("/snippets/{name}", h.deleteProjectSnippet)
 
 		rDoc := r.Group("/doc/{docId}")
 		rDoc.Use(httpUtils.ValidateAndSetId("docId"))
 		rDoc.DELETE("", h.deleteDocFromProject)
+		rDoc.POST("/flush", h.flushDoc)
 		rDoc.POST("/rename", h.renameDocInProject)
 		rDoc.POST("/move", h.moveDocInProject)
 		rDoc.POST("/restore", h.restoreDeletedDocInProject)
@@ -273,10 +291,20 @@ func (h *httpController) addRoutes(router *httpUtils.Router, corsOptions httpUti
 		r.Use(blockRestrictedUsers)
 		r.GET("/members", h.listProjectMembers)
 		r.GET("/messages", h.getProjectMessages)
+		r.GET("/messages/v2", h.getProjectMessagesPage)
 		r.POST("/messages", h.sendProjectMessage)
+		r.GET("/messages/export", h.exportProjectMessages)
+		r.GET("/messages/search", h.searchProjectMessages)
+		rMessage := r.Group("/messages/{messageId}")
+		rMessage.Use(httpUtils.ValidateAndSetId("messageId"))
+		rMessage.POST("/edit", h.editProjectMessage)
+		rMessage.DELETE("", h.deleteProjectMessage)
+		rMessage.POST("/reactions", h.addProjectMessageReaction)
+		rMessage.DELETE("/reactions/{emoji}", h.removeProjectMessageReaction)
 
 		// History
 		r.GET("/updates", h.getProjectHistoryUpdates)
+		r.GET("/activity", h.getProjectActivity)
 		rDoc := r.Group("/doc/{docId}")
 		rDoc.Use(httpUtils.ValidateAndSetId("docId"))
 		rDoc.GET("/diff", h.getProjectDocDiff)
@@ -287,21 +315,28 @@ func (h *httpController) addRoutes(router *httpUtils.Router, corsOptions httpUti
 		r.Use(requireProjectAdminAccess)
 
 		r.PUT("/settings/admin/publicAccessLevel", h.setPublicAccessLevel)
+		r.PUT("/settings/admin/tokenReadAndWritePrivilegeLevel", h.setTokenReadAndWritePrivilegeLevel)
 		r.PUT("/settings/admin/contentLocked", h.setContentLocked)
+		r.PUT("/settings/admin/historyRetainFor", h.setHistoryRetainFor)
+		r.PUT("/settings/admin/maxUnFlushedAge", h.setMaxUnFlushedAge)
+		r.GET("/settings/admin/previewTokenAccess", h.previewTokenAccess)
 
 		r.POST("/invite", h.createProjectInvite)
 		r.GET("/invites", h.listProjectInvites)
+		r.GET("/token-members", h.listProjectTokenMembers)
 		rInvite := r.Group("/invite/{inviteId}")
 		rInvite.Use(httpUtils.ValidateAndSetId("inviteId"))
 		rInvite.DELETE("", h.revokeProjectInvite)
 		rInvite.POST("/resend", h.resendProjectInvite)
 
 		r.POST("/transfer-ownership", h.transferProjectOwnership)
+		r.POST("/history/restore", h.restoreProjectToVersion)
 
 		rUser := r.Group("/users/{userId}")
 		rUser.Use(httpUtils.ValidateAndSetId("userId"))
 		rUser.DELETE("", h.removeMemberFromProject)
 		rUser.PUT("", h.setMemberPrivilegeLevelInProject)
+		rUser.PUT("/expiry", h.setMemberAccessExpiresAtInProject)
 	}
 
 	projectJWTDocRouter := projectJWTRouter.Group("/doc/{docId}")
@@ -567,6 +602,12 @@ func (h *httpController) getSystemMessages(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusOK, m, err)
 }
 
+func (h *httpController) getProjectSystemMessages(c *httpUtils.Context) {
+	o := mustGetProjectOptionsFromJWT(c)
+	m, err := h.wm.GetForProject(c, o.UserId, o.ProjectId)
+	httpUtils.Respond(c, http.StatusOK, m, err)
+}
+
 func (h *httpController) getUserProjects(c *httpUtils.Context) {
 	request := &types.GetUserProjectsRequest{}
 	response := &types.GetUserProjectsResponse{}
@@ -585,6 +626,44 @@ func (h *httpController) getMetadataForProject(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusOK, response, err)
 }
 
+func (h *httpController) getBibliography(c *httpUtils.Context) {
+	request := &types.GetBibliographyRequest{}
+	request.ProjectId = mustGetProjectOptionsFromJWT(c).ProjectId
+	response := &types.GetBibliographyResponse{}
+	err := h.wm.GetBibliography(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
+func (h *httpController) getDependencyGraph(c *httpUtils.Context) {
+	request := &types.GetDependencyGraphRequest{}
+	if !h.mustProcessQuery(request, c) {
+		return
+	}
+	request.ProjectId = mustGetProjectOptionsFromJWT(c).ProjectId
+	response := &types.GetDependencyGraphResponse{}
+	err := h.wm.GetDependencyGraph(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
+func (h *httpController) listUnusedFiles(c *httpUtils.Context) {
+	request := &types.ListUnusedFilesRequest{}
+	if !h.mustProcessQuery(request, c) {
+		return
+	}
+	request.ProjectId = mustGetProjectOptionsFromJWT(c).ProjectId
+	response := &types.ListUnusedFilesResponse{}
+	err := h.wm.ListUnusedFiles(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
+func (h *httpController) lintProject(c *httpUtils.Context) {
+	request := &types.LintProjectRequest{}
+	request.ProjectId = mustGetProjectOptionsFromJWT(c).ProjectId
+	response := &types.LintProjectResponse{}
+	err := h.wm.LintProject(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
 func (h *httpController) getMetadataForDoc(c *httpUtils.Context) {
 	request := &types.GetMetadataForDocRequest{}
 	if !httpUtils.MustParseJSON(request, c) {
@@ -597,6 +676,17 @@ func (h *httpController) getMetadataForDoc(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusOK, response, err)
 }
 
+func (h *httpController) getMetadataForDocs(c *httpUtils.Context) {
+	request := &types.GetMetadataForDocsRequest{}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	request.ProjectId = mustGetProjectOptionsFromJWT(c).ProjectId
+	response := &types.GetMetadataForDocsResponse{}
+	err := h.wm.GetMetadataForDocs(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
 func (h *httpController) login(c *httpUtils.Context) {
 	request := &types.LoginRequest{}
 	response := &types.LoginResponse{}
@@ -653,11 +743,48 @@ func (h *httpController) getProjectMessages(c *httpUtils.Context) {
 		return
 	}
 	request.ProjectId = projectJWT.MustGet(c).ProjectId
-	response := make(types.GetProjectChatMessagesResponse, 0)
-	err := h.wm.GetProjectMessages(c, request, &response)
+	response := &types.GetProjectChatMessagesResponse{}
+	err := h.wm.GetProjectMessages(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response.Messages, err)
+}
+
+func (h *httpController) getProjectMessagesPage(c *httpUtils.Context) {
+	request := &types.GetProjectChatMessagesRequest{}
+	if !h.mustProcessQuery(request, c) {
+		return
+	}
+	request.ProjectId = projectJWT.MustGet(c).ProjectId
+	response := &types.GetProjectChatMessagesResponse{}
+	err := h.wm.GetProjectMessages(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
+func (h *httpController) searchProjectMessages(c *httpUtils.Context) {
+	request := &types.SearchProjectChatMessagesRequest{}
+	if !h.mustProcessQuery(request, c) {
+		return
+	}
+	request.ProjectId = projectJWT.MustGet(c).ProjectId
+	response := &types.SearchProjectChatMessagesResponse{}
+	err := h.wm.SearchProjectMessages(c, request, response)
 	httpUtils.Respond(c, http.StatusOK, response, err)
 }
 
+func (h *httpController) exportProjectMessages(c *httpUtils.Context) {
+	request := &types.ExportProjectChatRequest{
+		ProjectId: projectJWT.MustGet(c).ProjectId,
+	}
+	response := &types.ExportProjectChatResponse{}
+	if err := h.wm.ExportProjectMessages(c, request, response); err != nil {
+		httpUtils.RespondErr(c, err)
+		return
+	}
+	prepareFileResponse(c, response.Filename, int64(len(response.Transcript)))
+	c.Writer.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	c.Writer.WriteHeader(http.StatusOK)
+	_, _ = c.Writer.Write(response.Transcript)
+}
+
 func (h *httpController) sendProjectMessage(c *httpUtils.Context) {
 	request := &types.SendProjectChatMessageRequest{}
 	if !httpUtils.MustParseJSON(request, c) {
@@ -669,6 +796,51 @@ func (h *httpController) sendProjectMessage(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusNoContent, nil, err)
 }
 
+func (h *httpController) editProjectMessage(c *httpUtils.Context) {
+	request := &types.EditProjectChatMessageRequest{}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	request.ProjectId = projectJWT.MustGet(c).ProjectId
+	request.UserId = projectJWT.MustGet(c).UserId
+	request.MessageId = httpUtils.GetId(c, "messageId")
+	err := h.wm.EditProjectMessage(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
+func (h *httpController) addProjectMessageReaction(c *httpUtils.Context) {
+	request := &types.ReactToProjectChatMessageRequest{}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	request.ProjectId = projectJWT.MustGet(c).ProjectId
+	request.UserId = projectJWT.MustGet(c).UserId
+	request.MessageId = httpUtils.GetId(c, "messageId")
+	err := h.wm.AddProjectMessageReaction(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
+func (h *httpController) removeProjectMessageReaction(c *httpUtils.Context) {
+	request := &types.ReactToProjectChatMessageRequest{
+		ProjectId: projectJWT.MustGet(c).ProjectId,
+		UserId:    projectJWT.MustGet(c).UserId,
+		MessageId: httpUtils.GetId(c, "messageId"),
+		Emoji:     message.Emoji(c.Param("emoji")),
+	}
+	err := h.wm.RemoveProjectMessageReaction(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
+func (h *httpController) deleteProjectMessage(c *httpUtils.Context) {
+	request := &types.DeleteProjectChatMessageRequest{
+		ProjectId: projectJWT.MustGet(c).ProjectId,
+		UserId:    projectJWT.MustGet(c).UserId,
+		MessageId: httpUtils.GetId(c, "messageId"),
+	}
+	err := h.wm.DeleteProjectMessage(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
 func (h *httpController) optInBetaProgram(c *httpUtils.Context) {
 	request := &types.OptInBetaProgramRequest{}
 	if !h.mustGetOrCreateSession(c, request, nil) {
@@ -695,10 +867,25 @@ func (h *httpController) getProjectEntities(c *httpUtils.Context) {
 	if !h.mustGetOrCreateSession(c, request, response) {
 		return
 	}
+	if !h.mustProcessQuery(request, c) {
+		return
+	}
 	err := h.wm.GetProjectEntities(c, request, response)
 	httpUtils.Respond(c, http.StatusOK, response, err)
 }
 
+func (h *httpController) getProjectStatistics(c *httpUtils.Context) {
+	request := &types.GetProjectStatisticsRequest{
+		ProjectId: httpUtils.GetId(c, "projectId"),
+	}
+	response := &types.GetProjectStatisticsResponse{}
+	if !h.mustGetOrCreateSession(c, request, response) {
+		return
+	}
+	err := h.wm.GetProjectStatistics(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
 func (h *httpController) grantTokenAccessReadAndWrite(c *httpUtils.Context) {
 	request := &types.GrantTokenAccessRequest{
 		Token: project.AccessToken(c.Param("token")),
@@ -889,6 +1076,10 @@ func (h *httpController) getProjectFile(c *httpUtils.Context) {
 		httpUtils.Respond(c, http.StatusOK, nil, err)
 		return
 	}
+	if response.RedirectURL != "" {
+		httpUtils.Redirect(c, response.RedirectURL)
+		return
+	}
 	prepareFileResponse(c, response.Filename, response.Size)
 	http.ServeContent(
 		c.Writer, c.Request, string(response.Filename), time.Time{},
@@ -914,6 +1105,38 @@ func (h *httpController) getProjectFileSize(c *httpUtils.Context) {
 	c.Writer.WriteHeader(http.StatusOK)
 }
 
+func (h *httpController) getProjectFileMetadata(c *httpUtils.Context) {
+	request := &types.GetProjectFileMetadataRequest{
+		ProjectId: httpUtils.GetId(c, "projectId"),
+		FileId:    httpUtils.GetId(c, "fileId"),
+	}
+	if !h.mustGetOrCreateSession(c, request, nil) {
+		return
+	}
+	response := &types.GetProjectFileMetadataResponse{}
+	err := h.wm.GetProjectFileMetadata(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
+func (h *httpController) getProjectFileThumbnail(c *httpUtils.Context) {
+	request := &types.GetProjectFileThumbnailRequest{
+		ProjectId: httpUtils.GetId(c, "projectId"),
+		FileId:    httpUtils.GetId(c, "fileId"),
+	}
+	if !h.mustGetOrCreateSession(c, request, nil) {
+		return
+	}
+	response := &types.GetProjectFileThumbnailResponse{}
+	err := h.wm.GetProjectFileThumbnail(c, request, response)
+	if err != nil {
+		httpUtils.Respond(c, http.StatusOK, nil, err)
+		return
+	}
+	c.Writer.Header().Set("Content-Type", "image/png")
+	http.ServeContent(c.Writer, c.Request, "", time.Time{}, response.Reader)
+	_ = response.Reader.Close()
+}
+
 func (h *httpController) addDocToProject(c *httpUtils.Context) {
 	request := &types.AddDocRequest{}
 	if !httpUtils.MustParseJSON(request, c) {
@@ -1139,6 +1362,14 @@ func (h *httpController) listProjectMembers(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusOK, response, err)
 }
 
+func (h *httpController) listProjectTokenMembers(c *httpUtils.Context) {
+	request := &types.ListProjectTokenMembersRequest{}
+	h.mustProcessSignedProjectOptions(request, c)
+	response := &types.ListProjectTokenMembersResponse{}
+	err := h.wm.ListProjectTokenMembers(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
 func (h *httpController) removeMemberFromProject(c *httpUtils.Context) {
 	request := &types.RemoveProjectMemberRequest{
 		MemberId: httpUtils.GetId(c, "userId"),
@@ -1148,6 +1379,17 @@ func (h *httpController) removeMemberFromProject(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusNoContent, nil, err)
 }
 
+func (h *httpController) setMemberAccessExpiresAtInProject(c *httpUtils.Context) {
+	request := &types.SetMemberAccessExpiresAtInProjectRequest{}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	h.mustProcessSignedProjectOptions(request, c)
+	request.MemberId = httpUtils.GetId(c, "userId")
+	err := h.wm.SetMemberAccessExpiresAtInProject(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
 func (h *httpController) setMemberPrivilegeLevelInProject(c *httpUtils.Context) {
 	request := &types.SetMemberPrivilegeLevelInProjectRequest{}
 	if !httpUtils.MustParseJSON(request, c) {
@@ -1190,6 +1432,33 @@ func (h *httpController) setCompiler(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusNoContent, nil, err)
 }
 
+func (h *httpController) listProjectSnippets(c *httpUtils.Context) {
+	request := &types.ListProjectSnippetsRequest{}
+	h.mustProcessSignedProjectOptions(request, c)
+	response := &types.ListProjectSnippetsResponse{}
+	err := h.wm.ListProjectSnippets(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
+func (h *httpController) setProjectSnippet(c *httpUtils.Context) {
+	request := &types.SetProjectSnippetRequest{}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	h.mustProcessSignedProjectOptions(request, c)
+	err := h.wm.SetProjectSnippet(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
+func (h *httpController) deleteProjectSnippet(c *httpUtils.Context) {
+	request := &types.DeleteProjectSnippetRequest{
+		Name: project.SnippetName(c.Param("name")),
+	}
+	h.mustProcessSignedProjectOptions(request, c)
+	err := h.wm.DeleteProjectSnippet(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
 func (h *httpController) setImageName(c *httpUtils.Context) {
 	request := &types.SetImageNameRequest{}
 	if !httpUtils.MustParseJSON(request, c) {
@@ -1231,6 +1500,16 @@ func (h *httpController) setPublicAccessLevel(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusOK, response, err)
 }
 
+func (h *httpController) setTokenReadAndWritePrivilegeLevel(c *httpUtils.Context) {
+	request := &types.SetTokenReadAndWritePrivilegeLevelRequest{}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	h.mustProcessSignedProjectOptions(request, c)
+	err := h.wm.SetTokenReadAndWritePrivilegeLevel(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
 func (h *httpController) getAccessTokens(c *httpUtils.Context) {
 	request := &types.GetAccessTokensRequest{}
 	h.mustProcessSignedProjectOptions(request, c)
@@ -1239,6 +1518,18 @@ func (h *httpController) getAccessTokens(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusOK, response, err)
 }
 
+func (h *httpController) previewTokenAccess(c *httpUtils.Context) {
+	request := &types.PreviewTokenAccessRequest{
+		PrivilegeLevel: sharedTypes.PrivilegeLevel(
+			c.Request.URL.Query().Get("privilegeLevel"),
+		),
+	}
+	h.mustProcessSignedProjectOptions(request, c)
+	response := &types.PreviewTokenAccessResponse{}
+	err := h.wm.PreviewTokenAccess(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
 func (h *httpController) setContentLocked(c *httpUtils.Context) {
 	request := &types.SetContentLockedRequest{}
 	if !httpUtils.MustParseJSON(request, c) {
@@ -1249,6 +1540,26 @@ func (h *httpController) setContentLocked(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusNoContent, nil, err)
 }
 
+func (h *httpController) setMaxUnFlushedAge(c *httpUtils.Context) {
+	request := &types.SetMaxUnFlushedAgeRequest{}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	h.mustProcessSignedProjectOptions(request, c)
+	err := h.wm.SetMaxUnFlushedAge(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
+func (h *httpController) setHistoryRetainFor(c *httpUtils.Context) {
+	request := &types.SetHistoryRetainForRequest{}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	h.mustProcessSignedProjectOptions(request, c)
+	err := h.wm.SetHistoryRetainFor(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
 func (h *httpController) clearSessions(c *httpUtils.Context) {
 	request := &types.ClearSessionsRequest{
 		IPAddress: c.ClientIP(),
@@ -1314,6 +1625,22 @@ func (h *httpController) createFromZip(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusOK, response, err)
 }
 
+func (h *httpController) createFromGitURL(c *httpUtils.Context) {
+	request := &types.CreateProjectFromGitURLRequest{}
+	if !h.mustRequireLoggedInSession(c, request) {
+		return
+	}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	response := &types.CreateProjectResponse{}
+	err := h.wm.CreateFromGitURL(c, request, response)
+	if err != nil && errors.IsValidationError(err) {
+		response.Error = "Error: " + err.Error()
+	}
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
 func (h *httpController) getUserNotifications(c *httpUtils.Context) {
 	request := &types.GetNotificationsRequest{}
 	if !h.mustRequireLoggedInSession(c, request) {
@@ -1397,12 +1724,41 @@ func (h *httpController) refreshLinkedFile(c *httpUtils.Context) {
 }
 
 func (h *httpController) createProjectZIP(c *httpUtils.Context) {
-	request := &types.CreateProjectZIPRequest{
-		ProjectId: httpUtils.GetId(c, "projectId"),
-	}
+	request := &types.StreamProjectZIPRequest{}
+	request.ProjectId = httpUtils.GetId(c, "projectId")
 	if !h.mustGetOrCreateSession(c, request, nil) {
 		return
 	}
+	if !h.mustProcessQuery(request, c) {
+		return
+	}
+	if c.Request.Header.Get("Range") != "" {
+		// Range requests need the full archive for seeking.
+		h.createProjectZIPOnDisk(c, &request.CreateProjectZIPRequest)
+		return
+	}
+
+	streaming := false
+	request.GetWriter = func(filename sharedTypes.Filename) (io.Writer, error) {
+		cd := fmt.Sprintf("attachment; filename=%q", filename)
+		c.Writer.Header().Set("Content-Disposition", cd)
+		c.Writer.Header().Set("Content-Type", request.Format.ContentType())
+		httpUtils.EndTotalTimer(c)
+		streaming = true
+		return c.Writer, nil
+	}
+	if err := h.wm.StreamProjectZIP(c, request); err != nil {
+		if !streaming {
+			httpUtils.RespondErr(c, err)
+			return
+		}
+		// Abort the connection rather than completing a truncated archive.
+		httpUtils.GetAndLogErrResponseDetails(c, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func (h *httpController) createProjectZIPOnDisk(c *httpUtils.Context, request *types.CreateProjectZIPRequest) {
 	response := &types.CreateProjectZIPResponse{}
 	defer response.Cleanup()
 
@@ -1412,6 +1768,7 @@ func (h *httpController) createProjectZIP(c *httpUtils.Context) {
 	}
 	cd := fmt.Sprintf("attachment; filename=%q", response.Filename)
 	c.Writer.Header().Set("Content-Disposition", cd)
+	c.Writer.Header().Set("Content-Type", request.Format.ContentType())
 	httpUtils.EndTotalTimer(c)
 	http.ServeFile(c.Writer, c.Request, response.FSPath)
 }
@@ -1433,6 +1790,7 @@ func (h *httpController) createMultiProjectZIP(c *httpUtils.Context) {
 	}
 	cd := fmt.Sprintf("attachment; filename=%q", response.Filename)
 	c.Writer.Header().Set("Content-Disposition", cd)
+	c.Writer.Header().Set("Content-Type", request.Format.ContentType())
 	httpUtils.EndTotalTimer(c)
 	http.ServeFile(c.Writer, c.Request, response.FSPath)
 }
@@ -1475,6 +1833,18 @@ func (h *httpController) updateEditorConfig(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusNoContent, nil, err)
 }
 
+func (h *httpController) setSpellCheckLanguageForOwnedProjects(c *httpUtils.Context) {
+	request := &types.SetSpellCheckLanguageForOwnedProjectsRequest{}
+	if !h.mustRequireLoggedInSession(c, request) {
+		return
+	}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	err := h.wm.SetSpellCheckLanguageForOwnedProjects(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
 func (h *httpController) changeEmailAddress(c *httpUtils.Context) {
 	request := &types.ChangeEmailAddressRequest{}
 	if !h.mustRequireLoggedInSession(c, request) {
@@ -1571,6 +1941,17 @@ func (h *httpController) getProjectHistoryUpdates(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusOK, res, err)
 }
 
+func (h *httpController) getProjectActivity(c *httpUtils.Context) {
+	request := &types.GetProjectActivityRequest{}
+	if !h.mustProcessQuery(request, c) {
+		return
+	}
+	h.mustProcessSignedProjectOptions(request, c)
+	res := &types.GetProjectActivityResponse{}
+	err := h.wm.GetProjectActivity(c, request, res)
+	httpUtils.Respond(c, http.StatusOK, res, err)
+}
+
 func (h *httpController) getProjectDocDiff(c *httpUtils.Context) {
 	request := &types.GetDocDiffRequest{}
 	if !h.mustProcessQuery(request, c) {
@@ -1583,6 +1964,15 @@ func (h *httpController) getProjectDocDiff(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusOK, res, err)
 }
 
+func (h *httpController) flushDoc(c *httpUtils.Context) {
+	request := &types.FlushDocRequest{}
+	h.mustProcessSignedProjectOptions(request, c)
+	request.DocId = httpUtils.GetId(c, "docId")
+	response := &types.FlushDocResponse{}
+	err := h.wm.FlushDoc(c, request, response)
+	httpUtils.Respond(c, http.StatusOK, response, err)
+}
+
 func (h *httpController) restoreDocVersion(c *httpUtils.Context) {
 	request := &types.RestoreDocVersionRequest{}
 	if err := request.FromV.ParseIfSet(c.Param("version")); err != nil {
@@ -1595,6 +1985,16 @@ func (h *httpController) restoreDocVersion(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusNoContent, nil, err)
 }
 
+func (h *httpController) restoreProjectToVersion(c *httpUtils.Context) {
+	request := &types.RestoreProjectToVersionRequest{}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	h.mustProcessSignedProjectOptions(request, c)
+	err := h.wm.RestoreProjectToVersion(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
 func (h *httpController) registerUser(c *httpUtils.Context) {
 	response := &types.RegisterUserResponse{}
 	request := &types.RegisterUserRequest{}
@@ -2004,6 +2404,34 @@ func (h *httpController) getDictionary(c *httpUtils.Context) {
 	httpUtils.Respond(c, http.StatusOK, res, err)
 }
 
+func (h *httpController) exportLearnedWords(c *httpUtils.Context) {
+	request := &types.ExportLearnedWordsRequest{}
+	if !h.mustRequireLoggedInSession(c, request) {
+		return
+	}
+	res := &types.ExportLearnedWordsResponse{}
+	if err := h.wm.ExportLearnedWords(c, request, res); err != nil {
+		httpUtils.RespondErr(c, err)
+		return
+	}
+	c.Writer.Header().Set(
+		"Content-Disposition", `attachment; filename="learned-words.txt"`,
+	)
+	httpUtils.RespondPlain(c, http.StatusOK, res.String())
+}
+
+func (h *httpController) importLearnedWords(c *httpUtils.Context) {
+	request := &types.ImportLearnedWordsRequest{}
+	if !h.mustRequireLoggedInSession(c, request) {
+		return
+	}
+	if !httpUtils.MustParseJSON(request, c) {
+		return
+	}
+	err := h.wm.ImportLearnedWords(c, request)
+	httpUtils.Respond(c, http.StatusNoContent, nil, err)
+}
+
 func (h *httpController) learnWord(c *httpUtils.Context) {
 	request := &types.LearnWordRequest{}
 	if !h.mustRequireLoggedInSession(c, request) {
@@ -0,0 +1,77 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"strings"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+type CreateProjectFromGitURLRequest struct {
+	WithSession
+
+	Compiler       sharedTypes.Compiler `json:"compiler"`
+	HasDefaultName bool                 `json:"-"`
+	Name           project.Name         `json:"name"`
+	Ref            string               `json:"ref"`
+	URL            *sharedTypes.URL     `json:"url"`
+}
+
+func (r *CreateProjectFromGitURLRequest) Preprocess() {
+	if r.Ref == "" {
+		r.Ref = "HEAD"
+	}
+	if r.Name == "" && r.URL != nil {
+		r.HasDefaultName = true
+		p := strings.TrimSuffix(strings.TrimRight(r.URL.Path, "/"), ".git")
+		r.Name = project.Name(p[strings.LastIndexByte(p, '/')+1:])
+	}
+}
+
+func (r *CreateProjectFromGitURLRequest) Validate() error {
+	if r.Compiler != "" {
+		if err := r.Compiler.Validate(); err != nil {
+			return errors.Tag(err, "compiler")
+		}
+	}
+	if err := r.Name.Validate(); err != nil {
+		return errors.Tag(err, "name")
+	}
+	if r.URL == nil {
+		return &errors.ValidationError{Msg: "missing url"}
+	}
+	if err := r.URL.Validate(); err != nil {
+		return errors.Tag(err, "url")
+	}
+	if strings.Contains(r.Ref, "..") ||
+		strings.HasPrefix(r.Ref, "/") ||
+		strings.HasSuffix(r.Ref, "/") {
+		return &errors.ValidationError{Msg: "invalid ref"}
+	}
+	for _, c := range r.Ref {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == '/':
+		default:
+			return &errors.ValidationError{Msg: "invalid ref"}
+		}
+	}
+	return nil
+}
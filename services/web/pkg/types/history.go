@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -24,8 +24,12 @@ type GetProjectHistoryUpdatesRequest = trackChangesTypes.GetProjectHistoryUpdate
 
 type GetProjectHistoryUpdatesResponse = trackChangesTypes.GetProjectHistoryUpdatesResponse
 
+type ProjectHistoryUpdate = trackChangesTypes.Update
+
 type GetDocDiffRequest = trackChangesTypes.GetDocDiffRequest
 
 type GetDocDiffResponse = trackChangesTypes.GetDocDiffResponse
 
 type RestoreDocVersionRequest = trackChangesTypes.RestoreDocVersionRequest
+
+type RestoreProjectToVersionRequest = trackChangesTypes.RestoreProjectToVersionRequest
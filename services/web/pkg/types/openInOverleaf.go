@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -71,10 +71,6 @@ func (r *OpenInOverleafRequest) Preprocess() {
 		r.HasDefaultName = true
 		r.ProjectName = "Untitled"
 	}
-	if r.Compiler == "" {
-		r.Compiler = project.DefaultCompiler
-	}
-
 	hasMainTex := false
 	nInlinedDocs := 0
 	for i := range r.Snippets {
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,6 +17,7 @@
 package types
 
 import (
+	"fmt"
 	"html/template"
 	"net/smtp"
 	"strings"
@@ -27,6 +28,8 @@ import (
 	"github.com/das7pad/overleaf-go/pkg/csp"
 	"github.com/das7pad/overleaf-go/pkg/email"
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/doc"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
 	"github.com/das7pad/overleaf-go/pkg/models/user"
 	"github.com/das7pad/overleaf-go/pkg/objectStorage"
 	"github.com/das7pad/overleaf-go/pkg/options/env"
@@ -37,19 +40,24 @@ import (
 )
 
 type Options struct {
-	AdminEmail        sharedTypes.Email            `json:"admin_email"`
-	AllowedImages     []sharedTypes.ImageName      `json:"allowed_images"`
-	AllowedImageNames []templates.AllowedImageName `json:"allowed_image_names"`
-	AppName           string                       `json:"app_name"`
-	BcryptCost        int                          `json:"bcrypt_cost"`
-	CDNURL            sharedTypes.URL              `json:"cdn_url"`
-	CSPReportURL      *sharedTypes.URL             `json:"csp_report_url"`
-	DefaultImage      sharedTypes.ImageName        `json:"default_image"`
-	Email             struct {
+	AdminEmail                sharedTypes.Email            `json:"admin_email"`
+	AllowedImages             []sharedTypes.ImageName      `json:"allowed_images"`
+	AllowedImageNames         []templates.AllowedImageName `json:"allowed_image_names"`
+	AllowedPublicAccessLevels PublicAccessLevels           `json:"allowed_public_access_levels"`
+	AppName                   string                       `json:"app_name"`
+	BcryptCost                int                          `json:"bcrypt_cost"`
+	CDNURL                    sharedTypes.URL              `json:"cdn_url"`
+	CSPReportURL              *sharedTypes.URL             `json:"csp_report_url"`
+	DefaultCompiler           sharedTypes.Compiler         `json:"default_compiler"`
+	DefaultImage              sharedTypes.ImageName        `json:"default_image"`
+	DefaultProjectFolders     []sharedTypes.DirName        `json:"default_project_folders"`
+	Email                     struct {
 		CustomFooter     string            `json:"custom_footer"`
 		CustomFooterHTML template.HTML     `json:"custom_footer_html"`
+		DKIM             email.DKIMOptions `json:"dkim"`
 		From             email.Identity    `json:"from"`
 		FallbackReplyTo  email.Identity    `json:"fallback_reply_to"`
+		Retry            email.RetryPolicy `json:"retry"`
 		SMTPAddress      email.SMTPAddress `json:"smtp_address"`
 		SMTPHello        string            `json:"smtp_hello"`
 		SMTPIdentity     string            `json:"smtp_identity"`
@@ -60,6 +68,7 @@ type Options struct {
 	LearnCacheDuration  time.Duration         `json:"learn_cache_duration"`
 	LearnImageCacheBase sharedTypes.DirName   `json:"learn_image_cache_base"`
 	ManifestPath        string                `json:"manifest_path"`
+	MaxFilesPerUpload   int                   `json:"max_files_per_upload"`
 	Nav                 templates.NavOptions  `json:"nav"`
 	PDFDownloadDomain   PDFDownloadDomain     `json:"pdf_download_domain"`
 	Sentry              SentryOptions         `json:"sentry"`
@@ -70,12 +79,19 @@ type Options struct {
 		ProjectId sharedTypes.UUID  `json:"projectId"`
 		UserId    sharedTypes.UUID  `json:"userId"`
 	} `json:"smoke_test"`
-	StatusPageURL             *sharedTypes.URL      `json:"status_page_url"`
-	TeXLiveImageNameOverride  sharedTypes.ImageName `json:"texlive_image_name_override"`
-	EmailConfirmationDisabled bool                  `json:"email_confirmation_disabled"`
-	RegistrationDisabled      bool                  `json:"registration_disabled"`
-	RobotsNoindex             bool                  `json:"robots_noindex"`
-	WatchManifest             bool                  `json:"watch_manifest"`
+	StatusPageURL                *sharedTypes.URL        `json:"status_page_url"`
+	TeXLiveImageNameOverride     sharedTypes.ImageName   `json:"texlive_image_name_override"`
+	UploadImageDownscaling       ImageDownscalingOptions `json:"upload_image_downscaling"`
+	UploadScanner                UploadScannerOptions    `json:"upload_scanner"`
+	Thumbnails                   ThumbnailOptions        `json:"thumbnails"`
+	ProjectDownload              ProjectDownloadOptions  `json:"project_download"`
+	AnonymousTokenAccessDisabled bool                    `json:"anonymous_token_access_disabled"`
+	EmailConfirmationDisabled    bool                    `json:"email_confirmation_disabled"`
+	GitImportEnabled             bool                    `json:"git_import_enabled"`
+	PropagateDefaultCompiler     bool                    `json:"propagate_default_compiler"`
+	RegistrationDisabled         bool                    `json:"registration_disabled"`
+	RobotsNoindex                bool                    `json:"robots_noindex"`
+	WatchManifest                bool                    `json:"watch_manifest"`
 
 	APIs struct {
 		Clsi struct {
@@ -98,9 +114,14 @@ type Options struct {
 
 	SessionCookie signedCookie.Options `json:"session_cookie"`
 
+	SnapshotEncryption doc.EncryptionOptions `json:"snapshot_encryption"`
+
 	RateLimits struct {
-		LinkSharingTokenLookupConcurrency int64 `json:"link_sharing_token_lookup_concurrency"`
+		LinkSharingTokenLookupConcurrency int64         `json:"link_sharing_token_lookup_concurrency"`
+		ProjectInviteResendInterval       time.Duration `json:"project_invite_resend_interval"`
 	} `json:"rate_limits"`
+
+	emailSender email.Sender
 }
 
 func (o *Options) FillFromEnv() {
@@ -122,6 +143,9 @@ func (o *Options) Validate() error {
 	if len(o.AllowedImages) == 0 {
 		return &errors.ValidationError{Msg: "allowed_images is missing"}
 	}
+	if err := o.AllowedPublicAccessLevels.Validate(); err != nil {
+		return errors.Tag(err, "allowed_public_access_levels is invalid")
+	}
 	if o.AppName == "" {
 		return &errors.ValidationError{Msg: "app_name is missing"}
 	}
@@ -134,9 +158,19 @@ func (o *Options) Validate() error {
 	if !strings.HasSuffix(o.CDNURL.Path, "/") {
 		return &errors.ValidationError{Msg: `cdn_url must end with "/"`}
 	}
+	if o.DefaultCompiler != "" {
+		if err := o.DefaultCompiler.Validate(); err != nil {
+			return errors.Tag(err, "default_compiler is invalid")
+		}
+	}
 	if len(o.DefaultImage) == 0 {
 		return &errors.ValidationError{Msg: "default_image is missing"}
 	}
+	for _, d := range o.DefaultProjectFolders {
+		if err := d.Validate(); err != nil {
+			return errors.Tag(err, "default_project_folders is invalid")
+		}
+	}
 	if err := o.I18n.Validate(); err != nil {
 		return errors.Tag(err, "i18n is invalid")
 	}
@@ -148,11 +182,32 @@ func (o *Options) Validate() error {
 			Msg: "learn_image_cache_base is missing",
 		}
 	}
+	if o.MaxFilesPerUpload < 0 ||
+		o.MaxFilesPerUpload > constants.MaxFilesPerProject {
+		return &errors.ValidationError{
+			Msg: fmt.Sprintf(
+				"max_files_per_upload must be between 0 and %d",
+				constants.MaxFilesPerProject,
+			),
+		}
+	}
 	if o.ManifestPath == "" {
 		return &errors.ValidationError{
 			Msg: "manifest_path is missing, use 'cdn' for download at boot",
 		}
 	}
+	if err := o.UploadImageDownscaling.Validate(); err != nil {
+		return errors.Tag(err, "upload_image_downscaling is invalid")
+	}
+	if err := o.UploadScanner.Validate(); err != nil {
+		return errors.Tag(err, "upload_scanner is invalid")
+	}
+	if err := o.Thumbnails.Validate(); err != nil {
+		return errors.Tag(err, "thumbnails is invalid")
+	}
+	if err := o.ProjectDownload.Validate(); err != nil {
+		return errors.Tag(err, "project_download is invalid")
+	}
 	if err := o.SiteURL.Validate(); err != nil {
 		return errors.Tag(err, "site_url is invalid")
 	}
@@ -186,6 +241,14 @@ func (o *Options) Validate() error {
 	if err := o.Email.SMTPAddress.Validate(); err != nil {
 		return errors.Tag(err, "email.smtp_address is invalid")
 	}
+	if o.Email.DKIM.IsConfigured() {
+		if err := o.Email.DKIM.Validate(); err != nil {
+			return errors.Tag(err, "email.dkim is invalid")
+		}
+	}
+	if err := o.Email.Retry.Validate(); err != nil {
+		return errors.Tag(err, "email.retry is invalid")
+	}
 	if !o.Email.SMTPAddress.IsSpecial() {
 		if o.Email.SMTPUser == "" {
 			return &errors.ValidationError{Msg: "email.smtp_user is missing"}
@@ -226,15 +289,57 @@ func (o *Options) Validate() error {
 	if err := o.SessionCookie.Validate(); err != nil {
 		return errors.Tag(err, "session_cookie is invalid")
 	}
+	if err := o.SnapshotEncryption.Validate(); err != nil {
+		return errors.Tag(err, "snapshot_encryption is invalid")
+	}
 
 	if o.RateLimits.LinkSharingTokenLookupConcurrency < 1 {
 		return errors.Tag(&errors.ValidationError{
 			Msg: "link_sharing_token_lookup_concurrency must be at least 1",
 		}, "rate_limits is invalid")
 	}
+	if o.RateLimits.ProjectInviteResendInterval < 0 {
+		return errors.Tag(&errors.ValidationError{
+			Msg: "project_invite_resend_interval must not be negative",
+		}, "rate_limits is invalid")
+	}
 	return nil
 }
 
+type PublicAccessLevels []project.PublicAccessLevel
+
+func (p PublicAccessLevels) Validate() error {
+	for _, l := range p {
+		if err := l.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Allows checks whether the given level may be used. An empty list allows
+// all levels.
+func (p PublicAccessLevels) Allows(l project.PublicAccessLevel) bool {
+	if len(p) == 0 || l == project.PrivateAccess {
+		return true
+	}
+	for _, allowed := range p {
+		if l == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDefaultCompiler returns the compiler for new projects that do not
+// specify one.
+func (o *Options) GetDefaultCompiler() sharedTypes.Compiler {
+	if o.DefaultCompiler == "" {
+		return project.DefaultCompiler
+	}
+	return o.DefaultCompiler
+}
+
 func (o *Options) AssetsOptions() assets.Options {
 	return assets.Options{
 		SiteURL:       o.SiteURL,
@@ -249,16 +354,38 @@ type EmailOptions struct {
 	Send   *email.SendOptions
 }
 
+func (o *Options) smtpAuth() smtp.Auth {
+	if o.Email.SMTPAddress.IsSpecial() {
+		return nil
+	}
+	return smtp.PlainAuth(
+		o.Email.SMTPIdentity,
+		o.Email.SMTPUser,
+		o.Email.SMTPPassword,
+		o.Email.SMTPAddress.Host(),
+	)
+}
+
+// UsePooledEmailSender shares a single persistent SMTP connection across
+// all subsequent EmailOptions.
+func (o *Options) UsePooledEmailSender() {
+	o.emailSender = email.NewPooledSender(
+		o.Email.SMTPAddress, o.Email.SMTPHello, o.smtpAuth(),
+	)
+}
+
 func (o *Options) EmailOptions() *EmailOptions {
-	var a smtp.Auth
-	if !o.Email.SMTPAddress.IsSpecial() {
-		a = smtp.PlainAuth(
-			o.Email.SMTPIdentity,
-			o.Email.SMTPUser,
-			o.Email.SMTPPassword,
-			o.Email.SMTPAddress.Host(),
+	sender := o.emailSender
+	if sender == nil {
+		sender = email.NewSender(
+			o.Email.SMTPAddress, o.Email.SMTPHello, o.smtpAuth(),
 		)
 	}
+	var dkim *email.DKIMSigner
+	if o.Email.DKIM.IsConfigured() {
+		// The key has been checked in Validate already.
+		dkim, _ = o.Email.DKIM.NewSigner()
+	}
 	return &EmailOptions{
 		Public: &email.PublicOptions{
 			AppName:          o.AppName,
@@ -267,15 +394,116 @@ func (o *Options) EmailOptions() *EmailOptions {
 			SiteURL:          o.SiteURL.String(),
 		},
 		Send: &email.SendOptions{
+			DKIM:            dkim,
 			From:            o.Email.From,
 			FallbackReplyTo: o.Email.FallbackReplyTo,
-			Sender: email.NewSender(
-				o.Email.SMTPAddress, o.Email.SMTPHello, a,
-			),
+			Retry:           o.Email.Retry,
+			Sender:          sender,
 		},
 	}
 }
 
+// ImageDownscalingOptions configures the downscaling of uploaded png/jpeg
+// images with a width or height above MaxDimension pixels. Files smaller
+// than MinFileSize bytes are stored as-is. A zero MaxDimension disables
+// the downscaling.
+type ImageDownscalingOptions struct {
+	MaxDimension int   `json:"max_dimension"`
+	MinFileSize  int64 `json:"min_file_size"`
+}
+
+func (o *ImageDownscalingOptions) Validate() error {
+	if o.MaxDimension < 0 {
+		return &errors.ValidationError{
+			Msg: "max_dimension must not be negative",
+		}
+	}
+	if o.MinFileSize < 0 {
+		return &errors.ValidationError{
+			Msg: "min_file_size must not be negative",
+		}
+	}
+	return nil
+}
+
+// UploadScannerOptions configures an external scanner for uploads, e.g.
+// a virus scanner. The Command receives the uploaded file on stdin and
+// rejects it by exiting with a non-zero code. Scanning is disabled when
+// Command is empty.
+type UploadScannerOptions struct {
+	Command []string      `json:"command"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+func (o *UploadScannerOptions) Validate() error {
+	if len(o.Command) > 0 && o.Command[0] == "" {
+		return &errors.ValidationError{Msg: "command is missing binary"}
+	}
+	if o.Timeout < 0 {
+		return &errors.ValidationError{Msg: "timeout must not be negative"}
+	}
+	return nil
+}
+
+// ThumbnailOptions configures the on-demand generation of thumbnails for
+// image and PDF files. Thumbnails are disabled when MaxDimension is 0.
+// The PDFCommand receives the PDF file on stdin and writes a png/jpeg
+// image of the first page to stdout, e.g. using ghostscript:
+//
+//	["gs", "-q", "-dSAFER", "-dBATCH", "-dNOPAUSE", "-sDEVICE=png16m",
+//	 "-dFirstPage=1", "-dLastPage=1", "-r50", "-sOutputFile=-", "-"]
+//
+// PDF files do not get thumbnails when PDFCommand is empty.
+// Concurrency limits how many thumbnails get generated in parallel and
+// defaults to 2. Cached thumbnails get deleted after MaxAge, which
+// defaults to 30 days.
+type ThumbnailOptions struct {
+	Concurrency  int           `json:"concurrency"`
+	MaxAge       time.Duration `json:"max_age"`
+	MaxDimension int           `json:"max_dimension"`
+	PDFCommand   []string      `json:"pdf_command"`
+	Timeout      time.Duration `json:"timeout"`
+}
+
+func (o *ThumbnailOptions) Validate() error {
+	if o.Concurrency < 0 {
+		return &errors.ValidationError{
+			Msg: "concurrency must not be negative",
+		}
+	}
+	if o.MaxAge < 0 {
+		return &errors.ValidationError{Msg: "max_age must not be negative"}
+	}
+	if o.MaxDimension < 0 {
+		return &errors.ValidationError{
+			Msg: "max_dimension must not be negative",
+		}
+	}
+	if len(o.PDFCommand) > 0 && o.PDFCommand[0] == "" {
+		return &errors.ValidationError{Msg: "pdf_command is missing binary"}
+	}
+	if o.Timeout < 0 {
+		return &errors.ValidationError{Msg: "timeout must not be negative"}
+	}
+	return nil
+}
+
+// ProjectDownloadOptions configures the download of multiple projects.
+// MultiProjectConcurrency limits how many projects get fetched in parallel,
+// it defaults to 4.
+type ProjectDownloadOptions struct {
+	MultiProjectConcurrency int `json:"multi_project_concurrency"`
+}
+
+func (o *ProjectDownloadOptions) Validate() error {
+	if o.MultiProjectConcurrency < 0 {
+		return &errors.ValidationError{
+			Msg: "multi_project_concurrency must not be negative",
+		}
+	}
+	return nil
+}
+
 type SentryOptions struct {
 	Frontend templates.SentryFrontendOptions `json:"frontend"`
 }
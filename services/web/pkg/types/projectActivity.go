@@ -0,0 +1,79 @@
+// Golang port of Overleaf
+// Copyright (C) 2024 Jakob Ackermann <das7pad@outlook.com>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"net/url"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/message"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
+)
+
+type ProjectActivityKind string
+
+const (
+	ProjectActivityAudit       ProjectActivityKind = "audit"
+	ProjectActivityChatMessage ProjectActivityKind = "chat-message"
+	ProjectActivityEdit        ProjectActivityKind = "edit"
+)
+
+type ProjectActivity struct {
+	Kind        ProjectActivityKind    `json:"kind"`
+	Timestamp   sharedTypes.Timestamp  `json:"timestamp"`
+	Audit       *project.AuditLogEntry `json:"audit,omitempty"`
+	ChatMessage *message.Message       `json:"chatMessage,omitempty"`
+	Edit        *ProjectHistoryUpdate  `json:"edit,omitempty"`
+}
+
+// Id breaks ties between activities with the same timestamp. Edits have no
+// id and sort after other activities with the same timestamp.
+func (a *ProjectActivity) Id() sharedTypes.UUID {
+	switch a.Kind {
+	case ProjectActivityAudit:
+		return a.Audit.Id
+	case ProjectActivityChatMessage:
+		return a.ChatMessage.Id
+	default:
+		return sharedTypes.UUID{}
+	}
+}
+
+type GetProjectActivityRequest struct {
+	WithProjectIdAndUserId
+	Before   sharedTypes.Timestamp `form:"before"`
+	BeforeId sharedTypes.UUID      `form:"before_id"`
+}
+
+func (r *GetProjectActivityRequest) FromQuery(q url.Values) error {
+	if err := r.Before.ParseIfSet(q.Get("before")); err != nil {
+		return errors.Tag(err, "query parameter 'before'")
+	}
+	if s := q.Get("before_id"); s != "" {
+		if err := r.BeforeId.UnmarshalText([]byte(s)); err != nil {
+			return errors.Tag(err, "query parameter 'before_id'")
+		}
+	}
+	return nil
+}
+
+type GetProjectActivityResponse struct {
+	Activities          []ProjectActivity     `json:"activities"`
+	NextBeforeTimestamp sharedTypes.Timestamp `json:"nextBeforeTimestamp,omitempty"`
+	NextBeforeId        *sharedTypes.UUID     `json:"nextBeforeId,omitempty"`
+}
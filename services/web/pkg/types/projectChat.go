@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,6 +18,7 @@ package types
 
 import (
 	"net/url"
+	"strings"
 
 	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/models/message"
@@ -27,19 +28,83 @@ import (
 type GetProjectChatMessagesRequest struct {
 	ProjectId sharedTypes.UUID      `form:"-"`
 	Before    sharedTypes.Timestamp `form:"before"`
+	BeforeId  sharedTypes.UUID      `form:"before_id"`
 }
 
 func (r *GetProjectChatMessagesRequest) FromQuery(q url.Values) error {
 	if err := r.Before.ParseIfSet(q.Get("before")); err != nil {
 		return errors.Tag(err, "query parameter 'before'")
 	}
+	if s := q.Get("before_id"); s != "" {
+		if err := r.BeforeId.UnmarshalText([]byte(s)); err != nil {
+			return errors.Tag(err, "query parameter 'before_id'")
+		}
+	}
 	return nil
 }
 
-type GetProjectChatMessagesResponse = []message.Message
+type GetProjectChatMessagesResponse struct {
+	Messages     []message.Message `json:"messages"`
+	NextBeforeId *sharedTypes.UUID `json:"nextBeforeId,omitempty"`
+}
+
+type SearchProjectChatMessagesRequest struct {
+	ProjectId sharedTypes.UUID `form:"-"`
+	Query     string           `form:"q"`
+}
+
+const maxChatSearchQueryLength = 200
+
+func (r *SearchProjectChatMessagesRequest) FromQuery(q url.Values) error {
+	r.Query = q.Get("q")
+	return nil
+}
+
+func (r *SearchProjectChatMessagesRequest) Validate() error {
+	if strings.TrimSpace(r.Query) == "" {
+		return &errors.ValidationError{Msg: "missing search query"}
+	}
+	if len(r.Query) > maxChatSearchQueryLength {
+		return &errors.ValidationError{Msg: "search query too long"}
+	}
+	return nil
+}
+
+type SearchProjectChatMessagesResponse struct {
+	Messages []message.Message `json:"messages"`
+}
 
 type SendProjectChatMessageRequest struct {
 	ProjectId sharedTypes.UUID `json:"-"`
 	UserId    sharedTypes.UUID `json:"-"`
 	Content   string           `json:"content"`
 }
+
+type EditProjectChatMessageRequest struct {
+	ProjectId sharedTypes.UUID `json:"-"`
+	UserId    sharedTypes.UUID `json:"-"`
+	MessageId sharedTypes.UUID `json:"-"`
+	Content   string           `json:"content"`
+}
+
+type ReactToProjectChatMessageRequest struct {
+	ProjectId sharedTypes.UUID `json:"-"`
+	UserId    sharedTypes.UUID `json:"-"`
+	MessageId sharedTypes.UUID `json:"-"`
+	Emoji     message.Emoji    `json:"emoji"`
+}
+
+type DeleteProjectChatMessageRequest struct {
+	ProjectId sharedTypes.UUID `json:"-"`
+	UserId    sharedTypes.UUID `json:"-"`
+	MessageId sharedTypes.UUID `json:"-"`
+}
+
+type ExportProjectChatRequest struct {
+	ProjectId sharedTypes.UUID `json:"-"`
+}
+
+type ExportProjectChatResponse struct {
+	Filename   sharedTypes.Filename
+	Transcript []byte
+}
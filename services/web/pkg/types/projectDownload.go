@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,6 +17,7 @@
 package types
 
 import (
+	"io"
 	"net/url"
 	"os"
 	"strings"
@@ -26,12 +27,44 @@ import (
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
+type ArchiveFormat string
+
+const (
+	ArchiveFormatZIP   ArchiveFormat = "zip"
+	ArchiveFormatTarGz ArchiveFormat = "targz"
+)
+
+func (f ArchiveFormat) Validate() error {
+	switch f {
+	case "", ArchiveFormatZIP, ArchiveFormatTarGz:
+		return nil
+	default:
+		return &errors.ValidationError{Msg: "unknown archive format"}
+	}
+}
+
+func (f ArchiveFormat) Extension() string {
+	if f == ArchiveFormatTarGz {
+		return ".tar.gz"
+	}
+	return ".zip"
+}
+
+func (f ArchiveFormat) ContentType() string {
+	if f == ArchiveFormatTarGz {
+		return "application/gzip"
+	}
+	return "application/zip"
+}
+
 type CreateMultiProjectZIPRequest struct {
 	WithSession
+	Format     ArchiveFormat      `json:"-"`
 	ProjectIds []sharedTypes.UUID `json:"-"`
 }
 
 func (r *CreateMultiProjectZIPRequest) FromQuery(q url.Values) error {
+	r.Format = ArchiveFormat(q.Get("format"))
 	for _, raw := range strings.Split(q.Get("project_ids"), ",") {
 		id, err := m2pq.ParseID(raw)
 		if err != nil {
@@ -48,12 +81,47 @@ func (r *CreateMultiProjectZIPRequest) Validate() error {
 	if len(r.ProjectIds) == 0 {
 		return &errors.ValidationError{Msg: "must provide at least one project id"}
 	}
+	if err := r.Format.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
 type CreateProjectZIPRequest struct {
 	WithSession
+	Format    ArchiveFormat    `json:"-"`
 	ProjectId sharedTypes.UUID `json:"-"`
+
+	// Path limits the archive to the given folder.
+	Path sharedTypes.DirName `json:"-"`
+}
+
+func (r *CreateProjectZIPRequest) FromQuery(q url.Values) error {
+	r.Format = ArchiveFormat(q.Get("format"))
+	if s := strings.Trim(q.Get("path"), "/"); s != "" {
+		r.Path = sharedTypes.DirName(s + "/")
+	}
+	return nil
+}
+
+func (r *CreateProjectZIPRequest) Validate() error {
+	if err := r.Format.Validate(); err != nil {
+		return err
+	}
+	if r.Path != "" {
+		if err := r.Path.Validate(); err != nil {
+			return errors.Tag(err, "path")
+		}
+	}
+	return nil
+}
+
+type StreamProjectZIPRequest struct {
+	CreateProjectZIPRequest
+
+	// GetWriter is called with the filename of the archive prior to
+	// writing it.
+	GetWriter func(filename sharedTypes.Filename) (io.Writer, error) `json:"-"`
 }
 
 type CreateProjectZIPResponse struct {
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,13 +17,21 @@
 package types
 
 import (
+	"net/url"
+
 	"github.com/das7pad/overleaf-go/pkg/models/project"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
 type GetProjectEntitiesRequest struct {
 	WithSession
-	ProjectId sharedTypes.UUID `json:"-"`
+	ProjectId      sharedTypes.UUID `json:"-"`
+	IncludeSubType bool             `json:"-"`
+}
+
+func (r *GetProjectEntitiesRequest) FromQuery(q url.Values) error {
+	r.IncludeSubType = q.Get("includeSubType") == "true"
+	return nil
 }
 
 type GetProjectEntitiesResponse struct {
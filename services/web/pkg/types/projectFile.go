@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -19,6 +19,7 @@ package types
 import (
 	"io"
 
+	"github.com/das7pad/overleaf-go/pkg/models/project"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
@@ -40,7 +41,29 @@ type GetProjectFileRequest struct {
 }
 
 type GetProjectFileResponse struct {
-	Filename sharedTypes.Filename `json:"-"`
-	Reader   io.ReadSeekCloser    `json:"-"`
-	Size     int64                `json:"-"`
+	Filename    sharedTypes.Filename `json:"-"`
+	Reader      io.ReadSeekCloser    `json:"-"`
+	RedirectURL string               `json:"-"`
+	Size        int64                `json:"-"`
+}
+
+type GetProjectFileMetadataRequest struct {
+	WithSession
+	ProjectId sharedTypes.UUID `json:"-"`
+	FileId    sharedTypes.UUID `json:"-"`
+}
+
+type GetProjectFileMetadataResponse struct {
+	project.FileRef
+}
+
+type GetProjectFileThumbnailRequest struct {
+	WithSession
+	ProjectId sharedTypes.UUID `json:"-"`
+	FileId    sharedTypes.UUID `json:"-"`
+}
+
+type GetProjectFileThumbnailResponse struct {
+	Reader io.ReadSeekCloser `json:"-"`
+	Size   int64             `json:"-"`
 }
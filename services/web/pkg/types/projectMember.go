@@ -17,6 +17,9 @@
 package types
 
 import (
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/models/user"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
@@ -34,11 +37,34 @@ type ListProjectMembersResponse struct {
 	Members []user.AsProjectMember `json:"members"`
 }
 
+type ListProjectTokenMembersRequest struct {
+	WithProjectIdAndUserId
+}
+
+type ListProjectTokenMembersResponse struct {
+	Members []user.AsProjectTokenMember `json:"members"`
+}
+
 type RemoveProjectMemberRequest struct {
 	WithProjectIdAndUserId
 	MemberId sharedTypes.UUID `json:"-"`
 }
 
+type SetMemberAccessExpiresAtInProjectRequest struct {
+	WithProjectIdAndUserId
+	MemberId        sharedTypes.UUID `json:"-"`
+	AccessExpiresAt *time.Time       `json:"accessExpiresAt"`
+}
+
+func (r *SetMemberAccessExpiresAtInProjectRequest) Validate() error {
+	if r.AccessExpiresAt != nil && !r.AccessExpiresAt.After(time.Now()) {
+		return &errors.ValidationError{
+			Msg: "accessExpiresAt must be in the future",
+		}
+	}
+	return nil
+}
+
 type SetMemberPrivilegeLevelInProjectRequest struct {
 	WithProjectIdAndUserId
 	MemberId       sharedTypes.UUID           `json:"-"`
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,6 +17,10 @@
 package types
 
 import (
+	"net/url"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/project"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 )
 
@@ -39,6 +43,65 @@ type GetMetadataForDocResponse struct {
 	ProjectDocMetadata *ProjectDocMetadata `json:"meta,omitempty"`
 }
 
+type GetMetadataForDocsRequest struct {
+	ProjectId sharedTypes.UUID   `json:"-"`
+	DocIds    []sharedTypes.UUID `json:"docIds"`
+}
+
+func (r *GetMetadataForDocsRequest) Validate() error {
+	if len(r.DocIds) == 0 {
+		return &errors.ValidationError{Msg: "missing docIds"}
+	}
+	if len(r.DocIds) > 100 {
+		return &errors.ValidationError{Msg: "too many docIds"}
+	}
+	return nil
+}
+
+type GetMetadataForDocsResponse struct {
+	// ProjectMetadata omits docs that do not exist.
+	ProjectMetadata ProjectMetadata `json:"projectMeta"`
+}
+
+type GetBibliographyRequest struct {
+	ProjectId sharedTypes.UUID `json:"-"`
+}
+
+type BibEntry struct {
+	Key    string `json:"key"`
+	Title  string `json:"title,omitempty"`
+	Author string `json:"author,omitempty"`
+	Year   string `json:"year,omitempty"`
+}
+
+// BibConflict is a citation key that is defined in multiple bib files.
+type BibConflict struct {
+	Key   string                 `json:"key"`
+	Paths []sharedTypes.PathName `json:"paths"`
+}
+
+type GetBibliographyResponse struct {
+	Conflicts []BibConflict `json:"conflicts"`
+	Entries   []BibEntry    `json:"entries"`
+	Truncated bool          `json:"truncated,omitempty"`
+}
+
+type LintProjectRequest struct {
+	ProjectId sharedTypes.UUID `json:"-"`
+}
+
+type LintWarning struct {
+	Path    sharedTypes.PathName `json:"path"`
+	Line    int                  `json:"line"`
+	Column  int                  `json:"column"`
+	Message string               `json:"message"`
+}
+
+type LintProjectResponse struct {
+	Warnings  []LintWarning `json:"warnings"`
+	Truncated bool          `json:"truncated,omitempty"`
+}
+
 type SuggestedLatexCommand struct {
 	Caption string  `json:"caption"`
 	Snippet string  `json:"snippet"`
@@ -66,3 +129,45 @@ type ProjectDocMetadata struct {
 type LightProjectMetadata map[string]LightDocProjectMetadata
 
 type ProjectMetadata map[string]ProjectDocMetadata
+
+type GetDependencyGraphRequest struct {
+	ProjectId   sharedTypes.UUID     `json:"-"`
+	RootDocPath sharedTypes.PathName `form:"rootDocPath"`
+}
+
+func (r *GetDependencyGraphRequest) FromQuery(q url.Values) error {
+	r.RootDocPath = sharedTypes.PathName(q.Get("rootDocPath"))
+	if r.RootDocPath != "" {
+		if err := r.RootDocPath.Validate(); err != nil {
+			return errors.Tag(err, "query parameter 'rootDocPath'")
+		}
+	}
+	return nil
+}
+
+type DependencyGraphEdge struct {
+	From    sharedTypes.PathName `json:"from"`
+	To      sharedTypes.PathName `json:"to"`
+	Command string               `json:"command"`
+}
+
+type GetDependencyGraphResponse struct {
+	Root         sharedTypes.PathName   `json:"root"`
+	Nodes        []sharedTypes.PathName `json:"nodes"`
+	Edges        []DependencyGraphEdge  `json:"edges"`
+	Missing      []sharedTypes.PathName `json:"missing"`
+	Unreferenced []sharedTypes.PathName `json:"unreferenced"`
+}
+
+type ListUnusedFilesRequest = GetDependencyGraphRequest
+
+type UnusedFile struct {
+	Id   sharedTypes.UUID     `json:"id"`
+	Path sharedTypes.PathName `json:"path"`
+	Type project.TreeNodeKind `json:"type"`
+}
+
+type ListUnusedFilesResponse struct {
+	Root  sharedTypes.PathName `json:"root"`
+	Files []UnusedFile         `json:"files"`
+}
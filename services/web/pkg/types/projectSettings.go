@@ -17,6 +17,10 @@
 package types
 
 import (
+	"strconv"
+	"time"
+
+	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/models/user"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	spellingTypes "github.com/das7pad/overleaf-go/services/spelling/pkg/types"
@@ -42,6 +46,11 @@ type SetSpellCheckLanguageRequest struct {
 	SpellCheckLanguage spellingTypes.SpellCheckLanguage `json:"spellCheckLanguage"`
 }
 
+type SetSpellCheckLanguageForOwnedProjectsRequest struct {
+	WithSession
+	SpellCheckLanguage spellingTypes.SpellCheckLanguage `json:"spellCheckLanguage"`
+}
+
 type SetRootDocIdRequest struct {
 	WithProjectIdAndUserId
 	RootDocId sharedTypes.UUID `json:"rootDocId"`
@@ -51,3 +60,61 @@ type SetContentLockedRequest struct {
 	WithProjectIdAndUserId
 	ContentLocked bool `json:"contentLocked"`
 }
+
+const (
+	minMaxUnFlushedAge = 10 * time.Second
+	maxMaxUnFlushedAge = time.Hour
+)
+
+type SetMaxUnFlushedAgeRequest struct {
+	WithProjectIdAndUserId
+	// MaxUnFlushedAgeSeconds of 0 resets to the default flush interval.
+	MaxUnFlushedAgeSeconds int64 `json:"maxUnFlushedAgeSeconds"`
+}
+
+func (r *SetMaxUnFlushedAgeRequest) MaxUnFlushedAge() time.Duration {
+	return time.Duration(r.MaxUnFlushedAgeSeconds) * time.Second
+}
+
+func (r *SetMaxUnFlushedAgeRequest) Validate() error {
+	if r.MaxUnFlushedAgeSeconds == 0 {
+		return nil
+	}
+	d := r.MaxUnFlushedAge()
+	if d < minMaxUnFlushedAge || d > maxMaxUnFlushedAge {
+		return &errors.ValidationError{
+			Msg: "maxUnFlushedAgeSeconds must be 0 or between " +
+				strconv.FormatInt(int64(minMaxUnFlushedAge/time.Second), 10) +
+				" and " +
+				strconv.FormatInt(int64(maxMaxUnFlushedAge/time.Second), 10),
+		}
+	}
+	return nil
+}
+
+const (
+	minHistoryRetainForDays = 1
+	maxHistoryRetainForDays = 3650
+)
+
+type SetHistoryRetainForRequest struct {
+	WithProjectIdAndUserId
+	// HistoryRetainForDays of 0 resets to the default retention period.
+	HistoryRetainForDays int64 `json:"historyRetainForDays"`
+}
+
+func (r *SetHistoryRetainForRequest) Validate() error {
+	if r.HistoryRetainForDays == 0 {
+		return nil
+	}
+	d := r.HistoryRetainForDays
+	if d < minHistoryRetainForDays || d > maxHistoryRetainForDays {
+		return &errors.ValidationError{
+			Msg: "historyRetainForDays must be 0 or between " +
+				strconv.FormatInt(minHistoryRetainForDays, 10) +
+				" and " +
+				strconv.FormatInt(maxHistoryRetainForDays, 10),
+		}
+	}
+	return nil
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2022 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2022-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,6 +17,9 @@
 package types
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/das7pad/overleaf-go/pkg/errors"
 )
 
@@ -51,3 +54,50 @@ func (r *UnlearnWordRequest) Validate() error {
 	}
 	return nil
 }
+
+type ExportLearnedWordsRequest struct {
+	WithSession
+}
+
+type ExportLearnedWordsResponse struct {
+	Words []string
+}
+
+// String renders the words as a list with one word per line.
+func (r *ExportLearnedWordsResponse) String() string {
+	if len(r.Words) == 0 {
+		return ""
+	}
+	return strings.Join(r.Words, "\n") + "\n"
+}
+
+const maxImportLearnedWords = 10_000
+
+type ImportLearnedWordsRequest struct {
+	WithSession
+	Words []string `json:"words"`
+}
+
+// Preprocess drops blank lines from a list in the export format.
+func (r *ImportLearnedWordsRequest) Preprocess() {
+	words := r.Words[:0]
+	for _, w := range r.Words {
+		if w = strings.TrimSpace(w); w != "" {
+			words = append(words, w)
+		}
+	}
+	r.Words = words
+}
+
+func (r *ImportLearnedWordsRequest) Validate() error {
+	if len(r.Words) == 0 {
+		return &errors.ValidationError{Msg: "must set non empty words"}
+	}
+	if len(r.Words) > maxImportLearnedWords {
+		return &errors.ValidationError{
+			Msg: "too many words, limit is " +
+				strconv.FormatInt(maxImportLearnedWords, 10),
+		}
+	}
+	return nil
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -18,7 +18,9 @@ package types
 
 import (
 	"github.com/das7pad/overleaf-go/pkg/asyncForm"
+	"github.com/das7pad/overleaf-go/pkg/errors"
 	"github.com/das7pad/overleaf-go/pkg/models/project"
+	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	"github.com/das7pad/overleaf-go/pkg/templates"
 )
 
@@ -48,6 +50,23 @@ type SetPublicAccessLevelResponse struct {
 	Tokens *project.Tokens `json:"tokens,omitempty"`
 }
 
+type SetTokenReadAndWritePrivilegeLevelRequest struct {
+	WithProjectIdAndUserId
+	PrivilegeLevel sharedTypes.PrivilegeLevel `json:"privilegeLevel"`
+}
+
+func (r *SetTokenReadAndWritePrivilegeLevelRequest) Validate() error {
+	switch r.PrivilegeLevel {
+	case "":
+		// Reset to default.
+	case sharedTypes.PrivilegeLevelReadOnly:
+	case sharedTypes.PrivilegeLevelReadAndWrite:
+	default:
+		return &errors.ValidationError{Msg: "invalid privilegeLevel"}
+	}
+	return nil
+}
+
 type GetAccessTokensRequest struct {
 	WithProjectIdAndUserId
 }
@@ -55,3 +74,33 @@ type GetAccessTokensRequest struct {
 type GetAccessTokensResponse struct {
 	Tokens *project.Tokens `json:"tokens,omitempty"`
 }
+
+type PreviewTokenAccessRequest struct {
+	WithProjectIdAndUserId
+	PrivilegeLevel sharedTypes.PrivilegeLevel `json:"-"`
+}
+
+func (r *PreviewTokenAccessRequest) Preprocess() {
+	if r.PrivilegeLevel == "" {
+		r.PrivilegeLevel = sharedTypes.PrivilegeLevelReadOnly
+	}
+}
+
+func (r *PreviewTokenAccessRequest) Validate() error {
+	switch r.PrivilegeLevel {
+	case sharedTypes.PrivilegeLevelReadOnly:
+	case sharedTypes.PrivilegeLevelReadAndWrite:
+	default:
+		return &errors.ValidationError{Msg: "invalid privilegeLevel"}
+	}
+	return nil
+}
+
+type PreviewTokenAccessResponse struct {
+	AuthorizationDetails project.AuthorizationDetails `json:"authorizationDetails"`
+	Editable             bool                         `json:"editable"`
+	IsRestrictedUser     project.IsRestrictedUser     `json:"isRestrictedTokenMember"`
+	Project              project.LoadEditorViewPublic `json:"project"`
+	RequiresLogin        bool                         `json:"requiresLogin"`
+	RootDocPath          sharedTypes.PathName         `json:"rootDocPath"`
+}
@@ -1,5 +1,5 @@
 // Golang port of Overleaf
-// Copyright (C) 2021-2023 Jakob Ackermann <das7pad@outlook.com>
+// Copyright (C) 2021-2024 Jakob Ackermann <das7pad@outlook.com>
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU Affero General Public License as published
@@ -17,28 +17,41 @@
 package types
 
 import (
+	"strings"
+	"time"
+
 	"github.com/das7pad/overleaf-go/pkg/asyncForm"
 	"github.com/das7pad/overleaf-go/pkg/errors"
+	"github.com/das7pad/overleaf-go/pkg/models/user"
 	"github.com/das7pad/overleaf-go/pkg/sharedTypes"
 	"github.com/das7pad/overleaf-go/pkg/templates"
 )
 
-func NewCMDCreateUserRequest(email sharedTypes.Email, adminUserId sharedTypes.UUID) CMDCreateUserRequest {
+func NewCMDCreateUserRequest(email sharedTypes.Email, adminUserId sharedTypes.UUID, tokenExpiry time.Duration) CMDCreateUserRequest {
 	return CMDCreateUserRequest{
 		fromCMD:     true,
 		Email:       email,
 		InitiatorId: adminUserId,
+		TokenExpiry: tokenExpiry,
 	}
 }
 
 type CMDCreateUserRequest struct {
 	fromCMD     bool
 	Email       sharedTypes.Email
+	FirstName   string
 	InitiatorId sharedTypes.UUID
+	LastName    string
+	TokenExpiry time.Duration
 }
 
 func (r *CMDCreateUserRequest) Preprocess() {
 	r.Email = r.Email.Normalize()
+	r.FirstName = strings.TrimSpace(r.FirstName)
+	r.LastName = strings.TrimSpace(r.LastName)
+	if r.TokenExpiry == 0 {
+		r.TokenExpiry = user.NewUserOneTimeTokenExpiry
+	}
 }
 
 func (r *CMDCreateUserRequest) Validate() error {
@@ -48,6 +61,15 @@ func (r *CMDCreateUserRequest) Validate() error {
 	if err := r.Email.Validate(); err != nil {
 		return err
 	}
+	if r.TokenExpiry < 0 {
+		return &errors.ValidationError{Msg: "token expiry must be positive"}
+	}
+	if r.TokenExpiry > user.NewUserOneTimeTokenExpiry {
+		return &errors.ValidationError{
+			Msg: "token expiry must not exceed " +
+				user.NewUserOneTimeTokenExpiry.String(),
+		}
+	}
 	return nil
 }
 